@@ -43,6 +43,17 @@ type cliParams struct {
 	dsdCaptureDuration   time.Duration
 	dsdCaptureFilePath   string
 	dsdCaptureCompressed bool
+
+	// inspect subcommand
+	inspectFilePath   string
+	inspectFormat     string
+	inspectNames      []string
+	inspectTags       []string
+	inspectPIDs       []int
+	inspectSummary    bool
+	inspectTop        int
+	inspectOutputPath string
+	inspectCompressed bool
 }
 
 // Commands returns a slice of subcommands for the 'agent' command.
@@ -68,6 +79,32 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	dogstatsdCaptureCmd.Flags().StringVarP(&cliParams.dsdCaptureFilePath, "path", "p", "", "Directory path to write the capture to.")
 	dogstatsdCaptureCmd.Flags().BoolVarP(&cliParams.dsdCaptureCompressed, "compressed", "z", true, "Should capture be zstd compressed.")
 
+	inspectCmd := &cobra.Command{
+		Use:   "inspect <file>",
+		Short: "Inspect the packets of a dogstatsd traffic capture",
+		Long: `Print the packets of a dogstatsd traffic capture file with their timestamp and origin PID,
+summarize the top metrics and contexts by volume, or write the matching packets to a new capture file.
+The packets can be filtered by metric name, tag and origin PID.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliParams.inspectFilePath = args[0]
+			return fxutil.OneShot(dogstatsdCaptureInspect,
+				fx.Supply(cliParams),
+				fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
+				core.Bundle(),
+			)
+		},
+	}
+	inspectCmd.Flags().StringVarP(&cliParams.inspectFormat, "format", "f", formatText, "Output format, text or json.")
+	inspectCmd.Flags().StringArrayVarP(&cliParams.inspectNames, "name", "n", nil, "Only keep the metrics, events and service checks with this name, shell patterns like 'http.*' are supported. Can be repeated.")
+	inspectCmd.Flags().StringArrayVarP(&cliParams.inspectTags, "tag", "t", nil, "Only keep the messages with this tag. Can be repeated, all the tags must match.")
+	inspectCmd.Flags().IntSliceVar(&cliParams.inspectPIDs, "pid", nil, "Only keep the packets sent by this origin PID. Can be repeated.")
+	inspectCmd.Flags().BoolVarP(&cliParams.inspectSummary, "summary", "s", false, "Print the top metrics and contexts by volume instead of the packets.")
+	inspectCmd.Flags().IntVar(&cliParams.inspectTop, "top", 10, "Number of metrics and contexts in the summary, 0 for all of them.")
+	inspectCmd.Flags().StringVarP(&cliParams.inspectOutputPath, "output", "o", "", "Write the matching packets to this new capture file instead of printing them.")
+	inspectCmd.Flags().BoolVarP(&cliParams.inspectCompressed, "compressed", "z", false, "Should the output capture be zstd compressed.")
+	dogstatsdCaptureCmd.AddCommand(inspectCmd)
+
 	// shut up grpc client!
 	grpclog.SetLoggerV2(grpclog.NewLoggerV2(io.Discard, io.Discard, io.Discard))
 
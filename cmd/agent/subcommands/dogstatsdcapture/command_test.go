@@ -28,3 +28,21 @@ func TestCommand(t *testing.T) {
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
+
+func TestInspectCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"dogstatsd-capture", "inspect", "capture.dog", "-f", "json", "-n", "http.*", "-t", "env:prod", "--pid", "10,20", "-s", "--top", "5", "-o", "filtered.dog"},
+		dogstatsdCaptureInspect,
+		func(cliParams *cliParams) {
+			require.Equal(t, "capture.dog", cliParams.inspectFilePath)
+			require.Equal(t, "json", cliParams.inspectFormat)
+			require.Equal(t, []string{"http.*"}, cliParams.inspectNames)
+			require.Equal(t, []string{"env:prod"}, cliParams.inspectTags)
+			require.Equal(t, []int{10, 20}, cliParams.inspectPIDs)
+			require.True(t, cliParams.inspectSummary)
+			require.Equal(t, 5, cliParams.inspectTop)
+			require.Equal(t, "filtered.dog", cliParams.inspectOutputPath)
+			require.False(t, cliParams.inspectCompressed)
+		})
+}
@@ -0,0 +1,449 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package dogstatsdcapture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/zstd"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+
+	kindMetric       = "metric"
+	kindEvent        = "event"
+	kindServiceCheck = "service_check"
+)
+
+// dogstatsdMessage is a message of a captured packet
+type dogstatsdMessage struct {
+	Kind string   `json:"kind"`
+	Name string   `json:"name"`
+	Type string   `json:"type,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	Raw  string   `json:"raw"`
+}
+
+// capturedPacket is a packet of a capture file with its matching messages
+type capturedPacket struct {
+	Timestamp time.Time          `json:"timestamp"`
+	PID       int32              `json:"pid"`
+	Container string             `json:"container,omitempty"`
+	Messages  []dogstatsdMessage `json:"messages"`
+}
+
+// captureFilter selects the messages of a capture file, an empty field
+// matches every message.
+type captureFilter struct {
+	// names are shell patterns matching the metric, event or service check names
+	names []string
+	// tags must all be set on the message
+	tags []string
+	pids []int32
+}
+
+func (f captureFilter) matchPID(pid int32) bool {
+	return len(f.pids) == 0 || slices.Contains(f.pids, pid)
+}
+
+func (f captureFilter) match(msg dogstatsdMessage) bool {
+	if len(f.names) > 0 && !slices.ContainsFunc(f.names, func(pattern string) bool {
+		matched, _ := path.Match(pattern, msg.Name)
+		return matched
+	}) {
+		return false
+	}
+	for _, tag := range f.tags {
+		if !slices.Contains(msg.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseMessage extracts the kind, name, type and tags of a DogStatsD message.
+func parseMessage(raw []byte) dogstatsdMessage {
+	msg := dogstatsdMessage{Raw: string(raw)}
+
+	var fields [][]byte
+	switch {
+	case bytes.HasPrefix(raw, []byte("_e{")):
+		// _e{<TITLE_LENGTH>,<TEXT_LENGTH>}:<TITLE>|<TEXT>|<METADATA>
+		msg.Kind = kindEvent
+		header, rest, found := bytes.Cut(raw[len("_e{"):], []byte("}:"))
+		titleLength, _, _ := bytes.Cut(header, []byte(","))
+		n, err := strconv.Atoi(string(titleLength))
+		if !found || err != nil || n < 0 || n > len(rest) {
+			return msg
+		}
+		msg.Name = string(rest[:n])
+		fields = bytes.Split(rest[n:], []byte("|"))
+	case bytes.HasPrefix(raw, []byte("_sc|")):
+		// _sc|<NAME>|<STATUS>|<METADATA>
+		msg.Kind = kindServiceCheck
+		fields = bytes.Split(raw[len("_sc|"):], []byte("|"))
+		msg.Name = string(fields[0])
+	default:
+		// <METRIC_NAME>:<VALUE>|<TYPE>|<METADATA>
+		msg.Kind = kindMetric
+		fields = bytes.Split(raw, []byte("|"))
+		name, _, _ := bytes.Cut(fields[0], []byte(":"))
+		msg.Name = string(name)
+		if len(fields) > 1 {
+			msg.Type = string(fields[1])
+		}
+	}
+
+	for _, field := range fields[1:] {
+		if bytes.HasPrefix(field, []byte("#")) && len(field) > 1 {
+			msg.Tags = strings.Split(string(field[1:]), ",")
+		}
+	}
+	return msg
+}
+
+// contextKey identifies the context of a message by its name and sorted tags.
+func contextKey(msg dogstatsdMessage) string {
+	tags := slices.Clone(msg.Tags)
+	sort.Strings(tags)
+	return msg.Name + "{" + strings.Join(tags, ",") + "}"
+}
+
+type volume struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	Bytes int    `json:"bytes"`
+}
+
+// captureSummary aggregates the volume of the matching messages.
+type captureSummary struct {
+	Packets     int       `json:"packets"`
+	Messages    int       `json:"messages"`
+	Bytes       int       `json:"bytes"`
+	First       time.Time `json:"first"`
+	Last        time.Time `json:"last"`
+	PIDs        int       `json:"pids"`
+	TopMetrics  []volume  `json:"top_metrics"`
+	TopContexts []volume  `json:"top_contexts"`
+
+	pids     map[int32]struct{}
+	metrics  map[string]*volume
+	contexts map[string]*volume
+}
+
+func newCaptureSummary() *captureSummary {
+	return &captureSummary{
+		pids:     make(map[int32]struct{}),
+		metrics:  make(map[string]*volume),
+		contexts: make(map[string]*volume),
+	}
+}
+
+func (s *captureSummary) add(packet capturedPacket) {
+	s.Packets++
+	if s.First.IsZero() || packet.Timestamp.Before(s.First) {
+		s.First = packet.Timestamp
+	}
+	if packet.Timestamp.After(s.Last) {
+		s.Last = packet.Timestamp
+	}
+	s.pids[packet.PID] = struct{}{}
+
+	for _, msg := range packet.Messages {
+		s.Messages++
+		s.Bytes += len(msg.Raw)
+		addVolume(s.metrics, msg.Name, len(msg.Raw))
+		addVolume(s.contexts, contextKey(msg), len(msg.Raw))
+	}
+}
+
+func addVolume(volumes map[string]*volume, name string, size int) {
+	v, ok := volumes[name]
+	if !ok {
+		v = &volume{Name: name}
+		volumes[name] = v
+	}
+	v.Count++
+	v.Bytes += size
+}
+
+// finalize computes the top metrics and contexts by number of messages.
+func (s *captureSummary) finalize(top int) {
+	s.PIDs = len(s.pids)
+	s.TopMetrics = topVolumes(s.metrics, top)
+	s.TopContexts = topVolumes(s.contexts, top)
+}
+
+func topVolumes(volumes map[string]*volume, top int) []volume {
+	result := make([]volume, 0, len(volumes))
+	for _, v := range volumes {
+		result = append(result, *v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	if top > 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
+
+func (s *captureSummary) render(w io.Writer, format string) error {
+	if format == formatJSON {
+		return json.NewEncoder(w).Encode(s)
+	}
+
+	fmt.Fprintf(w, "Packets:     %d\n", s.Packets)
+	fmt.Fprintf(w, "Messages:    %d\n", s.Messages)
+	fmt.Fprintf(w, "Bytes:       %d\n", s.Bytes)
+	fmt.Fprintf(w, "Origin PIDs: %d\n", s.PIDs)
+	if s.Packets > 0 {
+		fmt.Fprintf(w, "From:        %s\n", s.First.Format(time.RFC3339Nano))
+		fmt.Fprintf(w, "To:          %s\n", s.Last.Format(time.RFC3339Nano))
+	}
+
+	for _, section := range []struct {
+		title   string
+		volumes []volume
+	}{
+		{"Top metrics by volume", s.TopMetrics},
+		{"Top contexts by volume", s.TopContexts},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  MESSAGES\tBYTES\tNAME")
+		for _, v := range section.volumes {
+			fmt.Fprintf(tw, "  %d\t%d\t%s\n", v.Count, v.Bytes, v.Name)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderPacket prints a packet as a JSON line, or as a line per message.
+func renderPacket(w io.Writer, packet capturedPacket, format string) error {
+	if format == formatJSON {
+		return json.NewEncoder(w).Encode(packet)
+	}
+
+	origin := fmt.Sprintf("pid=%d", packet.PID)
+	if packet.Container != "" {
+		origin += " container=" + packet.Container
+	}
+	for _, msg := range packet.Messages {
+		if _, err := fmt.Fprintf(w, "%s %s %s\n", packet.Timestamp.Format(time.RFC3339Nano), origin, msg.Raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// captureInspector reads the packets of a capture file and passes the ones
+// with matching messages to its outputs.
+type captureInspector struct {
+	filter captureFilter
+	format string
+
+	// packets receives the matching packets, nil when they are not printed
+	packets io.Writer
+	// summary aggregates the matching packets, nil when no summary is requested
+	summary *captureSummary
+	// capture receives the matching packets in the capture file format, nil
+	// when no filtered capture is written
+	capture io.Writer
+}
+
+func (i *captureInspector) run(reader *replay.TrafficCaptureReader) error {
+	pidMap, state, err := reader.ReadState()
+	if err != nil {
+		// captures older than version 2 do not contain the tagger state
+		pidMap, state = nil, nil
+	}
+
+	tsResolution := time.Nanosecond
+	if reader.Version < 3 {
+		tsResolution = time.Second
+	}
+
+	if i.capture != nil {
+		if err := replay.WriteHeader(i.capture); err != nil {
+			return err
+		}
+	}
+
+	reader.Seek(0)
+	for {
+		msg, err := reader.ReadNext()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("unable to read the capture file: %w", err)
+		}
+
+		if !i.filter.matchPID(msg.Pid) {
+			continue
+		}
+
+		packet := capturedPacket{
+			Timestamp: time.Unix(0, msg.Timestamp*int64(tsResolution)).UTC(),
+			PID:       msg.Pid,
+			Container: pidMap[msg.Pid],
+		}
+		var payload [][]byte
+		for _, raw := range bytes.Split(msg.Payload[:msg.PayloadSize], []byte("\n")) {
+			if len(raw) == 0 {
+				continue
+			}
+			if m := parseMessage(raw); i.filter.match(m) {
+				packet.Messages = append(packet.Messages, m)
+				payload = append(payload, raw)
+			}
+		}
+		if len(packet.Messages) == 0 {
+			continue
+		}
+
+		if i.packets != nil {
+			if err := renderPacket(i.packets, packet, i.format); err != nil {
+				return err
+			}
+		}
+		if i.summary != nil {
+			i.summary.add(packet)
+		}
+		if i.capture != nil {
+			filtered := bytes.Join(payload, []byte("\n"))
+			// the filtered capture has the current version, whose timestamps are in nanoseconds
+			err := replay.WriteMessage(i.capture, &pb.UnixDogstatsdMsg{
+				Timestamp:     msg.Timestamp * int64(tsResolution),
+				PayloadSize:   int32(len(filtered)),
+				Payload:       filtered,
+				Pid:           msg.Pid,
+				AncillarySize: msg.AncillarySize,
+				Ancillary:     msg.Ancillary,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if i.capture != nil {
+		if _, err := replay.WriteState(i.capture, &pb.TaggerState{State: state, PidMap: pidMap}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//nolint:revive // TODO(AML) Fix revive linter
+func dogstatsdCaptureInspect(_ log.Component, _ config.Component, cliParams *cliParams) error {
+	if cliParams.inspectFormat != formatText && cliParams.inspectFormat != formatJSON {
+		return fmt.Errorf("unknown format %q, the format must be %s or %s", cliParams.inspectFormat, formatText, formatJSON)
+	}
+
+	reader, err := replay.NewTrafficCaptureReader(cliParams.inspectFilePath, 1, true)
+	if reader != nil {
+		defer reader.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", cliParams.inspectFilePath, err)
+	}
+
+	inspector := &captureInspector{
+		filter: captureFilter{
+			names: cliParams.inspectNames,
+			tags:  cliParams.inspectTags,
+		},
+		format: cliParams.inspectFormat,
+	}
+	for _, pid := range cliParams.inspectPIDs {
+		inspector.filter.pids = append(inspector.filter.pids, int32(pid))
+	}
+
+	stdout := bufio.NewWriter(os.Stdout)
+	defer stdout.Flush()
+
+	// the packets are only printed when neither a summary nor a filtered
+	// capture is requested
+	if cliParams.inspectSummary {
+		inspector.summary = newCaptureSummary()
+	}
+	var closeCapture func() error
+	if cliParams.inspectOutputPath != "" {
+		f, err := os.OpenFile(cliParams.inspectOutputPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		var zWriter *zstd.Writer
+		var w *bufio.Writer
+		if cliParams.inspectCompressed {
+			zWriter = zstd.NewWriter(f)
+			w = bufio.NewWriter(zWriter)
+		} else {
+			w = bufio.NewWriter(f)
+		}
+		inspector.capture = w
+		closeCapture = func() error {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if zWriter != nil {
+				return zWriter.Close()
+			}
+			return nil
+		}
+	}
+	if inspector.summary == nil && inspector.capture == nil {
+		inspector.packets = stdout
+	}
+
+	if err := inspector.run(reader); err != nil {
+		return err
+	}
+	if closeCapture != nil {
+		if err := closeCapture(); err != nil {
+			return err
+		}
+	}
+
+	if inspector.summary != nil {
+		inspector.summary.finalize(cliParams.inspectTop)
+		if err := inspector.summary.render(stdout, cliParams.inspectFormat); err != nil {
+			return err
+		}
+	}
+	if inspector.capture != nil {
+		fmt.Fprintf(stdout, "Filtered capture written to: %s\n", cliParams.inspectOutputPath)
+	}
+	return nil
+}
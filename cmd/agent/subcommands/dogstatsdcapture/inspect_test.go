@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package dogstatsdcapture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	replay "github.com/DataDog/datadog-agent/comp/dogstatsd/replay/impl"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
+)
+
+var testStart = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// writeTestCapture writes a capture file of the given version with 3 packets
+// from 2 PIDs.
+func writeTestCapture(t *testing.T, version byte) string {
+	packets := []struct {
+		offset  time.Duration
+		pid     int32
+		payload string
+	}{
+		{0, 10, "http.requests:1|c|#env:prod,service:web\nhttp.latency:5|h|#env:prod"},
+		{time.Second, 20, "http.requests:1|c|#env:staging\n_sc|disk.ok|0|#env:prod"},
+		{2 * time.Second, 10, "_e{5,4}:title|text|#env:prod\nhttp.requests:2|c|#service:web,env:prod"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, replay.WriteHeader(&buf))
+	// the version is stored in the low bits of the fifth byte of the header
+	buf.Bytes()[4] = 0xF0 | version
+	for _, p := range packets {
+		// the captures older than version 3 store the timestamps in seconds
+		timestamp := testStart.Add(p.offset).UnixNano()
+		if version < 3 {
+			timestamp = testStart.Add(p.offset).Unix()
+		}
+		require.NoError(t, replay.WriteMessage(&buf, &pb.UnixDogstatsdMsg{
+			Timestamp:   timestamp,
+			PayloadSize: int32(len(p.payload)),
+			Payload:     []byte(p.payload),
+			Pid:         p.pid,
+		}))
+	}
+	_, err := replay.WriteState(&buf, &pb.TaggerState{PidMap: map[int32]string{10: "container_id://abc"}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "capture.dog")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+	return path
+}
+
+func runInspector(t *testing.T, inspector *captureInspector) {
+	runInspectorOnVersion(t, inspector, 3)
+}
+
+func runInspectorOnVersion(t *testing.T, inspector *captureInspector, version byte) {
+	reader, err := replay.NewTrafficCaptureReader(writeTestCapture(t, version), 1, false)
+	require.NoError(t, err)
+	defer reader.Close()
+	require.NoError(t, inspector.run(reader))
+}
+
+func TestParseMessage(t *testing.T) {
+	for _, tt := range []struct {
+		raw      string
+		expected dogstatsdMessage
+	}{
+		{"metric:1|c|@0.5|#a:b,c|T1", dogstatsdMessage{Kind: kindMetric, Name: "metric", Type: "c", Tags: []string{"a:b", "c"}}},
+		{"metric:1:2|d", dogstatsdMessage{Kind: kindMetric, Name: "metric", Type: "d"}},
+		{"_e{5,6}:ti|le|te|xt!|p:low|#a:b", dogstatsdMessage{Kind: kindEvent, Name: "ti|le", Tags: []string{"a:b"}}},
+		{"_e{50,6}:title", dogstatsdMessage{Kind: kindEvent}},
+		{"_sc|check|0|#a:b|m:message", dogstatsdMessage{Kind: kindServiceCheck, Name: "check", Tags: []string{"a:b"}}},
+	} {
+		tt.expected.Raw = tt.raw
+		assert.Equal(t, tt.expected, parseMessage([]byte(tt.raw)), tt.raw)
+	}
+}
+
+func TestInspectText(t *testing.T) {
+	var out bytes.Buffer
+	runInspector(t, &captureInspector{
+		filter:  captureFilter{names: []string{"http.*"}, tags: []string{"env:prod"}},
+		format:  formatText,
+		packets: &out,
+	})
+
+	assert.Equal(t, "2025-01-02T03:04:05Z pid=10 container=container_id://abc http.requests:1|c|#env:prod,service:web\n"+
+		"2025-01-02T03:04:05Z pid=10 container=container_id://abc http.latency:5|h|#env:prod\n"+
+		"2025-01-02T03:04:07Z pid=10 container=container_id://abc http.requests:2|c|#service:web,env:prod\n", out.String())
+}
+
+func TestInspectJSON(t *testing.T) {
+	var out bytes.Buffer
+	runInspector(t, &captureInspector{
+		filter:  captureFilter{pids: []int32{20}},
+		format:  formatJSON,
+		packets: &out,
+	})
+
+	var packet capturedPacket
+	require.NoError(t, json.Unmarshal(out.Bytes(), &packet))
+	assert.Equal(t, testStart.Add(time.Second), packet.Timestamp)
+	assert.Equal(t, int32(20), packet.PID)
+	assert.Empty(t, packet.Container)
+	require.Len(t, packet.Messages, 2)
+	assert.Equal(t, kindServiceCheck, packet.Messages[1].Kind)
+	assert.Equal(t, "disk.ok", packet.Messages[1].Name)
+}
+
+func TestInspectSummary(t *testing.T) {
+	summary := newCaptureSummary()
+	runInspector(t, &captureInspector{summary: summary})
+	summary.finalize(2)
+
+	assert.Equal(t, 3, summary.Packets)
+	assert.Equal(t, 6, summary.Messages)
+	assert.Equal(t, 2, summary.PIDs)
+	assert.Equal(t, testStart, summary.First)
+	assert.Equal(t, testStart.Add(2*time.Second), summary.Last)
+	assert.Equal(t, []volume{
+		{Name: "http.requests", Count: 3, Bytes: 108},
+		{Name: "disk.ok", Count: 1, Bytes: 23},
+	}, summary.TopMetrics)
+	// the tags of a context are sorted
+	assert.Equal(t, volume{Name: "http.requests{env:prod,service:web}", Count: 2, Bytes: 78}, summary.TopContexts[0])
+
+	var out bytes.Buffer
+	require.NoError(t, summary.render(&out, formatText))
+	assert.Contains(t, out.String(), "Messages:    6\n")
+	assert.Contains(t, out.String(), "  3         108    http.requests\n")
+}
+
+func TestInspectOutputCapture(t *testing.T) {
+	for _, version := range []byte{2, 3} {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			var capture bytes.Buffer
+			runInspectorOnVersion(t, &captureInspector{
+				filter:  captureFilter{tags: []string{"env:prod"}, pids: []int32{10}},
+				capture: &capture,
+			}, version)
+
+			path := filepath.Join(t.TempDir(), "filtered.dog")
+			require.NoError(t, os.WriteFile(path, capture.Bytes(), 0600))
+			reader, err := replay.NewTrafficCaptureReader(path, 1, false)
+			require.NoError(t, err)
+			defer reader.Close()
+
+			pidMap, _, err := reader.ReadState()
+			require.NoError(t, err)
+			assert.Equal(t, map[int32]string{10: "container_id://abc"}, pidMap)
+
+			// the filtered capture can be inspected again
+			var out bytes.Buffer
+			require.NoError(t, (&captureInspector{format: formatText, packets: &out}).run(reader))
+			assert.Equal(t, ""+
+				"2025-01-02T03:04:05Z pid=10 container=container_id://abc http.requests:1|c|#env:prod,service:web\n"+
+				"2025-01-02T03:04:05Z pid=10 container=container_id://abc http.latency:5|h|#env:prod\n"+
+				"2025-01-02T03:04:07Z pid=10 container=container_id://abc _e{5,4}:title|text|#env:prod\n"+
+				"2025-01-02T03:04:07Z pid=10 container=container_id://abc http.requests:2|c|#service:web,env:prod\n",
+				out.String())
+
+			reader.Seek(0)
+			var timestamps []int64
+			for msg, err := reader.ReadNext(); err != io.EOF; msg, err = reader.ReadNext() {
+				require.NoError(t, err)
+				timestamps = append(timestamps, msg.Timestamp)
+			}
+			assert.Equal(t, []int64{testStart.UnixNano(), testStart.Add(2 * time.Second).UnixNano()}, timestamps)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package metrics implements 'agent metrics'.
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// cliParams are the command-line arguments for the subcommands
+type cliParams struct {
+	*command.GlobalParams
+
+	// name is the metric name given to the show subcommand
+	name string
+
+	tags       []string
+	source     string
+	jsonOutput bool
+}
+
+// Commands returns a slice of subcommands for the 'agent' command.
+func Commands(globalParams *command.GlobalParams) []*cobra.Command {
+	cliParams := &cliParams{
+		GlobalParams: globalParams,
+	}
+
+	oneShot := func(fn interface{}) error {
+		return fxutil.OneShot(fn,
+			fx.Supply(cliParams),
+			fx.Supply(command.GetDefaultCoreBundleParams(cliParams.GlobalParams)),
+			core.Bundle(),
+		)
+	}
+
+	metricsCmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Inspect the metrics recently flushed by the aggregator",
+		Long:  `Inspect the last value flushed by the aggregator for the most recently flushed metric contexts. The number of contexts kept by the Agent is set by aggregator_flushed_metrics_size.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the recently flushed metrics with their last value",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return oneShot(listMetrics)
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show the last flushed points of the contexts of a metric",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			cliParams.name = args[0]
+			return oneShot(showMetric)
+		},
+	}
+
+	for _, cmd := range []*cobra.Command{listCmd, showCmd} {
+		cmd.Flags().StringArrayVarP(&cliParams.tags, "tag", "t", nil, "only show the contexts with this tag, can be repeated")
+		cmd.Flags().StringVarP(&cliParams.source, "source", "s", "", "only show the metrics of this source, e.g. dogstatsd or the name of a check")
+		cmd.Flags().BoolVarP(&cliParams.jsonOutput, "json", "j", false, "print out raw json")
+		metricsCmd.AddCommand(cmd)
+	}
+
+	return []*cobra.Command{metricsCmd}
+}
+
+func listMetrics(_ log.Component, config config.Component, cliParams *cliParams) error {
+	body, err := requestFlushedMetrics(config, cliParams)
+	if err != nil || cliParams.jsonOutput {
+		return err
+	}
+
+	var metrics []flushedmetrics.Metric
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		fmt.Println("No flushed metric matches the filters.")
+		return nil
+	}
+	return renderList(os.Stdout, metrics)
+}
+
+func showMetric(_ log.Component, config config.Component, cliParams *cliParams) error {
+	body, err := requestFlushedMetrics(config, cliParams)
+	if err != nil || cliParams.jsonOutput {
+		return err
+	}
+
+	var metrics []flushedmetrics.Metric
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		return err
+	}
+	if len(metrics) == 0 {
+		fmt.Printf("No flushed context of %s matches the filters.\n", cliParams.name)
+		return nil
+	}
+	renderShow(os.Stdout, metrics)
+	return nil
+}
+
+// requestFlushedMetrics queries the flushed metrics from the agent, and
+// prints the response if the json output is requested.
+func requestFlushedMetrics(config config.Component, cliParams *cliParams) ([]byte, error) {
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+	ipcAddress, err := pkgconfigsetup.GetIPCAddress(pkgconfigsetup.Datadog())
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	if cliParams.name != "" {
+		query.Set("name", cliParams.name)
+	}
+	if cliParams.source != "" {
+		query.Set("source", cliParams.source)
+	}
+	for _, tag := range cliParams.tags {
+		query.Add("tag", tag)
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/metrics/flushed?%s", ipcAddress, pkgconfigsetup.Datadog().GetInt("cmd_port"), query.Encode())
+
+	// Set session token
+	if err := util.SetAuthToken(config); err != nil {
+		return nil, err
+	}
+
+	r, err := util.DoGet(c, urlstr, util.LeaveConnectionOpen)
+	if err != nil {
+		var errMap = make(map[string]string)
+		json.Unmarshal(r, &errMap) //nolint:errcheck
+		// If the error has been marshalled into a json object, check it and return it properly
+		if e, found := errMap["error"]; found {
+			return nil, errors.New(e)
+		}
+
+		fmt.Printf("Could not reach agent: %v \nMake sure the agent is running before requesting the flushed metrics and contact support if you continue having issues. \n", err)
+		return nil, err
+	}
+
+	if cliParams.jsonOutput {
+		var prettyJSON bytes.Buffer
+		json.Indent(&prettyJSON, r, "", "  ") //nolint:errcheck
+		fmt.Println(prettyJSON.String())
+	}
+	return r, nil
+}
+
+// renderList prints a line per context with its last value.
+func renderList(w io.Writer, metrics []flushedmetrics.Metric) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tSOURCE\tLAST VALUE\tFLUSHED AT\tTAGS")
+	for _, m := range metrics {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			m.Name, m.Type, m.Source, lastValue(m), m.FlushTime.Format(time.RFC3339), strings.Join(m.Tags, ","))
+	}
+	return tw.Flush()
+}
+
+// renderShow prints the points of each context.
+func renderShow(w io.Writer, metrics []flushedmetrics.Metric) {
+	for i, m := range metrics {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Name:       %s\n", m.Name)
+		fmt.Fprintf(w, "Type:       %s\n", m.Type)
+		fmt.Fprintf(w, "Source:     %s\n", m.Source)
+		if m.Host != "" {
+			fmt.Fprintf(w, "Host:       %s\n", m.Host)
+		}
+		fmt.Fprintf(w, "Tags:       %s\n", strings.Join(m.Tags, ", "))
+		fmt.Fprintf(w, "Flushed at: %s\n", m.FlushTime.Format(time.RFC3339))
+		fmt.Fprintln(w, "Points:")
+		for _, p := range m.Points {
+			fmt.Fprintf(w, "  %s  %s\n", formatTimestamp(int64(p.Timestamp)), formatFloat(p.Value))
+		}
+		for _, p := range m.Sketches {
+			fmt.Fprintf(w, "  %s  count=%d sum=%s min=%s max=%s avg=%s\n", formatTimestamp(p.Timestamp),
+				p.Count, formatFloat(p.Sum), formatFloat(p.Min), formatFloat(p.Max), formatFloat(p.Avg))
+		}
+	}
+}
+
+func lastValue(m flushedmetrics.Metric) string {
+	if n := len(m.Points); n > 0 {
+		return formatFloat(m.Points[n-1].Value)
+	}
+	if n := len(m.Sketches); n > 0 {
+		p := m.Sketches[n-1]
+		return fmt.Sprintf("count=%d avg=%s", p.Count, formatFloat(p.Avg))
+	}
+	return ""
+}
+
+func formatTimestamp(ts int64) string {
+	return time.Unix(ts, 0).Format(time.RFC3339)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"metrics", "list", "--tag", "env:prod", "-t", "service:web", "--source", "dogstatsd"},
+		listMetrics,
+		func(cliParams *cliParams) {
+			require.Equal(t, []string{"env:prod", "service:web"}, cliParams.tags)
+			require.Equal(t, "dogstatsd", cliParams.source)
+			require.False(t, cliParams.jsonOutput)
+		})
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"metrics", "show", "system.load.1", "--json"},
+		showMetric,
+		func(cliParams *cliParams) {
+			require.Equal(t, "system.load.1", cliParams.name)
+			require.True(t, cliParams.jsonOutput)
+		})
+}
+
+func TestRender(t *testing.T) {
+	flushTime := time.Unix(1700000010, 0)
+	metrics := []flushedmetrics.Metric{
+		{
+			Name:      "request.count",
+			Type:      "rate",
+			Host:      "web01",
+			Tags:      []string{"env:prod", "service:web"},
+			Source:    "dogstatsd",
+			FlushTime: flushTime,
+			Points:    []flushedmetrics.Point{{Timestamp: 1700000000, Value: 2.5}},
+		},
+		{
+			Name:      "request.latency",
+			Type:      flushedmetrics.DistributionType,
+			Tags:      []string{},
+			Source:    "dogstatsd",
+			FlushTime: flushTime,
+			Sketches:  []flushedmetrics.SketchPoint{{Timestamp: 1700000000, Count: 3, Sum: 6, Min: 1, Max: 3, Avg: 2}},
+		},
+	}
+
+	var list bytes.Buffer
+	require.NoError(t, renderList(&list, metrics))
+	assert.Contains(t, list.String(), "request.count    rate          dogstatsd  2.5")
+	assert.Contains(t, list.String(), "env:prod,service:web")
+	assert.Contains(t, list.String(), "count=3 avg=2")
+
+	var show bytes.Buffer
+	renderShow(&show, metrics)
+	assert.Contains(t, show.String(), "Host:       web01\n")
+	assert.Contains(t, show.String(), "Tags:       env:prod, service:web\n")
+	assert.Contains(t, show.String(), "  "+time.Unix(1700000000, 0).Format(time.RFC3339)+"  2.5\n")
+	assert.Contains(t, show.String(), "count=3 sum=6 min=1 max=3 avg=2\n")
+}
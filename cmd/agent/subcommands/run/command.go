@@ -134,6 +134,8 @@ import (
 	traceagentStatusImpl "github.com/DataDog/datadog-agent/comp/trace/status/statusimpl"
 	pkgcollector "github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	checkdefaults "github.com/DataDog/datadog-agent/pkg/collector/check/defaults"
+	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/net"
 	profileStatus "github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/status"
 	"github.com/DataDog/datadog-agent/pkg/collector/python"
@@ -142,6 +144,7 @@ import (
 	commonsettings "github.com/DataDog/datadog-agent/pkg/config/settings"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/jmxfetch"
+	logsprocessor "github.com/DataDog/datadog-agent/pkg/logs/processor"
 	proccontainers "github.com/DataDog/datadog-agent/pkg/process/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	clusteragentStatus "github.com/DataDog/datadog-agent/pkg/status/clusteragent"
@@ -168,6 +171,9 @@ import (
 	ddruntime "github.com/DataDog/datadog-agent/pkg/runtime"
 )
 
+// logMetricsSenderID is the ID of the sender of the metrics generated from logs
+const logMetricsSenderID checkid.ID = "logs-metrics"
+
 type cliParams struct {
 	*command.GlobalParams
 
@@ -469,6 +475,7 @@ func getSharedFxOption() fx.Option {
 					"runtime_block_profile_rate":             commonsettings.NewRuntimeBlockProfileRate(),
 					"dogstatsd_stats":                        internalsettings.NewDsdStatsRuntimeSetting(serverDebug),
 					"dogstatsd_capture_duration":             internalsettings.NewDsdCaptureDurationRuntimeSetting("dogstatsd_capture_duration"),
+					"dogstatsd_tag_filterlist":               internalsettings.NewDsdTagFilterListRuntimeSetting(),
 					"log_payloads":                           commonsettings.NewLogPayloadsRuntimeSetting(),
 					"internal_profiling_goroutines":          commonsettings.NewProfilingGoroutines(),
 					"multi_region_failover.enabled":          internalsettings.NewMultiRegionFailoverRuntimeSetting("multi_region_failover.enabled", "Enable/disable Multi-Region Failover support."),
@@ -583,6 +590,11 @@ func startAgent(
 		pkgTelemetry.RegisterStatsSender(sender)
 	}
 
+	// Setup the sender of the metrics generated from logs, committed like the ones of a check
+	if sender, err := demultiplexer.GetSender(logMetricsSenderID); err == nil {
+		logsprocessor.StartLogMetrics(ctx, sender, checkdefaults.DefaultCheckInterval)
+	}
+
 	// Append version and timestamp to version history log file if this Agent is different than the last run version
 	installinfo.LogVersionHistory()
 
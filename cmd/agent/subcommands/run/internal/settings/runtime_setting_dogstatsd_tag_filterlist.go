@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+// DsdTagFilterListRuntimeSetting wraps operations to change the tag filter rules of dogstatsd at runtime.
+type DsdTagFilterListRuntimeSetting struct{}
+
+// NewDsdTagFilterListRuntimeSetting creates a new instance of DsdTagFilterListRuntimeSetting
+func NewDsdTagFilterListRuntimeSetting() *DsdTagFilterListRuntimeSetting {
+	return &DsdTagFilterListRuntimeSetting{}
+}
+
+// Description returns the runtime setting's description
+func (s *DsdTagFilterListRuntimeSetting) Description() string {
+	return "Set the dogstatsd tag filter rules. The value is a JSON list of rules, e.g. [{\"metric_name\": \"http.*\", \"action\": \"exclude\", \"tags\": [\"request_id\"]}]"
+}
+
+// Hidden returns whether or not this setting is hidden from the list of runtime settings
+func (s *DsdTagFilterListRuntimeSetting) Hidden() bool {
+	return false
+}
+
+// Name returns the name of the runtime setting
+func (s *DsdTagFilterListRuntimeSetting) Name() string {
+	return "dogstatsd_tag_filterlist"
+}
+
+// Get returns the current value of the runtime setting
+func (s *DsdTagFilterListRuntimeSetting) Get(config config.Component) (interface{}, error) {
+	return config.Get("dogstatsd_tag_filterlist"), nil
+}
+
+// Set changes the value of the runtime setting, the rules are applied by the
+// dogstatsd server when the setting is updated.
+func (s *DsdTagFilterListRuntimeSetting) Set(config config.Component, v interface{}, source model.Source) error {
+	var rules []interface{}
+
+	switch value := v.(type) {
+	case string:
+		if err := json.Unmarshal([]byte(value), &rules); err != nil {
+			return fmt.Errorf("DsdTagFilterListRuntimeSetting: invalid JSON list of rules: %v", err)
+		}
+	case []interface{}:
+		rules = value
+	default:
+		return fmt.Errorf("DsdTagFilterListRuntimeSetting: unsupported type %T", v)
+	}
+
+	for _, rule := range rules {
+		if _, ok := rule.(map[string]interface{}); !ok {
+			return fmt.Errorf("DsdTagFilterListRuntimeSetting: invalid rule %v", rule)
+		}
+	}
+
+	config.Set("dogstatsd_tag_filterlist", rules, source)
+	return nil
+}
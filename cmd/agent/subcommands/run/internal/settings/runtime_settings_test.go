@@ -103,3 +103,27 @@ func TestDogstatsdMetricsStats(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(v, true)
 }
+
+func TestDogstatsdTagFilterList(t *testing.T) {
+	cfg := config.NewMock(t)
+	s := NewDsdTagFilterListRuntimeSetting()
+
+	err := s.Set(cfg, `[{"metric_name": "http.*", "action": "exclude", "tags": ["request_id"]}]`, model.SourceCLI)
+	assert.NoError(t, err)
+	v, err := s.Get(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"metric_name": "http.*", "action": "exclude", "tags": []interface{}{"request_id"}},
+	}, v)
+	assert.Equal(t, model.SourceCLI, cfg.GetSource("dogstatsd_tag_filterlist"))
+
+	err = s.Set(cfg, []interface{}{}, model.SourceCLI)
+	assert.NoError(t, err)
+	v, err = s.Get(cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+
+	assert.Error(t, s.Set(cfg, "not json", model.SourceCLI))
+	assert.Error(t, s.Set(cfg, `["http.*"]`, model.SourceCLI))
+	assert.Error(t, s.Set(cfg, 42, model.SourceCLI))
+}
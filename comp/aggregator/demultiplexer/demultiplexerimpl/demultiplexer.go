@@ -13,6 +13,7 @@ import (
 
 	demultiplexerComp "github.com/DataDog/datadog-agent/comp/aggregator/demultiplexer"
 	"github.com/DataDog/datadog-agent/comp/aggregator/diagnosesendermanager"
+	api "github.com/DataDog/datadog-agent/comp/api/api/def"
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	"github.com/DataDog/datadog-agent/comp/core/status"
@@ -69,6 +70,7 @@ type provides struct {
 	SenderManager           sender.SenderManager
 	StatusProvider          status.InformationProvider
 	AggregatorDemultiplexer aggregator.Demultiplexer
+	Endpoint                api.AgentEndpointProvider
 }
 
 func newDemultiplexer(deps dependencies) (provides, error) {
@@ -109,6 +111,10 @@ func newDemultiplexer(deps dependencies) (provides, error) {
 			Log: deps.Log,
 		}),
 		AggregatorDemultiplexer: demultiplexer,
+		Endpoint: api.NewAgentEndpointProvider(flushedMetricsEndpoint{
+			store: agentDemultiplexer.FlushedMetrics(),
+			log:   deps.Log,
+		}.ServeHTTP, "/metrics/flushed", "GET"),
 	}, nil
 }
 
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package demultiplexerimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+// flushedMetricsEndpoint serves the last flushed series and sketches matching
+// the name, tag and source query parameters.
+type flushedMetricsEndpoint struct {
+	store *flushedmetrics.Store
+	log   log.Component
+}
+
+func (e flushedMetricsEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if e.store == nil {
+		httputils.SetJSONError(w, errors.New("the flushed metrics are not kept, aggregator_flushed_metrics_size is 0"), 400)
+		return
+	}
+
+	query := r.URL.Query()
+	metrics := e.store.Query(flushedmetrics.Filter{
+		Name:   query.Get("name"),
+		Tags:   query["tag"],
+		Source: query.Get("source"),
+	})
+
+	body, err := json.Marshal(metrics)
+	if err != nil {
+		httputils.SetJSONError(w, e.log.Errorf("Unable to marshal the flushed metrics: %v", err), 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
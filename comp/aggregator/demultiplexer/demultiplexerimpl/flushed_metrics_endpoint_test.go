@@ -0,0 +1,56 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package demultiplexerimpl
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func TestFlushedMetricsEndpoint(t *testing.T) {
+	store := flushedmetrics.NewStore(10)
+	for _, tags := range [][]string{{"env:prod"}, {"env:staging"}} {
+		store.RecordSerie(&metrics.Serie{
+			Name:   "foo",
+			Tags:   tagset.CompositeTagsFromSlice(tags),
+			Points: []metrics.Point{{Ts: 10, Value: 1}},
+			Source: metrics.MetricSourceDogstatsd,
+		}, time.Now())
+	}
+	endpoint := flushedMetricsEndpoint{store: store, log: logmock.New(t)}
+
+	rec := httptest.NewRecorder()
+	endpoint.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics/flushed?name=foo&tag=env:staging&source=dogstatsd", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result []flushedmetrics.Metric
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Len(t, result, 1)
+	assert.Equal(t, []string{"env:staging"}, result[0].Tags)
+
+	rec = httptest.NewRecorder()
+	endpoint.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics/flushed?name=bar", nil))
+	assert.Equal(t, "[]", rec.Body.String())
+}
+
+func TestFlushedMetricsEndpointDisabled(t *testing.T) {
+	endpoint := flushedMetricsEndpoint{log: logmock.New(t)}
+
+	rec := httptest.NewRecorder()
+	endpoint.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics/flushed", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "aggregator_flushed_metrics_size")
+}
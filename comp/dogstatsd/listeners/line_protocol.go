@@ -0,0 +1,227 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package listeners
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var lineSeparator = []byte("\n")
+
+// LineProtocolListener implements the StatsdListener interface for the
+// Graphite plaintext and InfluxDB line protocols. It listens to an UDP, TCP or
+// Unix socket address and sends back packets of newline separated lines ready
+// to be processed. Origin detection is not implemented for these protocols.
+type LineProtocolListener struct {
+	protocol        string
+	transport       string
+	packetConn      net.PacketConn
+	streamListener  net.Listener
+	connTracker     *ConnectionTracker
+	packetsBuffer   *packets.Buffer
+	packetAssembler *packets.Assembler
+	bufferSize      int
+	listenWg        sync.WaitGroup
+	telemetryStore  *TelemetryStore
+}
+
+// NewLineProtocolListener returns an idle listener of the line protocol of
+// sourceType, packets.Graphite or packets.Influx, on address. The address is
+// an URL whose scheme is one of udp, tcp, unix or unixgram.
+func NewLineProtocolListener(address string, sourceType packets.SourceType, packetOut chan packets.Packets, sharedPacketPoolManager *packets.PoolManager[packets.Packet], cfg model.Reader, telemetryStore *TelemetryStore, packetsTelemetryStore *packets.TelemetryStore) (*LineProtocolListener, error) {
+	var protocol string
+	switch sourceType {
+	case packets.Graphite:
+		protocol = "graphite"
+	case packets.Influx:
+		protocol = "influx"
+	default:
+		return nil, fmt.Errorf("unsupported line protocol source type %d", sourceType)
+	}
+
+	addr, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s listener address %q: %s", protocol, address, err)
+	}
+
+	listener := &LineProtocolListener{
+		protocol:       protocol,
+		transport:      addr.Scheme,
+		bufferSize:     cfg.GetInt("dogstatsd_buffer_size"),
+		telemetryStore: telemetryStore,
+	}
+
+	switch addr.Scheme {
+	case "udp":
+		listener.packetConn, err = net.ListenPacket(addr.Scheme, addr.Host)
+	case "unixgram":
+		if _, err = setupSocketBeforeListen(addr.Path, addr.Scheme); err == nil {
+			listener.packetConn, err = net.ListenPacket(addr.Scheme, addr.Path)
+		}
+	case "tcp":
+		listener.streamListener, err = net.Listen(addr.Scheme, addr.Host)
+	case "unix":
+		if _, err = setupSocketBeforeListen(addr.Path, addr.Scheme); err == nil {
+			listener.streamListener, err = net.Listen(addr.Scheme, addr.Path)
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s listener address %q: scheme must be one of udp, tcp, unix or unixgram", protocol, address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't listen on %s: %s", address, err)
+	}
+	if listener.streamListener != nil {
+		listener.connTracker = NewConnectionTracker(protocol+"-"+addr.Scheme, 1*time.Second)
+	}
+
+	packetsBufferSize := cfg.GetInt("dogstatsd_packet_buffer_size")
+	flushTimeout := cfg.GetDuration("dogstatsd_packet_buffer_flush_timeout")
+	listener.packetsBuffer = packets.NewBuffer(uint(packetsBufferSize), flushTimeout, packetOut, protocol, packetsTelemetryStore)
+	listener.packetAssembler = packets.NewAssembler(flushTimeout, listener.packetsBuffer, sharedPacketPoolManager, sourceType)
+
+	log.Debugf("dogstatsd-%s: %s successfully initialized", protocol, listener.LocalAddr())
+	return listener, nil
+}
+
+// LocalAddr returns the local network address of the listener.
+func (l *LineProtocolListener) LocalAddr() string {
+	if l.packetConn != nil {
+		return l.packetConn.LocalAddr().String()
+	}
+	return l.streamListener.Addr().String()
+}
+
+// Listen runs the intake loop. Should be called in its own goroutine
+func (l *LineProtocolListener) Listen() {
+	l.listenWg.Add(1)
+	go func() {
+		defer l.listenWg.Done()
+		if l.packetConn != nil {
+			l.listenPackets()
+		} else {
+			l.listenStreams()
+		}
+	}()
+}
+
+// listenPackets reads datagrams holding one or several lines.
+func (l *LineProtocolListener) listenPackets() {
+	log.Infof("dogstatsd-%s: starting to listen on %s://%s", l.protocol, l.transport, l.LocalAddr())
+	buffer := make([]byte, l.bufferSize)
+	for {
+		n, _, err := l.packetConn.ReadFrom(buffer)
+		t1 := time.Now()
+		if err != nil {
+			// connection has been closed
+			if strings.HasSuffix(err.Error(), " use of closed network connection") {
+				return
+			}
+			log.Errorf("dogstatsd-%s: error reading packet: %v", l.protocol, err)
+			l.telemetryStore.tlmLineProtocolPackets.Inc(l.protocol, l.transport, "error")
+			continue
+		}
+		l.addLines(buffer[:n])
+		l.telemetryStore.tlmListener.Observe(float64(time.Since(t1).Nanoseconds()), "", l.transport, l.protocol)
+	}
+}
+
+// listenStreams accepts connections sending lines until they are closed.
+func (l *LineProtocolListener) listenStreams() {
+	l.connTracker.Start()
+	log.Infof("dogstatsd-%s: starting to listen on %s://%s", l.protocol, l.transport, l.LocalAddr())
+	for {
+		conn, err := l.streamListener.Accept()
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), " use of closed network connection") {
+				log.Errorf("dogstatsd-%s: error accepting connection: %v", l.protocol, err)
+			}
+			return
+		}
+		go func() {
+			l.connTracker.Track(conn)
+			l.handleConnection(conn)
+			l.connTracker.Close(conn)
+		}()
+	}
+}
+
+// handleConnection reads the lines sent on a connection, lines longer than
+// the buffer are dropped.
+func (l *LineProtocolListener) handleConnection(conn net.Conn) {
+	buffer := make([]byte, l.bufferSize)
+	n := 0
+	discarding := false
+	for {
+		read, err := conn.Read(buffer[n:])
+		t1 := time.Now()
+		n += read
+
+		if eol := bytes.LastIndexByte(buffer[:n], '\n'); eol >= 0 {
+			lines := buffer[:eol]
+			if discarding {
+				// drop the end of the line which did not fit in the buffer
+				_, lines, _ = bytes.Cut(lines, lineSeparator)
+				discarding = false
+			}
+			l.addLines(lines)
+			n = copy(buffer, buffer[eol+1:n])
+		} else if n == len(buffer) {
+			log.Debugf("dogstatsd-%s: dropping a line longer than the buffer size %d", l.protocol, l.bufferSize)
+			l.telemetryStore.tlmLineProtocolPackets.Inc(l.protocol, l.transport, "error")
+			discarding = true
+			n = 0
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				if !discarding {
+					// the last line may not be terminated
+					l.addLines(buffer[:n])
+				}
+			} else if !strings.HasSuffix(err.Error(), " use of closed network connection") {
+				log.Errorf("dogstatsd-%s: error reading connection: %v", l.protocol, err)
+				l.telemetryStore.tlmLineProtocolPackets.Inc(l.protocol, l.transport, "error")
+			}
+			return
+		}
+		l.telemetryStore.tlmListener.Observe(float64(time.Since(t1).Nanoseconds()), "", l.transport, l.protocol)
+	}
+}
+
+// addLines sends newline separated lines to the packet assembler.
+func (l *LineProtocolListener) addLines(lines []byte) {
+	lines = bytes.TrimRight(lines, "\r\n")
+	if len(lines) == 0 {
+		return
+	}
+	l.telemetryStore.tlmLineProtocolPackets.Inc(l.protocol, l.transport, "ok")
+	l.telemetryStore.tlmLineProtocolBytes.Add(float64(len(lines)), l.protocol, l.transport)
+	l.packetAssembler.AddMessage(lines)
+}
+
+// Stop closes the listener and its connections and stops listening
+func (l *LineProtocolListener) Stop() {
+	if l.packetConn != nil {
+		l.packetConn.Close()
+	} else {
+		l.streamListener.Close()
+		l.connTracker.Stop()
+	}
+	l.listenWg.Wait()
+	l.packetAssembler.Close()
+	l.packetsBuffer.Close()
+}
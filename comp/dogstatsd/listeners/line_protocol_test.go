@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+//go:build !windows
+
+package listeners
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+)
+
+func newLineProtocolListenerTest(t *testing.T, address string, sourceType packets.SourceType) (*LineProtocolListener, chan packets.Packets, listenerDeps) {
+	deps := fulfillDepsWithConfig(t, map[string]interface{}{})
+	packetOut := make(chan packets.Packets, 8)
+	telemetryStore := NewTelemetryStore(nil, deps.Telemetry)
+	packetsTelemetryStore := packets.NewTelemetryStore(nil, deps.Telemetry)
+	l, err := NewLineProtocolListener(address, sourceType, packetOut, newPacketPoolManagerUDP(deps.Config, packetsTelemetryStore), deps.Config, telemetryStore, packetsTelemetryStore)
+	require.NoError(t, err)
+	l.Listen()
+	t.Cleanup(l.Stop)
+	return l, packetOut, deps
+}
+
+func readLineProtocolPacket(t *testing.T, packetOut chan packets.Packets) *packets.Packet {
+	select {
+	case pkts := <-packetOut:
+		require.Len(t, pkts, 1)
+		return pkts[0]
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "Timeout on receive channel")
+	}
+	return nil
+}
+
+func TestNewLineProtocolListenerErrors(t *testing.T) {
+	deps := fulfillDepsWithConfig(t, map[string]interface{}{})
+	telemetryStore := NewTelemetryStore(nil, deps.Telemetry)
+	packetsTelemetryStore := packets.NewTelemetryStore(nil, deps.Telemetry)
+	pool := newPacketPoolManagerUDP(deps.Config, packetsTelemetryStore)
+
+	_, err := NewLineProtocolListener("udp://127.0.0.1:0", packets.UDP, nil, pool, deps.Config, telemetryStore, packetsTelemetryStore)
+	assert.Error(t, err)
+	_, err = NewLineProtocolListener("http://127.0.0.1:0", packets.Graphite, nil, pool, deps.Config, telemetryStore, packetsTelemetryStore)
+	assert.Error(t, err)
+	_, err = NewLineProtocolListener("127.0.0.1:2003", packets.Graphite, nil, pool, deps.Config, telemetryStore, packetsTelemetryStore)
+	assert.Error(t, err)
+}
+
+func TestLineProtocolListenerUDP(t *testing.T) {
+	l, packetOut, deps := newLineProtocolListenerTest(t, "udp://127.0.0.1:0", packets.Graphite)
+
+	conn, err := net.Dial("udp", l.LocalAddr())
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("servers.web01.load 0.5 1700000000\n"))
+	require.NoError(t, err)
+
+	packet := readLineProtocolPacket(t, packetOut)
+	assert.Equal(t, "servers.web01.load 0.5 1700000000", string(packet.Contents))
+	assert.Equal(t, packets.Graphite, packet.Source)
+
+	telemetryMock, ok := deps.Telemetry.(telemetry.Mock)
+	require.True(t, ok)
+	packetsMetrics, err := telemetryMock.GetCountMetric("dogstatsd", "line_protocol_packets")
+	require.NoError(t, err)
+	require.Len(t, packetsMetrics, 1)
+	assert.Equal(t, map[string]string{"protocol": "graphite", "transport": "udp", "state": "ok"}, packetsMetrics[0].Tags())
+}
+
+func TestLineProtocolListenerTCP(t *testing.T) {
+	l, packetOut, _ := newLineProtocolListenerTest(t, "tcp://127.0.0.1:0", packets.Influx)
+
+	conn, err := net.Dial("tcp", l.LocalAddr())
+	require.NoError(t, err)
+	// the line longer than the buffer is dropped, the last line isn't terminated
+	_, err = conn.Write([]byte("cpu,host=web01 usage=1\n" + strings.Repeat("a", 9000) + "\ncpu,host=web02 usage=2"))
+	require.NoError(t, err)
+	conn.Close()
+
+	var lines []string
+	for len(lines) < 2 {
+		packet := readLineProtocolPacket(t, packetOut)
+		assert.Equal(t, packets.Influx, packet.Source)
+		lines = append(lines, strings.Split(string(packet.Contents), "\n")...)
+	}
+	assert.Equal(t, []string{"cpu,host=web01 usage=1", "cpu,host=web02 usage=2"}, lines)
+}
+
+func TestLineProtocolListenerUnix(t *testing.T) {
+	socketPath := testSocketPath(t)
+	_, packetOut, _ := newLineProtocolListenerTest(t, "unix://"+socketPath, packets.Graphite)
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("disk.used;mount=/var 42\n"))
+	require.NoError(t, err)
+	conn.Close()
+
+	packet := readLineProtocolPacket(t, packetOut)
+	assert.Equal(t, "disk.used;mount=/var 42", string(packet.Contents))
+}
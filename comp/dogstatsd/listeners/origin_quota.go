@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package listeners
+
+import (
+	"bytes"
+	"expvar"
+	"fmt"
+	"hash/maphash"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+const (
+	originQuotaGranularityContainer = "container"
+	originQuotaGranularityPod       = "pod"
+	originQuotaActionDrop           = "drop"
+	originQuotaActionDownsample     = "downsample"
+
+	originQuotaStateDropped     = "dropped"
+	originQuotaStateDownsampled = "downsampled"
+
+	// originQuotaIdleTimeout is how long an origin sending no samples is
+	// tracked, its drop counts are removed from the telemetry and the
+	// status page afterwards.
+	originQuotaIdleTimeout = 5 * time.Minute
+
+	// originQuotaShards is the number of independently locked shards the
+	// origins are spread over, so that the connections don't contend on a
+	// single lock.
+	originQuotaShards = 16
+
+	containerToPodCacheKeyPrefix = "dogstatsd_origin_quota_pod"
+	containerToPodCacheDuration  = time.Minute
+)
+
+// dogstatsdOriginQuotaExpvars holds, for each origin exceeding its budget, the
+// number of samples dropped and downsampled.
+var dogstatsdOriginQuotaExpvars = expvar.NewMap("dogstatsd-origin-quota")
+
+// originQuotaConfig is the `dogstatsd_origin_quota` setting.
+type originQuotaConfig struct {
+	samplesPerSecond int
+	perPod           bool
+	downsample       bool
+}
+
+// getOriginQuotaConfig reads the `dogstatsd_origin_quota` setting, it returns
+// nil when the quotas are disabled.
+func getOriginQuotaConfig(cfg model.Reader) (*originQuotaConfig, error) {
+	if !cfg.GetBool("dogstatsd_origin_quota.enabled") {
+		return nil, nil
+	}
+
+	conf := &originQuotaConfig{samplesPerSecond: cfg.GetInt("dogstatsd_origin_quota.samples_per_second")}
+	if conf.samplesPerSecond <= 0 {
+		return nil, fmt.Errorf("dogstatsd_origin_quota.samples_per_second must be positive, got %d", conf.samplesPerSecond)
+	}
+
+	switch granularity := cfg.GetString("dogstatsd_origin_quota.granularity"); granularity {
+	case originQuotaGranularityContainer:
+	case originQuotaGranularityPod:
+		conf.perPod = true
+	default:
+		return nil, fmt.Errorf("invalid dogstatsd_origin_quota.granularity %q, must be one of container or pod", granularity)
+	}
+
+	switch action := cfg.GetString("dogstatsd_origin_quota.action"); action {
+	case originQuotaActionDrop:
+	case originQuotaActionDownsample:
+		conf.downsample = true
+	default:
+		return nil, fmt.Errorf("invalid dogstatsd_origin_quota.action %q, must be one of drop or downsample", action)
+	}
+
+	return conf, nil
+}
+
+// originBudget tracks the samples received from an origin during the current
+// one-second window.
+type originBudget struct {
+	window   int64 // unix time of the current window
+	count    int
+	lastSeen time.Time
+	// stats is the expvar of the origin, set on its first dropped or
+	// downsampled sample
+	stats *expvar.Map
+}
+
+// originQuotaShard holds the budgets of part of the origins.
+type originQuotaShard struct {
+	sync.Mutex
+	origins     map[string]*originBudget
+	lastCleanup time.Time
+}
+
+// OriginQuota limits the number of DogStatsD messages accepted per second
+// from each container or pod, so that a noisy origin cannot saturate the
+// packets queue and starve the others. It is applied by the UDS listeners
+// before the packets are queued, to the origin detected on the socket: the
+// packets without origin are never limited.
+//
+// Each message of a packet, be it a metric sample, an event or a service
+// check, counts as one sample. Once the budget of an origin is exhausted,
+// its packets are either dropped or downsampled: a packet bringing the count
+// of the window to n is kept with a probability of budget/n and the sample
+// rate of its metric samples is scaled accordingly, which keeps the counts
+// and rates unbiased.
+type OriginQuota struct {
+	conf  originQuotaConfig
+	wmeta option.Option[workloadmeta.Component]
+
+	tlmSamples telemetry.Counter
+
+	seed   maphash.Seed
+	shards [originQuotaShards]originQuotaShard
+}
+
+// NewOriginQuota returns the quota configured by `dogstatsd_origin_quota`, or
+// nil when the quotas are disabled.
+func NewOriginQuota(cfg model.Reader, wmeta option.Option[workloadmeta.Component], telemetrycomp telemetry.Component) (*OriginQuota, error) {
+	conf, err := getOriginQuotaConfig(cfg)
+	if err != nil || conf == nil {
+		return nil, err
+	}
+	return newOriginQuota(*conf, wmeta, telemetrycomp), nil
+}
+
+func newOriginQuota(conf originQuotaConfig, wmeta option.Option[workloadmeta.Component], telemetrycomp telemetry.Component) *OriginQuota {
+	q := &OriginQuota{
+		conf:  conf,
+		wmeta: wmeta,
+		tlmSamples: telemetrycomp.NewCounter("dogstatsd", "origin_quota_samples",
+			[]string{"origin", "state"}, "Count of DogStatsD messages dropped or downsampled because their origin exceeded its dogstatsd_origin_quota budget"),
+		seed: maphash.MakeSeed(),
+	}
+	now := time.Now()
+	for i := range q.shards {
+		q.shards[i].origins = make(map[string]*originBudget)
+		q.shards[i].lastCleanup = now
+	}
+	return q
+}
+
+// allow returns whether the packet must be queued, its QuotaKeepRate is set
+// when it is downsampled. It is called concurrently by the connections.
+func (q *OriginQuota) allow(packet *packets.Packet, now time.Time) bool {
+	if packet.Origin == packets.NoOrigin {
+		return true
+	}
+	// the pod is resolved before locking the shard, as it may query workloadmeta
+	origin := q.originKey(packet.Origin)
+	messages := countMessages(packet.Contents)
+
+	shard := &q.shards[maphash.String(q.seed, origin)%originQuotaShards]
+	shard.Lock()
+	defer shard.Unlock()
+
+	if now.Sub(shard.lastCleanup) >= originQuotaIdleTimeout {
+		q.cleanup(shard, now)
+	}
+
+	budget, found := shard.origins[origin]
+	if !found {
+		budget = &originBudget{}
+		shard.origins[origin] = budget
+	}
+	budget.lastSeen = now
+	if window := now.Unix(); budget.window != window {
+		budget.window = window
+		budget.count = 0
+	}
+	budget.count += messages
+	if budget.count <= q.conf.samplesPerSecond {
+		return true
+	}
+
+	if q.conf.downsample {
+		keepRate := float64(q.conf.samplesPerSecond) / float64(budget.count)
+		if rand.Float64() < keepRate {
+			packet.QuotaKeepRate = keepRate
+			q.record(origin, budget, originQuotaStateDownsampled, messages)
+			return true
+		}
+	}
+	q.record(origin, budget, originQuotaStateDropped, messages)
+	return false
+}
+
+// originKey returns the entity ID of the origin the budget of a packet sent
+// by the given container applies to.
+func (q *OriginQuota) originKey(containerEntity string) string {
+	if !q.conf.perPod {
+		return containerEntity
+	}
+	if podUID := q.containerPod(containerEntity); podUID != "" {
+		return types.NewEntityID(types.KubernetesPodUID, podUID).String()
+	}
+	return containerEntity
+}
+
+// containerPod returns the UID of the pod of the container, or an empty
+// string when it is unknown. Both results are cached for a short time, so
+// that workloadmeta is not queried for each packet of the containers outside
+// of a pod.
+func (q *OriginQuota) containerPod(containerEntity string) string {
+	key := cache.BuildAgentKey(containerToPodCacheKeyPrefix, containerEntity)
+	if x, found := cache.Cache.Get(key); found {
+		return x.(string)
+	}
+
+	var podUID string
+	if wmeta, ok := q.wmeta.Get(); ok {
+		if _, containerID, err := types.ExtractPrefixAndID(containerEntity); err == nil {
+			if pod, err := wmeta.GetKubernetesPodForContainer(containerID); err == nil {
+				podUID = pod.ID
+			}
+		}
+	}
+	cache.Cache.Set(key, podUID, containerToPodCacheDuration)
+	return podUID
+}
+
+// record counts the dropped or downsampled messages of a packet. It must be
+// called with the lock of the shard held.
+func (q *OriginQuota) record(origin string, budget *originBudget, state string, messages int) {
+	q.tlmSamples.Add(float64(messages), origin, state)
+	if budget.stats == nil {
+		budget.stats = new(expvar.Map).Init()
+		budget.stats.Add("Dropped", 0)
+		budget.stats.Add("Downsampled", 0)
+		dogstatsdOriginQuotaExpvars.Set(origin, budget.stats)
+	}
+	if state == originQuotaStateDropped {
+		budget.stats.Add("Dropped", int64(messages))
+	} else {
+		budget.stats.Add("Downsampled", int64(messages))
+	}
+}
+
+// cleanup forgets the origins of the shard which sent no samples for
+// originQuotaIdleTimeout. It must be called with the lock of the shard held.
+func (q *OriginQuota) cleanup(shard *originQuotaShard, now time.Time) {
+	for origin, budget := range shard.origins {
+		if now.Sub(budget.lastSeen) < originQuotaIdleTimeout {
+			continue
+		}
+		delete(shard.origins, origin)
+		if budget.stats != nil {
+			dogstatsdOriginQuotaExpvars.Delete(origin)
+			q.tlmSamples.Delete(origin, originQuotaStateDropped)
+			q.tlmSamples.Delete(origin, originQuotaStateDownsampled)
+		}
+	}
+	shard.lastCleanup = now
+}
+
+// countMessages returns the number of newline separated messages of a packet.
+func countMessages(contents []byte) int {
+	count := bytes.Count(contents, []byte{'\n'})
+	if len(contents) > 0 && contents[len(contents)-1] != '\n' {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build test
+
+package listeners
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry"
+	"github.com/DataDog/datadog-agent/comp/core/telemetry/telemetryimpl"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
+	workloadmetamock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/mock"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+func newTestOriginQuota(t *testing.T, conf originQuotaConfig, wmeta option.Option[workloadmeta.Component]) *OriginQuota {
+	telemetryComp := fxutil.Test[telemetry.Component](t, telemetryimpl.MockModule())
+	q := newOriginQuota(conf, wmeta, telemetryComp)
+	t.Cleanup(func() {
+		dogstatsdOriginQuotaExpvars.Init()
+		cache.Cache.Flush()
+	})
+	return q
+}
+
+// containerPacket returns a packet of the given messages sent by a container.
+func containerPacket(containerID string, messages ...string) *packets.Packet {
+	packet := &packets.Packet{Contents: []byte(strings.Join(messages, "\n"))}
+	if containerID != "" {
+		packet.Origin = "container_id://" + containerID
+	}
+	return packet
+}
+
+func originQuotaStats(t *testing.T, origin string) (dropped int64, downsampled int64) {
+	stats, ok := dogstatsdOriginQuotaExpvars.Get(origin).(*expvar.Map)
+	require.True(t, ok, "no stats for %s", origin)
+	return stats.Get("Dropped").(*expvar.Int).Value(), stats.Get("Downsampled").(*expvar.Int).Value()
+}
+
+func TestGetOriginQuotaConfig(t *testing.T) {
+	conf, err := getOriginQuotaConfig(configmock.New(t))
+	require.NoError(t, err)
+	assert.Nil(t, conf)
+
+	conf, err = getOriginQuotaConfig(configmock.NewFromYAML(t, `
+dogstatsd_origin_quota:
+  enabled: true
+  samples_per_second: 100
+  granularity: pod
+  action: downsample
+`))
+	require.NoError(t, err)
+	assert.Equal(t, &originQuotaConfig{samplesPerSecond: 100, perPod: true, downsample: true}, conf)
+
+	for _, yaml := range []string{
+		"dogstatsd_origin_quota: {enabled: true, samples_per_second: 0}",
+		"dogstatsd_origin_quota: {enabled: true, granularity: node}",
+		"dogstatsd_origin_quota: {enabled: true, action: throttle}",
+	} {
+		_, err := getOriginQuotaConfig(configmock.NewFromYAML(t, yaml))
+		assert.Error(t, err, yaml)
+	}
+}
+
+func TestCountMessages(t *testing.T) {
+	assert.Equal(t, 0, countMessages(nil))
+	assert.Equal(t, 1, countMessages([]byte("requests:1|c")))
+	assert.Equal(t, 2, countMessages([]byte("requests:1|c\nrequests:1|c")))
+	assert.Equal(t, 2, countMessages([]byte("requests:1|c\nrequests:1|c\n")))
+}
+
+func TestOriginQuotaDrop(t *testing.T) {
+	q := newTestOriginQuota(t, originQuotaConfig{samplesPerSecond: 3}, option.None[workloadmeta.Component]())
+	now := time.Unix(1700000000, 0)
+
+	// each message counts against the budget
+	assert.True(t, q.allow(containerPacket("noisy", "requests:1|c", "requests:1|c"), now))
+	assert.True(t, q.allow(containerPacket("noisy", "requests:1|c"), now))
+	assert.False(t, q.allow(containerPacket("noisy", "requests:1|c", "requests:1|c"), now))
+	assert.False(t, q.allow(containerPacket("noisy", "requests:1|c"), now.Add(500*time.Millisecond)))
+
+	// the other origins have their own budget
+	assert.True(t, q.allow(containerPacket("quiet", "requests:1|c"), now))
+
+	// packets without origin are not limited
+	for i := 0; i < 5; i++ {
+		assert.True(t, q.allow(containerPacket("", "requests:1|c"), now))
+	}
+
+	// the budget is reset every second
+	assert.True(t, q.allow(containerPacket("noisy", "requests:1|c"), now.Add(time.Second)))
+
+	dropped, downsampled := originQuotaStats(t, "container_id://noisy")
+	assert.Equal(t, int64(3), dropped)
+	assert.Equal(t, int64(0), downsampled)
+	assert.Nil(t, dogstatsdOriginQuotaExpvars.Get("container_id://quiet"))
+}
+
+func TestOriginQuotaDownsample(t *testing.T) {
+	q := newTestOriginQuota(t, originQuotaConfig{samplesPerSecond: 10, downsample: true}, option.None[workloadmeta.Component]())
+	now := time.Unix(1700000000, 0)
+
+	kept := 0
+	weight := 0.0
+	for i := 0; i < 1000; i++ {
+		packet := containerPacket("noisy", "requests:1|c")
+		if q.allow(packet, now) {
+			kept++
+			if packet.QuotaKeepRate > 0 {
+				weight += 1 / packet.QuotaKeepRate
+			} else {
+				weight++
+			}
+		}
+	}
+
+	dropped, downsampled := originQuotaStats(t, "container_id://noisy")
+	assert.Equal(t, int64(1000), int64(kept)+dropped)
+	assert.Equal(t, int64(kept-10), downsampled)
+	// about 10*(1+ln(100)) packets are kept, weighted to the received count
+	assert.Less(t, kept, 200)
+	assert.InDelta(t, 1000, weight, 500)
+}
+
+func TestOriginQuotaPod(t *testing.T) {
+	wmeta := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		config.MockModule(),
+		fx.Supply(context.Background()),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+	wmeta.Set(&workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+	})
+	for _, id := range []string{"app", "sidecar"} {
+		wmeta.Set(&workloadmeta.Container{
+			EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: id},
+			Owner:    &workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+		})
+	}
+
+	q := newTestOriginQuota(t, originQuotaConfig{samplesPerSecond: 2, perPod: true}, option.New[workloadmeta.Component](wmeta))
+	now := time.Unix(1700000000, 0)
+
+	// the containers of a pod share its budget
+	assert.True(t, q.allow(containerPacket("app", "requests:1|c"), now))
+	assert.True(t, q.allow(containerPacket("sidecar", "requests:1|c"), now))
+	assert.False(t, q.allow(containerPacket("app", "requests:1|c"), now))
+
+	// containers outside of a pod have their own budget
+	assert.True(t, q.allow(containerPacket("standalone", "requests:1|c"), now))
+	assert.True(t, q.allow(containerPacket("standalone", "requests:1|c"), now))
+	assert.False(t, q.allow(containerPacket("standalone", "requests:1|c"), now))
+
+	dropped, _ := originQuotaStats(t, "kubernetes_pod_uid://pod-uid")
+	assert.Equal(t, int64(1), dropped)
+	dropped, _ = originQuotaStats(t, "container_id://standalone")
+	assert.Equal(t, int64(1), dropped)
+
+	// the containers without pod are cached too
+	podUID, found := cache.Cache.Get(cache.BuildAgentKey(containerToPodCacheKeyPrefix, "container_id://standalone"))
+	assert.True(t, found)
+	assert.Equal(t, "", podUID)
+}
+
+func TestOriginQuotaCleanup(t *testing.T) {
+	q := newTestOriginQuota(t, originQuotaConfig{samplesPerSecond: 1}, option.None[workloadmeta.Component]())
+	now := time.Now()
+
+	assert.True(t, q.allow(containerPacket("gone", "requests:1|c"), now))
+	assert.False(t, q.allow(containerPacket("gone", "requests:1|c"), now))
+	require.NotNil(t, dogstatsdOriginQuotaExpvars.Get("container_id://gone"))
+
+	// the origins are cleaned up when their shard is used
+	for i := range q.shards {
+		q.shards[i].lastCleanup = now.Add(-originQuotaIdleTimeout)
+	}
+	later := now.Add(originQuotaIdleTimeout)
+	for i := 0; i < 100; i++ {
+		q.allow(containerPacket(fmt.Sprintf("other-%d", i), "requests:1|c"), later)
+	}
+	assert.Nil(t, dogstatsdOriginQuotaExpvars.Get("container_id://gone"))
+	for i := range q.shards {
+		assert.NotContains(t, q.shards[i].origins, "container_id://gone")
+	}
+}
+
+func TestOriginQuotaConcurrent(t *testing.T) {
+	q := newTestOriginQuota(t, originQuotaConfig{samplesPerSecond: 100}, option.None[workloadmeta.Component]())
+	now := time.Unix(1700000000, 0)
+
+	// the connections share the budget of an origin
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	kept := 0
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if q.allow(containerPacket("noisy", "requests:1|c"), now) {
+					m.Lock()
+					kept++
+					m.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 100, kept)
+	dropped, _ := originQuotaStats(t, "container_id://noisy")
+	assert.Equal(t, int64(300), dropped)
+}
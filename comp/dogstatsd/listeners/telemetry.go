@@ -23,6 +23,9 @@ type TelemetryStore struct {
 	tlmUDSOriginDetectionError telemetry.Counter
 	tlmUDSPacketsBytes         telemetry.Counter
 	tlmUDSConnections          telemetry.Gauge
+	// Graphite and InfluxDB line protocols
+	tlmLineProtocolPackets telemetry.Counter
+	tlmLineProtocolBytes   telemetry.Counter
 
 	tlmListener telemetry.Histogram
 }
@@ -46,6 +49,10 @@ func NewTelemetryStore(buckets []float64, telemetrycomp telemetry.Component) *Te
 			[]string{"listener_id", "transport"}, "Dogstatsd UDS packets bytes"),
 		tlmUDSConnections: telemetrycomp.NewGauge("dogstatsd", "uds_connections",
 			[]string{"listener_id", "transport"}, "Dogstatsd UDS connections count"),
+		tlmLineProtocolPackets: telemetrycomp.NewCounter("dogstatsd", "line_protocol_packets",
+			[]string{"protocol", "transport", "state"}, "Dogstatsd Graphite and InfluxDB line protocols packets count"),
+		tlmLineProtocolBytes: telemetrycomp.NewCounter("dogstatsd", "line_protocol_packets_bytes",
+			[]string{"protocol", "transport"}, "Dogstatsd Graphite and InfluxDB line protocols packets bytes count"),
 		tlmListener: telemetrycomp.NewHistogram(
 			"dogstatsd",
 			"listener_read_latency",
@@ -61,6 +61,10 @@ type UDSListener struct {
 
 	wmeta option.Option[workloadmeta.Component]
 
+	// originQuota limits the packets queued for each origin, it is nil
+	// when dogstatsd_origin_quota is disabled.
+	originQuota *OriginQuota
+
 	transport string
 
 	dogstatsdMemBasedRateLimiter bool
@@ -145,13 +149,14 @@ func NewUDSOobPoolManager() *packets.PoolManager[[]byte] {
 }
 
 // NewUDSListener returns an idle UDS Statsd listener
-func NewUDSListener(packetOut chan packets.Packets, sharedPacketPoolManager *packets.PoolManager[packets.Packet], sharedOobPacketPoolManager *packets.PoolManager[[]byte], cfg model.Reader, capture replay.Component, transport string, wmeta option.Option[workloadmeta.Component], pidMap pidmap.Component, telemetryStore *TelemetryStore, packetsTelemetryStore *packets.TelemetryStore, telemetry telemetry.Component, originDetection bool) (*UDSListener, error) {
+func NewUDSListener(packetOut chan packets.Packets, sharedPacketPoolManager *packets.PoolManager[packets.Packet], sharedOobPacketPoolManager *packets.PoolManager[[]byte], cfg model.Reader, capture replay.Component, transport string, wmeta option.Option[workloadmeta.Component], pidMap pidmap.Component, originQuota *OriginQuota, telemetryStore *TelemetryStore, packetsTelemetryStore *packets.TelemetryStore, telemetry telemetry.Component, originDetection bool) (*UDSListener, error) {
 	listener := &UDSListener{
 		OriginDetection:              originDetection,
 		packetOut:                    packetOut,
 		sharedPacketPoolManager:      sharedPacketPoolManager,
 		trafficCapture:               capture,
 		pidMap:                       pidMap,
+		originQuota:                  originQuota,
 		dogstatsdMemBasedRateLimiter: cfg.GetBool("dogstatsd_mem_based_rate_limiter.enabled"),
 		config:                       cfg,
 		transport:                    transport,
@@ -367,6 +372,11 @@ func (l *UDSListener) handleConnection(conn netUnixConn, closeFunc CloseFunction
 		packet.Source = packets.UDS
 		packet.ListenerID = listenerID
 
+		if l.originQuota != nil && !l.originQuota.allow(packet, t1) {
+			l.sharedPacketPoolManager.Put(packet)
+			continue
+		}
+
 		// packetsBuffer handles the forwarding of the packets to the dogstatsd server intake channel
 		packetsBuffer.Append(packet)
 	}
@@ -25,7 +25,7 @@ import (
 )
 
 func udsDatagramListenerFactory(packetOut chan packets.Packets, manager *packets.PoolManager[packets.Packet], cfg config.Component, pidMap pidmap.Component, telemetryStore *TelemetryStore, packetsTelemetryStore *packets.TelemetryStore, telemetry telemetry.Component) (StatsdListener, error) {
-	return NewUDSDatagramListener(packetOut, manager, nil, cfg, nil, option.None[workloadmeta.Component](), pidMap, telemetryStore, packetsTelemetryStore, telemetry)
+	return NewUDSDatagramListener(packetOut, manager, nil, cfg, nil, option.None[workloadmeta.Component](), pidMap, nil, telemetryStore, packetsTelemetryStore, telemetry)
 }
 
 func TestNewUDSDatagramListener(t *testing.T) {
@@ -21,6 +21,14 @@ var (
 const (
 	matchTypeWildcard = "wildcard"
 	matchTypeRegex    = "regex"
+
+	actionMap  = "map"
+	actionDrop = "drop"
+	actionKeep = "keep"
+
+	tagOperationRename  = "rename"
+	tagOperationRemove  = "remove"
+	tagOperationReplace = "replace"
 )
 
 //
@@ -40,10 +48,21 @@ type MappingProfileConfig struct {
 
 // MetricMapping represent one mapping rule
 type MetricMappingConfig struct {
-	Match     string            `mapstructure:"match" json:"match" yaml:"match"`
-	MatchType string            `mapstructure:"match_type" json:"match_type" yaml:"match_type"`
-	Name      string            `mapstructure:"name" json:"name" yaml:"name"`
-	Tags      map[string]string `mapstructure:"tags" json:"tags" yaml:"tags"`
+	Match         string               `mapstructure:"match" json:"match" yaml:"match"`
+	MatchType     string               `mapstructure:"match_type" json:"match_type" yaml:"match_type"`
+	Action        string               `mapstructure:"action" json:"action,omitempty" yaml:"action,omitempty"`
+	Name          string               `mapstructure:"name" json:"name" yaml:"name"`
+	Tags          map[string]string    `mapstructure:"tags" json:"tags" yaml:"tags"`
+	TagOperations []TagOperationConfig `mapstructure:"tag_operations" json:"tag_operations,omitempty" yaml:"tag_operations,omitempty"`
+}
+
+// TagOperationConfig represent one operation on the tags of the mapped metrics
+type TagOperationConfig struct {
+	Operation   string `mapstructure:"operation" json:"operation" yaml:"operation"`
+	Tag         string `mapstructure:"tag" json:"tag" yaml:"tag"`
+	NewTag      string `mapstructure:"new_tag" json:"new_tag,omitempty" yaml:"new_tag,omitempty"`
+	Pattern     string `mapstructure:"pattern" json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Replacement string `mapstructure:"replacement" json:"replacement,omitempty" yaml:"replacement,omitempty"`
 }
 
 // MetricMapper contains mappings and cache instance
@@ -61,16 +80,30 @@ type MappingProfile struct {
 
 // MetricMapping represent one mapping rule
 type MetricMapping struct {
-	name  string
-	tags  map[string]string
-	regex *regexp.Regexp
+	action        string
+	name          string
+	tags          map[string]string
+	tagOperations []*tagOperation
+	regex         *regexp.Regexp
+}
+
+// tagOperation represent one operation on the tags with the given key
+type tagOperation struct {
+	operation   string
+	key         string
+	newKey      string
+	pattern     *regexp.Regexp
+	replacement string
 }
 
 // MapResult represent the outcome of the mapping
 type MapResult struct {
-	Name    string
-	Tags    []string
-	matched bool
+	Name string
+	Tags []string
+	// Drop is true if the metric must be dropped
+	Drop          bool
+	tagOperations []*tagOperation
+	matched       bool
 }
 
 // NewMetricMapper creates, validates, prepares a new MetricMapper
@@ -96,8 +129,25 @@ func NewMetricMapper(configProfiles []MappingProfileConfig, cacheSize int) (*Met
 			if matchType != matchTypeWildcard && matchType != matchTypeRegex {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid match type, must be `wildcard` or `regex`", profile.Name, i)
 			}
-			if currentMapping.Name == "" {
-				return nil, fmt.Errorf("profile: %s, mapping num %d: name is required", profile.Name, i)
+			action := currentMapping.Action
+			if action == "" {
+				action = actionMap
+			}
+			switch action {
+			case actionMap:
+				if currentMapping.Name == "" {
+					return nil, fmt.Errorf("profile: %s, mapping num %d: name is required", profile.Name, i)
+				}
+			case actionDrop:
+				if currentMapping.Name != "" || len(currentMapping.Tags) > 0 || len(currentMapping.TagOperations) > 0 {
+					return nil, fmt.Errorf("profile: %s, mapping num %d: name, tags and tag_operations are not allowed with the `drop` action", profile.Name, i)
+				}
+			case actionKeep:
+				if currentMapping.Name != "" {
+					return nil, fmt.Errorf("profile: %s, mapping num %d: name is not allowed with the `keep` action", profile.Name, i)
+				}
+			default:
+				return nil, fmt.Errorf("profile: %s, mapping num %d: invalid action, must be `map`, `drop` or `keep`", profile.Name, i)
 			}
 			if currentMapping.Match == "" {
 				return nil, fmt.Errorf("profile: %s, mapping num %d: match is required", profile.Name, i)
@@ -106,7 +156,17 @@ func NewMetricMapper(configProfiles []MappingProfileConfig, cacheSize int) (*Met
 			if err != nil {
 				return nil, err
 			}
-			profile.Mappings = append(profile.Mappings, &MetricMapping{name: currentMapping.Name, tags: currentMapping.Tags, regex: regex})
+			tagOperations, err := buildTagOperations(currentMapping.TagOperations)
+			if err != nil {
+				return nil, fmt.Errorf("profile: %s, mapping num %d: %v", profile.Name, i, err)
+			}
+			profile.Mappings = append(profile.Mappings, &MetricMapping{
+				action:        action,
+				name:          currentMapping.Name,
+				tags:          currentMapping.Tags,
+				tagOperations: tagOperations,
+				regex:         regex,
+			})
 		}
 		profiles = append(profiles, profile)
 	}
@@ -135,6 +195,41 @@ func buildRegex(matchRe string, matchType string) (*regexp.Regexp, error) {
 	return regex, nil
 }
 
+func buildTagOperations(configOperations []TagOperationConfig) ([]*tagOperation, error) {
+	if len(configOperations) == 0 {
+		return nil, nil
+	}
+	operations := make([]*tagOperation, 0, len(configOperations))
+	for i, configOperation := range configOperations {
+		if configOperation.Tag == "" {
+			return nil, fmt.Errorf("tag operation num %d: tag is required", i)
+		}
+		operation := &tagOperation{operation: configOperation.Operation, key: configOperation.Tag}
+		switch configOperation.Operation {
+		case tagOperationRename:
+			if configOperation.NewTag == "" {
+				return nil, fmt.Errorf("tag operation num %d: new_tag is required to rename a tag", i)
+			}
+			operation.newKey = configOperation.NewTag
+		case tagOperationRemove:
+		case tagOperationReplace:
+			if configOperation.Pattern == "" {
+				return nil, fmt.Errorf("tag operation num %d: pattern is required to replace a tag value", i)
+			}
+			pattern, err := regexp.Compile(configOperation.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("tag operation num %d: invalid pattern `%s`: %v", i, configOperation.Pattern, err)
+			}
+			operation.pattern = pattern
+			operation.replacement = configOperation.Replacement
+		default:
+			return nil, fmt.Errorf("tag operation num %d: invalid operation, must be `rename`, `remove` or `replace`", i)
+		}
+		operations = append(operations, operation)
+	}
+	return operations, nil
+}
+
 // Map returns a MapResult
 func (m *MetricMapper) Map(metricName string) *MapResult {
 	for _, profile := range m.Profiles {
@@ -154,12 +249,21 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 				continue
 			}
 
-			name := string(mapping.regex.ExpandString(
-				[]byte{},
-				mapping.name,
-				metricName,
-				matches,
-			))
+			if mapping.action == actionDrop {
+				mapResult := &MapResult{Drop: true, matched: true}
+				m.cache.add(metricName, mapResult)
+				return mapResult
+			}
+
+			name := metricName
+			if mapping.action == actionMap {
+				name = string(mapping.regex.ExpandString(
+					[]byte{},
+					mapping.name,
+					metricName,
+					matches,
+				))
+			}
 
 			tags := make([]string, 0, len(mapping.tags))
 			for tagKey, tagValueExpr := range mapping.tags {
@@ -167,7 +271,7 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 				tags = append(tags, tagKey+":"+tagValue)
 			}
 
-			mapResult := &MapResult{Name: name, matched: true, Tags: tags}
+			mapResult := &MapResult{Name: name, matched: true, Tags: tags, tagOperations: mapping.tagOperations}
 			m.cache.add(metricName, mapResult)
 			return mapResult
 		}
@@ -177,3 +281,48 @@ func (m *MetricMapper) Map(metricName string) *MapResult {
 	}
 	return nil
 }
+
+// ApplyTagOperations applies the tag operations of the mapping to the given
+// tags, in place, and returns the resulting tags.
+func (r *MapResult) ApplyTagOperations(tags []string) []string {
+	if len(r.tagOperations) == 0 {
+		return tags
+	}
+	kept := tags[:0]
+	for _, tag := range tags {
+		if tag, ok := r.applyTagOperations(tag); ok {
+			kept = append(kept, tag)
+		}
+	}
+	return kept
+}
+
+// applyTagOperations returns the tag updated by the tag operations, or false
+// if the tag is removed.
+func (r *MapResult) applyTagOperations(tag string) (string, bool) {
+	for _, operation := range r.tagOperations {
+		key, value, hasValue := strings.Cut(tag, ":")
+		if key != operation.key {
+			continue
+		}
+		switch operation.operation {
+		case tagOperationRename:
+			tag = operation.newKey
+			if hasValue {
+				tag += ":" + value
+			}
+		case tagOperationRemove:
+			return "", false
+		case tagOperationReplace:
+			if !hasValue {
+				continue
+			}
+			value = operation.pattern.ReplaceAllString(value, operation.replacement)
+			if value == "" {
+				return "", false
+			}
+			tag = key + ":" + value
+		}
+	}
+	return tag, true
+}
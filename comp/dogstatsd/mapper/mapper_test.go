@@ -354,6 +354,79 @@ dogstatsd_mapper_profiles:
 	}
 }
 
+func TestMappingActions(t *testing.T) {
+	mapper, err := getMapper(t, `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        action: keep
+        tags:
+          job: "$1"
+      - match: "test.job.*.*"
+        action: drop
+      - match: "test.task.*"
+        action: map
+        name: "test.task"
+        tags:
+          task: "$1"
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &MapResult{Name: "test.job.duration.backup", Tags: []string{"job:backup"}, matched: true}, mapper.Map("test.job.duration.backup"))
+	assert.Equal(t, &MapResult{Drop: true, matched: true}, mapper.Map("test.job.size.backup"))
+	assert.Equal(t, &MapResult{Name: "test.task", Tags: []string{"task:backup"}, matched: true}, mapper.Map("test.task.backup"))
+	assert.Nil(t, mapper.Map("test.other"))
+
+	// the drop decisions are cached
+	result, cached := mapper.cache.get("test.job.size.backup")
+	assert.True(t, cached)
+	assert.True(t, result.Drop)
+	assert.Equal(t, &MapResult{Drop: true, matched: true}, mapper.Map("test.job.size.backup"))
+}
+
+func TestMappingTagOperations(t *testing.T) {
+	mapper, err := getMapper(t, `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.requests.*"
+        name: "test.requests"
+        tags:
+          endpoint: "$1"
+        tag_operations:
+          - operation: rename
+            tag: svc
+            new_tag: service
+          - operation: remove
+            tag: request_id
+          - operation: replace
+            tag: status
+            pattern: '^(\d)\d\d$'
+            replacement: '${1}xx'
+          - operation: replace
+            tag: user
+            pattern: '.*'
+            replacement: ''
+      - match: "test.other"
+        name: "test.other"
+`)
+	require.NoError(t, err)
+
+	result := mapper.Map("test.requests.login")
+	require.NotNil(t, result)
+	tags := result.ApplyTagOperations([]string{"svc:web", "request_id:42", "status:404", "user:bob", "svc", "env:prod", "status"})
+	assert.Equal(t, []string{"service:web", "status:4xx", "service", "env:prod", "status"}, tags)
+	assert.Equal(t, []string{"endpoint:login"}, result.Tags)
+
+	// tags are left untouched without tag operations
+	result = mapper.Map("test.other")
+	require.NotNil(t, result)
+	assert.Equal(t, []string{"svc:web", "request_id:42"}, result.ApplyTagOperations([]string{"svc:web", "request_id:42"}))
+}
+
 func TestMappingErrors(t *testing.T) {
 	scenarios := []struct {
 		name          string
@@ -474,6 +547,91 @@ dogstatsd_mapper_profiles:
 			},
 			expectedError: "invalid match type",
 		},
+		{
+			name: "Invalid action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: rename
+        name: "test.job.duration"
+`,
+			expectedError: "invalid action",
+		},
+		{
+			name: "Name with drop action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: drop
+        name: "test.job.duration"
+`,
+			expectedError: "are not allowed with the `drop` action",
+		},
+		{
+			name: "Name with keep action",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        action: keep
+        name: "test.job.duration"
+`,
+			expectedError: "name is not allowed with the `keep` action",
+		},
+		{
+			name: "Invalid tag operation",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        name: "test.job.duration"
+        tag_operations:
+          - operation: upper
+            tag: job
+`,
+			expectedError: "invalid operation",
+		},
+		{
+			name: "Rename without new tag",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        name: "test.job.duration"
+        tag_operations:
+          - operation: rename
+            tag: job
+`,
+			expectedError: "new_tag is required",
+		},
+		{
+			name: "Invalid replace pattern",
+			config: `
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration"
+        name: "test.job.duration"
+        tag_operations:
+          - operation: replace
+            tag: job
+            pattern: '(['
+`,
+			expectedError: "invalid pattern",
+		},
 		{
 			name: "Missing profile name",
 			config: `
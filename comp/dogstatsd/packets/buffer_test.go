@@ -57,7 +57,7 @@ func TestBufferTelemetry(t *testing.T) {
 
 	bufferSizeBytesMetricLabel := bufferSizeBytesMetrics[0].Tags()
 	assert.Equal(t, bufferSizeBytesMetricLabel["listener_id"], "test_buffer")
-	assert.Equal(t, float64(262), bufferSizeBytesMetrics[0].Value())
+	assert.Equal(t, float64(278), bufferSizeBytesMetrics[0].Value())
 }
 
 func TestBufferTelemetryFull(t *testing.T) {
@@ -124,7 +124,7 @@ func TestBufferTelemetryFull(t *testing.T) {
 
 	channelPacketsBytesMetricLabel := channelPacketsBytesMetrics[0].Tags()
 	assert.Equal(t, channelPacketsBytesMetricLabel["listener_id"], "test_buffer")
-	assert.Equal(t, float64(131), channelPacketsBytesMetrics[0].Value())
+	assert.Equal(t, float64(139), channelPacketsBytesMetrics[0].Value())
 
 	assert.Equal(t, float64(1), channelSizeMetrics[0].Value())
 }
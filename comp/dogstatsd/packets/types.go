@@ -21,6 +21,10 @@ const (
 	UDS
 	// NamedPipe Windows named pipe listner
 	NamedPipe
+	// Graphite listener of the Graphite plaintext protocol
+	Graphite
+	// Influx listener of the InfluxDB line protocol
+	Influx
 )
 
 // Packet represents a statsd packet ready to process,
@@ -36,6 +40,9 @@ type Packet struct {
 	ProcessID  uint32     // ProcessID that sent the packet
 	ListenerID string     // Listener ID
 	Source     SourceType // Type of listener that produced the packet
+	// QuotaKeepRate is the probability with which the packet was kept when its
+	// origin exceeded its quota, 0 when it was not downsampled
+	QuotaKeepRate float64
 }
 
 // Packets is a slice of packet pointers
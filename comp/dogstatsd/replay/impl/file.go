@@ -6,11 +6,15 @@
 package replayimpl
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/h2non/filetype"
 	"github.com/h2non/filetype/matchers"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/core"
 )
 
 var (
@@ -73,3 +77,59 @@ func WriteHeader(w io.Writer) error {
 
 	return nil
 }
+
+// WriteMessage writes a packet to the Writer argument to conform to the .dog
+// file format: the serialized message prefixed with its size.
+func WriteMessage(w io.Writer, msg *pb.UnixDogstatsdMsg) error {
+	buff, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = writeRecord(w, buff)
+	return err
+}
+
+// WriteState writes the tagger state ending the .dog file format to the
+// Writer argument and returns the number of bytes written.
+func WriteState(w io.Writer, state *pb.TaggerState) (int, error) {
+	s, err := proto.Marshal(state)
+	if err != nil {
+		return 0, err
+	}
+
+	// Record State Separator
+	if n, err := w.Write([]byte{0, 0, 0, 0}); err != nil {
+		return n, err
+	}
+
+	// Record State
+	n, err := w.Write(s)
+
+	// Record size
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(s)))
+
+	if n, err := w.Write(buf); err != nil {
+		return n, err
+	}
+
+	// n + 4 bytes for separator + 4 bytes for state size
+	return n + 8, err
+}
+
+// writeRecord writes the byte slice argument prefixed with its size.
+func writeRecord(w io.Writer, p []byte) (int, error) {
+	buf := make([]byte, 4)
+	binary.LittleEndian.PutUint32(buf, uint32(len(p)))
+
+	// Record size
+	if n, err := w.Write(buf); err != nil {
+		return n, err
+	}
+
+	// Record
+	n, err := w.Write(p)
+
+	return n + 4, err
+}
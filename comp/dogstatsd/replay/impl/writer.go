@@ -7,7 +7,6 @@ package replayimpl
 
 import (
 	"bufio"
-	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
@@ -20,8 +19,6 @@ import (
 	"github.com/DataDog/zstd"
 	"github.com/spf13/afero"
 
-	"github.com/golang/protobuf/proto"
-
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
 	taggerproto "github.com/DataDog/datadog-agent/comp/core/tagger/proto"
 	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
@@ -348,29 +345,7 @@ func (tc *TrafficCaptureWriter) writeState() (int, error) {
 
 	log.Debugf("Going to write STATE: %#v", pbState)
 
-	s, err := proto.Marshal(pbState)
-	if err != nil {
-		return 0, err
-	}
-
-	// Record State Separator
-	if n, err := tc.writer.Write([]byte{0, 0, 0, 0}); err != nil {
-		return n, err
-	}
-
-	// Record State
-	n, err := tc.writer.Write(s)
-
-	// Record size
-	buf := make([]byte, 4)
-	binary.LittleEndian.PutUint32(buf, uint32(len(s)))
-
-	if n, err := tc.writer.Write(buf); err != nil {
-		return n, err
-	}
-
-	// n + 4 bytes for separator + 4 bytes for state size
-	return n + 8, err
+	return WriteState(tc.writer, pbState)
 }
 
 // writeNext writes the next replay.CaptureBuffer after serializing it to a protobuf format.
@@ -385,27 +360,10 @@ func (tc *TrafficCaptureWriter) writeNext(msg *replay.CaptureBuffer) error {
 		Ancillary:     msg.Pb.Ancillary,
 	}
 
-	buff, err := proto.Marshal(&pb)
-	if err != nil {
-		return err
-	}
-
-	_, err = tc.Write(buff)
-	return err
+	return WriteMessage(tc.writer, &pb)
 }
 
 // Write writes the byte slice argument to file.
 func (tc *TrafficCaptureWriter) Write(p []byte) (int, error) {
-	buf := make([]byte, 4)
-	binary.LittleEndian.PutUint32(buf, uint32(len(p)))
-
-	// Record size
-	if n, err := tc.writer.Write(buf); err != nil {
-		return n, err
-	}
-
-	// Record
-	n, err := tc.writer.Write(p)
-
-	return n + 4, err
+	return writeRecord(tc.writer, p)
 }
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+var (
+	graphiteTagSeparator      = []byte(";")
+	graphiteTagValueSeparator = []byte("=")
+)
+
+// parseGraphiteMetricSample parses a line of the Graphite plaintext protocol,
+// `<path>[;<tag>=<value>...] <value> [<timestamp>]`, into a gauge. Tags of
+// the Graphite tag support are turned into `<tag>:<value>` tags.
+func (p *parser) parseGraphiteMetricSample(message []byte) (dogstatsdMetricSample, error) {
+	fields := bytes.Fields(message)
+	if len(fields) != 2 && len(fields) != 3 {
+		return dogstatsdMetricSample{}, fmt.Errorf("invalid graphite message format")
+	}
+
+	path, rawTags, _ := bytes.Cut(fields[0], graphiteTagSeparator)
+	if len(path) == 0 {
+		return dogstatsdMetricSample{}, fmt.Errorf("invalid graphite metric path: %q", fields[0])
+	}
+	var tags []string
+	if len(rawTags) > 0 {
+		tags = make([]string, 0, bytes.Count(rawTags, graphiteTagSeparator)+1)
+		for len(rawTags) > 0 {
+			var rawTag []byte
+			rawTag, rawTags, _ = bytes.Cut(rawTags, graphiteTagSeparator)
+			key, value, found := bytes.Cut(rawTag, graphiteTagValueSeparator)
+			if !found || len(key) == 0 || len(value) == 0 {
+				return dogstatsdMetricSample{}, fmt.Errorf("invalid graphite tag: %q", rawTag)
+			}
+			tags = append(tags, string(key)+":"+string(value))
+		}
+	}
+
+	value, err := parseFloat64(fields[1])
+	if err != nil {
+		return dogstatsdMetricSample{}, fmt.Errorf("could not parse graphite metric value: %v", err)
+	}
+
+	// timestamps are in seconds, carbon considers -1 as the time of reception
+	var timestamp time.Time
+	if len(fields) == 3 && p.readTimestamps {
+		ts, err := parseFloat64(fields[2])
+		if err != nil {
+			return dogstatsdMetricSample{}, fmt.Errorf("could not parse graphite timestamp %q: %v", fields[2], err)
+		}
+		if ts > 0 {
+			timestamp = time.Unix(int64(ts), 0)
+		}
+	}
+
+	return dogstatsdMetricSample{
+		name:       p.interner.LoadOrStore(path),
+		value:      value,
+		metricType: gaugeType,
+		sampleRate: 1,
+		tags:       tags,
+		ts:         timestamp,
+	}, nil
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+)
+
+func newTestParser(t *testing.T, overrides map[string]any) *parser {
+	deps := newServerDeps(t, fx.Replace(config.MockParams{Overrides: overrides}))
+	stringInternerTelemetry := newSiTelemetry(false, deps.Telemetry)
+	return newParser(deps.Config, newFloat64ListPool(deps.Telemetry), 1, deps.WMeta, stringInternerTelemetry)
+}
+
+func TestParseGraphite(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": false})
+
+	sample, err := p.parseGraphiteMetricSample([]byte("servers.web01.cpu.load 0.75 1700000000"))
+	require.NoError(t, err)
+	assert.Equal(t, "servers.web01.cpu.load", sample.name)
+	assert.InEpsilon(t, 0.75, sample.value, epsilon)
+	assert.Equal(t, gaugeType, sample.metricType)
+	assert.InEpsilon(t, 1.0, sample.sampleRate, epsilon)
+	assert.Empty(t, sample.tags)
+	// timestamps are only read without the aggregation pipeline
+	assert.Zero(t, sample.ts)
+
+	sample, err = p.parseGraphiteMetricSample([]byte("disk.used;host=web01;mount=/var  42"))
+	require.NoError(t, err)
+	assert.Equal(t, "disk.used", sample.name)
+	assert.InEpsilon(t, 42.0, sample.value, epsilon)
+	assert.Equal(t, []string{"host:web01", "mount:/var"}, sample.tags)
+}
+
+func TestParseGraphiteTimestamp(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": true})
+
+	sample, err := p.parseGraphiteMetricSample([]byte("cpu.load 0.75 1700000000"))
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0), sample.ts)
+
+	// carbon considers -1 as the time of reception
+	sample, err = p.parseGraphiteMetricSample([]byte("cpu.load 0.75 -1"))
+	require.NoError(t, err)
+	assert.Zero(t, sample.ts)
+}
+
+func TestParseGraphiteErrors(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": true})
+
+	for _, message := range []string{
+		"",
+		"cpu.load",
+		"cpu.load 1 2 3",
+		"cpu.load abc",
+		";host=web01 1",
+		"cpu.load;host 1",
+		"cpu.load;host= 1",
+		"cpu.load 1 yesterday",
+	} {
+		_, err := p.parseGraphiteMetricSample([]byte(message))
+		assert.Error(t, err, message)
+	}
+}
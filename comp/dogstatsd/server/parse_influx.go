@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// parseInfluxMetricSamples parses a line of the InfluxDB line protocol,
+// `<measurement>[,<tag>=<value>...] <field>=<value>[,<field>=<value>...] [<timestamp>]`,
+// into a gauge named `<measurement>.<field>` for every numeric or boolean
+// field, tagged with the `<tag>:<value>` tags of the line. String fields are
+// ignored.
+func (p *parser) parseInfluxMetricSamples(message []byte) ([]dogstatsdMetricSample, error) {
+	sections := splitInflux(message, ' ', true)
+	if len(sections) != 2 && len(sections) != 3 {
+		return nil, fmt.Errorf("invalid influx message format")
+	}
+
+	seriesKey := splitInflux(sections[0], ',', false)
+	measurement := unescapeInflux(seriesKey[0])
+	if len(measurement) == 0 {
+		return nil, fmt.Errorf("invalid influx measurement: %q", sections[0])
+	}
+	var tags []string
+	if len(seriesKey) > 1 {
+		tags = make([]string, 0, len(seriesKey)-1)
+		for _, rawTag := range seriesKey[1:] {
+			key, value, err := cutInfluxKeyValue(rawTag)
+			if err != nil || len(value) == 0 {
+				return nil, fmt.Errorf("invalid influx tag: %q", rawTag)
+			}
+			tags = append(tags, string(key)+":"+string(unescapeInflux(value)))
+		}
+	}
+
+	var timestamp time.Time
+	if len(sections) == 3 && p.readTimestamps {
+		ts, err := parseInt64(sections[2])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse influx timestamp %q: %v", sections[2], err)
+		}
+		timestamp = time.Unix(0, ts)
+	}
+
+	rawFields := splitInflux(sections[1], ',', true)
+	samples := make([]dogstatsdMetricSample, 0, len(rawFields))
+	for _, rawField := range rawFields {
+		key, rawValue, err := cutInfluxKeyValue(rawField)
+		if err != nil {
+			return nil, fmt.Errorf("invalid influx field: %q", rawField)
+		}
+		value, numeric, err := parseInfluxFieldValue(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse influx field %q: %v", key, err)
+		}
+		if !numeric {
+			continue
+		}
+		samples = append(samples, dogstatsdMetricSample{
+			name:       p.interner.LoadOrStore(append(append(append([]byte{}, measurement...), '.'), key...)),
+			value:      value,
+			metricType: gaugeType,
+			sampleRate: 1,
+			// the tags of the samples are updated in place by their enrichment
+			tags: slices.Clone(tags),
+			ts:   timestamp,
+		})
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no numeric influx field")
+	}
+	return samples, nil
+}
+
+// parseInfluxFieldValue parses the value of a field, it returns false for
+// string values which can't be submitted as metrics.
+func parseInfluxFieldValue(rawValue []byte) (float64, bool, error) {
+	if len(rawValue) == 0 {
+		return 0, false, fmt.Errorf("empty value")
+	}
+	switch string(rawValue) {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, true, nil
+	}
+	switch rawValue[len(rawValue)-1] {
+	case '"':
+		return 0, false, nil
+	case 'i':
+		value, err := parseInt64(rawValue[:len(rawValue)-1])
+		return float64(value), err == nil, err
+	case 'u':
+		value, err := strconv.ParseUint(string(rawValue[:len(rawValue)-1]), 10, 64)
+		return float64(value), err == nil, err
+	}
+	value, err := parseFloat64(rawValue)
+	return value, err == nil, err
+}
+
+// cutInfluxKeyValue splits a `<key>=<value>` pair on its first unescaped
+// equal sign, the key is unescaped.
+func cutInfluxKeyValue(pair []byte) ([]byte, []byte, error) {
+	for i := 0; i < len(pair); i++ {
+		switch pair[i] {
+		case '\\':
+			i++
+		case '=':
+			if i == 0 {
+				return nil, nil, fmt.Errorf("empty key")
+			}
+			return unescapeInflux(pair[:i]), pair[i+1:], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no value")
+}
+
+// splitInflux splits data on the separator when it is not escaped with a
+// backslash, nor in a double-quoted string if quotes is true.
+func splitInflux(data []byte, separator byte, quotes bool) [][]byte {
+	var parts [][]byte
+	start := 0
+	quoted := false
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] == '\\':
+			i++
+		case quotes && data[i] == '"':
+			quoted = !quoted
+		case !quoted && data[i] == separator:
+			parts = append(parts, data[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, data[start:])
+}
+
+// unescapeInflux removes the backslashes escaping commas, spaces and equal
+// signs in measurements, tag keys and values and field keys.
+func unescapeInflux(data []byte) []byte {
+	if !bytes.ContainsRune(data, '\\') {
+		return data
+	}
+	unescaped := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '\\' && i+1 < len(data) {
+			switch data[i+1] {
+			case ',', ' ', '=', '\\':
+				i++
+			}
+		}
+		unescaped = append(unescaped, data[i])
+	}
+	return unescaped
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInflux(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": false})
+
+	samples, err := p.parseInfluxMetricSamples([]byte(`cpu,host=web01,region=us-east usage_user=12.5,usage_idle=80i,online=true,label="a b, c=d",count=3u 1700000000000000000`))
+	require.NoError(t, err)
+	require.Len(t, samples, 4)
+
+	expected := []struct {
+		name  string
+		value float64
+	}{
+		{"cpu.usage_user", 12.5},
+		{"cpu.usage_idle", 80},
+		{"cpu.online", 1},
+		{"cpu.count", 3},
+	}
+	for i, sample := range samples {
+		assert.Equal(t, expected[i].name, sample.name)
+		assert.Equal(t, expected[i].value, sample.value)
+		assert.Equal(t, gaugeType, sample.metricType)
+		assert.InEpsilon(t, 1.0, sample.sampleRate, epsilon)
+		assert.Equal(t, []string{"host:web01", "region:us-east"}, sample.tags)
+		assert.Zero(t, sample.ts)
+	}
+
+	// the samples don't share their tags
+	samples[0].tags[0] = "host:web02"
+	assert.Equal(t, "host:web01", samples[1].tags[0])
+}
+
+func TestParseInfluxEscaping(t *testing.T) {
+	p := newTestParser(t, map[string]any{})
+
+	samples, err := p.parseInfluxMetricSamples([]byte(`disk\ io,path=C:\\Program\ Files,dev\=ice=sda read\ bytes=1`))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "disk io.read bytes", samples[0].name)
+	assert.Equal(t, []string{`path:C:\Program Files`, "dev=ice:sda"}, samples[0].tags)
+
+	samples, err = p.parseInfluxMetricSamples([]byte("mem free=1"))
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.Equal(t, "mem.free", samples[0].name)
+	assert.Empty(t, samples[0].tags)
+}
+
+func TestParseInfluxTimestamp(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": true})
+
+	samples, err := p.parseInfluxMetricSamples([]byte("cpu usage=1 1700000000123456789"))
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(0, 1700000000123456789), samples[0].ts)
+}
+
+func TestParseInfluxErrors(t *testing.T) {
+	p := newTestParser(t, map[string]any{"dogstatsd_no_aggregation_pipeline": true})
+
+	for _, message := range []string{
+		"",
+		"cpu",
+		"cpu usage=1 1 2",
+		",host=a usage=1",
+		"cpu,host usage=1",
+		"cpu,host= usage=1",
+		"cpu usage",
+		"cpu =1",
+		"cpu usage=",
+		"cpu usage=abc",
+		"cpu usage=1.5i",
+		`cpu label="only strings"`,
+		"cpu usage=1 yesterday",
+	} {
+		_, err := p.parseInfluxMetricSamples([]byte(message))
+		assert.Error(t, err, message)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/fx"
@@ -158,6 +159,14 @@ type server struct {
 
 	enrichConfig enrichConfig
 
+	// tagFilter holds the rules of dogstatsd_tag_filterlist, it is swapped
+	// when the setting is updated at runtime.
+	tagFilter atomic.Pointer[tagFilter]
+
+	// originQuota limits the packets the UDS listeners accept from each
+	// origin, it is nil when dogstatsd_origin_quota is disabled.
+	originQuota *listeners.OriginQuota
+
 	wmeta option.Option[workloadmeta.Component]
 
 	// telemetry
@@ -323,9 +332,40 @@ func newServerCompat(cfg model.Reader, log log.Component, capture replay.Compone
 	s.listernersTelemetry = listeners.NewTelemetryStore(getBuckets(cfg, log, "telemetry.dogstatsd.listeners_latency_buckets"), telemetrycomp)
 	s.packetsTelemetry = packets.NewTelemetryStore(getBuckets(cfg, log, "telemetry.dogstatsd.listeners_channel_latency_buckets"), telemetrycomp)
 
+	if originQuota, err := listeners.NewOriginQuota(cfg, wmeta, telemetrycomp); err != nil {
+		log.Errorf("Dogstatsd: origin quotas disabled: %v", err)
+	} else {
+		s.originQuota = originQuota
+	}
+
+	s.tagFilter.Store(&tagFilter{})
+	s.loadTagFilter()
+	cfg.OnUpdate(func(setting string, _, _ any) {
+		if setting == "dogstatsd_tag_filterlist" {
+			s.loadTagFilter()
+		}
+	})
+
 	return s
 }
 
+// loadTagFilter loads the rules of dogstatsd_tag_filterlist, the current
+// rules are kept if the new ones are invalid.
+func (s *server) loadTagFilter() {
+	rules, err := getTagFilterRules(s.config)
+	if err != nil {
+		s.log.Errorf("Dogstatsd: %v", err)
+		return
+	}
+	filter, err := newTagFilter(rules)
+	if err != nil {
+		s.log.Errorf("Dogstatsd: invalid dogstatsd_tag_filterlist: %v", err)
+		return
+	}
+	s.tagFilter.Store(filter)
+	s.log.Debugf("Dogstatsd: loaded %d tag filter rules", len(rules))
+}
+
 func (s *server) startHook(context context.Context) error {
 	err := s.start(context)
 	if err != nil {
@@ -369,7 +409,7 @@ func (s *server) start(context.Context) error {
 	}
 
 	if len(socketPath) > 0 {
-		unixListener, err := listeners.NewUDSDatagramListener(packetsChannel, sharedPacketPoolManager, sharedUDSOobPoolManager, s.config, s.tCapture, s.wmeta, s.pidMap, s.listernersTelemetry, s.packetsTelemetry, s.telemetry)
+		unixListener, err := listeners.NewUDSDatagramListener(packetsChannel, sharedPacketPoolManager, sharedUDSOobPoolManager, s.config, s.tCapture, s.wmeta, s.pidMap, s.originQuota, s.listernersTelemetry, s.packetsTelemetry, s.telemetry)
 		if err != nil {
 			s.log.Errorf("Can't init UDS listener on path %s: %s", socketPath, err.Error())
 		} else {
@@ -379,7 +419,7 @@ func (s *server) start(context.Context) error {
 
 	if len(socketStreamPath) > 0 {
 		s.log.Warnf("dogstatsd_stream_socket is not yet supported, run it at your own risk")
-		unixListener, err := listeners.NewUDSStreamListener(packetsChannel, sharedPacketPoolManager, sharedUDSOobPoolManager, s.config, s.tCapture, s.wmeta, s.pidMap, s.listernersTelemetry, s.packetsTelemetry, s.telemetry)
+		unixListener, err := listeners.NewUDSStreamListener(packetsChannel, sharedPacketPoolManager, sharedUDSOobPoolManager, s.config, s.tCapture, s.wmeta, s.pidMap, s.originQuota, s.listernersTelemetry, s.packetsTelemetry, s.telemetry)
 		if err != nil {
 			s.log.Errorf("Can't init listener: %s", err.Error())
 		} else {
@@ -407,6 +447,23 @@ func (s *server) start(context.Context) error {
 		}
 	}
 
+	for _, lineProtocol := range []struct {
+		setting    string
+		sourceType packets.SourceType
+	}{
+		{"dogstatsd_graphite_listeners", packets.Graphite},
+		{"dogstatsd_influx_listeners", packets.Influx},
+	} {
+		for _, address := range s.config.GetStringSlice(lineProtocol.setting) {
+			lineProtocolListener, err := listeners.NewLineProtocolListener(address, lineProtocol.sourceType, packetsChannel, sharedPacketPoolManager, s.config, s.listernersTelemetry, s.packetsTelemetry)
+			if err != nil {
+				s.log.Errorf("Can't init %s listener: %s", lineProtocol.setting, err.Error())
+			} else {
+				tmpListeners = append(tmpListeners, lineProtocolListener)
+			}
+		}
+	}
+
 	if len(tmpListeners) == 0 {
 		return fmt.Errorf("listening on neither udp nor socket, please check your configuration")
 	}
@@ -616,6 +673,12 @@ func (s *server) eolEnabled(sourceType packets.SourceType) bool {
 	return false
 }
 
+// isLineProtocol returns true for the packets of the Graphite and InfluxDB
+// line protocols listeners.
+func isLineProtocol(sourceType packets.SourceType) bool {
+	return sourceType == packets.Graphite || sourceType == packets.Influx
+}
+
 func (s *server) errLog(format string, params ...interface{}) {
 	if s.disableVerboseLogs {
 		s.log.Debugf(format, params...)
@@ -640,6 +703,10 @@ func (s *server) parsePackets(batcher dogstatsdBatcher, parser *parser, packets
 				s.Statistics.StatEvent(1)
 			}
 			messageType := findMessageType(message)
+			if isLineProtocol(packet.Source) {
+				// only metrics are sent with the Graphite and InfluxDB line protocols
+				messageType = metricSampleType
+			}
 
 			switch messageType {
 			case serviceCheckType:
@@ -661,13 +728,21 @@ func (s *server) parsePackets(batcher dogstatsdBatcher, parser *parser, packets
 
 				samples = samples[0:0]
 
-				samples, err = s.parseMetricMessage(samples, parser, message, packet.Origin, packet.ProcessID, packet.ListenerID, s.originTelemetry)
+				if isLineProtocol(packet.Source) {
+					samples, err = s.parseLineProtocolMessage(samples, parser, message, packet.Source, packet.ListenerID)
+				} else {
+					samples, err = s.parseMetricMessage(samples, parser, message, packet.Origin, packet.ProcessID, packet.ListenerID, s.originTelemetry)
+				}
 				if err != nil {
 					s.errLog("Dogstatsd: error parsing metric message '%q': %s", message, err)
 					continue
 				}
 
 				for idx := range samples {
+					if packet.QuotaKeepRate > 0 {
+						// the packet was downsampled by the origin quota of the listener
+						samples[idx].SampleRate *= packet.QuotaKeepRate
+					}
 					s.Debug.StoreMetricStats(samples[idx])
 
 					if samples[idx].Timestamp > 0.0 {
@@ -750,33 +825,100 @@ func (s *server) parseMetricMessage(metricSamples []metrics.MetricSample, parser
 		return metricSamples, err
 	}
 
+	return s.mapAndEnrichMetricSample(metricSamples, sample, origin, processID, listenerID, okCnt), nil
+}
+
+// parseLineProtocolMessage parses a line of the Graphite plaintext or InfluxDB
+// line protocols, the metric samples read go through the same mapping and
+// enrichment as the ones of DogStatsD messages.
+func (s *server) parseLineProtocolMessage(metricSamples []metrics.MetricSample, parser *parser, message []byte, source packets.SourceType, listenerID string) ([]metrics.MetricSample, error) {
+	var samples []dogstatsdMetricSample
+	var err error
+	if source == packets.Graphite {
+		var sample dogstatsdMetricSample
+		sample, err = parser.parseGraphiteMetricSample(message)
+		samples = []dogstatsdMetricSample{sample}
+	} else {
+		samples, err = parser.parseInfluxMetricSamples(message)
+	}
+	if err != nil {
+		dogstatsdMetricParseErrors.Add(1)
+		s.tlmProcessedError.Inc()
+		return metricSamples, err
+	}
+
+	for _, sample := range samples {
+		metricSamples = s.mapAndEnrichMetricSample(metricSamples, sample, packets.NoOrigin, 0, listenerID, s.tlmProcessedOk)
+	}
+	return metricSamples, nil
+}
+
+// mapAndEnrichMetricSample applies the mapper to the sample and appends the
+// enriched metric samples to metricSamples.
+func (s *server) mapAndEnrichMetricSample(metricSamples []metrics.MetricSample, sample dogstatsdMetricSample, origin string, processID uint32, listenerID string, okCnt telemetry.SimpleCounter) []metrics.MetricSample {
 	if s.mapper != nil {
 		mapResult := s.mapper.Map(sample.name)
+		if mapResult != nil && mapResult.Drop {
+			s.log.Tracef("Dogstatsd mapper: metric %q dropped", sample.name)
+			if len(sample.values) > 0 {
+				s.sharedFloat64List.put(sample.values)
+			}
+			return metricSamples
+		}
 		if mapResult != nil {
 			s.log.Tracef("Dogstatsd mapper: metric mapped from %q to %q with tags %v", sample.name, mapResult.Name, mapResult.Tags)
 			sample.name = mapResult.Name
+			sample.tags = mapResult.ApplyTagOperations(sample.tags)
 			sample.tags = append(sample.tags, mapResult.Tags...)
 		}
 	}
 
-	metricSamples = enrichMetricSample(metricSamples, sample, origin, processID, listenerID, s.enrichConfig)
+	start := len(metricSamples)
+	enriched := enrichMetricSample(metricSamples[start:], sample, origin, processID, listenerID, s.enrichConfig)
+	metricSamples = append(metricSamples[:start], enriched...)
+
+	if start < len(metricSamples) {
+		s.filterTags(metricSamples[start:])
+	}
 
 	if len(sample.values) > 0 {
 		s.sharedFloat64List.put(sample.values)
 	}
 
-	for idx := range metricSamples {
-		// All metricSamples already share the same Tags slice. We can
-		// extends the first one and reuse it for the rest.
-		if idx == 0 {
+	for idx := start; idx < len(metricSamples); idx++ {
+		// All metricSamples of the sample already share the same Tags slice.
+		// We can extends the first one and reuse it for the rest.
+		if idx == start {
 			metricSamples[idx].Tags = append(metricSamples[idx].Tags, s.extraTags...)
 		} else {
-			metricSamples[idx].Tags = metricSamples[0].Tags
+			metricSamples[idx].Tags = metricSamples[start].Tags
 		}
 		dogstatsdMetricPackets.Add(1)
 		okCnt.Inc()
 	}
-	return metricSamples, nil
+	return metricSamples
+}
+
+// filterTags applies the tag filter rule of the metric to the tags of the
+// samples of a message, before the extra tags of the server are added. The
+// samples of a multi-value message share their tags, which are filtered once.
+func (s *server) filterTags(samples []metrics.MetricSample) {
+	rule := s.tagFilter.Load().ruleFor(samples[0].Name)
+	if rule == nil {
+		return
+	}
+	tags := samples[0].Tags
+	filtered := rule.apply(tags)
+	for i := range samples {
+		samples[i].Tags = filtered
+	}
+	if stripped := tags[len(filtered):]; len(stripped) > 0 && s.Debug.IsDebugEnabled() {
+		keys := make([]string, 0, len(stripped))
+		for _, tag := range stripped {
+			keys = append(keys, tagKey(tag))
+		}
+		s.Debug.StoreFilteredTags(samples[0].Name, keys)
+	}
 }
 
 func (s *server) parseEventMessage(parser *parser, message []byte, origin string, processID uint32) (*event.Event, error) {
@@ -319,3 +319,20 @@ func TestDogstatsdMappingProfilesEnv(t *testing.T) {
 	mappings, _ := getDogstatsdMappingProfiles(cfg)
 	assert.Equal(t, expected, mappings)
 }
+
+func TestParsePacketsOriginQuotaKeepRate(t *testing.T) {
+	deps := fulfillDepsWithConfigOverride(t, map[string]interface{}{"dogstatsd_port": listeners.RandomPortName})
+	s := deps.Server.(*server)
+	parser := newParser(deps.Config, s.sharedFloat64List, 1, deps.WMeta, s.stringInternerTelemetry)
+
+	// the sample rate of the packets downsampled by the origin quota is scaled
+	packets := genTestPackets([]byte("requests:1|c|@0.5\nlatency:2|d"), []byte("requests:1|c"))
+	packets[0].QuotaKeepRate = 0.1
+
+	var b batcherMock
+	s.parsePackets(&b, parser, packets, metrics.MetricSampleBatch{})
+	require.Len(t, b.samples, 3)
+	assert.InDelta(t, 0.05, b.samples[0].SampleRate, 1e-9)
+	assert.InDelta(t, 0.1, b.samples[1].SampleRate, 1e-9)
+	assert.Equal(t, 1.0, b.samples[2].SampleRate)
+}
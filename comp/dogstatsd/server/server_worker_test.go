@@ -15,6 +15,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/core/telemetry"
 	"github.com/DataDog/datadog-agent/comp/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/comp/dogstatsd/packets"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/metrics/event"
 )
@@ -332,6 +333,41 @@ dogstatsd_mapper_profiles:
 			},
 			expectedCacheSize: 1000,
 		},
+		{
+			name: "Actions and tag operations",
+			config: `
+dogstatsd_port: __random__
+dogstatsd_mapper_profiles:
+  - name: test
+    prefix: 'test.'
+    mappings:
+      - match: "test.job.duration.*"
+        action: keep
+        tag_operations:
+          - operation: remove
+            tag: request_id
+      - match: "test.job.*.*"
+        action: drop
+      - match: "test.task.*"
+        name: "test.task"
+        tags:
+          task: "$1"
+        tag_operations:
+          - operation: rename
+            tag: svc
+            new_tag: service
+`,
+			packets: [][]byte{
+				[]byte("test.job.duration.backup:666|g|#request_id:1,some:tag"),
+				[]byte("test.job.size.backup:666|g|#some:tag"),
+				[]byte("test.task.backup:666|g|#svc:web"),
+			},
+			expectedSamples: []*tMetricSample{
+				defaultMetric().withName("test.job.duration.backup").withTags([]string{"some:tag"}),
+				defaultMetric().withName("test.task").withTags([]string{"service:web", "task:backup"}),
+			},
+			expectedCacheSize: 1000,
+		},
 		{
 			name: "Cache size",
 			config: `
@@ -367,6 +403,7 @@ dogstatsd_mapper_profiles:
 			var b batcherMock
 			s.parsePackets(&b, parser, genTestPackets(scenario.packets...), metrics.MetricSampleBatch{})
 
+			require.Len(t, b.samples, len(scenario.expectedSamples))
 			for idx, sample := range b.samples {
 				scenario.expectedSamples[idx].testMetric(t, sample)
 			}
@@ -577,3 +614,35 @@ func TestNextMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestLineProtocols(t *testing.T) {
+	deps := fulfillDepsWithConfigYaml(t, `
+dogstatsd_port: __random__
+dogstatsd_tags: ["env:test"]
+statsd_metric_blocklist: ["cpu.usage_idle"]
+dogstatsd_mapper_profiles:
+  - name: "servers"
+    prefix: "servers."
+    mappings:
+      - match: "servers.*.load"
+        name: "server.load"
+        tags:
+          server: "$1"
+`)
+	s := deps.Server.(*server)
+	parser := newParser(deps.Config, s.sharedFloat64List, 1, deps.WMeta, s.stringInternerTelemetry)
+	var b batcherMock
+
+	graphite := genTestPackets([]byte("servers.web01.load 0.5\ndisk.used;mount=/var 42\ninvalid"))
+	graphite[0].Source = packets.Graphite
+	influx := genTestPackets([]byte(`cpu,host=web01 usage_user=12.5,usage_idle=80i,label="idle"` + "\n_e{1,1}:a|b=1"))
+	influx[0].Source = packets.Influx
+	s.parsePackets(&b, parser, append(graphite, influx...), metrics.MetricSampleBatch{})
+
+	require.Len(t, b.samples, 3)
+	defaultMetric().withName("server.load").withValue(0.5).withTags([]string{"server:web01", "env:test"}).testMetric(t, b.samples[0])
+	defaultMetric().withName("disk.used").withValue(42).withTags([]string{"mount:/var", "env:test"}).testMetric(t, b.samples[1])
+	defaultMetric().withName("cpu.usage_user").withValue(12.5).withTags([]string{"env:test"}).testMetric(t, b.samples[2])
+	// the host tag is used as the host of the metric
+	assert.Equal(t, "web01", b.samples[2].Host)
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+)
+
+const (
+	tagFilterInclude = "include"
+	tagFilterExclude = "exclude"
+
+	// tagFilterCacheSize is the number of metric names for which the glob
+	// rule lookup is cached.
+	tagFilterCacheSize = 1000
+)
+
+// tagFilterRuleConfig is a rule of the `dogstatsd_tag_filterlist` setting.
+type tagFilterRuleConfig struct {
+	// MetricName is the name of the metrics the rule applies to, `*` matches
+	// any sequence of characters.
+	MetricName string `mapstructure:"metric_name" json:"metric_name"`
+	// Action is either include, to only keep the tags listed in Tags, or
+	// exclude, to strip them.
+	Action string `mapstructure:"action" json:"action"`
+	// Tags are the keys of the tags to keep or to strip.
+	Tags []string `mapstructure:"tags" json:"tags"`
+}
+
+type tagFilterRule struct {
+	include bool
+	keys    map[string]struct{}
+}
+
+type globTagFilterRule struct {
+	pattern *regexp.Regexp
+	rule    *tagFilterRule
+}
+
+// tagFilter strips the tags of the metrics matching its rules. Rules on
+// exact metric names take precedence over glob rules, which are evaluated in
+// their configuration order. The result of the glob rules evaluation is cached
+// by metric name, whether a rule matched or not.
+type tagFilter struct {
+	exact map[string]*tagFilterRule
+	globs []globTagFilterRule
+	cache *lru.Cache[string, *tagFilterRule]
+}
+
+// getTagFilterRules reads the `dogstatsd_tag_filterlist` setting.
+func getTagFilterRules(cfg model.Reader) ([]tagFilterRuleConfig, error) {
+	var rules []tagFilterRuleConfig
+	if cfg.IsSet("dogstatsd_tag_filterlist") {
+		if err := structure.UnmarshalKey(cfg, "dogstatsd_tag_filterlist", &rules); err != nil {
+			return nil, fmt.Errorf("could not parse dogstatsd_tag_filterlist: %v", err)
+		}
+	}
+	return rules, nil
+}
+
+func newTagFilter(rules []tagFilterRuleConfig) (*tagFilter, error) {
+	f := &tagFilter{exact: make(map[string]*tagFilterRule)}
+	for _, ruleConfig := range rules {
+		if ruleConfig.MetricName == "" {
+			return nil, fmt.Errorf("all tag filter rules must have a metric_name")
+		}
+
+		rule := &tagFilterRule{keys: make(map[string]struct{}, len(ruleConfig.Tags))}
+		switch ruleConfig.Action {
+		case tagFilterInclude:
+			rule.include = true
+		case tagFilterExclude:
+		default:
+			return nil, fmt.Errorf("invalid action %q for the tag filter rule of %s, must be one of include or exclude", ruleConfig.Action, ruleConfig.MetricName)
+		}
+		for _, key := range ruleConfig.Tags {
+			rule.keys[key] = struct{}{}
+		}
+
+		if !strings.Contains(ruleConfig.MetricName, "*") {
+			if _, found := f.exact[ruleConfig.MetricName]; found {
+				return nil, fmt.Errorf("duplicated tag filter rule for %s", ruleConfig.MetricName)
+			}
+			f.exact[ruleConfig.MetricName] = rule
+			continue
+		}
+		pattern := "^" + strings.ReplaceAll(regexp.QuoteMeta(ruleConfig.MetricName), `\*`, ".*") + "$"
+		f.globs = append(f.globs, globTagFilterRule{pattern: regexp.MustCompile(pattern), rule: rule})
+	}
+
+	if len(f.globs) > 0 {
+		cache, err := lru.New[string, *tagFilterRule](tagFilterCacheSize)
+		if err != nil {
+			return nil, err
+		}
+		f.cache = cache
+	}
+	return f, nil
+}
+
+// ruleFor returns the rule applying to the metric, or nil.
+func (f *tagFilter) ruleFor(metricName string) *tagFilterRule {
+	if rule, found := f.exact[metricName]; found {
+		return rule
+	}
+	if f.cache == nil {
+		return nil
+	}
+	if rule, found := f.cache.Get(metricName); found {
+		return rule
+	}
+
+	var rule *tagFilterRule
+	for _, glob := range f.globs {
+		if glob.pattern.MatchString(metricName) {
+			rule = glob.rule
+			break
+		}
+	}
+	f.cache.Add(metricName, rule)
+	return rule
+}
+
+// apply filters the tags in place. The kept tags are returned and the
+// stripped tags are moved after them in the tags slice, in
+// tags[len(kept):len(tags)].
+func (r *tagFilterRule) apply(tags []string) []string {
+	n := 0
+	for i, tag := range tags {
+		if _, found := r.keys[tagKey(tag)]; found != r.include {
+			continue
+		}
+		tags[n], tags[i] = tags[i], tags[n]
+		n++
+	}
+	return tags[:n]
+}
+
+func tagKey(tag string) string {
+	if idx := strings.IndexByte(tag, ':'); idx >= 0 {
+		return tag[:idx]
+	}
+	return tag
+}
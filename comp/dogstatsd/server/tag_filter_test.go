@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestNewTagFilter(t *testing.T) {
+	filter, err := newTagFilter([]tagFilterRuleConfig{
+		{MetricName: "http.requests", Action: "include", Tags: []string{"service", "status"}},
+		{MetricName: "http.*", Action: "exclude", Tags: []string{"request_id"}},
+		{MetricName: "*.latency", Action: "exclude", Tags: []string{"user_id"}},
+	})
+	require.NoError(t, err)
+
+	// exact names take precedence over globs
+	tags := []string{"request_id:1", "service:web", "user_id:2", "status:200"}
+	assert.Equal(t, []string{"service:web", "status:200"}, filter.ruleFor("http.requests").apply(tags))
+
+	// globs are evaluated in order
+	tags = []string{"request_id:1", "service:web", "user_id:2"}
+	kept := filter.ruleFor("http.latency").apply(tags)
+	assert.Equal(t, []string{"service:web", "user_id:2"}, kept)
+	assert.Equal(t, []string{"request_id:1"}, tags[len(kept):])
+
+	tags = []string{"request_id:1", "user_id:2", "team"}
+	assert.Equal(t, []string{"request_id:1", "team"}, filter.ruleFor("db.latency").apply(tags))
+
+	assert.Nil(t, filter.ruleFor("db.queries"))
+	assert.Nil(t, filter.ruleFor("xhttp.requests"))
+}
+
+func TestTagFilterCache(t *testing.T) {
+	filter, err := newTagFilter([]tagFilterRuleConfig{
+		{MetricName: "http.requests", Action: "include", Tags: []string{"service"}},
+		{MetricName: "http.*", Action: "exclude", Tags: []string{"request_id"}},
+	})
+	require.NoError(t, err)
+
+	// exact names are not cached
+	assert.NotNil(t, filter.ruleFor("http.requests"))
+	assert.Equal(t, 0, filter.cache.Len())
+
+	// glob matches and misses are cached
+	rule := filter.ruleFor("http.latency")
+	assert.Nil(t, filter.ruleFor("db.queries"))
+	cached, found := filter.cache.Get("http.latency")
+	assert.True(t, found)
+	assert.Same(t, rule, cached)
+	cached, found = filter.cache.Get("db.queries")
+	assert.True(t, found)
+	assert.Nil(t, cached)
+
+	// the filters without glob rules don't need a cache
+	filter, err = newTagFilter([]tagFilterRuleConfig{{MetricName: "http.requests", Action: "include", Tags: []string{"service"}}})
+	require.NoError(t, err)
+	assert.Nil(t, filter.cache)
+	assert.Nil(t, filter.ruleFor("db.queries"))
+}
+
+func TestNewTagFilterErrors(t *testing.T) {
+	for _, rules := range [][]tagFilterRuleConfig{
+		{{Action: "exclude", Tags: []string{"request_id"}}},
+		{{MetricName: "http.requests", Action: "drop", Tags: []string{"request_id"}}},
+		{{MetricName: "http.requests", Tags: []string{"request_id"}}},
+		{
+			{MetricName: "http.requests", Action: "exclude", Tags: []string{"request_id"}},
+			{MetricName: "http.requests", Action: "include", Tags: []string{"service"}},
+		},
+	} {
+		_, err := newTagFilter(rules)
+		assert.Error(t, err)
+	}
+}
+
+func TestGetTagFilterRules(t *testing.T) {
+	cfg := configmock.NewFromYAML(t, `
+dogstatsd_tag_filterlist:
+  - metric_name: "http.*"
+    action: exclude
+    tags: ["request_id", "user_id"]
+`)
+	rules, err := getTagFilterRules(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []tagFilterRuleConfig{{MetricName: "http.*", Action: "exclude", Tags: []string{"request_id", "user_id"}}}, rules)
+
+	t.Setenv("DD_DOGSTATSD_TAG_FILTERLIST", `[{"metric_name":"db.queries","action":"include","tags":["service"]}]`)
+	rules, err = getTagFilterRules(configmock.New(t))
+	require.NoError(t, err)
+	assert.Equal(t, []tagFilterRuleConfig{{MetricName: "db.queries", Action: "include", Tags: []string{"service"}}}, rules)
+}
+
+func TestTagFilterReload(t *testing.T) {
+	deps := fulfillDepsWithConfigYaml(t, `
+dogstatsd_port: __random__
+dogstatsd_tags: ["env:test"]
+dogstatsd_tag_filterlist:
+  - metric_name: "http.requests"
+    action: exclude
+    tags: ["request_id"]
+`)
+	s := deps.Server.(*server)
+	parser := newParser(deps.Config, s.sharedFloat64List, 1, deps.WMeta, s.stringInternerTelemetry)
+
+	parse := func() metrics.MetricSample {
+		var b batcherMock
+		s.parsePackets(&b, parser, genTestPackets([]byte("http.requests:1|c|#request_id:1,service:web,user_id:2")), metrics.MetricSampleBatch{})
+		require.Len(t, b.samples, 1)
+		return b.samples[0]
+	}
+
+	assert.ElementsMatch(t, []string{"service:web", "user_id:2", "env:test"}, parse().Tags)
+
+	// the tags of all the samples of a multi-value message are filtered
+	var b batcherMock
+	s.parsePackets(&b, parser, genTestPackets([]byte("http.requests:1:2:3|c|#request_id:1,service:web")), metrics.MetricSampleBatch{})
+	require.Len(t, b.samples, 3)
+	for _, sample := range b.samples {
+		assert.ElementsMatch(t, []string{"service:web", "env:test"}, sample.Tags)
+	}
+
+	// extra tags are not filtered
+	deps.Config.Set("dogstatsd_tag_filterlist", []interface{}{
+		map[string]interface{}{"metric_name": "http.*", "action": "include", "tags": []interface{}{"service"}},
+	}, model.SourceAgentRuntime)
+	assert.ElementsMatch(t, []string{"service:web", "env:test"}, parse().Tags)
+
+	// invalid rules are ignored
+	deps.Config.Set("dogstatsd_tag_filterlist", []interface{}{
+		map[string]interface{}{"metric_name": "http.*", "action": "drop"},
+	}, model.SourceAgentRuntime)
+	assert.ElementsMatch(t, []string{"service:web", "env:test"}, parse().Tags)
+
+	deps.Config.Set("dogstatsd_tag_filterlist", []interface{}{}, model.SourceAgentRuntime)
+	assert.ElementsMatch(t, []string{"request_id:1", "service:web", "user_id:2", "env:test"}, parse().Tags)
+}
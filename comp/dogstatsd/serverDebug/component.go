@@ -17,6 +17,8 @@ type Component interface {
 
 	// StoreMetricStats stores stats on the given metric sample.
 	StoreMetricStats(sample metrics.MetricSample)
+	// StoreFilteredTags stores the keys of the tags stripped from a metric by the tag filter rules.
+	StoreFilteredTags(name string, keys []string)
 
 	// IsDebugEnabled gets the DsdServerDebug instance which provides metric stats
 	IsDebugEnabled() bool
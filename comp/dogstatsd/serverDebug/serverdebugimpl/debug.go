@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -54,6 +55,9 @@ type metricStat struct {
 	Count    uint64    `json:"count"`
 	LastSeen time.Time `json:"last_seen"`
 	Tags     string    `json:"tags"`
+	// FilteredTags are the keys of the tags stripped from the metric by the
+	// tag filter rules.
+	FilteredTags string `json:"filtered_tags,omitempty"`
 }
 
 type serverDebugImpl struct {
@@ -61,6 +65,8 @@ type serverDebugImpl struct {
 	log     log.Component
 	enabled *atomic.Bool
 	Stats   map[ckey.ContextKey]metricStat `json:"stats"`
+	// filteredTags are the sorted keys of the tags stripped per metric name
+	filteredTags map[string][]string
 	// counting number of metrics processed last X seconds
 	metricsCounts metricsCountBuckets
 	// keyGen is used to generate hashes of the metrics received by dogstatsd
@@ -86,9 +92,10 @@ func newServerDebug(deps dependencies) serverdebug.Component {
 
 func newServerDebugCompat(l log.Component, cfg model.Reader) serverdebug.Component {
 	sd := &serverDebugImpl{
-		log:     l,
-		enabled: atomic.NewBool(false),
-		Stats:   make(map[ckey.ContextKey]metricStat),
+		log:          l,
+		enabled:      atomic.NewBool(false),
+		Stats:        make(map[ckey.ContextKey]metricStat),
+		filteredTags: make(map[string][]string),
 		metricsCounts: metricsCountBuckets{
 			counts:     [5]uint64{0, 0, 0, 0, 0},
 			metricChan: make(chan struct{}),
@@ -134,13 +141,13 @@ func FormatDebugStats(stats []byte) (string, error) {
 	// write the response
 	buf := bytes.NewBuffer(nil)
 
-	header := fmt.Sprintf("%-40s | %-20s | %-10s | %-20s\n", "Metric", "Tags", "Count", "Last Seen")
+	header := fmt.Sprintf("%-40s | %-20s | %-10s | %-20s | %-20s\n", "Metric", "Tags", "Count", "Last Seen", "Filtered Tags")
 	buf.Write([]byte(header))
 	buf.Write([]byte(strings.Repeat("-", len(header)) + "\n"))
 
 	for _, key := range order {
 		stats := dogStats[key]
-		buf.Write([]byte(fmt.Sprintf("%-40s | %-20s | %-10d | %-20v\n", stats.Name, stats.Tags, stats.Count, stats.LastSeen)))
+		buf.Write([]byte(fmt.Sprintf("%-40s | %-20s | %-10d | %-20v | %-20s\n", stats.Name, stats.Tags, stats.Count, stats.LastSeen, stats.FilteredTags)))
 	}
 
 	if len(dogStats) == 0 {
@@ -177,6 +184,9 @@ func (d *serverDebugImpl) StoreMetricStats(sample metrics.MetricSample) {
 	ms.LastSeen = now
 	ms.Name = sample.Name
 	ms.Tags = strings.Join(d.tagsAccumulator.Get(), " ") // we don't want/need to share the underlying array
+	if keys, found := d.filteredTags[sample.Name]; found {
+		ms.FilteredTags = strings.Join(keys, " ")
+	}
 	d.Stats[key] = ms
 
 	if d.dogstatsdDebugLogger != nil {
@@ -187,6 +197,25 @@ func (d *serverDebugImpl) StoreMetricStats(sample metrics.MetricSample) {
 	d.metricsCounts.metricChan <- struct{}{}
 }
 
+// StoreFilteredTags stores the keys of the tags stripped from a metric by the
+// tag filter rules, they are reported with the stats of the metric.
+func (d *serverDebugImpl) StoreFilteredTags(name string, keys []string) {
+	if !d.enabled.Load() {
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	filtered := d.filteredTags[name]
+	for _, key := range keys {
+		if idx, found := slices.BinarySearch(filtered, key); !found {
+			filtered = slices.Insert(filtered, idx, key)
+		}
+	}
+	d.filteredTags[name] = filtered
+}
+
 // SetMetricStatsEnabled enables or disables metric stats
 func (d *serverDebugImpl) SetMetricStatsEnabled(enable bool) {
 	d.Lock()
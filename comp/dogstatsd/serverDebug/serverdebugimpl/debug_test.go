@@ -166,3 +166,40 @@ func TestDebugStats(t *testing.T) {
 	require.Equal(t, hash4, hash5)
 
 }
+
+func TestDebugStatsFilteredTags(t *testing.T) {
+	cfg := make(map[string]interface{})
+	cfg["dogstatsd_logging_enabled"] = false
+	debug := fulfillDeps(t, cfg)
+	d := debug.(*serverDebugImpl)
+
+	// filtered tags are not stored while the stats are disabled
+	d.StoreFilteredTags("some.metric1", []string{"user_id"})
+
+	d.SetMetricStatsEnabled(true)
+	defer d.SetMetricStatsEnabled(false)
+
+	keygen := ckey.NewKeyGenerator()
+	sample1 := metrics.MetricSample{Name: "some.metric1", Tags: []string{"a"}}
+	sample2 := metrics.MetricSample{Name: "some.metric2", Tags: []string{"b"}}
+	hash1 := keygen.Generate(sample1.Name, "", tagset.NewHashingTagsAccumulatorWithTags(sample1.Tags))
+	hash2 := keygen.Generate(sample2.Name, "", tagset.NewHashingTagsAccumulatorWithTags(sample2.Tags))
+
+	d.StoreFilteredTags("some.metric1", []string{"request_id"})
+	d.StoreFilteredTags("some.metric1", []string{"host_id", "request_id"})
+	d.StoreMetricStats(sample1)
+	d.StoreMetricStats(sample2)
+
+	data, err := d.GetJSONDebugStats()
+	require.NoError(t, err)
+	var stats map[ckey.ContextKey]metricStat
+	require.NoError(t, json.Unmarshal(data, &stats))
+
+	assert.Equal(t, "host_id request_id", stats[hash1].FilteredTags)
+	assert.Equal(t, "", stats[hash2].FilteredTags)
+
+	formatted, err := FormatDebugStats(data)
+	require.NoError(t, err)
+	assert.Contains(t, formatted, "Filtered Tags")
+	assert.Contains(t, formatted, "host_id request_id")
+}
@@ -36,6 +36,9 @@ func newMockServerDebug() serverdebug.Component {
 func (d *mockServerDebug) StoreMetricStats(_ metrics.MetricSample) {
 }
 
+func (d *mockServerDebug) StoreFilteredTags(_ string, _ []string) {
+}
+
 func (d *mockServerDebug) SetMetricStatsEnabled(enable bool) {
 	d.enabled.Store(enable)
 }
@@ -98,4 +98,11 @@ func (s statusProvider) populateStatus(stats map[string]interface{}) {
 		}
 		stats["dogstatsdStats"] = dogstatsdStats
 	}
+	if originQuota := expvar.Get("dogstatsd-origin-quota"); originQuota != nil {
+		originQuotaStats := make(map[string]interface{})
+		json.Unmarshal([]byte(originQuota.String()), &originQuotaStats) //nolint:errcheck
+		if len(originQuotaStats) > 0 {
+			stats["dogstatsdOriginQuota"] = originQuotaStats
+		}
+	}
 }
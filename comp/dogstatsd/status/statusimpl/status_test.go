@@ -7,6 +7,7 @@ package statusimpl
 
 import (
 	"bytes"
+	"expvar"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,3 +54,28 @@ func TestStatusOut(t *testing.T) {
 		})
 	}
 }
+
+func TestStatusOriginQuota(t *testing.T) {
+	originQuota := expvar.Get("dogstatsd-origin-quota").(*expvar.Map)
+	stats := new(expvar.Map).Init()
+	stats.Add("Dropped", 1200)
+	stats.Add("Downsampled", 30)
+	originQuota.Set("container_id://noisy", stats)
+	t.Cleanup(func() { originQuota.Delete("container_id://noisy") })
+
+	provider := newStatus().StatusProvider.Provider
+
+	json := make(map[string]interface{})
+	assert.NoError(t, provider.JSON(false, json))
+	assert.Equal(t, map[string]interface{}{
+		"container_id://noisy": map[string]interface{}{"Dropped": 1200.0, "Downsampled": 30.0},
+	}, json["dogstatsdOriginQuota"])
+
+	text := new(bytes.Buffer)
+	assert.NoError(t, provider.Text(false, text))
+	assert.Contains(t, text.String(), "container_id://noisy: 1,200 dropped, 30 downsampled")
+
+	html := new(bytes.Buffer)
+	assert.NoError(t, provider.HTML(false, html))
+	assert.Contains(t, html.String(), "container_id://noisy: 1,200 dropped, 30 downsampled<br>")
+}
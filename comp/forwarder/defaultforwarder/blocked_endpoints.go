@@ -18,6 +18,9 @@ import (
 type block struct {
 	nbError int
 	until   time.Time
+	// since is when the endpoint started failing, it is reset once the
+	// endpoint fully recovered.
+	since time.Time
 }
 
 type blockedEndpoints struct {
@@ -70,6 +73,9 @@ func (e *blockedEndpoints) close(endpoint string) {
 		b = &block{}
 	}
 
+	if b.nbError == 0 {
+		b.since = time.Now()
+	}
 	b.nbError = e.backoffPolicy.IncError(b.nbError)
 	b.until = time.Now().Add(e.getBackoffDuration(b.nbError))
 
@@ -89,6 +95,9 @@ func (e *blockedEndpoints) recover(endpoint string) {
 
 	b.nbError = e.backoffPolicy.DecError(b.nbError)
 	b.until = time.Now().Add(e.getBackoffDuration(b.nbError))
+	if b.nbError == 0 {
+		b.since = time.Time{}
+	}
 
 	e.errorPerEndpoint[endpoint] = b
 }
@@ -103,6 +112,37 @@ func (e *blockedEndpoints) isBlock(endpoint string) bool {
 	return false
 }
 
+// blockedSince returns when the earliest of the currently blocked endpoints
+// started failing, and false when no endpoint is blocked.
+func (e *blockedEndpoints) blockedSince(now time.Time) (time.Time, bool) {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	var since time.Time
+	blocked := false
+	for _, b := range e.errorPerEndpoint {
+		if now.Before(b.until) && (!blocked || b.since.Before(since)) {
+			since = b.since
+			blocked = true
+		}
+	}
+	return since, blocked
+}
+
+// failing returns whether an endpoint failed and did not fully recover yet,
+// even if its backoff is over.
+func (e *blockedEndpoints) failing() bool {
+	e.m.RLock()
+	defer e.m.RUnlock()
+
+	for _, b := range e.errorPerEndpoint {
+		if b.nbError > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *blockedEndpoints) getBackoffDuration(numErrors int) time.Duration {
 	return e.backoffPolicy.GetBackoffDuration(numErrors)
 }
@@ -320,3 +320,36 @@ func TestIsblockUnknown(t *testing.T) {
 
 	assert.False(t, e.isBlock("test"))
 }
+
+func TestBlockedSince(t *testing.T) {
+	mockConfig := mock.New(t)
+	log := logmock.New(t)
+	e := newBlockedEndpoints(mockConfig, log)
+
+	_, blocked := e.blockedSince(time.Now())
+	assert.False(t, blocked)
+
+	start := time.Now()
+	e.close("test")
+	e.close("test")
+	e.close("test")
+	since, blocked := e.blockedSince(time.Now())
+	require.True(t, blocked)
+	assert.False(t, since.Before(start))
+	assert.Equal(t, e.errorPerEndpoint["test"].since, since)
+
+	// the endpoint is not blocked anymore after its backoff, but it is still
+	// failing
+	_, blocked = e.blockedSince(time.Now().Add(time.Hour))
+	assert.False(t, blocked)
+	assert.True(t, e.failing())
+
+	// since is kept until the endpoint fully recovered
+	e.recover("test")
+	assert.Equal(t, since, e.errorPerEndpoint["test"].since)
+	for e.errorPerEndpoint["test"].nbError > 0 {
+		e.recover("test")
+	}
+	assert.True(t, e.errorPerEndpoint["test"].since.IsZero())
+	assert.False(t, e.failing())
+}
@@ -107,6 +107,8 @@ type Options struct {
 	DomainResolvers                map[string]pkgresolver.DomainResolver
 	ConnectionResetInterval        time.Duration
 	CompletionHandler              transaction.HTTPCompletionHandler
+	// Failover is nil when the failover of the primary domain is disabled
+	Failover *FailoverOptions
 }
 
 // SetFeature sets forwarder features in a feature set
@@ -195,6 +197,10 @@ func NewOptionsWithResolvers(config config.Component, log log.Component, domainR
 		}
 	}
 
+	if config.GetBool("forwarder_failover.enabled") {
+		option.Failover = newFailoverOptions(config, log, domainResolvers)
+	}
+
 	return option
 }
 
@@ -223,6 +229,7 @@ type DefaultForwarder struct {
 	domainForwarders map[string]*domainForwarder
 	domainResolvers  map[string]pkgresolver.DomainResolver
 	localForwarder   *domainForwarder // domain forward used for communication with the local cluster-agent
+	failover         *endpointFailover
 	healthChecker    *forwarderHealth
 	internalState    *atomic.Uint32
 	m                sync.Mutex // To control Start/Stop races
@@ -259,6 +266,7 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 	var optionalRemovalPolicy *retry.FileRemovalPolicy
 	storageMaxSize := config.GetInt64("forwarder_storage_max_size_in_bytes")
 	var diskUsageLimit *retry.DiskUsageLimit
+	var encryption *retry.Encryption
 
 	// Disk Persistence is a core-only feature for now.
 	if storageMaxSize == 0 {
@@ -286,6 +294,16 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 		diskRatio := config.GetFloat64("forwarder_storage_max_disk_ratio")
 		diskUsageLimit = retry.NewDiskUsageLimit(storagePath, filesystem.NewDisk(), storageMaxSize, diskRatio)
 
+		if config.GetBool("forwarder_storage_encryption.enabled") {
+			encryption, err = retry.NewEncryption(
+				config.GetString("forwarder_storage_encryption.passphrase"),
+				config.GetStringSlice("forwarder_storage_encryption.previous_passphrases"))
+			if err != nil {
+				// Never store the transactions in plaintext when the encryption is requested.
+				log.Errorf("Retry queue storage on disk disabled. Cannot initialize the encryption: %v", err)
+				diskUsageLimit = nil
+			}
+		}
 	} else {
 		log.Infof("Retry queue storage on disk is disabled because the feature is unavailable for this process.")
 	}
@@ -294,6 +312,42 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 	domainForwarderSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: true}
 	transactionContainerSort := transaction.SortByCreatedTimeAndPriority{HighPriorityFirst: false}
 
+	newForwarder := func(domain string, resolver pkgresolver.DomainResolver, isMRF bool, isLocal bool) *domainForwarder {
+		var domainFolderPath string
+		var err error
+		if optionalRemovalPolicy != nil {
+			domainFolderPath, err = optionalRemovalPolicy.RegisterDomain(domain)
+			if err != nil {
+				log.Errorf("Retry queue storage on disk disabled. Cannot register the domain '%v': %v", domain, err)
+			}
+		}
+
+		pointCountTelemetry := retry.NewPointCountTelemetry(domain)
+		transactionContainer := retry.BuildTransactionRetryQueue(
+			log,
+			options.RetryQueuePayloadsTotalMaxSize,
+			flushToDiskMemRatio,
+			domainFolderPath,
+			diskUsageLimit,
+			transactionContainerSort,
+			resolver,
+			pointCountTelemetry,
+			encryption)
+		fwd := newDomainForwarder(
+			config,
+			log,
+			domain,
+			isMRF,
+			isLocal,
+			transactionContainer,
+			options.NumberOfWorkers,
+			options.ConnectionResetInterval,
+			domainForwarderSort,
+			pointCountTelemetry)
+		f.domainForwarders[domain] = fwd
+		return fwd
+	}
+
 	for domain, resolver := range options.DomainResolvers {
 		isMRF := false
 		if config.GetBool("multi_region_failover.enabled") {
@@ -315,38 +369,8 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 		if !isLocal && (resolver.GetAPIKeys() == nil || len(resolver.GetAPIKeys()) == 0) {
 			log.Errorf("No API keys for domain '%s', dropping domain ", domain)
 		} else {
-			var domainFolderPath string
-			var err error
-			if optionalRemovalPolicy != nil {
-				domainFolderPath, err = optionalRemovalPolicy.RegisterDomain(domain)
-				if err != nil {
-					log.Errorf("Retry queue storage on disk disabled. Cannot register the domain '%v': %v", domain, err)
-				}
-			}
-
-			pointCountTelemetry := retry.NewPointCountTelemetry(domain)
-			transactionContainer := retry.BuildTransactionRetryQueue(
-				log,
-				options.RetryQueuePayloadsTotalMaxSize,
-				flushToDiskMemRatio,
-				domainFolderPath,
-				diskUsageLimit,
-				transactionContainerSort,
-				resolver,
-				pointCountTelemetry)
 			f.domainResolvers[domain] = resolver
-			fwd := newDomainForwarder(
-				config,
-				log,
-				domain,
-				isMRF,
-				isLocal,
-				transactionContainer,
-				options.NumberOfWorkers,
-				options.ConnectionResetInterval,
-				domainForwarderSort,
-				pointCountTelemetry)
-			f.domainForwarders[domain] = fwd
+			fwd := newForwarder(domain, resolver, isMRF, isLocal)
 			// Register all alternate domains for each forwarder
 			for _, v := range resolver.GetAlternateDomains() {
 				f.domainForwarders[v] = fwd
@@ -354,6 +378,10 @@ func NewDefaultForwarder(config config.Component, log log.Component, options *Op
 		}
 	}
 
+	if options.Failover != nil {
+		f.failover = f.newEndpointFailover(options.Failover, options.DomainResolvers, newForwarder)
+	}
+
 	config.OnUpdate(func(setting string, oldValue, newValue any) {
 		if setting != "api_key" {
 			return
@@ -494,6 +522,7 @@ func (f *DefaultForwarder) createAdvancedHTTPTransactions(endpoint transaction.E
 
 	for _, payload := range payloads {
 		for domain, dr := range f.domainResolvers {
+			domain, dr = f.failover.route(domain, dr)
 			drDomain, destinationType := dr.Resolve(endpoint) // drDomain is the domain with agent version if not local
 			if payload.Destination == transaction.LocalOnly {
 				// if it is local payload, we should not send it to the remote endpoint
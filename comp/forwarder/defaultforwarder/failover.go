@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	pkgresolver "github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/resolver"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+)
+
+// FailoverOptions configures the failover of the primary domain to standby domains.
+type FailoverOptions struct {
+	// PrimaryDomain is the key of the primary domain in Options.DomainResolvers
+	PrimaryDomain string
+	// Standbys are the resolvers of the standby domains, by order of preference
+	Standbys []pkgresolver.DomainResolver
+	// OpenDuration is how long the circuit breaker of the primary domain must
+	// stay open before failing over
+	OpenDuration time.Duration
+}
+
+// standbyEndpoint is an entry of `forwarder_failover.standby_endpoints`
+type standbyEndpoint struct {
+	URL    string `mapstructure:"url"`
+	APIKey string `mapstructure:"api_key"`
+}
+
+// newFailoverOptions reads the failover configuration. It returns nil when no
+// usable standby endpoint is configured.
+func newFailoverOptions(config config.Component, log log.Component, domainResolvers map[string]pkgresolver.DomainResolver) *FailoverOptions {
+	primary := utils.GetInfraEndpoint(config)
+	if _, ok := domainResolvers[primary]; !ok {
+		log.Errorf("Forwarder failover disabled: the main endpoint '%s' is not configured", primary)
+		return nil
+	}
+
+	var endpoints []standbyEndpoint
+	if err := structure.UnmarshalKey(config, "forwarder_failover.standby_endpoints", &endpoints); err != nil {
+		log.Errorf("Forwarder failover disabled: cannot parse 'forwarder_failover.standby_endpoints': %v", err)
+		return nil
+	}
+
+	openDuration := config.GetInt("forwarder_failover.open_duration")
+	if openDuration < 0 {
+		log.Warnf("Configured forwarder_failover.open_duration (%v) is negative; 0 will be used", openDuration)
+		openDuration = 0
+	}
+
+	options := &FailoverOptions{
+		PrimaryDomain: primary,
+		OpenDuration:  time.Duration(openDuration) * time.Second,
+	}
+	for _, e := range endpoints {
+		if e.URL == "" || e.APIKey == "" {
+			log.Warnf("Ignoring the standby endpoint '%s': both url and api_key are required", e.URL)
+			continue
+		}
+		if _, ok := domainResolvers[e.URL]; ok {
+			log.Warnf("Ignoring the standby endpoint '%s': it is already configured as an endpoint receiving all the data", e.URL)
+			continue
+		}
+		options.Standbys = append(options.Standbys, pkgresolver.NewSingleDomainResolver(e.URL, []string{e.APIKey}))
+	}
+
+	if len(options.Standbys) == 0 {
+		log.Warn("Forwarder failover disabled: no standby endpoint is configured")
+		return nil
+	}
+	return options
+}
+
+// failoverTarget is a domain the traffic of the primary domain can be sent to.
+type failoverTarget struct {
+	domain   string
+	resolver pkgresolver.DomainResolver
+	blocked  *blockedEndpoints
+}
+
+// endpointFailover sends the traffic of the primary domain to a standby domain
+// when the circuit breaker of the primary domain has been open for
+// openDuration, and switches back to the primary domain once it fully
+// recovered.
+//
+// While a standby domain is active, the primary domain only receives the
+// retries of the transactions which failed on it. The end of its backoff is
+// not enough to switch back: the successes of these retries must bring its
+// error count back to zero.
+type endpointFailover struct {
+	log          log.Component
+	primary      failoverTarget
+	standbys     []failoverTarget
+	openDuration time.Duration
+
+	m           sync.Mutex
+	active      int // index of the active standby, -1 when the primary is active
+	activeSince time.Time
+	failovers   int64
+}
+
+// newEndpointFailover creates the domainForwarders of the standby domains and
+// the failover of the primary domain. The standby domains are not added to the
+// domain resolvers as they only receive data while they are active.
+func (f *DefaultForwarder) newEndpointFailover(
+	options *FailoverOptions,
+	domainResolvers map[string]pkgresolver.DomainResolver,
+	newForwarder func(domain string, resolver pkgresolver.DomainResolver, isMRF bool, isLocal bool) *domainForwarder,
+) *endpointFailover {
+	primary := domainResolvers[options.PrimaryDomain]
+	primaryForwarder, ok := f.domainForwarders[primary.GetBaseDomain()]
+	if !ok {
+		f.log.Errorf("Forwarder failover disabled: the main endpoint '%s' was dropped", primary.GetBaseDomain())
+		return nil
+	}
+
+	e := &endpointFailover{
+		log: f.log,
+		primary: failoverTarget{
+			domain:   primary.GetBaseDomain(),
+			resolver: primary,
+			blocked:  primaryForwarder.blockedList,
+		},
+		openDuration: options.OpenDuration,
+		active:       -1,
+		activeSince:  time.Now(),
+	}
+
+	for _, resolver := range options.Standbys {
+		domain, err := utils.AddAgentVersionToDomain(resolver.GetBaseDomain(), "app")
+		if err != nil {
+			f.log.Warnf("Ignoring the standby endpoint '%s': %v", resolver.GetBaseDomain(), err)
+			continue
+		}
+		if _, ok := f.domainForwarders[domain]; ok {
+			f.log.Warnf("Ignoring the standby endpoint '%s': it is already configured", domain)
+			continue
+		}
+		resolver.SetBaseDomain(domain)
+		fwd := newForwarder(domain, resolver, false, false)
+		e.standbys = append(e.standbys, failoverTarget{
+			domain:   domain,
+			resolver: resolver,
+			blocked:  fwd.blockedList,
+		})
+		f.healthChecker.standbyResolvers = append(f.healthChecker.standbyResolvers, resolver)
+	}
+
+	if len(e.standbys) == 0 {
+		f.log.Warn("Forwarder failover disabled: no standby endpoint is configured")
+		return nil
+	}
+
+	f.log.Infof("Forwarder failover enabled: '%s' fails over to %d standby endpoint(s) after %v", e.primary.domain, len(e.standbys), e.openDuration)
+	e.report()
+	return e
+}
+
+// route returns the domain and the resolver the transactions of a domain are
+// sent to.
+func (e *endpointFailover) route(domain string, dr pkgresolver.DomainResolver) (string, pkgresolver.DomainResolver) {
+	if e == nil || domain != e.primary.domain {
+		return domain, dr
+	}
+
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	e.update(time.Now())
+	target := e.target()
+	return target.domain, target.resolver
+}
+
+// update switches the active target according to the state of the circuit
+// breakers. It must be called with the lock held.
+func (e *endpointFailover) update(now time.Time) {
+	since, open := e.primary.blocked.blockedSince(now)
+
+	if e.active < 0 {
+		if !open || now.Sub(since) < e.openDuration {
+			return
+		}
+		if i := e.healthyStandby(now); i >= 0 {
+			e.log.Warnf("Too many errors for '%s' since %s: failing over to '%s'", e.primary.domain, since.Format(time.RFC3339), e.standbys[i].domain)
+			e.activate(i, now)
+		}
+		return
+	}
+
+	if !e.primary.blocked.failing() {
+		e.log.Infof("'%s' recovered: switching back from '%s'", e.primary.domain, e.standbys[e.active].domain)
+		e.activate(-1, now)
+		return
+	}
+
+	if _, standbyOpen := e.standbys[e.active].blocked.blockedSince(now); standbyOpen {
+		if i := e.healthyStandby(now); i >= 0 {
+			e.log.Warnf("Too many errors for the standby endpoint '%s': failing over to '%s'", e.standbys[e.active].domain, e.standbys[i].domain)
+			e.activate(i, now)
+		}
+	}
+}
+
+// healthyStandby returns the index of the first standby whose circuit breaker
+// is closed, or -1 when there is none.
+func (e *endpointFailover) healthyStandby(now time.Time) int {
+	for i, standby := range e.standbys {
+		if _, open := standby.blocked.blockedSince(now); !open {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *endpointFailover) activate(i int, now time.Time) {
+	e.active = i
+	e.activeSince = now
+	if i >= 0 {
+		e.failovers++
+	}
+	e.report()
+}
+
+func (e *endpointFailover) target() failoverTarget {
+	if e.active < 0 {
+		return e.primary
+	}
+	return e.standbys[e.active]
+}
+
+func (e *endpointFailover) report() {
+	setFailoverStatus(e.primary.domain, e.target().domain, e.activeSince, e.failovers)
+}
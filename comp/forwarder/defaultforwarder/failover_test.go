@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package defaultforwarder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/endpoints"
+	"github.com/DataDog/datadog-agent/comp/forwarder/defaultforwarder/transaction"
+	mock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/config/utils"
+)
+
+func newTestFailoverTarget(t *testing.T, domain string) failoverTarget {
+	return failoverTarget{
+		domain:  domain,
+		blocked: newBlockedEndpoints(mock.New(t), logmock.New(t)),
+	}
+}
+
+// openCircuitBreaker blocks an endpoint of the target from `since` to `until`.
+func openCircuitBreaker(target failoverTarget, since time.Time, until time.Time) {
+	target.blocked.errorPerEndpoint[target.domain+"/api/v2/series"] = &block{nbError: 1, since: since, until: until}
+}
+
+func TestEndpointFailoverTransitions(t *testing.T) {
+	e := &endpointFailover{
+		log:          logmock.New(t),
+		primary:      newTestFailoverTarget(t, "primary"),
+		standbys:     []failoverTarget{newTestFailoverTarget(t, "standby1"), newTestFailoverTarget(t, "standby2")},
+		openDuration: time.Minute,
+		active:       -1,
+	}
+	now := time.Now()
+
+	// the circuit breaker of the primary is not open for long enough
+	openCircuitBreaker(e.primary, now, now.Add(time.Hour))
+	e.update(now.Add(30 * time.Second))
+	assert.Equal(t, "primary", e.target().domain)
+
+	e.update(now.Add(time.Minute))
+	assert.Equal(t, "standby1", e.target().domain)
+	assert.Equal(t, int64(1), e.failovers)
+
+	// the active standby fails
+	openCircuitBreaker(e.standbys[0], now, now.Add(time.Hour))
+	e.update(now.Add(2 * time.Minute))
+	assert.Equal(t, "standby2", e.target().domain)
+	assert.Equal(t, int64(2), e.failovers)
+
+	// no healthy standby left, the active one is kept
+	openCircuitBreaker(e.standbys[1], now, now.Add(time.Hour))
+	e.update(now.Add(3 * time.Minute))
+	assert.Equal(t, "standby2", e.target().domain)
+
+	// the backoff of the primary is over but none of its retries succeeded
+	e.update(now.Add(2 * time.Hour))
+	assert.Equal(t, "standby2", e.target().domain)
+
+	// the primary recovers
+	e.primary.blocked.recover("primary/api/v2/series")
+	e.update(now.Add(2 * time.Hour))
+	assert.Equal(t, "primary", e.target().domain)
+	assert.Equal(t, int64(2), e.failovers)
+}
+
+func TestEndpointFailoverBackoffExpiredWithoutSuccess(t *testing.T) {
+	e := &endpointFailover{
+		log:          logmock.New(t),
+		primary:      newTestFailoverTarget(t, "primary"),
+		standbys:     []failoverTarget{newTestFailoverTarget(t, "standby")},
+		openDuration: time.Minute,
+		active:       -1,
+	}
+	now := time.Now()
+	openCircuitBreaker(e.primary, now, now.Add(2*time.Minute))
+	e.update(now.Add(time.Minute))
+	require.Equal(t, "standby", e.target().domain)
+
+	// the traffic stays on the standby after each backoff of the primary
+	// until one of its retries succeeds
+	for i := 1; i <= 3; i++ {
+		e.update(now.Add(time.Duration(i) * time.Hour))
+		assert.Equal(t, "standby", e.target().domain)
+		e.primary.blocked.close("primary/api/v2/series")
+	}
+	assert.Equal(t, int64(1), e.failovers)
+
+	for e.primary.blocked.failing() {
+		e.primary.blocked.recover("primary/api/v2/series")
+	}
+	e.update(now.Add(4 * time.Hour))
+	assert.Equal(t, "primary", e.target().domain)
+	assert.Equal(t, int64(1), e.failovers)
+}
+
+func TestEndpointFailoverNoHealthyStandby(t *testing.T) {
+	e := &endpointFailover{
+		log:          logmock.New(t),
+		primary:      newTestFailoverTarget(t, "primary"),
+		standbys:     []failoverTarget{newTestFailoverTarget(t, "standby")},
+		openDuration: time.Minute,
+		active:       -1,
+	}
+	now := time.Now()
+	openCircuitBreaker(e.primary, now, now.Add(time.Hour))
+	openCircuitBreaker(e.standbys[0], now, now.Add(time.Hour))
+
+	e.update(now.Add(2 * time.Minute))
+	assert.Equal(t, "primary", e.target().domain)
+	assert.Equal(t, int64(0), e.failovers)
+}
+
+func TestDefaultForwarderFailover(t *testing.T) {
+	mockConfig := mock.New(t)
+	t.Cleanup(func() { failoverStatus.Init() })
+	mockConfig.SetWithoutSource("forwarder_failover.enabled", true)
+	mockConfig.SetWithoutSource("forwarder_failover.open_duration", 0)
+	mockConfig.SetWithoutSource("forwarder_failover.standby_endpoints", []map[string]interface{}{
+		{"url": "https://app.datadoghq.eu", "api_key": "standby_key"},
+		{"url": "https://app.datadoghq.com", "api_key": "primary_key"},
+		{"url": "https://app.us3.datadoghq.com"},
+	})
+	log := logmock.New(t)
+
+	options := NewOptions(mockConfig, log, map[string][]string{utils.GetInfraEndpoint(mockConfig): {"primary_key"}})
+	require.NotNil(t, options.Failover)
+	require.Len(t, options.Failover.Standbys, 1)
+
+	forwarder := NewDefaultForwarder(mockConfig, log, options)
+	require.NotNil(t, forwarder.failover)
+	primaryDomain, _ := utils.AddAgentVersionToDomain(utils.GetInfraEndpoint(mockConfig), "app")
+	standbyDomain, _ := utils.AddAgentVersionToDomain("https://app.datadoghq.eu", "app")
+	assert.Contains(t, forwarder.domainForwarders, standbyDomain)
+	assert.NotContains(t, forwarder.domainResolvers, standbyDomain)
+
+	payloads := transaction.NewBytesPayloadsWithoutMetaData([]*[]byte{{}})
+	transactions := forwarder.createHTTPTransactions(endpoints.SeriesEndpoint, payloads, transaction.Series, nil)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, primaryDomain, transactions[0].Domain)
+	assert.Equal(t, "primary_key", transactions[0].Headers.Get(apiHTTPHeaderKey))
+
+	forwarder.domainForwarders[primaryDomain].blockedList.close(transactions[0].GetTarget())
+	transactions = forwarder.createHTTPTransactions(endpoints.SeriesEndpoint, payloads, transaction.Series, nil)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, standbyDomain, transactions[0].Domain)
+	assert.Equal(t, "standby_key", transactions[0].Headers.Get(apiHTTPHeaderKey))
+	assert.Equal(t, standbyDomain, failoverActive.Value())
+}
@@ -42,6 +42,13 @@ var (
 	apiKeyStatus  = expvar.Map{}
 	apiKeyFailure = expvar.Map{}
 
+	// failoverStatus is only populated when the failover of the primary domain is enabled
+	failoverStatus      = expvar.Map{}
+	failoverPrimary     = expvar.String{}
+	failoverActive      = expvar.String{}
+	failoverActiveSince = expvar.String{}
+	failoverCount       = expvar.Int{}
+
 	// domainURLRegexp determines if an URL belongs to Datadog or not. If the URL belongs to Datadog it's prefixed
 	// with 'api.' (see computeDomainURLAPIKeyMap).
 	domainURLRegexp = regexp.MustCompile(`([a-z]{2}\d\.)?(datadoghq\.[a-z]+|ddog-gov\.com)$`)
@@ -60,6 +67,20 @@ func initForwarderHealthExpvars() {
 	apiKeyFailure.Init()
 	transaction.ForwarderExpvars.Set("APIKeyStatus", &apiKeyStatus)
 	transaction.ForwarderExpvars.Set("APIKeyFailure", &apiKeyFailure)
+	failoverStatus.Init()
+	transaction.ForwarderExpvars.Set("Failover", &failoverStatus)
+}
+
+// setFailoverStatus reports the domain receiving the traffic of the primary domain.
+func setFailoverStatus(primary string, active string, activeSince time.Time, failovers int64) {
+	failoverPrimary.Set(primary)
+	failoverActive.Set(active)
+	failoverActiveSince.Set(activeSince.Format(time.RFC3339))
+	failoverCount.Set(failovers)
+	failoverStatus.Set("Primary", &failoverPrimary)
+	failoverStatus.Set("Active", &failoverActive)
+	failoverStatus.Set("ActiveSince", &failoverActiveSince)
+	failoverStatus.Set("Failovers", &failoverCount)
 }
 
 // forwarderHealth report the health status of the Forwarder. A Forwarder is
@@ -72,6 +93,7 @@ type forwarderHealth struct {
 	stopped               chan struct{}
 	timeout               time.Duration
 	domainResolvers       map[string]resolver.DomainResolver
+	standbyResolvers      []resolver.DomainResolver // the standby domains of the failover, their keys are validated too
 	keysPerAPIEndpoint    map[string][]string
 	disableAPIKeyChecking bool
 	validationInterval    time.Duration
@@ -92,6 +114,9 @@ func (fh *forwarderHealth) init() {
 	for _, dr := range fh.domainResolvers {
 		apiKeyCount += len(dr.GetAPIKeys())
 	}
+	for _, dr := range fh.standbyResolvers {
+		apiKeyCount += len(dr.GetAPIKeys())
+	}
 
 	fh.timeout = validateAPIKeyTimeout
 	if apiKeyCount != 0 {
@@ -197,12 +222,18 @@ func (fh *forwarderHealth) updateAPIKey(oldKey, newKey string) {
 // computeDomainURLAPIKeyMap populates a map containing API Endpoints per API keys that belongs to the forwarderHealth struct
 func (fh *forwarderHealth) computeDomainURLAPIKeyMap() {
 	fh.keyMapMutex.Lock()
-	for domain, dr := range fh.domainResolvers {
+	addKeys := func(domain string, dr resolver.DomainResolver) {
 		if domainURLRegexp.MatchString(domain) {
 			domain = "https://api." + domainURLRegexp.FindString(domain)
 		}
 		fh.keysPerAPIEndpoint[domain] = append(fh.keysPerAPIEndpoint[domain], dr.GetAPIKeys()...)
 	}
+	for domain, dr := range fh.domainResolvers {
+		addKeys(domain, dr)
+	}
+	for _, dr := range fh.standbyResolvers {
+		addKeys(dr.GetBaseDomain(), dr)
+	}
 	fh.keyMapMutex.Unlock()
 }
 
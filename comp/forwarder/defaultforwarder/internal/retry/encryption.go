@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedFileMagic starts the encrypted retry files, it is followed by the
+// format version. A serialized HttpTransactionProto cannot start with it as
+// 'D' is not a valid protobuf tag.
+var encryptedFileMagic = []byte("DDRQENC")
+
+const (
+	encryptedFileVersion = 1
+	encryptionSaltSize   = 16
+	encryptionKeySize    = 32
+
+	// kdfScrypt identifies scrypt as the key derivation function of a file.
+	kdfScrypt = 1
+
+	// The scrypt parameters, N=2^15, r=8 and p=1 as recommended for
+	// interactive logins. The key is derived before the header is
+	// authenticated, so the files with other parameters are rejected rather
+	// than letting a corrupted header exhaust the memory or the CPU.
+	scryptLogN = 15
+	scryptR    = 8
+	scryptP    = 1
+
+	// maxCachedKeys is the number of derived keys kept to decrypt the files.
+	maxCachedKeys = 16
+)
+
+// kdfParamsSize is the size of the key derivation parameters in the header:
+// the function, scrypt log2(N), r and p, followed by the salt.
+const kdfParamsSize = 4 + encryptionSaltSize
+
+var encryptedFileHeaderSize = len(encryptedFileMagic) + 1 + kdfParamsSize
+
+// errEncryptionDisabled is returned when reading an encrypted retry file
+// while the encryption is disabled.
+var errEncryptionDisabled = errors.New("the file is encrypted but forwarder_storage_encryption is disabled")
+
+// Encryption encrypts the retry files with AES-256-GCM.
+//
+// The key is derived from the passphrase with scrypt. The key derivation
+// parameters, including a random salt, are stored in the file header along
+// with the nonce, and the header is authenticated with the payload. As the
+// derivation is deliberately slow, the salt is drawn once per instance and
+// the derived keys are cached.
+//
+// The files are always encrypted with the current passphrase, the previous
+// passphrases are only used to decrypt the files written before a key
+// rotation. As the retried transactions are stored again when they fail,
+// the files end up encrypted with the current passphrase.
+type Encryption struct {
+	// passphrases are the current passphrase followed by the previous ones
+	passphrases [][]byte
+	// kdfParams are the key derivation parameters of the files written
+	kdfParams []byte
+
+	mu sync.Mutex
+	// keys are the derived keys by passphrase index and key derivation parameters
+	keys map[string][]byte
+}
+
+// NewEncryption creates a new instance of Encryption.
+func NewEncryption(passphrase string, previousPassphrases []string) (*Encryption, error) {
+	if passphrase == "" {
+		return nil, errors.New("the encryption passphrase is empty")
+	}
+	e := &Encryption{
+		passphrases: [][]byte{[]byte(passphrase)},
+		kdfParams:   make([]byte, kdfParamsSize),
+		keys:        make(map[string][]byte),
+	}
+	for _, p := range previousPassphrases {
+		if p != "" {
+			e.passphrases = append(e.passphrases, []byte(p))
+		}
+	}
+
+	e.kdfParams[0] = kdfScrypt
+	e.kdfParams[1] = scryptLogN
+	e.kdfParams[2] = scryptR
+	e.kdfParams[3] = scryptP
+	if _, err := rand.Read(e.kdfParams[4:]); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// isEncrypted returns whether the content of a retry file is encrypted.
+func isEncrypted(content []byte) bool {
+	return bytes.HasPrefix(content, encryptedFileMagic)
+}
+
+func (e *Encryption) encrypt(plaintext []byte) ([]byte, error) {
+	header := make([]byte, 0, encryptedFileHeaderSize)
+	header = append(header, encryptedFileMagic...)
+	header = append(header, encryptedFileVersion)
+	header = append(header, e.kdfParams...)
+
+	aead, err := e.newAEAD(0, e.kdfParams)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, header...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+func (e *Encryption) decrypt(content []byte) ([]byte, error) {
+	if !isEncrypted(content) || len(content) < encryptedFileHeaderSize {
+		return nil, errors.New("the file is not encrypted")
+	}
+	if version := content[len(encryptedFileMagic)]; version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported encrypted file version %d", version)
+	}
+	header := content[:encryptedFileHeaderSize]
+	kdfParams := header[len(encryptedFileMagic)+1:]
+
+	for i := range e.passphrases {
+		aead, err := e.newAEAD(i, kdfParams)
+		if err != nil {
+			return nil, err
+		}
+		if len(content) < encryptedFileHeaderSize+aead.NonceSize() {
+			return nil, errors.New("the encrypted file is truncated")
+		}
+		nonce := content[encryptedFileHeaderSize : encryptedFileHeaderSize+aead.NonceSize()]
+		ciphertext := content[encryptedFileHeaderSize+aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, header); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, errors.New("the file cannot be decrypted with the configured passphrases, either it is corrupted or it was encrypted with another passphrase")
+}
+
+// newAEAD returns the cipher of the passphrase at index i for the given key
+// derivation parameters.
+func (e *Encryption) newAEAD(i int, kdfParams []byte) (cipher.AEAD, error) {
+	key, err := e.key(i, kdfParams)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// key returns the key derived from the passphrase at index i, from the cache
+// if it was already derived with the same parameters.
+func (e *Encryption) key(i int, kdfParams []byte) ([]byte, error) {
+	cacheKey := string(append([]byte{byte(i)}, kdfParams...))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if key, found := e.keys[cacheKey]; found {
+		return key, nil
+	}
+	key, err := deriveKey(e.passphrases[i], kdfParams)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.keys) >= maxCachedKeys {
+		clear(e.keys)
+	}
+	e.keys[cacheKey] = key
+	return key, nil
+}
+
+// deriveKey derives a 256-bit key from the passphrase with the key
+// derivation parameters of a file header.
+func deriveKey(passphrase []byte, kdfParams []byte) ([]byte, error) {
+	if kdfParams[0] != kdfScrypt {
+		return nil, fmt.Errorf("unsupported key derivation function %d", kdfParams[0])
+	}
+	logN, r, p := int(kdfParams[1]), int(kdfParams[2]), int(kdfParams[3])
+	if logN != scryptLogN || r != scryptR || p != scryptP {
+		return nil, fmt.Errorf("unsupported scrypt parameters N=2^%d, r=%d, p=%d", logN, r, p)
+	}
+	return scrypt.Key(passphrase, kdfParams[4:], 1<<logN, r, p, encryptionKeySize)
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package retry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryption(t *testing.T) {
+	_, err := NewEncryption("", nil)
+	require.Error(t, err)
+
+	e, err := NewEncryption("passphrase", nil)
+	require.NoError(t, err)
+	plaintext := []byte("payload")
+
+	encrypted, err := e.encrypt(plaintext)
+	require.NoError(t, err)
+	assert.True(t, isEncrypted(encrypted))
+	assert.NotContains(t, string(encrypted), "payload")
+
+	// the nonce is random
+	other, err := e.encrypt(plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, other)
+
+	decrypted, err := e.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestEncryptionDecryptErrors(t *testing.T) {
+	e, err := NewEncryption("passphrase", nil)
+	require.NoError(t, err)
+	encrypted, err := e.encrypt([]byte("payload"))
+	require.NoError(t, err)
+
+	other, err := NewEncryption("other", nil)
+	require.NoError(t, err)
+	_, err = other.decrypt(encrypted)
+	assert.Error(t, err)
+
+	// the header is authenticated
+	tampered := append([]byte{}, encrypted...)
+	tampered[encryptedFileHeaderSize-1] ^= 1
+	_, err = e.decrypt(tampered)
+	assert.Error(t, err)
+
+	tampered = append([]byte{}, encrypted...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = e.decrypt(tampered)
+	assert.Error(t, err)
+
+	_, err = e.decrypt(encrypted[:encryptedFileHeaderSize+4])
+	assert.Error(t, err)
+
+	_, err = e.decrypt([]byte("plaintext"))
+	assert.Error(t, err)
+}
+
+func TestEncryptionKeyRotation(t *testing.T) {
+	old, err := NewEncryption("old", nil)
+	require.NoError(t, err)
+	encrypted, err := old.encrypt([]byte("payload"))
+	require.NoError(t, err)
+
+	rotated, err := NewEncryption("new", []string{"", "old"})
+	require.NoError(t, err)
+	decrypted, err := rotated.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+
+	// the files are encrypted with the current passphrase
+	encrypted, err = rotated.encrypt([]byte("payload"))
+	require.NoError(t, err)
+	_, err = old.decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestEncryptionKeyDerivation(t *testing.T) {
+	e, err := NewEncryption("passphrase", nil)
+	require.NoError(t, err)
+	encrypted, err := e.encrypt([]byte("payload"))
+	require.NoError(t, err)
+
+	// the key derivation parameters are stored in the header
+	kdfParams := encrypted[len(encryptedFileMagic)+1 : encryptedFileHeaderSize]
+	assert.Equal(t, []byte{kdfScrypt, scryptLogN, scryptR, scryptP}, kdfParams[:4])
+
+	// the salt is random
+	other, err := NewEncryption("passphrase", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, e.kdfParams[4:], other.kdfParams[4:])
+	decrypted, err := other.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("payload"), decrypted)
+
+	// unknown functions and other parameters are rejected
+	for i, value := range []byte{0, scryptLogN + 5, scryptR * 4, scryptP + 15} {
+		tampered := append([]byte{}, encrypted...)
+		tampered[len(encryptedFileMagic)+1+i] = value
+		_, err = other.decrypt(tampered)
+		assert.Error(t, err)
+	}
+}
@@ -30,6 +30,8 @@ type onDiskRetryQueue struct {
 	currentSizeInBytes  int64
 	telemetry           onDiskRetryQueueTelemetry
 	pointCountTelemetry *PointCountTelemetry
+	// encryption encrypts the files, nil if the files are stored in plaintext.
+	encryption *Encryption
 }
 
 func newOnDiskRetryQueue(
@@ -38,7 +40,8 @@ func newOnDiskRetryQueue(
 	storagePath string,
 	diskUsageLimit *DiskUsageLimit,
 	telemetry onDiskRetryQueueTelemetry,
-	pointCountTelemetry *PointCountTelemetry) (*onDiskRetryQueue, error) {
+	pointCountTelemetry *PointCountTelemetry,
+	encryption *Encryption) (*onDiskRetryQueue, error) {
 
 	if err := os.MkdirAll(storagePath, 0700); err != nil {
 		return nil, err
@@ -51,6 +54,7 @@ func newOnDiskRetryQueue(
 		diskUsageLimit:      diskUsageLimit,
 		telemetry:           telemetry,
 		pointCountTelemetry: pointCountTelemetry,
+		encryption:          encryption,
 	}
 
 	if err := storage.reloadExistingRetryFiles(); err != nil {
@@ -82,6 +86,11 @@ func (s *onDiskRetryQueue) Store(transactions []transaction.Transaction) error {
 	if err != nil {
 		return err
 	}
+	if s.encryption != nil {
+		if bytes, err = s.encryption.encrypt(bytes); err != nil {
+			return err
+		}
+	}
 	bufferSize := int64(len(bytes))
 
 	if err := s.makeRoomFor(bufferSize); err != nil {
@@ -119,7 +128,7 @@ func (s *onDiskRetryQueue) ExtractLast() ([]transaction.Transaction, error) {
 	s.telemetry.addDeserializeCount()
 	index := len(s.filenames) - 1
 	path := s.filenames[index]
-	bytes, err := os.ReadFile(path)
+	bytes, err := s.readFile(path)
 
 	// Remove the file even in case of a read failure.
 	if errRemoveFile := s.removeFileAt(index); errRemoveFile != nil {
@@ -166,7 +175,7 @@ func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 		filename := s.filenames[index]
 		s.log.Errorf("Maximum disk space for retry transactions is reached. Removing %s", filename)
 
-		bytes, err := os.ReadFile(filename)
+		bytes, err := s.readFile(filename)
 		if err != nil {
 			s.log.Errorf("Cannot read the file %v: %v", filename, err)
 		} else if transactions, _, errDeserialize := s.serializer.Deserialize(bytes); errDeserialize == nil {
@@ -188,6 +197,26 @@ func (s *onDiskRetryQueue) makeRoomFor(bufferSize int64) error {
 	return nil
 }
 
+// readFile reads a retry file and decrypts it if it is encrypted. The files
+// stored in plaintext, for instance before the encryption was enabled, are
+// still read.
+func (s *onDiskRetryQueue) readFile(path string) ([]byte, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil || !isEncrypted(bytes) {
+		return bytes, err
+	}
+	if s.encryption == nil {
+		s.telemetry.addDecryptErrorsCount()
+		return nil, fmt.Errorf("cannot read %v: %w", path, errEncryptionDisabled)
+	}
+	bytes, err = s.encryption.decrypt(bytes)
+	if err != nil {
+		s.telemetry.addDecryptErrorsCount()
+		return nil, fmt.Errorf("cannot decrypt %v: %w", path, err)
+	}
+	return bytes, nil
+}
+
 func (s *onDiskRetryQueue) onPointDropped(count int) {
 	s.telemetry.addPointDroppedCount(count)
 	s.pointCountTelemetry.OnPointDropped(count)
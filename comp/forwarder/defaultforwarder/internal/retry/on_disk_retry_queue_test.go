@@ -8,6 +8,7 @@
 package retry
 
 import (
+	"os"
 	"strconv"
 	"testing"
 
@@ -26,7 +27,7 @@ func TestOnDiskRetryQueue(t *testing.T) {
 	path := t.TempDir()
 
 	pointDropped := fileStoragePointDroppedCountTelemetry.expvar.Value()
-	q := newTestOnDiskRetryQueue(t, a, path, 1000)
+	q := newTestOnDiskRetryQueue(t, a, path, 1000, nil)
 	err := q.Store(createHTTPTransactionCollectionTests("endpoint1", "endpoint2"))
 	a.NoError(err)
 	err = q.Store(createHTTPTransactionCollectionTests("endpoint3", "endpoint4"))
@@ -52,7 +53,7 @@ func TestOnDiskRetryQueueMaxSize(t *testing.T) {
 
 	maxSizeInBytes := int64(100)
 	pointDropped := fileStoragePointDroppedCountTelemetry.expvar.Value()
-	q := newTestOnDiskRetryQueue(t, a, path, maxSizeInBytes)
+	q := newTestOnDiskRetryQueue(t, a, path, maxSizeInBytes, nil)
 
 	i := 0
 	err := q.Store(createHTTPTransactionCollectionTests(strconv.Itoa(i)))
@@ -90,11 +91,11 @@ func TestOnDiskRetryQueueReloadExistingRetryFiles(t *testing.T) {
 	a := assert.New(t)
 	path := t.TempDir()
 
-	retryQueue := newTestOnDiskRetryQueue(t, a, path, 1000)
+	retryQueue := newTestOnDiskRetryQueue(t, a, path, 1000, nil)
 	err := retryQueue.Store(createHTTPTransactionCollectionTests("endpoint1", "endpoint2"))
 	a.NoError(err)
 
-	newRetryQueue := newTestOnDiskRetryQueue(t, a, path, 1000)
+	newRetryQueue := newTestOnDiskRetryQueue(t, a, path, 1000, nil)
 	a.Equal(retryQueue.GetDiskSpaceUsed(), newRetryQueue.GetDiskSpaceUsed())
 	a.Equal(retryQueue.getFilesCount(), newRetryQueue.getFilesCount())
 	transactions, err := newRetryQueue.ExtractLast()
@@ -102,6 +103,76 @@ func TestOnDiskRetryQueueReloadExistingRetryFiles(t *testing.T) {
 	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
 }
 
+func TestOnDiskRetryQueueEncryption(t *testing.T) {
+	a := assert.New(t)
+	path := t.TempDir()
+
+	encryption, err := NewEncryption("passphrase", nil)
+	a.NoError(err)
+	q := newTestOnDiskRetryQueue(t, a, path, 1000, encryption)
+	a.NoError(q.Store(createHTTPTransactionCollectionTests("endpoint1", "endpoint2")))
+
+	content, err := os.ReadFile(q.filenames[0])
+	a.NoError(err)
+	a.True(isEncrypted(content))
+	a.NotContains(string(content), "endpoint1")
+	a.Equal(int64(len(content)), q.GetDiskSpaceUsed())
+
+	transactions, err := q.ExtractLast()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1", "endpoint2"}, getEndpointsFromTransactions(transactions))
+}
+
+func TestOnDiskRetryQueueEncryptionKeyRotation(t *testing.T) {
+	a := assert.New(t)
+	path := t.TempDir()
+
+	oldEncryption, err := NewEncryption("old", nil)
+	a.NoError(err)
+	retryQueue := newTestOnDiskRetryQueue(t, a, path, 1000, oldEncryption)
+	a.NoError(retryQueue.Store(createHTTPTransactionCollectionTests("endpoint1")))
+
+	newEncryption, err := NewEncryption("new", []string{"old"})
+	a.NoError(err)
+	newRetryQueue := newTestOnDiskRetryQueue(t, a, path, 1000, newEncryption)
+	transactions, err := newRetryQueue.ExtractLast()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1"}, getEndpointsFromTransactions(transactions))
+}
+
+func TestOnDiskRetryQueueUndecryptableFiles(t *testing.T) {
+	a := assert.New(t)
+	path := t.TempDir()
+
+	encryption, err := NewEncryption("passphrase", nil)
+	a.NoError(err)
+	plaintextQueue := newTestOnDiskRetryQueue(t, a, path, 1000, nil)
+	a.NoError(plaintextQueue.Store(createHTTPTransactionCollectionTests("endpoint1")))
+	encryptedQueue := newTestOnDiskRetryQueue(t, a, path, 1000, encryption)
+	a.NoError(encryptedQueue.Store(createHTTPTransactionCollectionTests("endpoint2")))
+	a.NoError(encryptedQueue.Store(createHTTPTransactionCollectionTests("endpoint3")))
+
+	// the files encrypted with another passphrase, or while the encryption
+	// is disabled, are dropped
+	decryptErrors := decryptErrorsCountTelemetry.expvar.Value()
+	otherEncryption, err := NewEncryption("other", nil)
+	a.NoError(err)
+	q := newTestOnDiskRetryQueue(t, a, path, 1000, otherEncryption)
+	_, err = q.ExtractLast()
+	a.Error(err)
+	q = newTestOnDiskRetryQueue(t, a, path, 1000, nil)
+	_, err = q.ExtractLast()
+	a.ErrorIs(err, errEncryptionDisabled)
+	a.Equal(decryptErrors+2, decryptErrorsCountTelemetry.expvar.Value())
+
+	// the plaintext files are still read once the encryption is enabled
+	q = newTestOnDiskRetryQueue(t, a, path, 1000, encryption)
+	a.Equal(1, q.getFilesCount())
+	transactions, err := q.ExtractLast()
+	a.NoError(err)
+	a.Equal([]string{"endpoint1"}, getEndpointsFromTransactions(transactions))
+}
+
 func createHTTPTransactionCollectionTests(endpoints ...string) []transaction.Transaction {
 	var transactions []transaction.Transaction
 
@@ -125,7 +196,7 @@ func getEndpointsFromTransactions(transactions []transaction.Transaction) []stri
 	return endpoints
 }
 
-func newTestOnDiskRetryQueue(t *testing.T, a *assert.Assertions, path string, maxSizeInBytes int64) *onDiskRetryQueue {
+func newTestOnDiskRetryQueue(t *testing.T, a *assert.Assertions, path string, maxSizeInBytes int64, encryption *Encryption) *onDiskRetryQueue {
 	telemetry := newOnDiskRetryQueueTelemetry("domain")
 	disk := diskUsageRetrieverMock{
 		diskUsage: &filesystem.DiskUsage{
@@ -134,7 +205,7 @@ func newTestOnDiskRetryQueue(t *testing.T, a *assert.Assertions, path string, ma
 		}}
 	diskUsageLimit := NewDiskUsageLimit("", disk, maxSizeInBytes, 1)
 	log := logmock.New(t)
-	storage, err := newOnDiskRetryQueue(log, NewHTTPTransactionsSerializer(log, resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry, NewPointCountTelemetryMock())
+	storage, err := newOnDiskRetryQueue(log, NewHTTPTransactionsSerializer(log, resolver.NewSingleDomainResolver(domainName, nil)), path, diskUsageLimit, telemetry, NewPointCountTelemetryMock(), encryption)
 	a.NoError(err)
 	return storage
 }
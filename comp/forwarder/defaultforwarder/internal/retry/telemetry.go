@@ -77,6 +77,7 @@ var (
 	fileStoragePointDroppedCountTelemetry   *counterExpvar
 	deserializeErrorsCountTelemetry         *counterExpvar
 	deserializeTransactionsCountTelemetry   *counterExpvar
+	decryptErrorsCountTelemetry             *counterExpvar
 )
 
 func init() {
@@ -202,6 +203,12 @@ func init() {
 		domainTag,
 		"The number of transactions read from the disk",
 		&fileStorageExpvar)
+	decryptErrorsCountTelemetry = newCounterExpvar(
+		"file_storage",
+		"decrypt_errors_count",
+		domainTag,
+		"The number of files dropped because they cannot be decrypted",
+		&fileStorageExpvar)
 }
 
 // FileRemovalPolicyTelemetry handles the telemetry for FileRemovalPolicy.
@@ -304,6 +311,10 @@ func (t onDiskRetryQueueTelemetry) addDeserializeTransactionsCount(count int) {
 	deserializeTransactionsCountTelemetry.add(float64(count), t.domainName)
 }
 
+func (t onDiskRetryQueueTelemetry) addDecryptErrorsCount() {
+	decryptErrorsCountTelemetry.add(1, t.domainName)
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	var camelCase string
@@ -186,7 +186,8 @@ func newOnDiskRetryQueueTest(t *testing.T, a *assert.Assertions) *onDiskRetryQue
 		path,
 		diskUsageLimit,
 		newOnDiskRetryQueueTelemetry("domain"),
-		NewPointCountTelemetryMock())
+		NewPointCountTelemetryMock(),
+		nil)
 	a.NoError(err)
 	return q
 }
@@ -9,8 +9,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
@@ -85,3 +87,25 @@ func TestHTML(t *testing.T) {
 
 	assert.NotEqual(t, "", b.String())
 }
+
+func TestTextFailover(t *testing.T) {
+	config := config.NewMock(t)
+	setFailoverStatus("https://app.datadoghq.com", "https://app.datadoghq.eu", time.Unix(1700000000, 0), 1)
+	t.Cleanup(func() { failoverStatus.Init() })
+
+	provider := statusProvider{
+		config: config,
+	}
+
+	b := new(bytes.Buffer)
+	require.NoError(t, provider.Text(false, b))
+
+	assert.Contains(t, b.String(), "Endpoint failover")
+	assert.Contains(t, b.String(), "Primary endpoint: https://app.datadoghq.com")
+	assert.Contains(t, b.String(), "https://app.datadoghq.eu")
+	assert.Contains(t, b.String(), "Number of failovers: 1")
+
+	b.Reset()
+	require.NoError(t, provider.HTML(false, b))
+	assert.Contains(t, b.String(), "Active endpoint: https://app.datadoghq.eu")
+}
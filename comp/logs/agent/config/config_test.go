@@ -74,6 +74,9 @@ func compareEndpoint(t *testing.T, expected Endpoint, actual Endpoint) {
 	assert.Equal(t, expected.TrackType, actual.TrackType, "TrackType is not Equal")
 	assert.Equal(t, expected.Protocol, actual.Protocol, "Protocol is not Equal")
 	assert.Equal(t, expected.Origin, actual.Origin, "Origin is not Equal")
+	assert.Equal(t, expected.Format, actual.Format, "Format is not Equal")
+	assert.Equal(t, expected.Path, actual.Path, "Path is not Equal")
+	assert.Equal(t, expected.Headers, actual.Headers, "Headers is not Equal")
 }
 
 func (suite *ConfigTestSuite) compareEndpoints(expected *Endpoints, actual *Endpoints) {
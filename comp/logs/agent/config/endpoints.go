@@ -28,6 +28,18 @@ type IntakeProtocol string
 // IntakeOrigin indicates the log source to use for an endpoint intake.
 type IntakeOrigin string
 
+// EndpointFormat indicates the format of the payloads sent to an endpoint.
+type EndpointFormat string
+
+const (
+	// DatadogFormat is the format of the Datadog intake, it is the default one.
+	DatadogFormat EndpointFormat = ""
+	// OTLPFormat sends logs as OTLP/HTTP protobuf payloads, e.g. to an OpenTelemetry collector.
+	OTLPFormat EndpointFormat = "otlp"
+	// NDJSONFormat sends logs as newline-delimited JSON, e.g. to a generic HTTP sink.
+	NDJSONFormat EndpointFormat = "ndjson"
+)
+
 const (
 	_ EPIntakeVersion = iota
 	// EPIntakeVersion1 is version 1 of the envets platform intake API
@@ -71,6 +83,13 @@ type Endpoint struct {
 	TrackType IntakeTrackType
 	Protocol  IntakeProtocol
 	Origin    IntakeOrigin
+
+	// Format, Path and Headers allow sending logs to HTTP endpoints which are
+	// not Datadog intakes. Path overrides the default path of the format and
+	// Headers are added to every request.
+	Format  EndpointFormat    `mapstructure:"format" json:"format"`
+	Path    string            `mapstructure:"path" json:"path"`
+	Headers map[string]string `mapstructure:"headers" json:"headers"`
 }
 
 // unmarshalEndpoint is used to load additional endpoints from the configuration which stored as JSON/mapstructure.
@@ -143,6 +162,10 @@ func loadTCPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys) []Endpoint {
 
 	newEndpoints := make([]Endpoint, 0, len(additionals))
 	for idx, e := range additionals {
+		if e.Format != DatadogFormat {
+			log.Warnf("additional endpoint %s uses the %s format which is only supported over HTTP, ignoring it", e.Host, e.Format)
+			continue
+		}
 		newE := NewEndpoint(e.APIKey, configKeyUsed, e.Host, e.Port, false)
 
 		newE.isAdditionalEndpoint = true
@@ -179,6 +202,12 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 
 	newEndpoints := make([]Endpoint, 0, len(additionals))
 	for idx, e := range additionals {
+		switch e.Format {
+		case DatadogFormat, OTLPFormat, NDJSONFormat:
+		default:
+			log.Errorf("additional endpoint %s uses an unknown format '%s', ignoring it", e.Host, e.Format)
+			continue
+		}
 		newE := NewEndpoint(e.APIKey, configKeyUsed, e.Host, e.Port, false)
 
 		newE.isAdditionalEndpoint = true
@@ -205,10 +234,14 @@ func loadHTTPAdditionalEndpoints(main Endpoint, l *LogsConfigKeys, intakeTrackTy
 			newE.useSSL = main.useSSL
 		}
 
+		newE.Format = e.Format
+		newE.Path = e.Path
+		newE.Headers = e.Headers
+
 		if newE.Version == 0 {
 			newE.Version = main.Version
 		}
-		if newE.Version == EPIntakeVersion2 {
+		if newE.Version == EPIntakeVersion2 && newE.Format == DatadogFormat {
 			newE.TrackType = intakeTrackType
 			newE.Protocol = intakeProtocol
 			newE.Origin = intakeOrigin
@@ -264,6 +297,9 @@ func (e *Endpoint) GetStatus(prefix string, useHTTP bool) string {
 		}
 	}
 
+	if e.Format != DatadogFormat {
+		return fmt.Sprintf("%sSending %s logs in %s format in %s to %s on port %d", prefix, compression, e.Format, protocol, host, port)
+	}
 	return fmt.Sprintf("%sSending %s logs in %s to %s on port %d", prefix, compression, protocol, host, port)
 }
 
@@ -880,6 +880,68 @@ func (suite *EndpointsTestSuite) TestloadHTTPAdditionalEndpoints() {
 	compareEndpoint(suite.T(), expected2, endpoints[1])
 }
 
+func (suite *EndpointsTestSuite) TestloadHTTPAdditionalEndpointsWithFormat() {
+	jsonString := `[{
+			"Host":    "otel-collector",
+			"Port":    4318,
+			"use_ssl": false,
+			"format":  "otlp",
+			"headers": {"Authorization": "Bearer token"}
+		},
+		{
+			"Host":    "sink",
+			"format":  "ndjson",
+			"path":    "/logs"
+		},
+		{
+			"Host":    "unknown",
+			"format":  "xml"
+		}]`
+	suite.config.SetWithoutSource("logs_config.additional_endpoints", jsonString)
+
+	expected1 := Endpoint{
+		apiKey:                 atomic.NewString(""),
+		configSettingPath:      "logs_config.additional_endpoints",
+		isAdditionalEndpoint:   true,
+		additionalEndpointsIdx: 0,
+		isReliable:             true,
+		Host:                   "otel-collector",
+		Port:                   4318,
+		Version:                EPIntakeVersion2,
+		Format:                 OTLPFormat,
+		Headers:                map[string]string{"Authorization": "Bearer token"},
+	}
+	expected2 := Endpoint{
+		apiKey:                 atomic.NewString(""),
+		configSettingPath:      "logs_config.additional_endpoints",
+		isAdditionalEndpoint:   true,
+		additionalEndpointsIdx: 1,
+		isReliable:             true,
+		useSSL:                 true,
+		Host:                   "sink",
+		Version:                EPIntakeVersion2,
+		Format:                 NDJSONFormat,
+		Path:                   "/logs",
+	}
+
+	main := Endpoint{
+		useSSL:  true,
+		Version: EPIntakeVersion2,
+	}
+
+	logsConfig := defaultLogsConfigKeys(suite.config)
+	endpoints := loadHTTPAdditionalEndpoints(main, logsConfig, "logs", "", "")
+
+	// the endpoint with an unknown format is ignored and the intake track
+	// type is not used by endpoints which are not Datadog intakes
+	suite.Suite.Require().Len(endpoints, 2)
+	compareEndpoint(suite.T(), expected1, endpoints[0])
+	compareEndpoint(suite.T(), expected2, endpoints[1])
+
+	// formats are only supported over HTTP
+	suite.Suite.Require().Empty(loadTCPAdditionalEndpoints(main, logsConfig))
+}
+
 func TestEndpointsTestSuite(t *testing.T) {
 	suite.Run(t, new(EndpointsTestSuite))
 }
@@ -18,10 +18,12 @@ import (
 const (
 	TCPType           = "tcp"
 	UDPType           = "udp"
+	SyslogType        = "syslog"
 	FileType          = "file"
 	DockerType        = "docker"
 	ContainerdType    = "containerd"
 	JournaldType      = "journald"
+	KafkaType         = "kafka"
 	IntegrationType   = "integration"
 	WindowsEventType  = "windows_event"
 	StringChannelType = "string_channel"
@@ -34,6 +36,18 @@ const (
 	SHIFTJIS string = "shift-jis"
 )
 
+// Log formats parsed into structured logs by the processor
+const (
+	// LogfmtFormat parses lines of key=value pairs
+	LogfmtFormat = "logfmt"
+	// ApacheCombinedFormat parses access logs in the combined or common log format
+	ApacheCombinedFormat = "apache_combined"
+	// NginxFormat parses nginx access and error logs
+	NginxFormat = "nginx"
+	// AutoFormat parses lines of any of the formats above
+	AutoFormat = "auto"
+)
+
 // LogsConfig represents a log source config, which can be for instance
 // a file to tail or a port to listen to.
 type LogsConfig struct {
@@ -43,11 +57,14 @@ type LogsConfig struct {
 
 	Port        int    // Network
 	IdleTimeout string `mapstructure:"idle_timeout" json:"idle_timeout"` // Network
+	Protocol    string `mapstructure:"protocol" json:"protocol"`         // Syslog
 	Path        string // File, Journald
 
 	Encoding     string   `mapstructure:"encoding" json:"encoding"`             // File
 	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
 	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	// ReadCompressed enables reading gzip and zstd archives from the beginning, once.
+	ReadCompressed bool `mapstructure:"read_compressed" json:"read_compressed"` // File
 
 	//nolint:revive // TODO(AML) Fix revive linter
 	ConfigId           string   `mapstructure:"config_id" json:"config_id"`                   // Journald
@@ -59,6 +76,10 @@ type LogsConfig struct {
 	ExcludeMatches     []string `mapstructure:"exclude_matches" json:"exclude_matches"`       // Journald
 	ContainerMode      bool     `mapstructure:"container_mode" json:"container_mode"`         // Journald
 
+	Brokers       []string `mapstructure:"brokers" json:"brokers"`               // Kafka
+	Topics        []string `mapstructure:"topics" json:"topics"`                 // Kafka
+	ConsumerGroup string   `mapstructure:"consumer_group" json:"consumer_group"` // Kafka
+
 	Image string // Docker
 	Label string // Docker
 	// Name contains the container name
@@ -86,6 +107,17 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+	// LogMetrics are the metrics generated from the logs of the source.
+	LogMetrics []*LogMetricRule `mapstructure:"log_metrics" json:"log_metrics"`
+	// Format is the format of the logs, lines matching it are sent as structured logs.
+	Format string `mapstructure:"format" json:"format"`
+	// TimestampPattern matches the timestamp of the logs in their content, it
+	// is parsed with TimestampFormat in Timezone when it has no time zone.
+	TimestampPattern string `mapstructure:"timestamp_pattern" json:"timestamp_pattern"`
+	TimestampFormat  string `mapstructure:"timestamp_format" json:"timestamp_format"`
+	Timezone         string `mapstructure:"timezone" json:"timezone"`
+	// timestamp holds the compiled timestamp settings, see CompileTimestamp.
+	timestamp *timestampSettings
 	// ProcessRawMessage is used to process the raw message instead of only the content part of the message.
 	ProcessRawMessage *bool `mapstructure:"process_raw_message" json:"process_raw_message"`
 
@@ -117,12 +149,17 @@ func (c *LogsConfig) Dump(multiline bool) string {
 	case UDPType:
 		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
 		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
+	case SyslogType:
+		fmt.Fprintf(&b, ws("Port: %d,"), c.Port)
+		fmt.Fprintf(&b, ws("Protocol: %#v,"), c.Protocol)
+		fmt.Fprintf(&b, ws("IdleTimeout: %#v,"), c.IdleTimeout)
 	case FileType:
 		fmt.Fprintf(&b, ws("Path: %#v,"), c.Path)
 		fmt.Fprintf(&b, ws("Encoding: %#v,"), c.Encoding)
 		fmt.Fprintf(&b, ws("Identifier: %#v,"), c.Identifier)
 		fmt.Fprintf(&b, ws("ExcludePaths: %#v,"), c.ExcludePaths)
 		fmt.Fprintf(&b, ws("TailingMode: %#v,"), c.TailingMode)
+		fmt.Fprintf(&b, ws("ReadCompressed: %t,"), c.ReadCompressed)
 	case DockerType, ContainerdType:
 		fmt.Fprintf(&b, ws("Image: %#v,"), c.Image)
 		fmt.Fprintf(&b, ws("Label: %#v,"), c.Label)
@@ -136,6 +173,11 @@ func (c *LogsConfig) Dump(multiline bool) string {
 		fmt.Fprintf(&b, ws("IncludeUserUnits: %#v,"), c.IncludeUserUnits)
 		fmt.Fprintf(&b, ws("ExcludeUserUnits: %#v,"), c.ExcludeUserUnits)
 		fmt.Fprintf(&b, ws("ContainerMode: %t,"), c.ContainerMode)
+	case KafkaType:
+		fmt.Fprintf(&b, ws("Brokers: %#v,"), c.Brokers)
+		fmt.Fprintf(&b, ws("Topics: %#v,"), c.Topics)
+		fmt.Fprintf(&b, ws("ConsumerGroup: %#v,"), c.ConsumerGroup)
+		fmt.Fprintf(&b, ws("TailingMode: %#v,"), c.TailingMode)
 	case WindowsEventType:
 		fmt.Fprintf(&b, ws("ChannelPath: %#v,"), c.ChannelPath)
 		fmt.Fprintf(&b, ws("Query: %#v,"), c.Query)
@@ -150,6 +192,11 @@ func (c *LogsConfig) Dump(multiline bool) string {
 	fmt.Fprintf(&b, ws("SourceCategory: %#v,"), c.SourceCategory)
 	fmt.Fprintf(&b, ws("Tags: %#v,"), c.Tags)
 	fmt.Fprintf(&b, ws("ProcessingRules: %#v,"), c.ProcessingRules)
+	fmt.Fprintf(&b, ws("LogMetrics: %#v,"), c.LogMetrics)
+	fmt.Fprintf(&b, ws("Format: %#v,"), c.Format)
+	fmt.Fprintf(&b, ws("TimestampPattern: %#v,"), c.TimestampPattern)
+	fmt.Fprintf(&b, ws("TimestampFormat: %#v,"), c.TimestampFormat)
+	fmt.Fprintf(&b, ws("Timezone: %#v,"), c.Timezone)
 	if c.ProcessRawMessage != nil {
 		fmt.Fprintf(&b, ws("ProcessRawMessage: %t,"), *c.ProcessRawMessage)
 	} else {
@@ -171,31 +218,49 @@ func (c *LogsConfig) Dump(multiline bool) string {
 func (c *LogsConfig) PublicJSON() ([]byte, error) {
 	// Export only fields that are explicitly documented in the public documentation
 	return json.Marshal(&struct {
-		Type            string            `json:"type,omitempty"`
-		Port            int               `json:"port,omitempty"`           // Network
-		Path            string            `json:"path,omitempty"`           // File, Journald
-		Encoding        string            `json:"encoding,omitempty"`       // File
-		ExcludePaths    []string          `json:"exclude_paths,omitempty"`  // File
-		TailingMode     string            `json:"start_position,omitempty"` // File
-		ChannelPath     string            `json:"channel_path,omitempty"`   // Windows Event
-		Service         string            `json:"service,omitempty"`
-		Source          string            `json:"source,omitempty"`
-		Tags            []string          `json:"tags,omitempty"`
-		ProcessingRules []*ProcessingRule `json:"log_processing_rules,omitempty"`
-		AutoMultiLine   *bool             `json:"auto_multi_line_detection,omitempty"`
+		Type             string            `json:"type,omitempty"`
+		Port             int               `json:"port,omitempty"`            // Network
+		Protocol         string            `json:"protocol,omitempty"`        // Syslog
+		Path             string            `json:"path,omitempty"`            // File, Journald
+		Encoding         string            `json:"encoding,omitempty"`        // File
+		ExcludePaths     []string          `json:"exclude_paths,omitempty"`   // File
+		TailingMode      string            `json:"start_position,omitempty"`  // File
+		ReadCompressed   bool              `json:"read_compressed,omitempty"` // File
+		Topics           []string          `json:"topics,omitempty"`          // Kafka
+		ConsumerGroup    string            `json:"consumer_group,omitempty"`  // Kafka
+		ChannelPath      string            `json:"channel_path,omitempty"`    // Windows Event
+		Service          string            `json:"service,omitempty"`
+		Source           string            `json:"source,omitempty"`
+		Tags             []string          `json:"tags,omitempty"`
+		ProcessingRules  []*ProcessingRule `json:"log_processing_rules,omitempty"`
+		LogMetrics       []*LogMetricRule  `json:"log_metrics,omitempty"`
+		Format           string            `json:"format,omitempty"`
+		TimestampPattern string            `json:"timestamp_pattern,omitempty"`
+		TimestampFormat  string            `json:"timestamp_format,omitempty"`
+		Timezone         string            `json:"timezone,omitempty"`
+		AutoMultiLine    *bool             `json:"auto_multi_line_detection,omitempty"`
 	}{
-		Type:            c.Type,
-		Port:            c.Port,
-		Path:            c.Path,
-		Encoding:        c.Encoding,
-		ExcludePaths:    c.ExcludePaths,
-		TailingMode:     c.TailingMode,
-		ChannelPath:     c.ChannelPath,
-		Service:         c.Service,
-		Source:          c.Source,
-		Tags:            c.Tags,
-		ProcessingRules: c.ProcessingRules,
-		AutoMultiLine:   c.AutoMultiLine,
+		Type:             c.Type,
+		Port:             c.Port,
+		Protocol:         c.Protocol,
+		Path:             c.Path,
+		Encoding:         c.Encoding,
+		ExcludePaths:     c.ExcludePaths,
+		TailingMode:      c.TailingMode,
+		ReadCompressed:   c.ReadCompressed,
+		Topics:           c.Topics,
+		ConsumerGroup:    c.ConsumerGroup,
+		ChannelPath:      c.ChannelPath,
+		Service:          c.Service,
+		Source:           c.Source,
+		Tags:             c.Tags,
+		ProcessingRules:  c.ProcessingRules,
+		LogMetrics:       c.LogMetrics,
+		Format:           c.Format,
+		TimestampPattern: c.TimestampPattern,
+		TimestampFormat:  c.TimestampFormat,
+		Timezone:         c.Timezone,
+		AutoMultiLine:    c.AutoMultiLine,
 	})
 }
 
@@ -260,6 +325,37 @@ func (c *LogsConfig) Validate() error {
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType:
+		if c.Port == 0 {
+			return fmt.Errorf("syslog source must have a port")
+		}
+		if c.Protocol != "" && c.Protocol != TCPType && c.Protocol != UDPType {
+			return fmt.Errorf("invalid protocol '%v' for syslog source, must be either tcp or udp", c.Protocol)
+		}
+	case c.Type == KafkaType:
+		if len(c.Brokers) == 0 {
+			return fmt.Errorf("kafka source must have brokers")
+		}
+		if len(c.Topics) == 0 {
+			return fmt.Errorf("kafka source must have topics")
+		}
+		if mode, found := TailingModeFromString(c.TailingMode); c.TailingMode != "" && (!found || (mode != Beginning && mode != End)) {
+			return fmt.Errorf("invalid start position '%v' for kafka source, must be either beginning or end", c.TailingMode)
+		}
+	}
+	switch c.Format {
+	case "", LogfmtFormat, ApacheCombinedFormat, NginxFormat, AutoFormat:
+	default:
+		return fmt.Errorf("invalid format '%v', must be one of %v, %v, %v or %v", c.Format, LogfmtFormat, ApacheCombinedFormat, NginxFormat, AutoFormat)
+	}
+	if err := c.CompileTimestamp(); err != nil {
+		return err
+	}
+	if err := ValidateLogMetricRules(c.LogMetrics); err != nil {
+		return err
+	}
+	if err := CompileLogMetricRules(c.LogMetrics); err != nil {
+		return err
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -21,8 +21,20 @@ func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 		{Type: FileType, Path: "/var/log/foo.log"},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 514, Protocol: UDPType},
+		{Type: KafkaType, Brokers: []string{"localhost:9092"}, Topics: []string{"logs"}},
+		{Type: KafkaType, Brokers: []string{"localhost:9092"}, Topics: []string{"logs"}, ConsumerGroup: "agent", TailingMode: "beginning"},
 		{Type: DockerType},
+		{Type: DockerType, Format: NginxFormat},
+		{Type: FileType, Path: "/var/log/foo.log", Format: AutoFormat},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExtractKV}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExtractKV, Pattern: `(\w+):(\w+)`}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONDropFields, Fields: []string{"a", "b.c"}}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONRenameField, SourceField: "a", TargetField: "b.c"}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, SampleRate: 0.5}}},
+		{Type: FileType, Path: "/var/log/foo.log", ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RateLimit, MaxPerSecond: 10, Pattern: `user=(\w+)`}}},
 	}
 
 	for _, config := range validConfigs {
@@ -37,6 +49,12 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: FileType},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "sctp"},
+		{Type: KafkaType, Topics: []string{"logs"}},
+		{Type: KafkaType, Brokers: []string{"localhost:9092"}},
+		{Type: KafkaType, Brokers: []string{"localhost:9092"}, Topics: []string{"logs"}, TailingMode: "forceBeginning"},
+		{Type: DockerType, Format: "syslog"},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
@@ -44,6 +62,16 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExtractKV, Pattern: `\w+=\w+`}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExtractKV, Pattern: "(?=abf)"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONDropFields}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONDropFields, Fields: []string{""}}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONRenameField, SourceField: "a"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: JSONRenameField, TargetField: "b"}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: Sample, SampleRate: 1.5}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RateLimit}}},
+		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: RateLimit, MaxPerSecond: 10, Pattern: "(?=abf)"}}},
 	}
 
 	for _, config := range invalidConfigs {
@@ -101,3 +129,18 @@ func TestPublicJSON(t *testing.T) {
 	expectedJSON := `{"type":"file","path":"/var/log/foo.log","encoding":"utf-8","service":"foo","source":"bar","tags":["foo:bar"]}`
 	assert.Equal(t, expectedJSON, string(ret))
 }
+
+func TestPublicJSONTimestampSettings(t *testing.T) {
+	config := LogsConfig{
+		Type:             FileType,
+		Path:             "/var/log/foo.log",
+		TimestampPattern: `^(\S+)`,
+		TimestampFormat:  "rfc3339",
+		Timezone:         "UTC",
+	}
+	ret, err := config.PublicJSON()
+	assert.NoError(t, err)
+
+	expectedJSON := `{"type":"file","path":"/var/log/foo.log","timestamp_pattern":"^(\\S+)","timestamp_format":"rfc3339","timezone":"UTC"}`
+	assert.Equal(t, expectedJSON, string(ret))
+}
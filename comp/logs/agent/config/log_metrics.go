@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Log metric types
+const (
+	CountLogMetric        = "count"
+	DistributionLogMetric = "distribution"
+)
+
+// LogMetricRule defines a metric generated from the logs matching a pattern.
+type LogMetricRule struct {
+	// Name is the name of the metric.
+	Name string `mapstructure:"name" json:"name"`
+	// Type is the type of the metric, count or distribution.
+	Type string `mapstructure:"type" json:"type"`
+	// Match is the pattern the logs must match to generate the metric.
+	Match string `mapstructure:"match" json:"match"`
+	// ValueField is the named group of Match holding the value of the metric,
+	// counts are incremented by one for every matching log without it.
+	ValueField string `mapstructure:"value_field" json:"value_field"`
+	// TagFields are the named groups of Match whose values are added as tags
+	// to the metric, tagged by the name of the group.
+	TagFields []string `mapstructure:"tag_fields" json:"tag_fields"`
+	// DropLog drops the logs matching Match once the metric is generated.
+	DropLog bool `mapstructure:"drop_log" json:"drop_log"`
+	// Regex is the compiled Match pattern, set by CompileLogMetricRules.
+	Regex *regexp.Regexp `json:"-"`
+}
+
+// ValidateLogMetricRules validates the rules and raises an error if one is misconfigured.
+// Each log metric rule must have:
+// - a name
+// - a valid type
+// - a valid match pattern that compiles
+// - a value_field for distributions
+// - a value_field and tag_fields which are named groups of the match pattern
+func ValidateLogMetricRules(rules []*LogMetricRule) error {
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("all log metrics must have a name")
+		}
+
+		switch rule.Type {
+		case CountLogMetric:
+		case DistributionLogMetric:
+			if rule.ValueField == "" {
+				return fmt.Errorf("no value_field provided for log metric: %s", rule.Name)
+			}
+		case "":
+			return fmt.Errorf("type must be set for log metric `%s`", rule.Name)
+		default:
+			return fmt.Errorf("type %s is not supported for log metric `%s`", rule.Type, rule.Name)
+		}
+
+		if rule.Match == "" {
+			return fmt.Errorf("no match pattern provided for log metric: %s", rule.Name)
+		}
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return fmt.Errorf("invalid match pattern %s for log metric: %s", rule.Match, rule.Name)
+		}
+		groups := re.SubexpNames()
+		if rule.ValueField != "" && !slices.Contains(groups, rule.ValueField) {
+			return fmt.Errorf("value_field %s is not a named group of the match pattern of log metric: %s", rule.ValueField, rule.Name)
+		}
+		for _, field := range rule.TagFields {
+			if field == "" || !slices.Contains(groups, field) {
+				return fmt.Errorf("tag_field %s is not a named group of the match pattern of log metric: %s", field, rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// CompileLogMetricRules compiles the match patterns of the rules.
+func CompileLogMetricRules(rules []*LogMetricRule) error {
+	for _, rule := range rules {
+		re, err := regexp.Compile(rule.Match)
+		if err != nil {
+			return err
+		}
+		rule.Regex = re
+	}
+	return nil
+}
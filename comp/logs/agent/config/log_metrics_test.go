@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLogMetricRulesShouldSucceedWithValidRules(t *testing.T) {
+	validRules := []*LogMetricRule{
+		{Name: "requests", Type: CountLogMetric, Match: "GET"},
+		{Name: "requests", Type: CountLogMetric, Match: `(?P<status>\d{3}) (?P<bytes>\d+)`, ValueField: "bytes", TagFields: []string{"status"}, DropLog: true},
+		{Name: "latency", Type: DistributionLogMetric, Match: `latency=(?P<latency>\d+)`, ValueField: "latency"},
+	}
+
+	for _, rule := range validRules {
+		rules := []*LogMetricRule{rule}
+		assert.Nil(t, ValidateLogMetricRules(rules))
+		assert.Nil(t, CompileLogMetricRules(rules))
+		assert.NotNil(t, rule.Regex)
+	}
+}
+
+func TestValidateLogMetricRulesShouldFailWithInvalidRules(t *testing.T) {
+	invalidRules := []*LogMetricRule{
+		{Type: CountLogMetric, Match: "GET"},
+		{Name: "requests", Match: "GET"},
+		{Name: "requests", Type: "gauge", Match: "GET"},
+		{Name: "requests", Type: CountLogMetric},
+		{Name: "requests", Type: CountLogMetric, Match: "(?=GET)"},
+		{Name: "latency", Type: DistributionLogMetric, Match: `latency=(?P<latency>\d+)`},
+		{Name: "latency", Type: DistributionLogMetric, Match: `latency=(\d+)`, ValueField: "latency"},
+		{Name: "requests", Type: CountLogMetric, Match: `(?P<status>\d{3})`, TagFields: []string{"method"}},
+		{Name: "requests", Type: CountLogMetric, Match: `(\d{3})`, TagFields: []string{""}},
+	}
+
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateLogMetricRules([]*LogMetricRule{rule}), rule.Name)
+	}
+
+	config := &LogsConfig{Type: FileType, Path: "/var/log/foo.log", LogMetrics: invalidRules[:1]}
+	assert.NotNil(t, config.Validate())
+}
@@ -12,12 +12,22 @@ import (
 
 // Processing rule types
 const (
-	ExcludeAtMatch = "exclude_at_match"
-	IncludeAtMatch = "include_at_match"
-	MaskSequences  = "mask_sequences"
-	MultiLine      = "multi_line"
+	ExcludeAtMatch  = "exclude_at_match"
+	IncludeAtMatch  = "include_at_match"
+	MaskSequences   = "mask_sequences"
+	MultiLine       = "multi_line"
+	ExtractKV       = "extract_kv"
+	JSONDropFields  = "json_drop_fields"
+	JSONRenameField = "json_rename_field"
+	Sample          = "sample"
+	RateLimit       = "rate_limit"
 )
 
+// defaultKeyValuePattern matches `key=value` pairs, where the value can be
+// double-quoted to contain spaces. It is used by extract_kv rules not
+// providing their own pattern.
+const defaultKeyValuePattern = `([\w.\-]+)=("[^"]*"|[^\s,;]*)`
+
 // ProcessingRule defines an exclusion or a masking rule to
 // be applied on log lines
 type ProcessingRule struct {
@@ -25,6 +35,17 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// Fields lists the fields removed by json_drop_fields rules, nested
+	// fields are separated by dots.
+	Fields []string `mapstructure:"fields" json:"fields"`
+	// SourceField and TargetField are the fields renamed by
+	// json_rename_field rules, nested fields are separated by dots.
+	SourceField string `mapstructure:"source_field" json:"source_field"`
+	TargetField string `mapstructure:"target_field" json:"target_field"`
+	// SampleRate is the ratio of logs kept by sample rules, between 0 and 1.
+	SampleRate float64 `mapstructure:"sample_rate" json:"sample_rate"`
+	// MaxPerSecond is the number of logs kept every second by rate_limit rules.
+	MaxPerSecond int `mapstructure:"max_per_second" json:"max_per_second"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
 	Placeholder []byte
@@ -34,7 +55,10 @@ type ProcessingRule struct {
 // Each processing rule must have:
 // - a valid name
 // - a valid type
-// - a valid pattern that compiles
+// - a valid pattern that compiles, optional for extract_kv, sample and rate_limit
+// rules and unused by json rules
+// - the fields it applies to for json rules
+// - a valid rate for sample and rate_limit rules
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -43,20 +67,45 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 
 		switch rule.Type {
 		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
+			if rule.Pattern == "" {
+				return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
+			}
+		case ExtractKV:
 			break
+		case JSONDropFields:
+			if len(rule.Fields) == 0 {
+				return fmt.Errorf("no fields provided for processing rule: %s", rule.Name)
+			}
+			for _, field := range rule.Fields {
+				if field == "" {
+					return fmt.Errorf("empty field provided for processing rule: %s", rule.Name)
+				}
+			}
+		case JSONRenameField:
+			if rule.SourceField == "" || rule.TargetField == "" {
+				return fmt.Errorf("source_field and target_field must be set for processing rule: %s", rule.Name)
+			}
+		case Sample:
+			if rule.SampleRate <= 0 || rule.SampleRate > 1 {
+				return fmt.Errorf("sample_rate must be greater than 0 and lower than or equal to 1 for processing rule: %s", rule.Name)
+			}
+		case RateLimit:
+			if rule.MaxPerSecond <= 0 {
+				return fmt.Errorf("max_per_second must be greater than 0 for processing rule: %s", rule.Name)
+			}
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
 			return fmt.Errorf("type %s is not supported for processing rule `%s`", rule.Type, rule.Name)
 		}
 
-		if rule.Pattern == "" {
-			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
-		}
-		_, err := regexp.Compile(rule.Pattern)
+		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+		if rule.Type == ExtractKV && rule.Pattern != "" && re.NumSubexp() != 2 {
+			return fmt.Errorf("pattern %s for processing rule: %s must have two capturing groups, for the key and the value", rule.Pattern, rule.Name)
+		}
 	}
 	return nil
 }
@@ -79,6 +128,16 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 			if err != nil {
 				return err
 			}
+		case ExtractKV:
+			if rule.Pattern == "" {
+				re = regexp.MustCompile(defaultKeyValuePattern)
+			}
+			rule.Regex = re
+		case Sample, RateLimit:
+			// the pattern restricts the logs the rule applies to
+			if rule.Pattern != "" {
+				rule.Regex = re
+			}
 		}
 	}
 	return nil
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamp formats which are not time layouts
+const (
+	// UnixTimestampFormat parses timestamps in seconds since the epoch
+	UnixTimestampFormat = "unix"
+	// UnixMsTimestampFormat parses timestamps in milliseconds since the epoch
+	UnixMsTimestampFormat = "unix_ms"
+)
+
+// namedTimestampLayouts are the time layouts which can be referred to by name
+// in timestamp_format.
+var namedTimestampLayouts = map[string]string{
+	"rfc3339":     time.RFC3339Nano,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"ansic":       time.ANSIC,
+	"unixdate":    time.UnixDate,
+	"stamp":       time.StampMicro,
+	"datetime":    "2006-01-02 15:04:05.999999999",
+}
+
+// ErrTimestampNotFound is returned when the timestamp pattern of a source
+// does not match the content of a log.
+var ErrTimestampNotFound = errors.New("timestamp pattern did not match")
+
+// timestampSettings holds the compiled timestamp settings of a source.
+type timestampSettings struct {
+	regex    *regexp.Regexp
+	layout   string
+	location *time.Location
+}
+
+// newTimestampSettings validates the timestamp_pattern, timestamp_format and
+// timezone settings of c and compiles them. It returns nil settings when c
+// has no timestamp_pattern.
+func newTimestampSettings(c *LogsConfig) (*timestampSettings, error) {
+	if c.TimestampPattern == "" {
+		if c.TimestampFormat != "" || c.Timezone != "" {
+			return nil, fmt.Errorf("timestamp_format and timezone require a timestamp_pattern")
+		}
+		return nil, nil
+	}
+	if c.TimestampFormat == "" {
+		return nil, fmt.Errorf("timestamp_pattern requires a timestamp_format")
+	}
+
+	re, err := regexp.Compile(c.TimestampPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp_pattern: %w", err)
+	}
+	if re.NumSubexp() > 1 {
+		return nil, fmt.Errorf("timestamp_pattern must have at most one capture group")
+	}
+
+	layout := c.TimestampFormat
+	if named, ok := namedTimestampLayouts[strings.ToLower(layout)]; ok {
+		layout = named
+	}
+
+	location := time.Local
+	if c.Timezone != "" {
+		if location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+	}
+
+	return &timestampSettings{regex: re, layout: layout, location: location}, nil
+}
+
+// CompileTimestamp compiles the timestamp settings of the source, returning
+// an error if they are invalid. It is called when the config is validated and
+// when its source is created, invalid settings disable the timestamp parsing.
+func (c *LogsConfig) CompileTimestamp() error {
+	settings, err := newTimestampSettings(c)
+	c.timestamp = settings
+	return err
+}
+
+// HasTimestampPattern returns true if the timestamp of the logs of the
+// source is parsed from their content.
+func (c *LogsConfig) HasTimestampPattern() bool {
+	return c.timestamp != nil
+}
+
+// ParseTimestamp returns the timestamp found in the content of a log by the
+// timestamp_pattern of the source, parsed with its timestamp_format. Times
+// without a time zone are in the timezone of the source, the local one by
+// default.
+func (c *LogsConfig) ParseTimestamp(content []byte) (time.Time, error) {
+	if !c.HasTimestampPattern() {
+		return time.Time{}, ErrTimestampNotFound
+	}
+	match := c.timestamp.regex.FindSubmatch(content)
+	if match == nil {
+		return time.Time{}, ErrTimestampNotFound
+	}
+	value := string(match[len(match)-1])
+
+	switch c.timestamp.layout {
+	case UnixTimestampFormat, UnixMsTimestampFormat:
+		epoch, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if c.timestamp.layout == UnixTimestampFormat {
+			epoch *= 1000
+		}
+		return time.UnixMicro(int64(epoch * 1000)), nil
+	}
+	return time.ParseInLocation(c.timestamp.layout, value, c.timestamp.location)
+}
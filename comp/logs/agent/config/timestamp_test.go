@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTimestampSettings(t *testing.T) {
+	invalidConfigs := []*LogsConfig{
+		{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`},
+		{Type: FileType, Path: "/var/log/foo.log", TimestampFormat: "rfc3339"},
+		{Type: FileType, Path: "/var/log/foo.log", Timezone: "UTC"},
+		{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+`, TimestampFormat: "rfc3339"},
+		{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+) (\S+)`, TimestampFormat: "rfc3339"},
+		{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339", Timezone: "Nowhere/Nothing"},
+	}
+	for _, config := range invalidConfigs {
+		assert.Error(t, config.Validate())
+		assert.False(t, config.HasTimestampPattern())
+	}
+
+	config := &LogsConfig{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339"}
+	require.NoError(t, config.Validate())
+	assert.True(t, config.HasTimestampPattern())
+}
+
+func TestCompileTimestamp(t *testing.T) {
+	config := &LogsConfig{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+`, TimestampFormat: "rfc3339"}
+	assert.Error(t, config.CompileTimestamp())
+	assert.False(t, config.HasTimestampPattern())
+
+	config = &LogsConfig{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339"}
+	assert.False(t, config.HasTimestampPattern())
+	require.NoError(t, config.CompileTimestamp())
+	ts, err := config.ParseTimestamp([]byte("2024-02-01T08:00:00Z hello"))
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC).Equal(ts))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	tests := []struct {
+		pattern  string
+		format   string
+		timezone string
+		content  string
+		expected time.Time
+	}{
+		{`^(\S+)`, "rfc3339", "", "2024-02-01T08:00:00.25+01:00 hello", time.Date(2024, 2, 1, 7, 0, 0, 250000000, time.UTC)},
+		{`ts=(\d+)`, UnixTimestampFormat, "", "level=info ts=1706774400 msg=hello", time.Unix(1706774400, 0)},
+		{`ts=(\S+)`, UnixTimestampFormat, "", "ts=1706774400.5", time.UnixMilli(1706774400500)},
+		{`"time":(\d+)`, UnixMsTimestampFormat, "", `{"time":1706774400123}`, time.UnixMilli(1706774400123)},
+		{`^\[([^\]]+)\]`, "02/Jan/2006:15:04:05", "Europe/Paris", "[01/Feb/2024:08:00:00] hello", time.Date(2024, 2, 1, 8, 0, 0, 0, paris)},
+		{`^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`, "datetime", "UTC", "2024-02-01 08:00:00 hello", time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		config := &LogsConfig{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: test.pattern, TimestampFormat: test.format, Timezone: test.timezone}
+		require.NoError(t, config.Validate())
+		ts, err := config.ParseTimestamp([]byte(test.content))
+		require.NoError(t, err, test.content)
+		assert.True(t, test.expected.Equal(ts), "%s: expected %s, got %s", test.content, test.expected, ts)
+	}
+}
+
+func TestParseTimestampFailures(t *testing.T) {
+	config := &LogsConfig{Type: FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339"}
+	require.NoError(t, config.Validate())
+
+	_, err := config.ParseTimestamp([]byte(""))
+	assert.ErrorIs(t, err, ErrTimestampNotFound)
+	_, err = config.ParseTimestamp([]byte("yesterday hello"))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrTimestampNotFound)
+
+	// sources without timestamp_pattern have no timestamp to parse
+	config = &LogsConfig{Type: FileType, Path: "/var/log/foo.log"}
+	require.NoError(t, config.Validate())
+	_, err = config.ParseTimestamp([]byte("2024-02-01T08:00:00Z"))
+	assert.ErrorIs(t, err, ErrTimestampNotFound)
+}
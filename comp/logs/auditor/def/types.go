@@ -9,4 +9,10 @@ package auditor
 type Registry interface {
 	GetOffset(identifier string) string
 	GetTailingMode(identifier string) string
+	// KeepAlive prevents the entry of identifier from expiring, for entries
+	// which are not regularly updated by new logs.
+	KeepAlive(identifier string)
+	// CommitOffset records the offset of identifier, for entries whose offset
+	// is not committed by the logs sent.
+	CommitOffset(identifier string, offset string)
 }
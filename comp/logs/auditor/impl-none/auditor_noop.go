@@ -37,6 +37,12 @@ func (a *NullAuditor) GetTailingMode(_ string) string {
 	return ""
 }
 
+// KeepAlive does nothing
+func (a *NullAuditor) KeepAlive(_ string) {}
+
+// CommitOffset does nothing
+func (a *NullAuditor) CommitOffset(_ string, _ string) {}
+
 // Start starts the NullAuditor main loop
 func (a *NullAuditor) Start() {
 	go a.run()
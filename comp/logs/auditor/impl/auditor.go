@@ -172,6 +172,22 @@ func (a *registryAuditor) GetTailingMode(identifier string) string {
 	return entry.TailingMode
 }
 
+// KeepAlive refreshes the last update of the entry matching identifier, if any,
+// so that it is not removed once its TTL is expired.
+func (a *registryAuditor) KeepAlive(identifier string) {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	if entry, exists := a.registry[identifier]; exists {
+		entry.LastUpdated = time.Now().UTC()
+	}
+}
+
+// CommitOffset records the offset of identifier. It is more recent than the
+// one of the logs not yet sent, which don't overwrite it.
+func (a *registryAuditor) CommitOffset(identifier string, offset string) {
+	a.updateRegistry(identifier, offset, a.GetTailingMode(identifier), time.Now().UnixNano())
+}
+
 // run keeps up to date the registry on different events
 func (a *registryAuditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
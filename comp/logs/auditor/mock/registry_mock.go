@@ -38,6 +38,14 @@ func (r *RegistryMock) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
 
+// KeepAlive does nothing.
+func (r *RegistryMock) KeepAlive(_ string) {}
+
+// CommitOffset sets the offset.
+func (r *RegistryMock) CommitOffset(_ string, offset string) {
+	r.offset = offset
+}
+
 // Channel returns a channel
 func (r *RegistryMock) Channel() chan *message.Payload {
 	return nil
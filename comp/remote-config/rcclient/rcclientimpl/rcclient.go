@@ -331,6 +331,13 @@ func (rc rcClient) agentConfigUpdateCallback(updates map[string]state.RawConfig,
 		targetCmp = localSysProbeConf
 	}
 
+	// The dogstatsd tag filter rules only apply to the core agent
+	if !rc.isSystemProbe {
+		if err := rc.applyDogstatsdTagFilterList(mergedConfig.DogstatsdTagFilterList); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
 	// Checks who (the source) is responsible for the last logLevel change
 	source := targetCmp.GetSource("log_level")
 
@@ -386,6 +393,26 @@ func (rc rcClient) agentConfigUpdateCallback(updates map[string]state.RawConfig,
 	}
 }
 
+// applyDogstatsdTagFilterList sets the dogstatsd tag filter rules received through remote config,
+// or falls back to the local rules once remote config doesn't set them anymore.
+func (rc rcClient) applyDogstatsdTagFilterList(rules []interface{}) error {
+	source := rc.config.GetSource("dogstatsd_tag_filterlist")
+	if rules == nil {
+		if source == model.SourceRC {
+			rc.config.UnsetForSource("dogstatsd_tag_filterlist", model.SourceRC)
+			pkglog.Infof("Removing remote-config dogstatsd tag filter rules")
+		}
+		return nil
+	}
+	if source == model.SourceCLI {
+		pkglog.Warnf("Remote config could not change the dogstatsd tag filter rules due to CLI override")
+		return nil
+	}
+
+	pkglog.Infof("Changing the dogstatsd tag filter rules through remote config")
+	return rc.settingsComponent.SetRuntimeSetting("dogstatsd_tag_filterlist", rules, model.SourceRC)
+}
+
 // agentTaskUpdateCallback is the callback function called when there is an AGENT_TASK config update
 // The RCClient can directly call back listeners, because there would be no way to send back
 // RCTE2 configuration applied state to RC backend.
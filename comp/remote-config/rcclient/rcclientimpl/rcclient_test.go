@@ -60,6 +60,29 @@ func (m *mockLogLevelRuntimeSettings) Hidden() bool {
 	return true
 }
 
+type mockDsdTagFilterListRuntimeSettings struct{}
+
+func (m *mockDsdTagFilterListRuntimeSettings) Get(config config.Component) (interface{}, error) {
+	return config.Get(m.Name()), nil
+}
+
+func (m *mockDsdTagFilterListRuntimeSettings) Set(config config.Component, v interface{}, source model.Source) error {
+	config.Set(m.Name(), v, source)
+	return nil
+}
+
+func (m *mockDsdTagFilterListRuntimeSettings) Name() string {
+	return "dogstatsd_tag_filterlist"
+}
+
+func (m *mockDsdTagFilterListRuntimeSettings) Description() string {
+	return ""
+}
+
+func (m *mockDsdTagFilterListRuntimeSettings) Hidden() bool {
+	return true
+}
+
 func applyEmpty(_ string, _ state.ApplyStatus) {}
 
 type MockComponent interface {
@@ -224,6 +247,77 @@ func TestAgentConfigCallback(t *testing.T) {
 	assert.Equal(t, model.SourceCLI, cfg.GetSource("log_level"))
 }
 
+func TestAgentConfigCallbackDogstatsdTagFilterList(t *testing.T) {
+	cfg := configmock.New(t)
+
+	rc := fxutil.Test[rcclient.Component](t,
+		fx.Options(
+			Module(),
+			fx.Provide(func() log.Component { return logmock.New(t) }),
+			fx.Provide(func() config.Component { return cfg }),
+			sysprobeconfig.NoneModule(),
+			fx.Supply(
+				rcclient.Params{
+					AgentName:    "test-agent",
+					AgentVersion: "7.0.0",
+				},
+			),
+			fx.Supply(
+				settings.Params{
+					Settings: map[string]settings.RuntimeSetting{
+						"log_level":                &mockLogLevelRuntimeSettings{logLevel: "info"},
+						"dogstatsd_tag_filterlist": &mockDsdTagFilterListRuntimeSettings{},
+					},
+					Config: cfg,
+				},
+			),
+			settingsimpl.Module(),
+		),
+	)
+
+	layerSetFilters := state.RawConfig{Config: []byte(`{"name": "layer1", "config": {"dogstatsd_tag_filterlist": [{"metric_name": "http.*", "action": "exclude", "tags": ["request_id"]}]}}`)}
+	layerUnsetFilters := state.RawConfig{Config: []byte(`{"name": "layer1", "config": {}}`)}
+	configOrder := state.RawConfig{Config: []byte(`{"internal_order": ["layer1"]}`)}
+
+	structRC := rc.(rcClient)
+
+	ipcAddress, err := pkgconfigsetup.GetIPCAddress(cfg)
+	assert.NoError(t, err)
+
+	structRC.client, _ = client.NewUnverifiedGRPCClient(
+		ipcAddress, pkgconfigsetup.GetIPCPort(), func() (string, error) { return security.FetchAuthToken(cfg) },
+		client.WithAgent("test-agent", "9.99.9"),
+		client.WithProducts(state.ProductAgentConfig),
+		client.WithPollInterval(time.Hour),
+	)
+
+	rules := []interface{}{map[string]interface{}{"metric_name": "http.*", "action": "exclude", "tags": []interface{}{"request_id"}}}
+
+	structRC.agentConfigUpdateCallback(map[string]state.RawConfig{
+		"datadog/2/AGENT_CONFIG/layer1/configname":              layerSetFilters,
+		"datadog/2/AGENT_CONFIG/configuration_order/configname": configOrder,
+	}, applyEmpty)
+	assert.Equal(t, rules, cfg.Get("dogstatsd_tag_filterlist"))
+	assert.Equal(t, model.SourceRC, cfg.GetSource("dogstatsd_tag_filterlist"))
+
+	// the local rules are restored once remote config doesn't set them anymore
+	structRC.agentConfigUpdateCallback(map[string]state.RawConfig{
+		"datadog/2/AGENT_CONFIG/layer1/configname":              layerUnsetFilters,
+		"datadog/2/AGENT_CONFIG/configuration_order/configname": configOrder,
+	}, applyEmpty)
+	assert.NotEqual(t, model.SourceRC, cfg.GetSource("dogstatsd_tag_filterlist"))
+	assert.Empty(t, cfg.Get("dogstatsd_tag_filterlist"))
+
+	// the rules set on the command line are not overridden
+	cfg.Set("dogstatsd_tag_filterlist", []interface{}{}, model.SourceCLI)
+	structRC.agentConfigUpdateCallback(map[string]state.RawConfig{
+		"datadog/2/AGENT_CONFIG/layer1/configname":              layerSetFilters,
+		"datadog/2/AGENT_CONFIG/configuration_order/configname": configOrder,
+	}, applyEmpty)
+	assert.Equal(t, model.SourceCLI, cfg.GetSource("dogstatsd_tag_filterlist"))
+	assert.Empty(t, cfg.Get("dogstatsd_tag_filterlist"))
+}
+
 func TestAgentMRFConfigCallback(t *testing.T) {
 	pkglog.SetupLogger(pkglog.Default(), "info")
 	cfg := configmock.New(t)
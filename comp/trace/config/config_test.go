@@ -41,6 +41,7 @@ import (
 	traceconfig "github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
 )
 
 // team: agent-apm
@@ -638,6 +639,8 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(t, o.Redis.Enabled)
 	assert.True(t, o.Memcached.Enabled)
 	assert.True(t, o.Memcached.KeepCommand)
+	assert.True(t, o.GraphQL.Enabled)
+	assert.True(t, o.GraphQL.RemoveAliases)
 	assert.True(t, o.CreditCards.Enabled)
 	assert.True(t, o.CreditCards.Luhn)
 	assert.True(t, o.Cache.Enabled)
@@ -977,6 +980,78 @@ func TestLoadEnv(t *testing.T) {
 		assert.Equal(t, true, cfg.ErrorTrackingStandalone)
 	})
 
+	t.Run("DD_APM_TAIL_SAMPLING", func(t *testing.T) {
+		t.Setenv("DD_APM_TAIL_SAMPLING_ENABLED", "true")
+		t.Setenv("DD_APM_TAIL_SAMPLING_DECISION_WAIT", "30")
+		t.Setenv("DD_APM_TAIL_SAMPLING_MAX_MEMORY", "1000000")
+		t.Setenv("DD_APM_TAIL_SAMPLING_RULES", `[{"name":"slow","type":"latency","min_duration_ms":500},{"name":"checkout","type":"tag","key":"http.route","value":"/checkout"}]`)
+
+		config := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/undocumented.yaml"},
+		}))
+		cfg := config.Object()
+
+		assert.NotNil(t, cfg)
+		assert.Equal(t, traceconfig.TailSamplingConfig{
+			Enabled:      true,
+			DecisionWait: 30 * time.Second,
+			MaxMemory:    1000000,
+			Rules: []*traceconfig.TailSamplingRule{
+				{Name: "slow", Type: traceconfig.TailSamplingRuleLatency, MinDurationMs: 500},
+				{Name: "checkout", Type: traceconfig.TailSamplingRuleTag, TagKey: "http.route", TagValue: "/checkout"},
+			},
+		}, cfg.TailSampling)
+	})
+
+	env = "DD_APM_SAMPLING_RULES"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, `[{"service":"web","resource":"GET /health*","tags":{"http.method":"GET"},"sample_rate":0},{"env":"prod","name":"http.request","target_tps":10}]`)
+
+		config := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/undocumented.yaml"},
+		}))
+		cfg := config.Object()
+
+		assert.NotNil(t, cfg)
+		assert.Equal(t, []*traceconfig.SamplingRule{
+			{Service: "web", Resource: "GET /health*", Tags: map[string]string{"http.method": "GET"}, SampleRate: pointer.Ptr(0.0)},
+			{Env: "prod", OperationName: "http.request", TargetTPS: pointer.Ptr(10.0)},
+		}, cfg.SamplingRules)
+	})
+
+	t.Run("DD_APM_PII_REDACTION", func(t *testing.T) {
+		t.Setenv("DD_APM_PII_REDACTION_ENABLED", "true")
+		t.Setenv("DD_APM_PII_REDACTION_RULES", `[{"key":"http.*","detectors":["email","jwt"]},{"key":"user.card"}]`)
+
+		config := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/undocumented.yaml"},
+		}))
+		cfg := config.Object()
+
+		assert.NotNil(t, cfg)
+		assert.Equal(t, traceconfig.PIIRedactionConfig{
+			Enabled: true,
+			Rules: []*traceconfig.PIIRedactionRule{
+				{Key: "http.*", Detectors: []string{"email", "jwt"}},
+				{Key: "user.card"},
+			},
+		}, cfg.PIIRedaction)
+	})
+
+	t.Run("DD_APM_ZIPKIN_JAEGER_RECEIVER", func(t *testing.T) {
+		t.Setenv("DD_APM_ZIPKIN_RECEIVER_ENABLED", "true")
+		t.Setenv("DD_APM_JAEGER_RECEIVER_ENABLED", "true")
+
+		config := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/undocumented.yaml"},
+		}))
+		cfg := config.Object()
+
+		assert.NotNil(t, cfg)
+		assert.True(t, cfg.ZipkinReceiverEnabled)
+		assert.True(t, cfg.JaegerReceiverEnabled)
+	})
+
 	for _, envKey := range []string{
 		"DD_IGNORE_RESOURCE", // deprecated
 		"DD_APM_IGNORE_RESOURCES",
@@ -1589,6 +1664,34 @@ func TestLoadEnv(t *testing.T) {
 		assert.True(t, cfg.Obfuscation.Memcached.KeepCommand)
 	})
 
+	env = "DD_APM_OBFUSCATION_GRAPHQL_ENABLED"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "false")
+
+		c := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+		}))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.False(t, pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled"))
+		assert.False(t, cfg.Obfuscation.GraphQL.Enabled)
+	})
+
+	env = "DD_APM_OBFUSCATION_GRAPHQL_REMOVE_ALIASES"
+	t.Run(env, func(t *testing.T) {
+		t.Setenv(env, "false")
+
+		c := buildConfigComponent(t, true, fx.Replace(corecomp.MockParams{
+			Params: corecomp.Params{ConfFilePath: "./testdata/full.yaml"},
+		}))
+		cfg := c.Object()
+
+		assert.NotNil(t, cfg)
+		assert.False(t, pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.remove_aliases"))
+		assert.False(t, cfg.Obfuscation.GraphQL.RemoveAliases)
+	})
+
 	env = "DD_APM_OBFUSCATION_MONGODB_ENABLED"
 	t.Run(env, func(t *testing.T) {
 		t.Setenv(env, "true")
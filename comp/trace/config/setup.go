@@ -252,6 +252,8 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	if core.IsSet("apm_config.connection_limit") {
 		c.ConnectionLimit = core.GetInt("apm_config.connection_limit")
 	}
+	c.ZipkinReceiverEnabled = core.GetBool("apm_config.zipkin_receiver.enabled")
+	c.JaegerReceiverEnabled = core.GetBool("apm_config.jaeger_receiver.enabled")
 	if core.IsSet("apm_config.sql_obfuscation_mode") {
 		c.SQLObfuscationMode = core.GetString("apm_config.sql_obfuscation_mode")
 	}
@@ -316,6 +318,60 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.ErrorTrackingStandalone = core.GetBool("apm_config.error_tracking_standalone.enabled")
 	}
 
+	if core.IsSet("apm_config.tail_sampling.enabled") {
+		c.TailSampling.Enabled = core.GetBool("apm_config.tail_sampling.enabled")
+	}
+	if core.IsSet("apm_config.tail_sampling.decision_wait") {
+		if wait := core.GetInt("apm_config.tail_sampling.decision_wait"); wait > 0 {
+			c.TailSampling.DecisionWait = getDuration(wait)
+		} else {
+			log.Warnf("Invalid apm_config.tail_sampling.decision_wait %d, it must be positive. Using the default: %v", wait, c.TailSampling.DecisionWait)
+		}
+	}
+	if core.IsSet("apm_config.tail_sampling.max_memory") {
+		c.TailSampling.MaxMemory = core.GetInt64("apm_config.tail_sampling.max_memory")
+	}
+	if k := "apm_config.tail_sampling.rules"; core.IsSet(k) {
+		rules := make([]*config.TailSamplingRule, 0)
+		if err := structure.UnmarshalKey(core, k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"name\": \"rule_name\",\"type\":\"latency\",\"min_duration_ms\":500}]', error: %v", k, err)
+		} else {
+			if err := validateTailSamplingRules(rules); err != nil {
+				return fmt.Errorf("tail_sampling.rules: %s", err)
+			}
+			c.TailSampling.Rules = rules
+		}
+	}
+
+	if k := "apm_config.sampling_rules"; core.IsSet(k) {
+		rules := make([]*config.SamplingRule, 0)
+		if err := structure.UnmarshalKey(core, k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"service\": \"web\",\"resource\":\"GET /health*\",\"sample_rate\":0.1}]', error: %v", k, err)
+		} else {
+			for i, r := range rules {
+				if err := r.Validate(); err != nil {
+					return fmt.Errorf("sampling_rules: rule %d: %s", i, err)
+				}
+			}
+			c.SamplingRules = rules
+		}
+	}
+
+	c.PIIRedaction.Enabled = core.GetBool("apm_config.pii_redaction.enabled")
+	if k := "apm_config.pii_redaction.rules"; core.IsSet(k) {
+		rules := make([]*config.PIIRedactionRule, 0)
+		if err := structure.UnmarshalKey(core, k, &rules); err != nil {
+			log.Errorf("Bad format for %q it should be of the form '[{\"key\": \"http.*\",\"detectors\":[\"email\",\"jwt\"]}]', error: %v", k, err)
+		} else {
+			for i, r := range rules {
+				if err := r.Validate(); err != nil {
+					return fmt.Errorf("pii_redaction.rules: rule %d: %s", i, err)
+				}
+			}
+			c.PIIRedaction.Rules = rules
+		}
+	}
+
 	if core.IsSet("apm_config.max_remote_traces_per_second") {
 		c.MaxRemoteTPS = core.GetFloat64("apm_config.max_remote_traces_per_second")
 	}
@@ -462,6 +518,8 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	}
 	c.Obfuscation.Memcached.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.memcached.enabled")
 	c.Obfuscation.Memcached.KeepCommand = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.memcached.keep_command")
+	c.Obfuscation.GraphQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled")
+	c.Obfuscation.GraphQL.RemoveAliases = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.remove_aliases")
 	c.Obfuscation.Redis.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.redis.enabled")
 	c.Obfuscation.Redis.RemoveAllArgs = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.redis.remove_all_args")
 	c.Obfuscation.Valkey.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.valkey.enabled")
@@ -706,6 +764,28 @@ func compileReplaceRules(rules []*config.ReplaceRule) error {
 	return nil
 }
 
+func validateTailSamplingRules(rules []*config.TailSamplingRule) error {
+	for _, r := range rules {
+		if r.Name == "" {
+			return errors.New(`all rules must have a "name" property`)
+		}
+		switch r.Type {
+		case config.TailSamplingRuleLatency:
+			if r.MinDurationMs <= 0 {
+				return fmt.Errorf("rule %q: latency rules must have a positive \"min_duration_ms\"", r.Name)
+			}
+		case config.TailSamplingRuleError:
+		case config.TailSamplingRuleTag:
+			if r.TagKey == "" {
+				return fmt.Errorf("rule %q: tag rules must have a \"key\"", r.Name)
+			}
+		default:
+			return fmt.Errorf("rule %q: unknown type %q, it must be one of latency, error or tag", r.Name, r.Type)
+		}
+	}
+	return nil
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
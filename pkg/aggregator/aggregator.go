@@ -143,6 +143,8 @@ var (
 	aggregatorOrchestratorManifestsErrors      = expvar.Int{}
 	aggregatorDogstatsdContexts                = expvar.Int{}
 	aggregatorDogstatsdContextsByMtype         = []expvar.Int{}
+	aggregatorContextLimiterDrops              = expvar.Int{}
+	aggregatorContextLimiterOverflows          = expvar.Int{}
 	aggregatorEventPlatformEvents              = expvar.Map{}
 	aggregatorEventPlatformEventsErrors        = expvar.Map{}
 
@@ -167,6 +169,8 @@ var (
 		[]string{"shard", "metric_type"}, "Count the number of checks contexts in the check aggregator, by metric type")
 	tlmChecksContextsBytesByMtype = telemetry.NewGauge("aggregator", "checks_contexts_bytes_by_mtype",
 		[]string{"shard", "metric_type", tags.BytesKindTelemetryKey}, "Estimated count of bytes taken by contexts in the check aggregator, by metric type")
+	tlmContextLimiterHits = telemetry.NewCounter("aggregator", "context_limiter_hits",
+		[]string{"source", "action"}, "Count of the samples of contexts over the limit of the context limiter, by source and action")
 
 	// Hold series to be added to aggregated series on each flush
 	recurrentSeries     metrics.Series
@@ -209,6 +213,8 @@ func init() {
 	aggregatorExpvars.Set("OrchestratorManifests", &aggregatorOrchestratorManifests)
 	aggregatorExpvars.Set("OrchestratorManifestsErrors", &aggregatorOrchestratorManifestsErrors)
 	aggregatorExpvars.Set("DogstatsdContexts", &aggregatorDogstatsdContexts)
+	aggregatorExpvars.Set("ContextLimiterDrops", &aggregatorContextLimiterDrops)
+	aggregatorExpvars.Set("ContextLimiterOverflows", &aggregatorContextLimiterOverflows)
 	aggregatorExpvars.Set("EventPlatformEvents", &aggregatorEventPlatformEvents)
 	aggregatorExpvars.Set("EventPlatformEventsErrors", &aggregatorEventPlatformEventsErrors)
 
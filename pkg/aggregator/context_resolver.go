@@ -11,10 +11,13 @@ import (
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/size"
 )
 
@@ -63,6 +66,16 @@ func (c *Context) DataSizeInBytes() int {
 // Make sure we implement the interface
 var _ size.HasSizeInBytes = &Context{}
 
+// newContextLimiter returns the limiter of the contexts of a sampler configured
+// by aggregator_context_limiter, or nil if the limit is disabled.
+func newContextLimiter() *limiter.Limiter {
+	action, err := limiter.ParseAction(pkgconfigsetup.Datadog().GetString("aggregator_context_limiter.action"))
+	if err != nil {
+		log.Warnf("%v, dropping the contexts over the limit", err)
+	}
+	return limiter.New(pkgconfigsetup.Datadog().GetInt("aggregator_context_limiter.limit"), action)
+}
+
 // contextResolver allows tracking and expiring contexts
 type contextResolver struct {
 	id               string
@@ -76,6 +89,9 @@ type contextResolver struct {
 	keyGenerator     *ckey.KeyGenerator
 	taggerBuffer     *tagset.HashingTagsAccumulator
 	metricBuffer     *tagset.HashingTagsAccumulator
+	limiter          *limiter.Limiter
+	// limiterSource is the telemetry source of the limiter, dogstatsd or checks
+	limiterSource string
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -83,7 +99,7 @@ func (cr *contextResolver) generateContextKey(metricSampleContext metrics.Metric
 	return cr.keyGenerator.GenerateWithTags2(metricSampleContext.GetName(), metricSampleContext.GetHost(), cr.taggerBuffer, cr.metricBuffer)
 }
 
-func newContextResolver(tagger tagger.Component, cache *tags.Store, id string) *contextResolver {
+func newContextResolver(tagger tagger.Component, cache *tags.Store, id string, limiter *limiter.Limiter, limiterSource string) *contextResolver {
 	return &contextResolver{
 		id:               id,
 		contextsByKey:    make(map[ckey.ContextKey]resolverEntry),
@@ -96,18 +112,38 @@ func newContextResolver(tagger tagger.Component, cache *tags.Store, id string) *
 		keyGenerator:     ckey.NewKeyGenerator(),
 		taggerBuffer:     tagset.NewHashingTagsAccumulator(),
 		metricBuffer:     tagset.NewHashingTagsAccumulator(),
+		limiter:          limiter,
+		limiterSource:    limiterSource,
 	}
 }
 
-// trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, timestamp int64) ckey.ContextKey {
+// trackContext returns the contextKey associated with the context of the metricSample and tracks that context.
+// It returns false if the context is over the limit of the limiter and its sample must be dropped.
+func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, timestamp int64) (ckey.ContextKey, bool) {
 	metricSampleContext.GetTags(cr.taggerBuffer, cr.metricBuffer, cr.tagger.EnrichTags) // tags here are not sorted and can contain duplicates
 	defer cr.taggerBuffer.Reset()
 	defer cr.metricBuffer.Reset()
 
 	contextKey, taggerKey, metricKey := cr.generateContextKey(metricSampleContext) // the generator will remove duplicates (and doesn't mind the order)
 
-	if entry, ok := cr.contextsByKey[contextKey]; !ok {
+	entry, ok := cr.contextsByKey[contextKey]
+	if !ok && !cr.limiter.Track(contextKey, metricSampleContext.GetName(), taggerKey) {
+		action := cr.limiter.Action()
+		tlmContextLimiterHits.Inc(cr.limiterSource, action.String())
+		if action == limiter.Drop {
+			aggregatorContextLimiterDrops.Add(1)
+			return contextKey, false
+		}
+
+		// collapse the context into the overflow context of its metric name and origin
+		aggregatorContextLimiterOverflows.Add(1)
+		cr.metricBuffer.Reset()
+		cr.metricBuffer.Append(limiter.OverflowTag)
+		contextKey, taggerKey, metricKey = cr.generateContextKey(metricSampleContext)
+		entry, ok = cr.contextsByKey[contextKey]
+	}
+
+	if !ok {
 		mtype := metricSampleContext.GetMetricType()
 		context := &Context{
 			Name:       metricSampleContext.GetName(),
@@ -135,7 +171,7 @@ func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSample
 		}
 	}
 
-	return contextKey
+	return contextKey, true
 }
 
 func (cr *contextResolver) get(key ckey.ContextKey) (*Context, bool) {
@@ -152,6 +188,7 @@ func (cr *contextResolver) remove(expiredContextKey ckey.ContextKey) {
 	delete(cr.contextsByKey, expiredContextKey)
 
 	if context != nil {
+		cr.limiter.Remove(expiredContextKey)
 		cr.countsByMtype[context.mtype]--
 		cr.bytesByMtype[context.mtype] -= uint64(context.SizeInBytes())
 		cr.dataBytesByMtype[context.mtype] -= uint64(context.DataSizeInBytes())
@@ -221,9 +258,9 @@ type timestampContextResolver struct {
 	counterExpireTime int64
 }
 
-func newTimestampContextResolver(tagger tagger.Component, cache *tags.Store, id string, contextExpireTime, counterExpireTime int64) *timestampContextResolver {
+func newTimestampContextResolver(tagger tagger.Component, cache *tags.Store, id string, contextExpireTime, counterExpireTime int64, limiter *limiter.Limiter) *timestampContextResolver {
 	return &timestampContextResolver{
-		resolver: newContextResolver(tagger, cache, id),
+		resolver: newContextResolver(tagger, cache, id, limiter, "dogstatsd"),
 
 		contextExpireTime: contextExpireTime,
 		counterExpireTime: counterExpireTime,
@@ -231,9 +268,8 @@ func newTimestampContextResolver(tagger tagger.Component, cache *tags.Store, id
 }
 
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *timestampContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp int64) ckey.ContextKey {
-	contextKey := cr.resolver.trackContext(metricSampleContext, currentTimestamp)
-	return contextKey
+func (cr *timestampContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext, currentTimestamp int64) (ckey.ContextKey, bool) {
+	return cr.resolver.trackContext(metricSampleContext, currentTimestamp)
 }
 
 func (cr *timestampContextResolver) length() int {
@@ -281,9 +317,9 @@ type countBasedContextResolver struct {
 	expireCountInterval int64
 }
 
-func newCountBasedContextResolver(expireCountInterval int, cache *tags.Store, tagger tagger.Component, id string) *countBasedContextResolver {
+func newCountBasedContextResolver(expireCountInterval int, cache *tags.Store, tagger tagger.Component, id string, limiter *limiter.Limiter) *countBasedContextResolver {
 	return &countBasedContextResolver{
-		resolver:            newContextResolver(tagger, cache, id),
+		resolver:            newContextResolver(tagger, cache, id, limiter, "checks"),
 		expireCount:         0,
 		expireCountInterval: int64(expireCountInterval),
 	}
@@ -299,9 +335,8 @@ func (cr *countBasedContextResolver) updateMetrics(countsByMTypeGauge telemetry.
 }
 
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
-func (cr *countBasedContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext) ckey.ContextKey {
-	contextKey := cr.resolver.trackContext(metricSampleContext, cr.expireCount)
-	return contextKey
+func (cr *countBasedContextResolver) trackContext(metricSampleContext metrics.MetricSampleContext) (ckey.ContextKey, bool) {
+	return cr.resolver.trackContext(metricSampleContext, cr.expireCount)
 }
 
 func (cr *countBasedContextResolver) get(key ckey.ContextKey) (*Context, bool) {
@@ -15,6 +15,7 @@ import (
 
 	nooptagger "github.com/DataDog/datadog-agent/comp/core/tagger/impl-noop"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/limiter"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -75,12 +76,12 @@ func testTrackContext(t *testing.T, store *tags.Store) {
 		SampleRate: 1,
 	}
 
-	contextResolver := newContextResolver(nooptagger.NewComponent(), store, "test")
+	contextResolver := newContextResolver(nooptagger.NewComponent(), store, "test", nil, "dogstatsd")
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 0)
-	contextKey2 := contextResolver.trackContext(&mSample2, 0)
-	contextKey3 := contextResolver.trackContext(&mSample3, 0)
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 0)
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 0)
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 0)
 
 	// When we look up the 2 keys, they return the correct contexts
 	context1 := contextResolver.contextsByKey[contextKey1].context
@@ -139,12 +140,12 @@ func testExpireContexts(t *testing.T, store *tags.Store) {
 		Tags:       []string{"foo"},
 		SampleRate: 1,
 	}
-	contextResolver := newTimestampContextResolver(nooptagger.NewComponent(), store, "test", 2, 4)
+	contextResolver := newTimestampContextResolver(nooptagger.NewComponent(), store, "test", 2, 4, nil)
 
 	// Track the 2 contexts
-	contextKey1 := contextResolver.trackContext(&mSample1, 4) // expires after 6
-	contextKey2 := contextResolver.trackContext(&mSample2, 6) // expires after 8
-	contextKey3 := contextResolver.trackContext(&mSample3, 6) // expires after 10
+	contextKey1, _ := contextResolver.trackContext(&mSample1, 4) // expires after 6
+	contextKey2, _ := contextResolver.trackContext(&mSample2, 6) // expires after 8
+	contextKey3, _ := contextResolver.trackContext(&mSample3, 6) // expires after 10
 
 	// With an expireTimestap of 3, both contexts are still valid
 	contextResolver.expireContexts(4)
@@ -191,13 +192,13 @@ func testCountBasedExpireContexts(t *testing.T, store *tags.Store) {
 	mSample1 := metrics.MetricSample{Name: "my.metric.name1"}
 	mSample2 := metrics.MetricSample{Name: "my.metric.name2"}
 	mSample3 := metrics.MetricSample{Name: "my.metric.name3"}
-	contextResolver := newCountBasedContextResolver(2, store, nooptagger.NewComponent(), "test")
+	contextResolver := newCountBasedContextResolver(2, store, nooptagger.NewComponent(), "test", nil)
 
-	contextKey1 := contextResolver.trackContext(&mSample1)
-	contextKey2 := contextResolver.trackContext(&mSample2)
+	contextKey1, _ := contextResolver.trackContext(&mSample1)
+	contextKey2, _ := contextResolver.trackContext(&mSample2)
 	require.Len(t, contextResolver.expireContexts(), 0)
 
-	contextKey3 := contextResolver.trackContext(&mSample3)
+	contextKey3, _ := contextResolver.trackContext(&mSample3)
 	contextResolver.trackContext(&mSample2)
 	require.Len(t, contextResolver.expireContexts(), 0)
 
@@ -216,9 +217,9 @@ func TestCountBasedExpireContexts(t *testing.T) {
 }
 
 func testTagDeduplication(t *testing.T, store *tags.Store) {
-	resolver := newContextResolver(nooptagger.NewComponent(), store, "test")
+	resolver := newContextResolver(nooptagger.NewComponent(), store, "test", nil, "dogstatsd")
 
-	ckey := resolver.trackContext(&metrics.MetricSample{
+	ckey, _ := resolver.trackContext(&metrics.MetricSample{
 		Name: "foo",
 		Tags: []string{"bar", "bar"},
 	}, 0)
@@ -254,7 +255,7 @@ func (s *mockSample) GetTags(tb, mb tagset.TagsAccumulator, _ metrics.EnrichTags
 }
 
 func TestOriginTelemetry(t *testing.T) {
-	r := newContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test")
+	r := newContextResolver(nooptagger.NewComponent(), tags.NewStore(true, "test"), "test", nil, "dogstatsd")
 	r.trackContext(&mockSample{"foo", []string{"foo"}, []string{"ook"}}, 0)
 	r.trackContext(&mockSample{"foo", []string{"foo"}, []string{"eek"}}, 0)
 	r.trackContext(&mockSample{"foo", []string{"bar"}, []string{"ook"}}, 0)
@@ -284,3 +285,59 @@ func TestOriginTelemetry(t *testing.T) {
 		Points: []metrics.Point{{Ts: ts, Value: 1.0}},
 	}})
 }
+
+func testContextLimiterDrop(t *testing.T, store *tags.Store) {
+	r := newContextResolver(nooptagger.NewComponent(), store, "test", limiter.New(2, limiter.Drop), "dogstatsd")
+
+	key1, ok := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:1"}}, 0)
+	assert.True(t, ok)
+	_, ok = r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:2"}}, 0)
+	assert.True(t, ok)
+	_, ok = r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:3"}}, 0)
+	assert.False(t, ok)
+
+	// known contexts, other origins and other metrics are not limited
+	_, ok = r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:1"}}, 0)
+	assert.True(t, ok)
+	_, ok = r.trackContext(&mockSample{"foo", []string{"pod:b"}, []string{"id:3"}}, 0)
+	assert.True(t, ok)
+	_, ok = r.trackContext(&mockSample{"bar", []string{"pod:a"}, []string{"id:3"}}, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 4, r.length())
+
+	// removed contexts release their slot
+	r.remove(key1)
+	_, ok = r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:3"}}, 0)
+	assert.True(t, ok)
+}
+
+func TestContextLimiterDrop(t *testing.T) {
+	testWithTagsStore(t, testContextLimiterDrop)
+}
+
+func testContextLimiterOverflow(t *testing.T, store *tags.Store) {
+	r := newContextResolver(nooptagger.NewComponent(), store, "test", limiter.New(1, limiter.Overflow), "checks")
+
+	key1, ok := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:1"}}, 0)
+	assert.True(t, ok)
+	key2, ok := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:2"}}, 0)
+	assert.True(t, ok)
+	key3, ok := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:3"}}, 0)
+	assert.True(t, ok)
+
+	assert.NotEqual(t, key1, key2)
+	assert.Equal(t, key2, key3)
+	assert.Equal(t, 2, r.length())
+	cx, _ := r.get(key2)
+	assertContext(t, cx, "foo", []string{"pod:a", limiter.OverflowTag}, "noop")
+
+	// removing the overflow context doesn't release the slot of the first one
+	r.remove(key2)
+	key4, ok := r.trackContext(&mockSample{"foo", []string{"pod:a"}, []string{"id:4"}}, 0)
+	assert.True(t, ok)
+	assert.Equal(t, key2, key4)
+}
+
+func TestContextLimiterOverflow(t *testing.T) {
+	testWithTagsStore(t, testContextLimiterOverflow)
+}
@@ -8,6 +8,7 @@ package aggregator
 import (
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
@@ -96,6 +97,7 @@ func createIterableMetrics(
 	logPayloads bool,
 	isServerless bool,
 	hostTagProvider *HostTagProvider,
+	flushedMetrics *flushedmetrics.Store,
 ) (*metrics.IterableSeries, *metrics.IterableSketches) {
 	var series *metrics.IterableSeries
 	var sketches *metrics.IterableSketches
@@ -110,6 +112,11 @@ func createIterableMetrics(
 				se.Tags = tagset.CombineCompositeTagsAndSlice(se.Tags, hostTagProvider.GetHostTags())
 			}
 			tagsetTlm.updateHugeSerieTelemetry(se)
+			// the series are flushed as they are appended, which can be long
+			// after the creation of the sink in the no-aggregation pipeline
+			if flushedMetrics != nil {
+				flushedMetrics.RecordSerie(se, time.Now())
+			}
 		}, flushAndSerializeInParallel.BufferSize, flushAndSerializeInParallel.ChannelSize)
 	}
 	if serializer.AreSketchesEnabled() {
@@ -124,6 +131,9 @@ func createIterableMetrics(
 				sketch.Tags = tagset.CombineCompositeTagsAndSlice(sketch.Tags, hostTagProvider.GetHostTags())
 			}
 			tagsetTlm.updateHugeSketchesTelemetry(sketch)
+			if flushedMetrics != nil {
+				flushedMetrics.RecordSketch(sketch, time.Now())
+			}
 		}, flushAndSerializeInParallel.BufferSize, flushAndSerializeInParallel.ChannelSize)
 	}
 	return series, sketches
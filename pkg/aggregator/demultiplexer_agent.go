@@ -19,6 +19,7 @@ import (
 	orchestratorforwarder "github.com/DataDog/datadog-agent/comp/forwarder/orchestrator"
 	haagent "github.com/DataDog/datadog-agent/comp/haagent/def"
 	compression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/tags"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
@@ -67,6 +68,9 @@ type AgentDemultiplexer struct {
 
 	hostTagProvider *HostTagProvider
 
+	// flushedMetrics keeps the last flushed series and sketches, nil if disabled
+	flushedMetrics *flushedmetrics.Store
+
 	// sharded statsd time samplers
 	statsd
 }
@@ -183,6 +187,8 @@ func initAgentDemultiplexer(log log.Component,
 			bufferSize, metricSamplePool, agg.flushAndSerializeInParallel, tagsStore)
 	}
 
+	flushedMetrics := flushedmetrics.NewStore(pkgconfigsetup.Datadog().GetInt("aggregator_flushed_metrics_size"))
+
 	var noAggWorker *noAggregationStreamWorker
 	var noAggSerializer serializer.MetricSerializer
 	if options.EnableNoAggregationPipeline {
@@ -193,6 +199,7 @@ func initAgentDemultiplexer(log log.Component,
 			noAggSerializer,
 			agg.flushAndSerializeInParallel,
 			tagger,
+			flushedMetrics,
 		)
 	}
 
@@ -215,6 +222,7 @@ func initAgentDemultiplexer(log log.Component,
 		},
 
 		hostTagProvider: NewHostTagProvider(),
+		flushedMetrics:  flushedMetrics,
 		senders:         newSenders(agg),
 
 		// statsd time samplers
@@ -234,6 +242,12 @@ func (d *AgentDemultiplexer) Options() AgentDemultiplexerOptions {
 	return d.options
 }
 
+// FlushedMetrics returns the store of the last flushed series and sketches,
+// nil if it is disabled.
+func (d *AgentDemultiplexer) FlushedMetrics() *flushedmetrics.Store {
+	return d.flushedMetrics
+}
+
 // AddAgentStartupTelemetry adds a startup event and count (in a DSD time sampler)
 // to be sent on the next flush.
 func (d *AgentDemultiplexer) AddAgentStartupTelemetry(agentVersion string) {
@@ -366,12 +380,24 @@ func (d *AgentDemultiplexer) Stop(flush bool) {
 		}
 	}
 
+	// serializers
+
+	stopSerializer(d.dataOutputs.sharedSerializer)
+	stopSerializer(d.dataOutputs.noAggSerializer)
+
 	// misc
 
 	d.dataOutputs.sharedSerializer = nil
 	d.senders = nil
 }
 
+// stopSerializer stops the outputs owned by the serializer, if it has any.
+func stopSerializer(s serializer.MetricSerializer) {
+	if stopper, ok := s.(interface{ Stop() }); ok {
+		stopper.Stop()
+	}
+}
+
 // ForceFlushToSerializer triggers the execution of a flush from all data of samplers
 // and the BufferedAggregator to the serializer.
 // Safe to call from multiple threads.
@@ -407,7 +433,7 @@ func (d *AgentDemultiplexer) flushToSerializer(start time.Time, waitForSerialize
 	}
 
 	logPayloads := pkgconfigsetup.Datadog().GetBool("log_payloads")
-	series, sketches := createIterableMetrics(d.aggregator.flushAndSerializeInParallel, d.sharedSerializer, logPayloads, false, d.hostTagProvider)
+	series, sketches := createIterableMetrics(d.aggregator.flushAndSerializeInParallel, d.sharedSerializer, logPayloads, false, d.hostTagProvider, d.flushedMetrics)
 	metrics.Serialize(
 		series,
 		sketches,
@@ -98,6 +98,7 @@ func (d *ServerlessDemultiplexer) Stop(flush bool) {
 	}
 
 	d.statsdWorker.stop()
+	d.serializer.Stop()
 
 	if d.forwarder != nil {
 		d.forwarder.Stop()
@@ -110,7 +111,7 @@ func (d *ServerlessDemultiplexer) ForceFlushToSerializer(start time.Time, waitFo
 	defer d.flushLock.Unlock()
 
 	logPayloads := pkgconfigsetup.Datadog().GetBool("log_payloads")
-	series, sketches := createIterableMetrics(d.flushAndSerializeInParallel, d.serializer, logPayloads, true, d.hostTagProvider)
+	series, sketches := createIterableMetrics(d.flushAndSerializeInParallel, d.serializer, logPayloads, true, d.hostTagProvider, nil)
 
 	metrics.Serialize(
 		series,
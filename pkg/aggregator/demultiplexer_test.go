@@ -25,8 +25,11 @@ import (
 	logscompressionmock "github.com/DataDog/datadog-agent/comp/serializer/logscompression/fx-mock"
 	compression "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/def"
 	metricscompressionmock "github.com/DataDog/datadog-agent/comp/serializer/metricscompression/fx-mock"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 
 	"github.com/stretchr/testify/assert"
@@ -205,6 +208,25 @@ func TestDemuxFlushAggregatorToSerializer(t *testing.T) {
 	require.Len(sketches, 0)
 }
 
+func TestCreateIterableMetricsFlushTime(t *testing.T) {
+	s := &MockSerializerIterableSerie{}
+	s.On("AreSeriesEnabled").Return(true)
+	s.On("AreSketchesEnabled").Return(false)
+	store := flushedmetrics.NewStore(10)
+
+	series, _ := createIterableMetrics(NewFlushAndSerializeInParallel(configmock.New(t)), s, false, false, NewHostTagProvider(), store)
+
+	// the flush time is the one of the serie, not the one of the creation of
+	// the sink which can be long before in the no-aggregation pipeline
+	time.Sleep(10 * time.Millisecond)
+	flushStart := time.Now()
+	series.Append(&metrics.Serie{Name: "my.metric", Points: []metrics.Point{{Ts: 10, Value: 1}}})
+
+	flushed := store.Query(flushedmetrics.Filter{Name: "my.metric"})
+	require.Len(t, flushed, 1)
+	assert.False(t, flushed[0].FlushTime.Before(flushStart))
+}
+
 func TestGetDogStatsDWorkerAndPipelineCount(t *testing.T) {
 	pc := pkgconfigsetup.Datadog().GetInt("dogstatsd_pipeline_count")
 	aa := pkgconfigsetup.Datadog().GetInt("dogstatsd_pipeline_autoadjust")
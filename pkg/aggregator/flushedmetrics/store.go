@@ -0,0 +1,250 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package flushedmetrics keeps the last flushed value of the series and
+// sketches sent by the aggregator, to inspect them from the agent CLI.
+package flushedmetrics
+
+import (
+	"container/list"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// DistributionType is the type of the metrics flushed as sketches.
+const DistributionType = "distribution"
+
+// Metric is the last flush of a context.
+type Metric struct {
+	Name      string        `json:"name"`
+	Type      string        `json:"type"`
+	Host      string        `json:"host,omitempty"`
+	Tags      []string      `json:"tags"`
+	Source    string        `json:"source"`
+	FlushTime time.Time     `json:"flush_time"`
+	Points    []Point       `json:"points,omitempty"`
+	Sketches  []SketchPoint `json:"sketches,omitempty"`
+}
+
+// Point is a point of a serie.
+type Point struct {
+	Timestamp float64 `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+// SketchPoint summarizes a point of a sketch serie.
+type SketchPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Count     int64   `json:"count"`
+	Sum       float64 `json:"sum"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+	Avg       float64 `json:"avg"`
+}
+
+// Filter selects the metrics returned by Query. Empty fields match all the metrics.
+type Filter struct {
+	// Name is the exact name of the metrics.
+	Name string
+	// Tags are tags all the metrics must have.
+	Tags []string
+	// Source is the source of the metrics, e.g. dogstatsd or the name of a check.
+	Source string
+}
+
+func (f Filter) match(m *Metric) bool {
+	if f.Source != "" && f.Source != m.Source {
+		return false
+	}
+	for _, tag := range f.Tags {
+		if !slices.Contains(m.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// key identifies a context. The series without context key, e.g. the
+// metrics of the no-aggregation pipeline, are identified by their host and
+// their tags instead.
+type key struct {
+	name       string
+	contextKey ckey.ContextKey
+	hostTags   string
+}
+
+// Store keeps the last flush of the most recently flushed contexts, evicting
+// the least recently flushed contexts once it holds size contexts.
+type Store struct {
+	mu     sync.Mutex
+	size   int
+	lru    *list.List
+	items  map[key]*list.Element
+	byName map[string]map[key]*list.Element
+}
+
+type entry struct {
+	key    key
+	metric Metric
+}
+
+// NewStore returns a Store keeping at most size contexts. It returns nil if
+// size is not positive, the methods of a nil Store are no-ops.
+func NewStore(size int) *Store {
+	if size <= 0 {
+		return nil
+	}
+	return &Store{
+		size:   size,
+		lru:    list.New(),
+		items:  make(map[key]*list.Element),
+		byName: make(map[string]map[key]*list.Element),
+	}
+}
+
+// RecordSerie records the flush of a serie.
+func (s *Store) RecordSerie(serie *metrics.Serie, flushTime time.Time) {
+	if s == nil {
+		return
+	}
+	m := Metric{
+		Name:      serie.Name,
+		Type:      serie.MType.String(),
+		Host:      serie.Host,
+		Tags:      tagsSlice(serie.Tags),
+		Source:    serie.Source.String(),
+		FlushTime: flushTime,
+		Points:    make([]Point, 0, len(serie.Points)),
+	}
+	for _, p := range serie.Points {
+		m.Points = append(m.Points, Point{Timestamp: p.Ts, Value: p.Value})
+	}
+	s.record(serie.ContextKey, m)
+}
+
+// RecordSketch records the flush of a sketch serie.
+func (s *Store) RecordSketch(sketch *metrics.SketchSeries, flushTime time.Time) {
+	if s == nil {
+		return
+	}
+	m := Metric{
+		Name:      sketch.Name,
+		Type:      DistributionType,
+		Host:      sketch.Host,
+		Tags:      tagsSlice(sketch.Tags),
+		Source:    sketch.Source.String(),
+		FlushTime: flushTime,
+		Sketches:  make([]SketchPoint, 0, len(sketch.Points)),
+	}
+	for _, p := range sketch.Points {
+		b := p.Sketch.Basic
+		m.Sketches = append(m.Sketches, SketchPoint{
+			Timestamp: p.Ts,
+			Count:     b.Cnt,
+			Sum:       b.Sum,
+			Min:       b.Min,
+			Max:       b.Max,
+			Avg:       b.Avg,
+		})
+	}
+	s.record(sketch.ContextKey, m)
+}
+
+func (s *Store) record(contextKey ckey.ContextKey, m Metric) {
+	sort.Strings(m.Tags)
+
+	k := key{name: m.Name, contextKey: contextKey}
+	if contextKey == 0 {
+		k.hostTags = m.Host + "|" + strings.Join(m.Tags, ",")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, found := s.items[k]; found {
+		elem.Value.(*entry).metric = m
+		s.lru.MoveToFront(elem)
+		return
+	}
+
+	if s.lru.Len() >= s.size {
+		s.remove(s.lru.Back())
+	}
+	elem := s.lru.PushFront(&entry{key: k, metric: m})
+	s.items[k] = elem
+	if s.byName[m.Name] == nil {
+		s.byName[m.Name] = make(map[key]*list.Element)
+	}
+	s.byName[m.Name][k] = elem
+}
+
+func (s *Store) remove(elem *list.Element) {
+	k := s.lru.Remove(elem).(*entry).key
+	delete(s.items, k)
+	delete(s.byName[k.name], k)
+	if len(s.byName[k.name]) == 0 {
+		delete(s.byName, k.name)
+	}
+}
+
+func tagsSlice(tags tagset.CompositeTags) []string {
+	slice := make([]string, 0, tags.Len())
+	tags.ForEach(func(tag string) {
+		slice = append(slice, tag)
+	})
+	return slice
+}
+
+// Query returns the metrics matching the filter, sorted by name and tags.
+func (s *Store) Query(filter Filter) []Metric {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	result := []Metric{}
+	if filter.Name != "" {
+		for _, elem := range s.byName[filter.Name] {
+			if m := &elem.Value.(*entry).metric; filter.match(m) {
+				result = append(result, *m)
+			}
+		}
+	} else {
+		for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+			if m := &elem.Value.(*entry).metric; filter.match(m) {
+				result = append(result, *m)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+		if c := slices.Compare(result[i].Tags, result[j].Tags); c != 0 {
+			return c < 0
+		}
+		return result[i].Host < result[j].Host
+	})
+	return result
+}
+
+// Len returns the number of contexts in the store.
+func (s *Store) Len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package flushedmetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func serie(name string, contextKey uint64, value float64, tags ...string) *metrics.Serie {
+	return &metrics.Serie{
+		Name:       name,
+		ContextKey: ckey.ContextKey(contextKey),
+		MType:      metrics.APIGaugeType,
+		Host:       "web01",
+		Tags:       tagset.CompositeTagsFromSlice(tags),
+		Points:     []metrics.Point{{Ts: 10, Value: value}},
+		Source:     metrics.MetricSourceDogstatsd,
+	}
+}
+
+func TestNilStore(t *testing.T) {
+	s := NewStore(0)
+	require.Nil(t, s)
+	s.RecordSerie(serie("foo", 1, 1), time.Now())
+	assert.Empty(t, s.Query(Filter{}))
+	assert.Equal(t, 0, s.Len())
+}
+
+func TestRecordSerie(t *testing.T) {
+	s := NewStore(10)
+	flushTime := time.Unix(20, 0)
+	s.RecordSerie(serie("foo", 1, 1, "env:prod", "a:b"), flushTime)
+	s.RecordSerie(serie("foo", 1, 2, "env:prod", "a:b"), flushTime)
+
+	assert.Equal(t, []Metric{{
+		Name:      "foo",
+		Type:      "gauge",
+		Host:      "web01",
+		Tags:      []string{"a:b", "env:prod"},
+		Source:    "dogstatsd",
+		FlushTime: flushTime,
+		Points:    []Point{{Timestamp: 10, Value: 2}},
+	}}, s.Query(Filter{}))
+}
+
+func TestRecordSketch(t *testing.T) {
+	s := NewStore(10)
+	sketch := &quantile.Sketch{}
+	sketch.Insert(quantile.Default(), 1, 2, 3)
+	s.RecordSketch(&metrics.SketchSeries{
+		Name:       "latency",
+		ContextKey: 1,
+		Points:     []metrics.SketchPoint{{Ts: 10, Sketch: sketch}},
+	}, time.Unix(20, 0))
+
+	metrics := s.Query(Filter{Name: "latency"})
+	require.Len(t, metrics, 1)
+	assert.Equal(t, DistributionType, metrics[0].Type)
+	assert.Equal(t, []SketchPoint{{Timestamp: 10, Count: 3, Sum: 6, Min: 1, Max: 3, Avg: 2}}, metrics[0].Sketches)
+}
+
+func TestQuery(t *testing.T) {
+	s := NewStore(10)
+	s.RecordSerie(serie("foo", 1, 1, "env:prod", "service:web"), time.Now())
+	s.RecordSerie(serie("foo", 2, 1, "env:staging", "service:web"), time.Now())
+	s.RecordSerie(serie("bar", 3, 1, "env:prod"), time.Now())
+	check := serie("bar", 4, 1, "env:staging")
+	check.Source = metrics.MetricSourceNginx
+	s.RecordSerie(check, time.Now())
+
+	names := func(metrics []Metric) []string {
+		var names []string
+		for _, m := range metrics {
+			names = append(names, m.Name+" "+m.Tags[0])
+		}
+		return names
+	}
+	assert.Equal(t, []string{"bar env:prod", "bar env:staging", "foo env:prod", "foo env:staging"}, names(s.Query(Filter{})))
+	assert.Equal(t, []string{"foo env:prod", "foo env:staging"}, names(s.Query(Filter{Name: "foo"})))
+	assert.Equal(t, []string{"bar env:prod", "foo env:prod"}, names(s.Query(Filter{Tags: []string{"env:prod"}})))
+	assert.Equal(t, []string{"foo env:prod"}, names(s.Query(Filter{Name: "foo", Tags: []string{"env:prod", "service:web"}})))
+	assert.Equal(t, []string{"bar env:staging"}, names(s.Query(Filter{Source: "nginx"})))
+	assert.Empty(t, s.Query(Filter{Name: "baz"}))
+}
+
+func TestEviction(t *testing.T) {
+	s := NewStore(2)
+	s.RecordSerie(serie("foo", 1, 1), time.Now())
+	s.RecordSerie(serie("bar", 2, 1), time.Now())
+	// flushing foo again makes bar the least recently flushed context
+	s.RecordSerie(serie("foo", 1, 2), time.Now())
+	s.RecordSerie(serie("baz", 3, 1), time.Now())
+
+	assert.Equal(t, 2, s.Len())
+	assert.Empty(t, s.Query(Filter{Name: "bar"}))
+	assert.NotContains(t, s.byName, "bar")
+	assert.Len(t, s.Query(Filter{Name: "foo"}), 1)
+	assert.Len(t, s.Query(Filter{Name: "baz"}), 1)
+}
+
+func TestSeriesWithoutContextKey(t *testing.T) {
+	s := NewStore(10)
+	a := serie("foo", 0, 1, "env:prod")
+	b := serie("foo", 0, 1, "env:staging")
+	s.RecordSerie(a, time.Now())
+	s.RecordSerie(b, time.Now())
+	s.RecordSerie(a, time.Now())
+
+	assert.Equal(t, 2, s.Len())
+}
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package limiter limits the number of contexts tracked by the samplers of the aggregator.
+package limiter
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+)
+
+// Action is the action applied to the contexts over the limit.
+type Action int
+
+const (
+	// Drop drops the samples of the contexts over the limit.
+	Drop Action = iota
+	// Overflow collapses the contexts over the limit into a single context
+	// tagged with OverflowTag instead of their metric tags.
+	Overflow
+)
+
+// OverflowTag is the tag of the contexts collapsing the contexts over the limit.
+const OverflowTag = "context_limit:overflow"
+
+// String returns the name of the action.
+func (a Action) String() string {
+	if a == Overflow {
+		return "overflow"
+	}
+	return "drop"
+}
+
+// ParseAction returns the action of the given name, drop or overflow.
+func ParseAction(name string) (Action, error) {
+	switch name {
+	case "drop":
+		return Drop, nil
+	case "overflow":
+		return Overflow, nil
+	}
+	return Drop, fmt.Errorf("invalid context limiter action %q, must be one of drop or overflow", name)
+}
+
+type key struct {
+	name   string
+	origin ckey.TagsKey
+}
+
+// Limiter counts the contexts per metric name and origin, the origin being
+// identified by the key of the tags added by the tagger, and rejects the new
+// contexts over the limit.
+//
+// Limiter is not thread-safe, every sampler owns its limiter. A nil Limiter
+// accepts all the contexts.
+type Limiter struct {
+	limit    int
+	action   Action
+	counts   map[key]int
+	contexts map[ckey.ContextKey]key
+}
+
+// New returns a limiter accepting up to limit contexts per metric name and
+// origin, it returns nil if limit is not positive.
+func New(limit int, action Action) *Limiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:    limit,
+		action:   action,
+		counts:   make(map[key]int),
+		contexts: make(map[ckey.ContextKey]key),
+	}
+}
+
+// Action returns the action applied to the contexts over the limit.
+func (l *Limiter) Action() Action {
+	return l.action
+}
+
+// Track counts a new context and returns true if it is accepted, or false if
+// the limit of its metric name and origin is reached.
+func (l *Limiter) Track(contextKey ckey.ContextKey, name string, origin ckey.TagsKey) bool {
+	if l == nil {
+		return true
+	}
+	k := key{name: name, origin: origin}
+	if l.counts[k] >= l.limit {
+		return false
+	}
+	l.counts[k]++
+	l.contexts[contextKey] = k
+	return true
+}
+
+// Remove releases the slot of a context, it is a no-op for the contexts not
+// accepted by Track.
+func (l *Limiter) Remove(contextKey ckey.ContextKey) {
+	if l == nil {
+		return
+	}
+	k, found := l.contexts[contextKey]
+	if !found {
+		return
+	}
+	delete(l.contexts, contextKey)
+	if l.counts[k] <= 1 {
+		delete(l.counts, k)
+		return
+	}
+	l.counts[k]--
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package limiter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	l := New(2, Drop)
+	require.NotNil(t, l)
+
+	assert.True(t, l.Track(1, "foo", 1))
+	assert.True(t, l.Track(2, "foo", 1))
+	assert.False(t, l.Track(3, "foo", 1))
+
+	// the budget is per metric name and origin
+	assert.True(t, l.Track(4, "foo", 2))
+	assert.True(t, l.Track(5, "bar", 1))
+
+	l.Remove(1)
+	assert.True(t, l.Track(3, "foo", 1))
+	assert.False(t, l.Track(6, "foo", 1))
+
+	// contexts which were not accepted are ignored
+	l.Remove(6)
+	assert.False(t, l.Track(6, "foo", 1))
+
+	l.Remove(5)
+	l.Remove(5)
+	assert.NotContains(t, l.counts, key{"bar", 1})
+	assert.NotContains(t, l.contexts, 5)
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l := New(0, Overflow)
+	assert.Nil(t, l)
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Track(1, "foo", 1))
+	}
+	l.Remove(1)
+}
+
+func TestParseAction(t *testing.T) {
+	action, err := ParseAction("drop")
+	assert.NoError(t, err)
+	assert.Equal(t, Drop, action)
+
+	action, err = ParseAction("overflow")
+	assert.NoError(t, err)
+	assert.Equal(t, Overflow, action)
+	assert.Equal(t, "overflow", action.String())
+
+	_, err = ParseAction("collapse")
+	assert.Error(t, err)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/flushedmetrics"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/internal/util"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -52,6 +53,9 @@ type noAggregationStreamWorker struct {
 	hostTagProvider *HostTagProvider
 	tagger          tagger.Component
 
+	// flushedMetrics keeps the last flushed series and sketches, it is shared with the Demultiplexer.
+	flushedMetrics *flushedmetrics.Store
+
 	logThrottling util.SimpleThrottler
 }
 
@@ -83,7 +87,7 @@ func init() {
 //nolint:revive // TODO(AML) Fix revive linter
 func newNoAggregationStreamWorker(maxMetricsPerPayload int, _ *metrics.MetricSamplePool,
 	serializer serializer.MetricSerializer, flushConfig FlushAndSerializeInParallel,
-	tagger tagger.Component,
+	tagger tagger.Component, flushedMetrics *flushedmetrics.Store,
 ) *noAggregationStreamWorker {
 	return &noAggregationStreamWorker{
 		serializer:           serializer,
@@ -104,7 +108,8 @@ func newNoAggregationStreamWorker(maxMetricsPerPayload int, _ *metrics.MetricSam
 		// every 5 minutes.
 		logThrottling: util.NewSimpleThrottler(200, 5*time.Minute, "Pausing the unsupported metric type warning message for 5m"),
 
-		tagger: tagger,
+		tagger:         tagger,
+		flushedMetrics: flushedMetrics,
 	}
 }
 
@@ -152,7 +157,7 @@ func (w *noAggregationStreamWorker) run() {
 	ticker := time.NewTicker(noAggWorkerStreamCheckFrequency)
 	defer ticker.Stop()
 	logPayloads := pkgconfigsetup.Datadog().GetBool("log_payloads")
-	w.seriesSink, w.sketchesSink = createIterableMetrics(w.flushConfig, w.serializer, logPayloads, false, w.hostTagProvider)
+	w.seriesSink, w.sketchesSink = createIterableMetrics(w.flushConfig, w.serializer, logPayloads, false, w.hostTagProvider, w.flushedMetrics)
 
 	stopped := false
 	var stopBlockChan chan struct{}
@@ -253,7 +258,7 @@ func (w *noAggregationStreamWorker) run() {
 			break
 		}
 
-		w.seriesSink, w.sketchesSink = createIterableMetrics(w.flushConfig, w.serializer, logPayloads, false, w.hostTagProvider)
+		w.seriesSink, w.sketchesSink = createIterableMetrics(w.flushConfig, w.serializer, logPayloads, false, w.hostTagProvider, w.flushedMetrics)
 	}
 
 	if stopBlockChan != nil {
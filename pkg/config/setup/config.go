@@ -307,6 +307,11 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("check_sampler_stateful_metric_expiration_time", 25*time.Hour)
 	config.BindEnvAndSetDefault("check_sampler_expire_metrics", true)
 	config.BindEnvAndSetDefault("check_sampler_context_metrics", false)
+	// The maximum number of contexts per metric name and origin tracked by each
+	// sampler of the aggregator, 0 disables the limit. Contexts over the limit
+	// are either dropped or collapsed into a single overflow context.
+	config.BindEnvAndSetDefault("aggregator_context_limiter.limit", 0)
+	config.BindEnvAndSetDefault("aggregator_context_limiter.action", "drop")
 	config.BindEnvAndSetDefault("host_aliases", []string{})
 
 	// overridden in IoT Agent main
@@ -1344,6 +1349,9 @@ func aggregator(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("aggregator_stop_timeout", 2)
 	config.BindEnvAndSetDefault("aggregator_buffer_size", 100)
 	config.BindEnvAndSetDefault("aggregator_use_tags_store", true)
+	// The number of contexts whose last flushed value is kept to be queried with
+	// the `agent metrics` command, 0 disables it.
+	config.BindEnvAndSetDefault("aggregator_flushed_metrics_size", 0)
 	config.BindEnvAndSetDefault("basic_telemetry_add_container_tags", false) // configure adding the agent container tags to the basic agent telemetry metrics (e.g. `datadog.agent.running`)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_chan_size", 200)
 	config.BindEnvAndSetDefault("aggregator_flush_metrics_and_serialize_in_parallel_buffer_size", 4000)
@@ -1385,11 +1393,36 @@ func forwarder(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("forwarder_storage_max_size_in_bytes", 0)                // 0 means disabled. This is a BETA feature.
 	config.BindEnvAndSetDefault("forwarder_storage_max_disk_ratio", 0.80)                // Do not store transactions on disk when the disk usage exceeds 80% of the disk capacity. Use 80% as some applications do not behave well when the disk space is very small.
 	config.BindEnvAndSetDefault("forwarder_retry_queue_capacity_time_interval_sec", 900) // 15 mins
+	// Encryption of the transactions stored on disk. The passphrases can be
+	// resolved by the secrets backend, the previous ones are only used to
+	// decrypt the files written before a rotation.
+	config.BindEnvAndSetDefault("forwarder_storage_encryption.enabled", false)
+	config.BindEnvAndSetDefault("forwarder_storage_encryption.passphrase", "")
+	config.BindEnvAndSetDefault("forwarder_storage_encryption.previous_passphrases", []string{})
+
+	// Failover of the primary endpoint to standby endpoints when its circuit breaker stays open
+	config.BindEnvAndSetDefault("forwarder_failover.enabled", false)
+	config.BindEnvAndSetDefault("forwarder_failover.open_duration", 60) // in seconds
+	config.BindEnv("forwarder_failover.standby_endpoints")
+	config.ParseEnvAsSlice("forwarder_failover.standby_endpoints", func(in string) []interface{} {
+		var endpoints []interface{}
+		if err := json.Unmarshal([]byte(in), &endpoints); err != nil {
+			log.Errorf(`"forwarder_failover.standby_endpoints" can not be parsed: %v`, err)
+		}
+		return endpoints
+	})
 
 	// Forwarder channels buffer size
 	config.BindEnvAndSetDefault("forwarder_high_prio_buffer_size", 100)
 	config.BindEnvAndSetDefault("forwarder_low_prio_buffer_size", 100)
 	config.BindEnvAndSetDefault("forwarder_requeue_buffer_size", 100)
+
+	// Prometheus remote-write output of the aggregated metrics
+	config.BindEnvAndSetDefault("prometheus_remote_write.enabled", false)
+	config.BindEnvAndSetDefault("prometheus_remote_write.url", "")
+	config.BindEnvAndSetDefault("prometheus_remote_write.headers", map[string]string{})
+	config.BindEnvAndSetDefault("prometheus_remote_write.timeout", 20)                        // in seconds
+	config.BindEnvAndSetDefault("prometheus_remote_write.retry_queue_max_size", 15*1024*1024) // in bytes
 }
 
 func dogstatsd(config pkgconfigmodel.Setup) {
@@ -1414,6 +1447,10 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("dogstatsd_non_local_traffic", false)
 	config.BindEnvAndSetDefault("dogstatsd_socket", defaultStatsdSocket) // Only enabled on unix systems
 	config.BindEnvAndSetDefault("dogstatsd_stream_socket", "")           // Experimental || Notice: empty means feature disabled
+	// Addresses receiving metrics in the Graphite plaintext and InfluxDB line protocols, for instance
+	// "udp://:2003", "tcp://:2003" or "unix:///var/run/datadog/graphite.socket".
+	config.BindEnvAndSetDefault("dogstatsd_graphite_listeners", []string{})
+	config.BindEnvAndSetDefault("dogstatsd_influx_listeners", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_pipeline_autoadjust", false)
 	config.BindEnvAndSetDefault("dogstatsd_pipeline_autoadjust_strategy", "max_throughput")
 	config.BindEnvAndSetDefault("dogstatsd_pipeline_count", 1)
@@ -1483,6 +1520,21 @@ func dogstatsd(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("statsd_metric_blocklist", []string{})
 	config.BindEnvAndSetDefault("statsd_metric_blocklist_match_prefix", false)
 
+	config.BindEnv("dogstatsd_tag_filterlist")
+	config.ParseEnvAsSlice("dogstatsd_tag_filterlist", func(in string) []interface{} {
+		var rules []interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Errorf(`"dogstatsd_tag_filterlist" can not be parsed: %v`, err)
+		}
+		return rules
+	})
+
+	// Per-origin budgets of metric samples
+	config.BindEnvAndSetDefault("dogstatsd_origin_quota.enabled", false)
+	config.BindEnvAndSetDefault("dogstatsd_origin_quota.samples_per_second", 10000)
+	config.BindEnvAndSetDefault("dogstatsd_origin_quota.granularity", "container")
+	config.BindEnvAndSetDefault("dogstatsd_origin_quota.action", "drop")
+
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
 	config.BindEnvAndSetDefault("histogram_aggregates", []string{"max", "median", "avg", "count"})
@@ -1572,6 +1624,12 @@ func logsagent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("logs_config.stop_grace_period", 30)
 	config.BindEnvAndSetDefault("logs_config.message_channel_size", 100)
 	config.BindEnvAndSetDefault("logs_config.payload_channel_size", 10)
+	// Store the payloads on disk while the intake is unreachable instead of blocking the pipelines.
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.enabled", false)
+	// Defaults to `logs_config.run_path`/buffer when empty.
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.path", "")
+	// Maximum size in bytes of the payloads stored on disk, shared by all the pipelines.
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.max_size", 100*1024*1024)
 
 	// maximum time that the unix tailer will hold a log file open after it has been rotated
 	config.BindEnvAndSetDefault("logs_config.close_timeout", 60)
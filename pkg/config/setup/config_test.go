@@ -1498,6 +1498,8 @@ func TestAPMObfuscationDefaultValue(t *testing.T) {
 	assert.False(t, conf.GetBool("apm_config.obfuscation.redis.remove_all_args"))
 	assert.True(t, conf.GetBool("apm_config.obfuscation.memcached.enabled"))
 	assert.False(t, conf.GetBool("apm_config.obfuscation.memcached.keep_command"))
+	assert.True(t, conf.GetBool("apm_config.obfuscation.graphql.enabled"))
+	assert.False(t, conf.GetBool("apm_config.obfuscation.graphql.remove_aliases"))
 	assert.True(t, conf.GetBool("apm_config.obfuscation.credit_cards.enabled"))
 	assert.False(t, conf.GetBool("apm_config.obfuscation.credit_cards.luhn"))
 	assert.Len(t, conf.GetStringSlice("apm_config.obfuscation.credit_cards.keep_values"), 0)
@@ -33,6 +33,12 @@ const registryAPIVersion = 2
 type Registry interface {
 	GetOffset(identifier string) string
 	GetTailingMode(identifier string) string
+	// KeepAlive prevents the entry of identifier from expiring, for entries
+	// which are not regularly updated by new logs.
+	KeepAlive(identifier string)
+	// CommitOffset records the offset of identifier, for entries whose offset
+	// is not committed by the logs sent.
+	CommitOffset(identifier string, offset string)
 }
 
 // A RegistryEntry represents an entry in the registry where we keep track
@@ -150,6 +156,22 @@ func (a *RegistryAuditor) GetTailingMode(identifier string) string {
 	return entry.TailingMode
 }
 
+// KeepAlive refreshes the last update of the entry matching identifier, if any,
+// so that it is not removed once its TTL is expired.
+func (a *RegistryAuditor) KeepAlive(identifier string) {
+	a.registryMutex.Lock()
+	defer a.registryMutex.Unlock()
+	if entry, exists := a.registry[identifier]; exists {
+		entry.LastUpdated = time.Now().UTC()
+	}
+}
+
+// CommitOffset records the offset of identifier. It is more recent than the
+// one of the logs not yet sent, which don't overwrite it.
+func (a *RegistryAuditor) CommitOffset(identifier string, offset string) {
+	a.updateRegistry(identifier, offset, a.GetTailingMode(identifier), time.Now().UnixNano())
+}
+
 // run keeps up to date the registry depending on different events
 func (a *RegistryAuditor) run() {
 	cleanUpTicker := time.NewTicker(defaultCleanupPeriod)
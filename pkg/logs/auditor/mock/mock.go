@@ -36,3 +36,11 @@ func (r *Registry) GetTailingMode(_ string) string {
 func (r *Registry) SetTailingMode(tailingMode string) {
 	r.tailingMode = tailingMode
 }
+
+// KeepAlive does nothing.
+func (r *Registry) KeepAlive(_ string) {}
+
+// CommitOffset sets the offset.
+func (r *Registry) CommitOffset(_ string, offset string) {
+	r.offset = offset
+}
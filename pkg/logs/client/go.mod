@@ -18,6 +18,7 @@ require (
 	github.com/DataDog/datadog-agent/pkg/version v0.62.3
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/net v0.35.0
+	google.golang.org/protobuf v1.36.5
 )
 
 require (
@@ -90,7 +91,6 @@ require (
 	golang.org/x/exp v0.0.0-20250218142911-aa4b98e5adaa // indirect
 	golang.org/x/sys v0.30.0 // indirect
 	golang.org/x/text v0.22.0 // indirect
-	google.golang.org/protobuf v1.36.5 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -68,6 +68,9 @@ type Destination struct {
 	protocol            config.IntakeProtocol
 	origin              config.IntakeOrigin
 	isMRF               bool
+	// formatter re-encodes payloads for endpoints which are not Datadog
+	// intakes, it is nil otherwise.
+	formatter payloadFormatter
 
 	// Concurrency
 	climit chan struct{} // semaphore for limiting concurrent background sends
@@ -158,6 +161,7 @@ func newDestination(endpoint config.Endpoint,
 		expVars:             expVars,
 		destMeta:            destMeta,
 		isMRF:               endpoint.IsMRF,
+		formatter:           newPayloadFormatter(endpoint.Format),
 		pipelineMonitor:     pipelineMonitor,
 		utilization:         pipelineMonitor.MakeUtilizationMonitor(destMeta.MonitorTag()),
 	}
@@ -296,29 +300,46 @@ func (d *Destination) unconditionalSend(payload *message.Payload) (err error) {
 	metrics.EncodedBytesSent.Add(int64(len(payload.Encoded)))
 	metrics.TlmEncodedBytesSent.Add(float64(len(payload.Encoded)))
 
-	req, err := http.NewRequest("POST", d.url, bytes.NewReader(payload.Encoded))
+	body, encoding, contentType := payload.Encoded, payload.Encoding, d.contentType
+	if d.formatter != nil {
+		body, encoding, err = formatPayload(d.formatter, payload, d.endpoint.UseCompression)
+		if err != nil {
+			// the payload can't be sent to this endpoint, retrying won't help
+			tlmDropped.Inc()
+			return err
+		}
+		contentType = d.formatter.contentType()
+	}
+
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
 	if err != nil {
 		// the request could not be built,
 		// this can happen when the method or the url are valid.
 		return err
 	}
-	req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
-	req.Header.Set("Content-Type", d.contentType)
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("User-Agent", fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
-
-	if payload.Encoding != "" {
-		req.Header.Set("Content-Encoding", payload.Encoding)
-	}
-	if d.protocol != "" {
-		req.Header.Set("DD-PROTOCOL", string(d.protocol))
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
 	}
-	if d.origin != "" {
-		req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
-		req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
+	for key, value := range d.endpoint.Headers {
+		req.Header.Set(key, value)
 	}
-	req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.Messages), 10))
+
 	then := time.Now()
-	req.Header.Set("dd-current-timestamp", strconv.FormatInt(then.UnixMilli(), 10))
+	// the Datadog headers, including the API key, are not sent to other endpoints
+	if d.formatter == nil {
+		req.Header.Set("DD-API-KEY", d.endpoint.GetAPIKey())
+		if d.protocol != "" {
+			req.Header.Set("DD-PROTOCOL", string(d.protocol))
+		}
+		if d.origin != "" {
+			req.Header.Set("DD-EVP-ORIGIN", string(d.origin))
+			req.Header.Set("DD-EVP-ORIGIN-VERSION", version.AgentVersion)
+		}
+		req.Header.Set("dd-message-timestamp", strconv.FormatInt(getMessageTimestamp(payload.Messages), 10))
+		req.Header.Set("dd-current-timestamp", strconv.FormatInt(then.UnixMilli(), 10))
+	}
 
 	req = req.WithContext(ctx)
 	resp, err := d.client.Do(req)
@@ -441,9 +462,16 @@ func buildURL(endpoint config.Endpoint) string {
 		Scheme: scheme,
 		Host:   address,
 	}
-	if endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "" {
+	switch {
+	case endpoint.Path != "":
+		url.Path = endpoint.Path
+	case endpoint.Format == config.OTLPFormat:
+		url.Path = otlpLogsPath
+	case endpoint.Format == config.NDJSONFormat:
+		url.Path = ndjsonLogsPath
+	case endpoint.Version == config.EPIntakeVersion2 && endpoint.TrackType != "":
 		url.Path = fmt.Sprintf("/api/v2/%s", endpoint.TrackType)
-	} else {
+	default:
 		url.Path = "/v1/input"
 	}
 	return url.String()
@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Default paths of the endpoints which are not Datadog intakes.
+const (
+	otlpLogsPath   = "/v1/logs"
+	ndjsonLogsPath = "/"
+)
+
+// payloadFormatter re-encodes the messages of a payload for endpoints which
+// are not Datadog intakes. The messages are expected to be encoded by the
+// JSON encoder of the processor, which is the one used over HTTP.
+type payloadFormatter interface {
+	contentType() string
+	format(messages []*message.Message) ([]byte, error)
+}
+
+// newPayloadFormatter returns the formatter of the given endpoint format, nil
+// for Datadog intakes.
+func newPayloadFormatter(format config.EndpointFormat) payloadFormatter {
+	switch format {
+	case config.OTLPFormat:
+		return otlpFormatter{}
+	case config.NDJSONFormat:
+		return ndjsonFormatter{}
+	default:
+		return nil
+	}
+}
+
+// formatPayload re-encodes and optionally compresses the messages of payload
+// with the given formatter, it returns the body and its content encoding.
+func formatPayload(formatter payloadFormatter, payload *message.Payload, compress bool) ([]byte, string, error) {
+	body, err := formatter.format(payload.Messages)
+	if err != nil || !compress {
+		return body, "", err
+	}
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, "", err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "gzip", nil
+}
+
+// encodedLog is the JSON representation of a message sent over HTTP.
+type encodedLog struct {
+	Message   string `json:"message"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	Hostname  string `json:"hostname"`
+	Service   string `json:"service"`
+	Source    string `json:"ddsource"`
+	Tags      string `json:"ddtags"`
+}
+
+// decodeLog decodes a message encoded in JSON, falling back to using its
+// content as the log message.
+func decodeLog(msg *message.Message) encodedLog {
+	var log encodedLog
+	if err := json.Unmarshal(msg.GetContent(), &log); err != nil {
+		return encodedLog{Message: string(msg.GetContent()), Status: msg.GetStatus()}
+	}
+	return log
+}
+
+// ndjsonFormatter sends the messages as newline-delimited JSON.
+type ndjsonFormatter struct{}
+
+func (ndjsonFormatter) contentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonFormatter) format(messages []*message.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		buf.Write(msg.GetContent())
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// otlpFormatter sends the messages as an OTLP ExportLogsServiceRequest, see
+// https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/logs/v1/logs.proto
+// Logs are grouped by host, service and source into resources.
+type otlpFormatter struct{}
+
+// Field numbers of the OTLP messages.
+const (
+	otlpResourceLogsField = 1 // ExportLogsServiceRequest.resource_logs
+
+	otlpResourceField  = 1 // ResourceLogs.resource
+	otlpScopeLogsField = 2 // ResourceLogs.scope_logs
+
+	otlpAttributesField = 1 // Resource.attributes
+
+	otlpScopeField      = 1 // ScopeLogs.scope
+	otlpLogRecordsField = 2 // ScopeLogs.log_records
+
+	otlpScopeNameField = 1 // InstrumentationScope.name
+
+	otlpTimeUnixNanoField         = 1  // LogRecord.time_unix_nano
+	otlpSeverityNumberField       = 2  // LogRecord.severity_number
+	otlpSeverityTextField         = 3  // LogRecord.severity_text
+	otlpBodyField                 = 5  // LogRecord.body
+	otlpRecordAttributesField     = 6  // LogRecord.attributes
+	otlpObservedTimeUnixNanoField = 11 // LogRecord.observed_time_unix_nano
+
+	otlpKeyField   = 1 // KeyValue.key
+	otlpValueField = 2 // KeyValue.value
+
+	otlpStringValueField = 1 // AnyValue.string_value
+)
+
+// otlpScopeName is the name of the instrumentation scope of the logs.
+const otlpScopeName = "datadog-agent"
+
+// otlpSeverities maps message statuses to OTLP severity numbers.
+var otlpSeverities = map[string]uint64{
+	message.StatusEmergency: 23, // FATAL3
+	message.StatusAlert:     22, // FATAL2
+	message.StatusCritical:  21, // FATAL
+	message.StatusError:     17, // ERROR
+	message.StatusWarning:   13, // WARN
+	message.StatusNotice:    10, // INFO2
+	message.StatusInfo:      9,  // INFO
+	message.StatusDebug:     5,  // DEBUG
+}
+
+type otlpResource struct {
+	hostname, service, source string
+}
+
+func (otlpFormatter) contentType() string {
+	return ProtobufContentType
+}
+
+func (otlpFormatter) format(messages []*message.Message) ([]byte, error) {
+	var resources []otlpResource
+	records := make(map[otlpResource][]byte)
+	for _, msg := range messages {
+		log := decodeLog(msg)
+		resource := otlpResource{hostname: log.Hostname, service: log.Service, source: log.Source}
+		if _, ok := records[resource]; !ok {
+			resources = append(resources, resource)
+		}
+		records[resource] = protowire.AppendTag(records[resource], otlpLogRecordsField, protowire.BytesType)
+		records[resource] = protowire.AppendBytes(records[resource], appendOTLPLogRecord(nil, log, msg.IngestionTimestamp))
+	}
+
+	var scope []byte
+	scope = protowire.AppendTag(scope, otlpScopeNameField, protowire.BytesType)
+	scope = protowire.AppendString(scope, otlpScopeName)
+
+	var request []byte
+	for _, resource := range resources {
+		var attributes []byte
+		for _, attribute := range [][2]string{
+			{"host.name", resource.hostname},
+			{"service.name", resource.service},
+			{"datadog.log.source", resource.source},
+		} {
+			if attribute[1] != "" {
+				attributes = appendOTLPAttribute(attributes, otlpAttributesField, attribute[0], attribute[1])
+			}
+		}
+
+		var scopeLogs []byte
+		scopeLogs = protowire.AppendTag(scopeLogs, otlpScopeField, protowire.BytesType)
+		scopeLogs = protowire.AppendBytes(scopeLogs, scope)
+		scopeLogs = append(scopeLogs, records[resource]...)
+
+		var resourceLogs []byte
+		resourceLogs = protowire.AppendTag(resourceLogs, otlpResourceField, protowire.BytesType)
+		resourceLogs = protowire.AppendBytes(resourceLogs, attributes)
+		resourceLogs = protowire.AppendTag(resourceLogs, otlpScopeLogsField, protowire.BytesType)
+		resourceLogs = protowire.AppendBytes(resourceLogs, scopeLogs)
+
+		request = protowire.AppendTag(request, otlpResourceLogsField, protowire.BytesType)
+		request = protowire.AppendBytes(request, resourceLogs)
+	}
+	return request, nil
+}
+
+// appendOTLPLogRecord appends a LogRecord to b, tags are sent as attributes.
+func appendOTLPLogRecord(b []byte, log encodedLog, ingestionTimestamp int64) []byte {
+	if log.Timestamp > 0 {
+		b = protowire.AppendTag(b, otlpTimeUnixNanoField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(log.Timestamp)*1e6)
+	}
+	if severity, ok := otlpSeverities[log.Status]; ok {
+		b = protowire.AppendTag(b, otlpSeverityNumberField, protowire.VarintType)
+		b = protowire.AppendVarint(b, severity)
+	}
+	if log.Status != "" {
+		b = protowire.AppendTag(b, otlpSeverityTextField, protowire.BytesType)
+		b = protowire.AppendString(b, log.Status)
+	}
+
+	var body []byte
+	body = protowire.AppendTag(body, otlpStringValueField, protowire.BytesType)
+	body = protowire.AppendString(body, log.Message)
+	b = protowire.AppendTag(b, otlpBodyField, protowire.BytesType)
+	b = protowire.AppendBytes(b, body)
+
+	for _, tag := range strings.Split(log.Tags, ",") {
+		if tag == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(tag, ":")
+		b = appendOTLPAttribute(b, otlpRecordAttributesField, key, value)
+	}
+
+	if ingestionTimestamp > 0 {
+		b = protowire.AppendTag(b, otlpObservedTimeUnixNanoField, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, uint64(ingestionTimestamp))
+	}
+	return b
+}
+
+// appendOTLPAttribute appends a KeyValue with a string value as the given
+// field to b, attributes without key are skipped.
+func appendOTLPAttribute(b []byte, field protowire.Number, key, value string) []byte {
+	if key == "" {
+		return b
+	}
+	var anyValue []byte
+	anyValue = protowire.AppendTag(anyValue, otlpStringValueField, protowire.BytesType)
+	anyValue = protowire.AppendString(anyValue, value)
+
+	var keyValue []byte
+	keyValue = protowire.AppendTag(keyValue, otlpKeyField, protowire.BytesType)
+	keyValue = protowire.AppendString(keyValue, key)
+	keyValue = protowire.AppendTag(keyValue, otlpValueField, protowire.BytesType)
+	keyValue = protowire.AppendBytes(keyValue, anyValue)
+
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, keyValue)
+}
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+func newEncodedMessage(content string) *message.Message {
+	msg := message.NewMessage(nil, nil, "", 1700000000123000000)
+	msg.SetEncoded([]byte(content))
+	return msg
+}
+
+// protoFields decodes a protobuf message into its fields, without the schema
+// nested messages are returned as bytes.
+func protoFields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	fields := make(map[protowire.Number][]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		var value interface{}
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			value, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			value, n = protowire.ConsumeFixed64(b)
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		fields[num] = append(fields[num], value)
+	}
+	return fields
+}
+
+// protoAttributes decodes a list of KeyValue with string values.
+func protoAttributes(t *testing.T, keyValues []interface{}) map[string]string {
+	attributes := make(map[string]string)
+	for _, kv := range keyValues {
+		fields := protoFields(t, kv.([]byte))
+		value := protoFields(t, fields[otlpValueField][0].([]byte))
+		attributes[string(fields[otlpKeyField][0].([]byte))] = string(value[otlpStringValueField][0].([]byte))
+	}
+	return attributes
+}
+
+func TestOTLPFormat(t *testing.T) {
+	messages := []*message.Message{
+		newEncodedMessage(`{"message":"hello","status":"error","timestamp":1700000000000,"hostname":"host","service":"web","ddsource":"nginx","ddtags":"env:prod,canary"}`),
+		newEncodedMessage(`{"message":"world","status":"info","timestamp":1700000000001,"hostname":"host","service":"db","ddsource":"postgres","ddtags":""}`),
+		newEncodedMessage(`{"message":"again","status":"warn","timestamp":1700000000002,"hostname":"host","service":"web","ddsource":"nginx","ddtags":""}`),
+	}
+
+	body, err := otlpFormatter{}.format(messages)
+	require.NoError(t, err)
+
+	resourceLogs := protoFields(t, body)[otlpResourceLogsField]
+	require.Len(t, resourceLogs, 2)
+
+	// first resource holds the logs of the web service
+	fields := protoFields(t, resourceLogs[0].([]byte))
+	resource := protoFields(t, fields[otlpResourceField][0].([]byte))
+	assert.Equal(t, map[string]string{"host.name": "host", "service.name": "web", "datadog.log.source": "nginx"}, protoAttributes(t, resource[otlpAttributesField]))
+
+	scopeLogs := protoFields(t, fields[otlpScopeLogsField][0].([]byte))
+	scope := protoFields(t, scopeLogs[otlpScopeField][0].([]byte))
+	assert.Equal(t, otlpScopeName, string(scope[otlpScopeNameField][0].([]byte)))
+	require.Len(t, scopeLogs[otlpLogRecordsField], 2)
+
+	record := protoFields(t, scopeLogs[otlpLogRecordsField][0].([]byte))
+	assert.Equal(t, uint64(1700000000000000000), record[otlpTimeUnixNanoField][0])
+	assert.Equal(t, uint64(1700000000123000000), record[otlpObservedTimeUnixNanoField][0])
+	assert.Equal(t, uint64(17), record[otlpSeverityNumberField][0])
+	assert.Equal(t, "error", string(record[otlpSeverityTextField][0].([]byte)))
+	body = protoFields(t, record[otlpBodyField][0].([]byte))[otlpStringValueField][0].([]byte)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, map[string]string{"env": "prod", "canary": ""}, protoAttributes(t, record[otlpRecordAttributesField]))
+
+	record = protoFields(t, scopeLogs[otlpLogRecordsField][1].([]byte))
+	assert.Equal(t, uint64(13), record[otlpSeverityNumberField][0])
+	assert.Empty(t, record[otlpRecordAttributesField])
+
+	// second resource holds the logs of the db service
+	fields = protoFields(t, resourceLogs[1].([]byte))
+	resource = protoFields(t, fields[otlpResourceField][0].([]byte))
+	assert.Equal(t, map[string]string{"host.name": "host", "service.name": "db", "datadog.log.source": "postgres"}, protoAttributes(t, resource[otlpAttributesField]))
+}
+
+func TestOTLPFormatFallsBackOnRawContent(t *testing.T) {
+	msg := newEncodedMessage("not json")
+	msg.Status = message.StatusWarning
+
+	body, err := otlpFormatter{}.format([]*message.Message{msg})
+	require.NoError(t, err)
+
+	resourceLogs := protoFields(t, body)[otlpResourceLogsField]
+	require.Len(t, resourceLogs, 1)
+	fields := protoFields(t, resourceLogs[0].([]byte))
+	assert.Empty(t, protoFields(t, fields[otlpResourceField][0].([]byte)))
+	scopeLogs := protoFields(t, fields[otlpScopeLogsField][0].([]byte))
+	record := protoFields(t, scopeLogs[otlpLogRecordsField][0].([]byte))
+	assert.Equal(t, uint64(13), record[otlpSeverityNumberField][0])
+	assert.Equal(t, "not json", string(protoFields(t, record[otlpBodyField][0].([]byte))[otlpStringValueField][0].([]byte)))
+}
+
+func TestNDJSONFormat(t *testing.T) {
+	body, err := ndjsonFormatter{}.format([]*message.Message{
+		newEncodedMessage(`{"message":"hello"}`),
+		newEncodedMessage(`{"message":"world"}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"message\":\"hello\"}\n{\"message\":\"world\"}\n", string(body))
+}
+
+func TestBuildURLWithFormat(t *testing.T) {
+	e := config.NewEndpoint("bar", "", "foo", 4318, false)
+	e.Format = config.OTLPFormat
+	assert.Equal(t, "http://foo:4318/v1/logs", buildURL(e))
+
+	e.Format = config.NDJSONFormat
+	assert.Equal(t, "http://foo:4318/", buildURL(e))
+
+	e.Path = "/ingest"
+	assert.Equal(t, "http://foo:4318/ingest", buildURL(e))
+}
+
+func TestDestinationSendsFormattedPayloads(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	statusCode := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(statusCode)
+		if statusCode == http.StatusOK {
+			requests <- r
+			bodies <- body
+		}
+		// the next attempt succeeds
+		statusCode = http.StatusOK
+	}))
+	defer server.Close()
+
+	url := strings.Split(server.URL, ":")
+	port, _ := strconv.Atoi(url[2])
+	endpoint := config.NewEndpoint("api_key", "", strings.TrimPrefix(url[1], "//"), port, false)
+	endpoint.BackoffFactor = 1
+	endpoint.BackoffBase = 1
+	endpoint.BackoffMax = 10
+	endpoint.RecoveryInterval = 1
+	endpoint.Format = config.NDJSONFormat
+	endpoint.UseCompression = true
+	endpoint.Headers = map[string]string{"Authorization": "Bearer token"}
+
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	defer destCtx.Stop()
+	dest := NewDestination(endpoint, JSONContentType, destCtx, 0, true, client.NewNoopDestinationMetadata(), configmock.New(t), metrics.NewNoopPipelineMonitor(""))
+
+	input := make(chan *message.Payload)
+	output := make(chan *message.Payload)
+	dest.Start(input, output, nil)
+	input <- &message.Payload{Messages: []*message.Message{newEncodedMessage(`{"message":"hello"}`)}, Encoded: []byte("ignored"), Encoding: "deflate"}
+	<-output
+
+	r := <-requests
+	assert.Equal(t, "/", r.URL.Path)
+	assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+	assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+	assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+	assert.Empty(t, r.Header.Get("DD-API-KEY"))
+
+	reader, err := gzip.NewReader(bytes.NewReader(<-bodies))
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"message\":\"hello\"}\n", string(body))
+	close(input)
+}
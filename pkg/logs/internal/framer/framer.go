@@ -42,6 +42,11 @@ const (
 	// headers are included in the log frame.  The size in those headers is not
 	// consulted.  The result does not include the trailing newlines.
 	DockerStream
+
+	// Syslog stream, as described in RFC 6587: frames are either octet-counted
+	// or newline-terminated.  The result does not include the length prefixes
+	// nor the trailing newlines.
+	SyslogStream
 )
 
 // Framer gets chunks of bytes (via Process(..)) and uses an
@@ -99,6 +104,8 @@ func NewFramer(
 		matcher = &oneByteNewLineMatcher{contentLenLimit}
 	case DockerStream:
 		matcher = &dockerStreamMatcher{contentLenLimit}
+	case SyslogStream:
+		matcher = newSyslogMatcher(contentLenLimit)
 	case NoFraming:
 		matcher = &noFramingMatcher{}
 	default:
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+// maxOctetCountDigits bounds the length of the MSG-LEN prefix of an
+// octet-counted frame, anything longer is considered as non-transparent framing.
+const maxOctetCountDigits = 9
+
+// syslogMatcher implements EndLineMatcher for syslog streams as described in
+// RFC 6587. It supports both octet-counting framing, where each frame is
+// prefixed with its length (`MSG-LEN SP SYSLOG-MSG`), and non-transparent
+// framing where frames are terminated by a newline. The framing method is
+// detected for every frame, a sender can thus mix both.
+type syslogMatcher struct {
+	// contentLenLimit is the maximum content length that will be returned.
+	// Lines longer than this value will be split into multiple frames.
+	contentLenLimit int
+	newline         oneByteNewLineMatcher
+	// remaining is the length of the content of an octet-counted frame longer
+	// than contentLenLimit that has not been returned yet.
+	remaining int
+}
+
+func newSyslogMatcher(contentLenLimit int) *syslogMatcher {
+	return &syslogMatcher{
+		contentLenLimit: contentLenLimit,
+		newline:         oneByteNewLineMatcher{contentLenLimit},
+	}
+}
+
+// FindFrame implements EndLineMatcher#FindFrame.
+func (s *syslogMatcher) FindFrame(buf []byte, seen int) ([]byte, int) {
+	if len(buf) == 0 {
+		return nil, 0
+	}
+
+	// continue splitting an octet-counted frame longer than contentLenLimit
+	if s.remaining > 0 {
+		n := min(s.remaining, s.contentLenLimit)
+		if n > len(buf) {
+			return nil, 0
+		}
+		s.remaining -= n
+		return buf[:n], n
+	}
+
+	// some senders terminate octet-counted frames with a trailer, consume it
+	// as an empty frame.
+	if buf[0] == '\n' || buf[0] == '\r' {
+		return buf[:0], 1
+	}
+
+	if buf[0] < '1' || buf[0] > '9' {
+		return s.newline.FindFrame(buf, seen)
+	}
+
+	msgLen := 0
+	for i := 0; i < len(buf); i++ {
+		c := buf[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if i >= maxOctetCountDigits {
+				return s.newline.FindFrame(buf, seen)
+			}
+			msgLen = msgLen*10 + int(c-'0')
+		case c == ' ':
+			end := i + 1 + msgLen
+			if end > s.contentLenLimit {
+				// like lines, frames longer than contentLenLimit are split
+				// rather than buffered whole: the announced length comes
+				// from the sender and can't be trusted.
+				end = max(s.contentLenLimit, i+2)
+				if end > len(buf) {
+					return nil, 0
+				}
+				s.remaining = msgLen - (end - i - 1)
+				return buf[i+1 : end], end
+			}
+			if end > len(buf) {
+				// the frame is not complete yet
+				return nil, 0
+			}
+			return buf[i+1 : end], end
+		default:
+			// not a MSG-LEN prefix, the frame is newline-terminated
+			return s.newline.FindFrame(buf, seen)
+		}
+	}
+	return nil, 0
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package framer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestSyslogOctetCounting(t *testing.T) {
+	gotContent := []string{}
+	gotLens := []int{}
+	outputFn := func(msg *message.Message, rawDataLen int) {
+		gotContent = append(gotContent, string(msg.GetContent()))
+		gotLens = append(gotLens, rawDataLen)
+	}
+
+	fr := NewFramer(outputFn, SyslogStream, 256000)
+
+	// frames split across multiple reads, with an embedded newline
+	fr.Process(message.NewMessage([]byte("11 <13>1 hello15 <13>1 wo"), nil, "", 0))
+	fr.Process(message.NewMessage([]byte("rld\nfoo"), nil, "", 0))
+
+	assert.Equal(t, []string{"<13>1 hello", "<13>1 world\nfoo"}, gotContent)
+	assert.Equal(t, []int{14, 18}, gotLens)
+}
+
+func TestSyslogOctetCountingOverLimit(t *testing.T) {
+	gotContent := []string{}
+	gotLens := []int{}
+	outputFn := func(msg *message.Message, rawDataLen int) {
+		gotContent = append(gotContent, string(msg.GetContent()))
+		gotLens = append(gotLens, rawDataLen)
+	}
+
+	fr := NewFramer(outputFn, SyslogStream, 20)
+
+	// the frame is split in frames of at most 20 bytes, the framing of the
+	// next frame is kept
+	fr.Process(message.NewMessage([]byte("30 <13>1 abcdefghijklmnopqrstuvwx5 hello"), nil, "", 0))
+	// a huge announced length doesn't make the framer wait for the frame
+	fr.Process(message.NewMessage([]byte("999999999 <13>1 abcdefghijk"), nil, "", 0))
+
+	assert.Equal(t, []string{"<13>1 abcdefghijk", "lmnopqrstuvwx", "hello", "<13>1 abcd"}, gotContent)
+	assert.Equal(t, []int{20, 13, 7, 20}, gotLens)
+}
+
+func TestSyslogNonTransparentFraming(t *testing.T) {
+	gotContent := []string{}
+	gotLens := []int{}
+	outputFn := func(msg *message.Message, rawDataLen int) {
+		gotContent = append(gotContent, string(msg.GetContent()))
+		gotLens = append(gotLens, rawDataLen)
+	}
+
+	fr := NewFramer(outputFn, SyslogStream, 256000)
+
+	fr.Process(message.NewMessage([]byte("<13>Oct 11 22:14:15 host app: one\n<13>1 - - - - - - two\n"), nil, "", 0))
+	// mixing framing methods, octet-counted frame terminated by a trailer
+	fr.Process(message.NewMessage([]byte("5 three\n<13> four\n"), nil, "", 0))
+	// not a valid MSG-LEN prefix
+	fr.Process(message.NewMessage([]byte("42abc\n"), nil, "", 0))
+
+	assert.Equal(t, []string{
+		"<13>Oct 11 22:14:15 host app: one",
+		"<13>1 - - - - - - two",
+		"three",
+		"",
+		"<13> four",
+		"42abc",
+	}, gotContent)
+	assert.Equal(t, []int{34, 22, 7, 1, 10, 6}, gotLens)
+}
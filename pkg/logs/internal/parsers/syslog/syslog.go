@@ -0,0 +1,304 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package syslog implements a Parser for syslog messages, as described in
+// RFC 5424 and in RFC 3164 (BSD syslog).
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/parsers"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// nilValue is used by RFC 5424 for fields that are not set.
+const nilValue = "-"
+
+// bom is the UTF-8 byte order mark that may prefix RFC 5424 messages.
+var bom = []byte{0xef, 0xbb, 0xbf}
+
+var errNotSyslog = errors.New("cannot parse syslog message, missing PRI part")
+
+// severityStatuses maps syslog severities to message statuses.
+var severityStatuses = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// facilityNames maps syslog facility codes to their names.
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+// New returns a new parser which will parse syslog messages.
+//
+// For example:
+//
+//	`<165>1 2003-10-11T22:14:15.003Z host app 1234 ID47 [origin ip="10.0.0.1"] a message`
+//
+// returns a structured message whose content is "a message", whose status is
+// "notice" and whose attributes hold the syslog header and structured data:
+//
+//	{
+//	    "message": "a message",
+//	    "syslog": {
+//	        "facility": 20,
+//	        "severity": 5,
+//	        "version": 1,
+//	        "timestamp": "2003-10-11T22:14:15.003Z",
+//	        "hostname": "host",
+//	        "appname": "app",
+//	        "procid": "1234",
+//	        "msgid": "ID47",
+//	        "structured_data": {"origin": {"ip": "10.0.0.1"}}
+//	    }
+//	}
+//
+// Messages that are not valid syslog messages are submitted as is.
+func New() parsers.Parser {
+	return &syslogFormat{}
+}
+
+type syslogFormat struct{}
+
+// Parse implements Parser#Parse
+func (p *syslogFormat) Parse(msg *message.Message) (*message.Message, error) {
+	content := msg.GetContent()
+
+	pri, rest, err := parsePri(content)
+	if err != nil {
+		return msg, err
+	}
+
+	attributes := map[string]interface{}{
+		"facility": pri / 8,
+		"severity": pri % 8,
+	}
+
+	var body []byte
+	if version, r, ok := parseVersion(rest); ok {
+		attributes["version"] = version
+		body, err = parseRFC5424(r, attributes)
+	} else {
+		body = parseRFC3164(rest, attributes)
+	}
+	if err != nil {
+		return msg, err
+	}
+
+	var tags []string
+	if facility := pri / 8; facility < len(facilityNames) {
+		tags = append(tags, "syslog_facility:"+facilityNames[facility])
+	}
+	if appname, ok := attributes["appname"].(string); ok {
+		tags = append(tags, "syslog_appname:"+appname)
+	}
+
+	structured := message.NewStructuredMessage(
+		&message.BasicStructuredContent{
+			Data: map[string]interface{}{
+				"message": string(body),
+				"syslog":  attributes,
+			},
+		},
+		msg.Origin,
+		severityStatuses[pri%8],
+		msg.IngestionTimestamp,
+	)
+	structured.RawDataLen = msg.RawDataLen
+	structured.ParsingExtra = msg.ParsingExtra
+	structured.ParsingExtra.Tags = append(structured.ParsingExtra.Tags, tags...)
+	structured.ServerlessExtra = msg.ServerlessExtra
+	return structured, nil
+}
+
+// SupportsPartialLine implements Parser#SupportsPartialLine
+func (p *syslogFormat) SupportsPartialLine() bool {
+	return false
+}
+
+// parsePri parses the `<PRI>` part of a message, returning the priority
+// value and the rest of the message.
+func parsePri(content []byte) (int, []byte, error) {
+	if len(content) < 3 || content[0] != '<' {
+		return 0, nil, errNotSyslog
+	}
+	end := bytes.IndexByte(content[:min(len(content), 5)], '>')
+	if end < 2 {
+		return 0, nil, errNotSyslog
+	}
+	pri, err := strconv.Atoi(string(content[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return 0, nil, fmt.Errorf("cannot parse syslog message, invalid PRI %q", content[1:end])
+	}
+	return pri, content[end+1:], nil
+}
+
+// parseVersion parses the VERSION field of RFC 5424 messages, it returns
+// false when the message is not a RFC 5424 message.
+func parseVersion(content []byte) (int, []byte, bool) {
+	sp := bytes.IndexByte(content, ' ')
+	if sp < 1 || sp > 2 {
+		return 0, nil, false
+	}
+	version, err := strconv.Atoi(string(content[:sp]))
+	if err != nil || version < 1 {
+		return 0, nil, false
+	}
+	return version, content[sp+1:], true
+}
+
+// parseRFC5424 parses the header and the structured data of a RFC 5424
+// message into attributes and returns the message body.
+func parseRFC5424(content []byte, attributes map[string]interface{}) ([]byte, error) {
+	for _, field := range []string{"timestamp", "hostname", "appname", "procid", "msgid"} {
+		var value []byte
+		value, content = nextField(content)
+		if value == nil {
+			return nil, fmt.Errorf("cannot parse syslog message, missing %s", field)
+		}
+		if string(value) != nilValue {
+			attributes[field] = string(value)
+		}
+	}
+
+	structuredData, rest, err := parseStructuredData(content)
+	if err != nil {
+		return nil, err
+	}
+	if len(structuredData) > 0 {
+		attributes["structured_data"] = structuredData
+	}
+
+	if len(rest) > 0 && rest[0] == ' ' {
+		rest = rest[1:]
+	}
+	return bytes.TrimPrefix(rest, bom), nil
+}
+
+// nextField returns the next space-delimited field and the rest of content.
+func nextField(content []byte) ([]byte, []byte) {
+	if len(content) == 0 {
+		return nil, nil
+	}
+	sp := bytes.IndexByte(content, ' ')
+	if sp == -1 {
+		return content, nil
+	}
+	return content[:sp], content[sp+1:]
+}
+
+// parseStructuredData parses the STRUCTURED-DATA part of a RFC 5424 message,
+// e.g. `[id1 param="value"][id2 param="value"]`.
+func parseStructuredData(content []byte) (map[string]interface{}, []byte, error) {
+	if len(content) == 0 {
+		return nil, content, nil
+	}
+	if content[0] == '-' {
+		return nil, content[1:], nil
+	}
+
+	structuredData := make(map[string]interface{})
+	for len(content) > 0 && content[0] == '[' {
+		end := bytes.IndexAny(content, " ]")
+		if end < 2 {
+			return nil, nil, errors.New("cannot parse syslog message, invalid structured data")
+		}
+		params := make(map[string]interface{})
+		structuredData[string(content[1:end])] = params
+		content = content[end:]
+
+		for len(content) > 0 && content[0] == ' ' {
+			content = content[1:]
+			eq := bytes.IndexByte(content, '=')
+			if eq < 1 || eq+1 >= len(content) || content[eq+1] != '"' {
+				return nil, nil, errors.New("cannot parse syslog message, invalid structured data parameter")
+			}
+			name := string(content[:eq])
+			value, rest, ok := parseParamValue(content[eq+2:])
+			if !ok {
+				return nil, nil, errors.New("cannot parse syslog message, unterminated structured data parameter")
+			}
+			params[name] = value
+			content = rest
+		}
+
+		if len(content) == 0 || content[0] != ']' {
+			return nil, nil, errors.New("cannot parse syslog message, unterminated structured data element")
+		}
+		content = content[1:]
+	}
+	return structuredData, content, nil
+}
+
+// parseParamValue parses a quoted PARAM-VALUE, unescaping `"`, `\` and `]`.
+// content starts right after the opening quote.
+func parseParamValue(content []byte) (string, []byte, bool) {
+	var value []byte
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			if i+1 < len(content) && (content[i+1] == '"' || content[i+1] == '\\' || content[i+1] == ']') {
+				i++
+			}
+			value = append(value, content[i])
+		case '"':
+			return string(value), content[i+1:], true
+		default:
+			value = append(value, content[i])
+		}
+	}
+	return "", nil, false
+}
+
+// parseRFC3164 parses the header of a BSD syslog message into attributes and
+// returns the message body. RFC 3164 only describes observed behaviors, this
+// parser is thus lenient and falls back to using the whole content as the
+// message body when the header can't be recognized.
+func parseRFC3164(content []byte, attributes map[string]interface{}) []byte {
+	// TIMESTAMP is `Mmm dd hh:mm:ss`, where the day is space-padded
+	const stampLen = len(time.Stamp)
+	if len(content) < stampLen {
+		return content
+	}
+	if _, err := time.Parse(time.Stamp, string(content[:stampLen])); err != nil {
+		return content
+	}
+	attributes["timestamp"] = string(content[:stampLen])
+	content = bytes.TrimLeft(content[stampLen:], " ")
+
+	// HOSTNAME is only present when followed by a TAG, a field ending with a
+	// colon is a TAG
+	if hostname, rest := nextField(content); len(hostname) > 0 && hostname[len(hostname)-1] != ':' && len(rest) > 0 {
+		attributes["hostname"] = string(hostname)
+		content = rest
+	}
+
+	// TAG is `appname[procid]:`
+	tag, rest := nextField(content)
+	if len(tag) < 2 || tag[len(tag)-1] != ':' {
+		return content
+	}
+	tag = tag[:len(tag)-1]
+	if open := bytes.IndexByte(tag, '['); open > 0 && tag[len(tag)-1] == ']' {
+		attributes["procid"] = string(tag[open+1 : len(tag)-1])
+		tag = tag[:open]
+	}
+	attributes["appname"] = string(tag)
+	return rest
+}
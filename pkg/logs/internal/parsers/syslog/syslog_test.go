@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package syslog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func TestSyslogRFC5424(t *testing.T) {
+	parser := New()
+
+	logMessage := message.NewMessage([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Appli\"cation"][origin ip="10.0.0.1"] `+"\xef\xbb\xbf"+`An application event`), nil, "", 0)
+	logMessage.RawDataLen = 42
+	msg, err := parser.Parse(logMessage)
+	require.NoError(t, err)
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, []byte("An application event"), msg.GetContent())
+	assert.Equal(t, message.StatusNotice, msg.Status)
+	assert.Equal(t, 42, msg.RawDataLen)
+	assert.Equal(t, []string{"syslog_facility:local4", "syslog_appname:evntslog"}, msg.ParsingExtra.Tags)
+
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"message": "An application event",
+		"syslog": {
+			"facility": 20,
+			"severity": 5,
+			"version": 1,
+			"timestamp": "2003-10-11T22:14:15.003Z",
+			"hostname": "mymachine.example.com",
+			"appname": "evntslog",
+			"procid": "1234",
+			"msgid": "ID47",
+			"structured_data": {
+				"exampleSDID@32473": {"iut": "3", "eventSource": "Appli\"cation"},
+				"origin": {"ip": "10.0.0.1"}
+			}
+		}
+	}`, string(rendered))
+}
+
+func TestSyslogRFC5424NilValues(t *testing.T) {
+	parser := New()
+
+	msg, err := parser.Parse(message.NewMessage([]byte(`<11>1 - - - - - -`), nil, "", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte(""), msg.GetContent())
+	assert.Equal(t, message.StatusError, msg.Status)
+	assert.Equal(t, []string{"syslog_facility:user"}, msg.ParsingExtra.Tags)
+
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message": "", "syslog": {"facility": 1, "severity": 3, "version": 1}}`, string(rendered))
+}
+
+func TestSyslogRFC3164(t *testing.T) {
+	parser := New()
+
+	msg, err := parser.Parse(message.NewMessage([]byte(`<34>Oct  1 22:14:15 mymachine su[42]: 'su root' failed for lonvick on /dev/pts/8`), nil, "", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("'su root' failed for lonvick on /dev/pts/8"), msg.GetContent())
+	assert.Equal(t, message.StatusCritical, msg.Status)
+
+	rendered, err := msg.Render()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"message": "'su root' failed for lonvick on /dev/pts/8",
+		"syslog": {
+			"facility": 4,
+			"severity": 2,
+			"timestamp": "Oct  1 22:14:15",
+			"hostname": "mymachine",
+			"appname": "su",
+			"procid": "42"
+		}
+	}`, string(rendered))
+
+	// no hostname
+	msg, err = parser.Parse(message.NewMessage([]byte(`<14>Oct 11 22:14:15 app: hello`), nil, "", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), msg.GetContent())
+	assert.Equal(t, message.StatusInfo, msg.Status)
+	assert.Equal(t, []string{"syslog_facility:user", "syslog_appname:app"}, msg.ParsingExtra.Tags)
+
+	// no header at all
+	msg, err = parser.Parse(message.NewMessage([]byte(`<15>just a message`), nil, "", 0))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("just a message"), msg.GetContent())
+	assert.Equal(t, message.StatusDebug, msg.Status)
+}
+
+func TestSyslogInvalid(t *testing.T) {
+	parser := New()
+
+	for _, content := range []string{
+		"not a syslog message",
+		"<>1 - - - - - -",
+		"<999>1 - - - - - -",
+		"<13>1 - - -",
+		`<13>1 - - - - - [id param="value`,
+		`<13>1 - - - - - [id param=value]`,
+	} {
+		msg, err := parser.Parse(message.NewMessage([]byte(content), nil, "", 0))
+		assert.Error(t, err, content)
+		assert.Equal(t, message.StateUnstructured, msg.State, content)
+		assert.Equal(t, []byte(content), msg.GetContent(), content)
+	}
+}
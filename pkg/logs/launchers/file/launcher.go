@@ -7,7 +7,10 @@
 package file
 
 import (
+	"io"
+	"os"
 	"regexp"
+	"strconv"
 	"time"
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
@@ -54,6 +57,18 @@ type Launcher struct {
 	scanPeriod             time.Duration
 	flarecontroller        *flareController.FlareController
 	tagger                 tagger.Component
+	// archives caches the identifiers of the archives by path.
+	archives map[string]archiveInfo
+	// consumedArchives holds the identifiers of the archives which have been
+	// entirely read.
+	consumedArchives map[string]bool
+}
+
+// archiveInfo is the identifier of an archive, valid while it is not modified.
+type archiveInfo struct {
+	id      string
+	size    int64
+	modTime time.Time
 }
 
 // NewLauncher returns a new launcher.
@@ -82,6 +97,8 @@ func NewLauncher(tailingLimit int, tailerSleepDuration time.Duration, validatePo
 		scanPeriod:             scanPeriod,
 		flarecontroller:        flarecontroller,
 		tagger:                 tagger,
+		archives:               make(map[string]archiveInfo),
+		consumedArchives:       make(map[string]bool),
 	}
 }
 
@@ -136,11 +153,15 @@ func (s *Launcher) cleanup() {
 	}
 	s.rotatedTailers = []*tailer.Tailer{}
 
-	for _, tailer := range s.tailers.All() {
+	tailers := s.tailers.All()
+	for _, tailer := range tailers {
 		stopper.Add(tailer)
 		s.tailers.Remove(tailer)
 	}
 	stopper.Stop()
+	for _, tailer := range tailers {
+		s.commitConsumedArchive(tailer)
+	}
 }
 
 // scan checks all the files we're expected to tail, compares them to the currently tailed files,
@@ -171,12 +192,14 @@ func (s *Launcher) scan() {
 		scanKey := file.GetScanKey()
 		tailer, isTailed := s.tailers.Get(scanKey)
 		if isTailed && tailer.IsFinished() {
+			s.commitConsumedArchive(tailer)
 			// skip this tailer as it must be stopped
 			continue
 		}
 
 		// If the file is currently being tailed, check for rotation and handle it appropriately.
-		if isTailed {
+		// Archives are read once and can't rotate.
+		if isTailed && !file.IsArchive() {
 			didRotate, err := tailer.DidRotate()
 			if err != nil {
 				log.Debugf("failed to detect log rotation: %v", err)
@@ -200,6 +223,7 @@ func (s *Launcher) scan() {
 	}
 
 	s.flarecontroller.SetAllFiles(allFiles)
+	s.cleanUpArchives(allFiles)
 
 	for _, tailer := range s.tailers.All() {
 		// stop all tailers which have not been selected
@@ -310,6 +334,9 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 		log.Debug("startNewTailer called with a nil file")
 		return false
 	}
+	if file.IsArchive() {
+		return s.startNewArchiveTailer(file)
+	}
 
 	channel, monitor := s.pipelineProvider.NextPipelineChanWithMonitor()
 	tailer := s.createTailer(file, channel, monitor)
@@ -333,6 +360,83 @@ func (s *Launcher) startNewTailer(file *tailer.File, m config.TailingMode) bool
 	return true
 }
 
+// startNewArchiveTailer creates a new tailer reading an archive from the beginning, or from the last committed
+// offset if it was partially read. Returns false if the archive has already been entirely read or if the operation
+// failed.
+func (s *Launcher) startNewArchiveTailer(file *tailer.File) bool {
+	id, err := s.archiveIdentifier(file.Path)
+	if err != nil {
+		log.Warnf("Could not identify archive %v: %v", file.Path, err)
+		return false
+	}
+
+	value := s.registry.GetOffset(id)
+	if s.consumedArchives[id] || value == tailer.ArchiveConsumedOffset {
+		s.consumedArchives[id] = true
+		// the registry entry is not updated by new logs anymore, it must not expire
+		// for the archive not to be read again
+		s.registry.KeepAlive(id)
+		return false
+	}
+	offset, _ := strconv.ParseInt(value, 10, 64)
+
+	channel, monitor := s.pipelineProvider.NextPipelineChanWithMonitor()
+	tailer := s.createTailer(file, channel, monitor)
+
+	log.Infof("Starting a new tailer for archive: %s (offset: %d) for tailer key %s", file.Path, offset, file.GetScanKey())
+	if err := tailer.Start(offset, io.SeekStart); err != nil {
+		log.Warn(err)
+		return false
+	}
+
+	s.tailers.Add(tailer)
+	return true
+}
+
+// commitConsumedArchive records in the registry that the archive of a finished tailer has been entirely read. It
+// doesn't depend on its last log, which may be filtered out or never sent, so that the archive is not read again.
+func (s *Launcher) commitConsumedArchive(t *tailer.Tailer) {
+	if !t.IsFinished() || !t.IsArchiveConsumed() || s.consumedArchives[t.Identifier()] {
+		return
+	}
+	s.consumedArchives[t.Identifier()] = true
+	s.registry.CommitOffset(t.Identifier(), tailer.ArchiveConsumedOffset)
+}
+
+// archiveIdentifier returns the identifier of the archive at path in the registry, it is only computed again when
+// the archive is modified.
+func (s *Launcher) archiveIdentifier(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info, ok := s.archives[path]; ok && info.size == fi.Size() && info.modTime.Equal(fi.ModTime()) {
+		return info.id, nil
+	}
+	id, err := tailer.ArchiveIdentifier(path)
+	if err != nil {
+		return "", err
+	}
+	s.archives[path] = archiveInfo{id: id, size: fi.Size(), modTime: fi.ModTime()}
+	return id, nil
+}
+
+// cleanUpArchives forgets the identifiers of the archives which are not matched anymore.
+func (s *Launcher) cleanUpArchives(paths []string) {
+	if len(s.archives) == 0 {
+		return
+	}
+	matched := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		matched[path] = true
+	}
+	for path := range s.archives {
+		if !matched[path] {
+			delete(s.archives, path)
+		}
+	}
+}
+
 // handleTailingModeChange determines the tailing behaviour when the tailing mode for a given file has its
 // configuration change. Two case may happen we can switch from "end" to "beginning" (1) and from "beginning" to
 // "end" (2). If the tailing mode is set to forceEnd or forceBeginning it will remain unchanged.
@@ -8,8 +8,10 @@
 package file
 
 import (
+	"compress/gzip"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -742,6 +744,120 @@ func TestLauncherFileDetectionSingleScan(t *testing.T) {
 	assert.True(t, launcher.tailers.Contains(path("b.log")))
 }
 
+func writeGzipArchive(t *testing.T, path string, content string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	_, err = w.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+}
+
+func newArchiveLauncher(t *testing.T, source *sources.LogSource) *Launcher {
+	fc := flareController.NewFlareController()
+	launcher := NewLauncher(10, 20*time.Millisecond, false, 10*time.Second, "by_name", fc, taggerMock.SetupFakeTagger(t))
+	launcher.pipelineProvider = mock.NewMockProvider()
+	launcher.registry = auditor.NewRegistry()
+	launcher.activeSources = append(launcher.activeSources, source)
+	status.Clear()
+	status.InitStatus(pkgconfigsetup.Datadog(), util.CreateSources([]*sources.LogSource{source}))
+	t.Cleanup(func() {
+		launcher.cleanup()
+		status.Clear()
+	})
+	return launcher
+}
+
+func TestLauncherReadsArchivesOnce(t *testing.T) {
+	testDir := t.TempDir()
+	_, err := os.Create(filepath.Join(testDir, "app.log"))
+	assert.NoError(t, err)
+	writeGzipArchive(t, filepath.Join(testDir, "app.log.1.gz"), "hello\nworld\n")
+	writeGzipArchive(t, filepath.Join(testDir, "app.log.2.gz"), "excluded\n")
+
+	source := sources.NewLogSource("", &config.LogsConfig{
+		Type:           config.FileType,
+		Path:           filepath.Join(testDir, "app.log*"),
+		ExcludePaths:   []string{filepath.Join(testDir, "app.log.2.gz")},
+		ReadCompressed: true,
+	})
+	launcher := newArchiveLauncher(t, source)
+	outputChan := launcher.pipelineProvider.NextPipelineChan()
+
+	launcher.scan()
+	assert.Equal(t, 2, launcher.tailers.Count())
+	msg := <-outputChan
+	assert.Equal(t, "hello", string(msg.GetContent()))
+	msg = <-outputChan
+	assert.Equal(t, "world", string(msg.GetContent()))
+
+	archiveTailer, _ := launcher.tailers.Get(filepath.Join(testDir, "app.log.1.gz"))
+	assert.Eventually(t, archiveTailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+
+	// the archive is committed as consumed and not read again, even once renamed by a rotation
+	launcher.scan()
+	assert.Equal(t, 1, launcher.tailers.Count())
+	assert.Equal(t, filetailer.ArchiveConsumedOffset, launcher.registry.GetOffset(archiveTailer.Identifier()))
+	assert.NoError(t, os.Rename(filepath.Join(testDir, "app.log.1.gz"), filepath.Join(testDir, "app.log.3.gz")))
+	launcher.scan()
+	assert.Equal(t, 1, launcher.tailers.Count())
+	assert.Empty(t, outputChan)
+}
+
+func TestLauncherSkipsArchivesConsumedInRegistry(t *testing.T) {
+	testDir := t.TempDir()
+	writeGzipArchive(t, filepath.Join(testDir, "app.log.1.gz"), "hello\n")
+
+	source := sources.NewLogSource("", &config.LogsConfig{
+		Type:           config.FileType,
+		Path:           filepath.Join(testDir, "*.gz"),
+		ReadCompressed: true,
+	})
+	launcher := newArchiveLauncher(t, source)
+	launcher.registry.(*auditor.Registry).SetOffset(filetailer.ArchiveConsumedOffset)
+
+	launcher.scan()
+	assert.Equal(t, 0, launcher.tailers.Count())
+}
+
+func TestLauncherCommitsConsumedArchives(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty": "\n\n",
+		// the last log may be filtered out by a processing rule and never reach the auditor
+		"last log filtered": "hello\nexcluded\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			testDir := t.TempDir()
+			writeGzipArchive(t, filepath.Join(testDir, "app.log.1.gz"), content)
+
+			source := sources.NewLogSource("", &config.LogsConfig{
+				Type:           config.FileType,
+				Path:           filepath.Join(testDir, "*.gz"),
+				ReadCompressed: true,
+			})
+			launcher := newArchiveLauncher(t, source)
+			outputChan := launcher.pipelineProvider.NextPipelineChan()
+
+			launcher.scan()
+			archiveTailer, _ := launcher.tailers.Get(filepath.Join(testDir, "app.log.1.gz"))
+			assert.Eventually(t, func() bool {
+				select {
+				case msg := <-outputChan:
+					assert.NotEqual(t, filetailer.ArchiveConsumedOffset, msg.Origin.Offset)
+				default:
+				}
+				return archiveTailer.IsFinished()
+			}, 5*time.Second, 10*time.Millisecond)
+			assert.Empty(t, launcher.registry.GetOffset(archiveTailer.Identifier()))
+
+			launcher.scan()
+			assert.Equal(t, 0, launcher.tailers.Count())
+			assert.Equal(t, filetailer.ArchiveConsumedOffset, launcher.registry.GetOffset(archiveTailer.Identifier()))
+		})
+	}
+}
+
 func getScanKey(path string, source *sources.LogSource) string {
 	return filetailer.NewFile(path, source, false).GetScanKey()
 }
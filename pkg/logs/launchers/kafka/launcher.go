@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package kafka implements the launcher of the kafka sources.
+package kafka
+
+import (
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/launchers"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/logs/tailers"
+	tailer "github.com/DataDog/datadog-agent/pkg/logs/tailers/kafka"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/startstop"
+)
+
+// Launcher starts a tailer consuming the topics of each kafka source.
+type Launcher struct {
+	addedSources     chan *sources.LogSource
+	removedSources   chan *sources.LogSource
+	pipelineProvider pipeline.Provider
+	registry         auditor.Registry
+	tailers          map[*sources.LogSource]*tailer.Tailer
+	stop             chan struct{}
+}
+
+// NewLauncher returns a new Launcher.
+func NewLauncher() *Launcher {
+	return &Launcher{
+		tailers: make(map[*sources.LogSource]*tailer.Tailer),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Start starts the launcher.
+func (l *Launcher) Start(sourceProvider launchers.SourceProvider, pipelineProvider pipeline.Provider, registry auditor.Registry, _ *tailers.TailerTracker) {
+	l.addedSources, l.removedSources = sourceProvider.SubscribeForType(config.KafkaType)
+	l.pipelineProvider = pipelineProvider
+	l.registry = registry
+	go l.run()
+}
+
+// run starts and stops the tailers of the sources.
+func (l *Launcher) run() {
+	for l.loop() {
+	}
+}
+
+// loop runs one iteration of the launcher's main loop, and returns true if it should be run again.
+func (l *Launcher) loop() bool {
+	select {
+	case source := <-l.addedSources:
+		t := tailer.NewTailer(source, l.pipelineProvider.NextPipelineChan(), l.registry)
+		if err := t.Start(); err != nil {
+			log.Warnf("Could not start consuming kafka topics %v: %v", source.Config.Topics, err)
+			break
+		}
+		l.tailers[source] = t
+	case source := <-l.removedSources:
+		if t, exists := l.tailers[source]; exists {
+			t.Stop()
+			delete(l.tailers, source)
+		}
+	case <-l.stop:
+		return false
+	}
+	return true
+}
+
+// Stop stops all the tailers, leaving their consumer groups.
+func (l *Launcher) Stop() {
+	l.stop <- struct{}{}
+	stopper := startstop.NewParallelStopper()
+	for source, t := range l.tailers {
+		stopper.Add(t)
+		delete(l.tailers, source)
+	}
+	stopper.Stop()
+}
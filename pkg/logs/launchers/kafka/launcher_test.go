@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	auditor "github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// newSource returns a kafka source whose broker is unreachable.
+func newSource() *sources.LogSource {
+	return sources.NewLogSource("", &config.LogsConfig{
+		Type:    config.KafkaType,
+		Brokers: []string{"127.0.0.1:1"},
+		Topics:  []string{"logs"},
+	})
+}
+
+func TestLauncherStartsAndStopsTailers(t *testing.T) {
+	launcher := NewLauncher()
+	logSources := sources.NewLogSources()
+	launcher.Start(logSources, mock.NewMockProvider(), auditor.NewRegistry(), nil)
+
+	removed, kept := newSource(), newSource()
+	logSources.AddSource(removed)
+	logSources.AddSource(kept)
+	// the status of the sources turns to an error as soon as their tailer
+	// fails to reach the broker once started
+	assert.Eventually(t, func() bool { return !removed.Status.IsPending() && !kept.Status.IsPending() }, 5*time.Second, 10*time.Millisecond)
+	logSources.RemoveSource(removed)
+
+	launcher.Stop()
+	assert.Empty(t, launcher.tailers)
+}
+
+func TestLauncherStopsTheTailersOfRemovedSources(t *testing.T) {
+	launcher := NewLauncher()
+	launcher.addedSources = make(chan *sources.LogSource, 1)
+	launcher.removedSources = make(chan *sources.LogSource, 1)
+	launcher.pipelineProvider = mock.NewMockProvider()
+	launcher.registry = auditor.NewRegistry()
+
+	removed, kept := newSource(), newSource()
+	for _, source := range []*sources.LogSource{removed, kept} {
+		launcher.addedSources <- source
+		require.True(t, launcher.loop())
+	}
+	removedTailer, keptTailer := launcher.tailers[removed], launcher.tailers[kept]
+	require.NotNil(t, removedTailer)
+	require.NotNil(t, keptTailer)
+	defer keptTailer.Stop()
+
+	launcher.removedSources <- removed
+	require.True(t, launcher.loop())
+
+	assert.NotContains(t, launcher.tailers, removed)
+	assert.True(t, removedTailer.IsStopped())
+	assert.Contains(t, launcher.tailers, kept)
+	assert.False(t, keptTailer.IsStopped())
+}
@@ -64,3 +64,28 @@ func TestTCPDoesNotTruncateMessagesThatAreBiggerThanTheReadBufferSize(t *testing
 
 	listener.Stop()
 }
+
+func TestTCPShouldParseSyslogMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	listener := NewTCPListener(pp, sources.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Port: tcpTestPort}), 9000)
+	listener.Start()
+	conn, err := net.Dial("tcp", listener.listener.Addr().String())
+	assert.Nil(t, err)
+	defer conn.Close()
+	var msg *message.Message
+
+	// octet-counted frame followed by a newline-terminated one
+	fmt.Fprint(conn, "34 <11>1 - host app - - - hello\nworld<14>Oct 11 22:14:15 host app: bye\n")
+	msg = <-msgChan
+	assert.Equal(t, message.StateStructured, msg.State)
+	assert.Equal(t, "hello\nworld", string(msg.GetContent()))
+	assert.Equal(t, message.StatusError, msg.Status)
+	assert.Contains(t, msg.Tags(), "syslog_appname:app")
+
+	msg = <-msgChan
+	assert.Equal(t, "bye", string(msg.GetContent()))
+	assert.Equal(t, message.StatusInfo, msg.Status)
+
+	listener.Stop()
+}
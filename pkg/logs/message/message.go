@@ -64,6 +64,9 @@ type Message struct {
 	Origin             *Origin
 	Status             string
 	IngestionTimestamp int64
+	// ParsedTimestamp is the time at which the log was emitted, when it is
+	// parsed from its content, see SetTimestamp.
+	ParsedTimestamp time.Time
 	// RawDataLen tracks the original size of the message content before any trimming/transformation.
 	// This is used when calculating the tailer offset - so this will NOT always be equal to `len(Content)`
 	// This is also used to track the original content size before the message is processed and encoded later
@@ -340,6 +343,24 @@ func (m *Message) GetStatus() string {
 	return m.Status
 }
 
+// SetTimestamp sets the time at which the log was emitted, the encoders use it
+// instead of the time of the encoding.
+func (m *Message) SetTimestamp(ts time.Time) {
+	m.ParsedTimestamp = ts.UTC()
+}
+
+// GetTimestamp returns the time at which the log was emitted when it is known,
+// the current time otherwise.
+func (m *Message) GetTimestamp() time.Time {
+	if !m.ParsedTimestamp.IsZero() {
+		return m.ParsedTimestamp
+	}
+	if !m.ServerlessExtra.Timestamp.IsZero() {
+		return m.ServerlessExtra.Timestamp
+	}
+	return time.Now().UTC()
+}
+
 // GetLatency returns the latency delta from ingestion time until now
 func (m *Message) GetLatency() int64 {
 	return time.Now().UnixNano() - m.IngestionTimestamp
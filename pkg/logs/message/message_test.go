@@ -7,6 +7,7 @@ package message
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,22 @@ func TestMessage(t *testing.T) {
 	assert.Equal(t, StatusInfo, message.GetStatus())
 
 }
+
+func TestMessageTimestamp(t *testing.T) {
+	msg := NewMessage([]byte("hello"), nil, StatusInfo, 0)
+	assert.WithinDuration(t, time.Now(), msg.GetTimestamp(), time.Minute)
+	assert.Equal(t, time.UTC, msg.GetTimestamp().Location())
+
+	ts := time.Date(2024, 2, 1, 9, 0, 0, 0, time.FixedZone("CET", 3600))
+	msg.SetTimestamp(ts)
+	assert.True(t, ts.Equal(msg.GetTimestamp()))
+	assert.Equal(t, time.UTC, msg.GetTimestamp().Location())
+	assert.True(t, msg.ServerlessExtra.Timestamp.IsZero())
+
+	// the timestamp parsed from the content takes precedence over the serverless one
+	msg = NewMessage([]byte("hello"), nil, StatusInfo, 0)
+	msg.ServerlessExtra.Timestamp = ts.Add(time.Hour).UTC()
+	assert.True(t, ts.Add(time.Hour).Equal(msg.GetTimestamp()))
+	msg.SetTimestamp(ts)
+	assert.True(t, ts.Equal(msg.GetTimestamp()))
+}
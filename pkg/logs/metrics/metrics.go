@@ -25,6 +25,19 @@ var (
 	// TlmLogsProcessed is the total number of processed logs.
 	TlmLogsProcessed = telemetry.NewCounter("logs", "processed",
 		nil, "Total number of processed logs")
+	// LogsSampledOut is the total number of logs dropped by sample and rate_limit processing rules.
+	LogsSampledOut = expvar.Int{}
+	// TlmLogsSampledOut is the total number of logs dropped by sample and rate_limit processing rules.
+	TlmLogsSampledOut = telemetry.NewCounter("logs", "sampled_out",
+		[]string{"rule_type"}, "Total number of logs dropped by sample and rate_limit processing rules")
+	// TlmLogsConvertedToMetrics is the total number of logs dropped once turned into metrics by log_metrics rules.
+	TlmLogsConvertedToMetrics = telemetry.NewCounter("logs", "converted_to_metrics",
+		nil, "Total number of logs dropped once turned into metrics by log_metrics rules")
+	// LogsTimestampParseFailures is the total number of logs whose timestamp could not be parsed from their content.
+	LogsTimestampParseFailures = expvar.Int{}
+	// TlmLogsTimestampParseFailures is the total number of logs whose timestamp could not be parsed from their content.
+	TlmLogsTimestampParseFailures = telemetry.NewCounter("logs", "timestamp_parse_failures",
+		[]string{"reason"}, "Total number of logs whose timestamp could not be parsed from their content")
 
 	// LogsSent is the total number of sent logs.
 	LogsSent = expvar.Int{}
@@ -97,6 +110,8 @@ func init() {
 	LogsExpvars = expvar.NewMap("logs-agent")
 	LogsExpvars.Set("LogsDecoded", &LogsDecoded)
 	LogsExpvars.Set("LogsProcessed", &LogsProcessed)
+	LogsExpvars.Set("LogsSampledOut", &LogsSampledOut)
+	LogsExpvars.Set("LogsTimestampParseFailures", &LogsTimestampParseFailures)
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
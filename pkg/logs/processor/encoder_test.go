@@ -237,3 +237,15 @@ func TestEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a����z", toValidUtf8([]byte("a\xf0\x8f\xbf\xbfz")))
 	assert.Equal(t, "世界����z 世界", toValidUtf8([]byte("世界\xf0\x8f\xbf\xbfz 世界")))
 }
+
+func TestJsonEncoderUsesMessageTimestamp(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+	msg.State = message.StateRendered
+	msg.SetTimestamp(time.UnixMilli(1706774400123))
+
+	assert.Nil(t, JSONEncoder.Encode(msg, "unknown"))
+	log := &jsonPayload{}
+	assert.Nil(t, json.Unmarshal(msg.GetContent(), log))
+	assert.Equal(t, int64(1706774400123), log.Timestamp)
+}
@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// messageField is the field holding the log message in rendered logs, it is
+// never overridden by field rules.
+const messageField = "message"
+
+// applyFieldRules applies the rules transforming the fields of a log, i.e.
+// extract_kv, json_drop_fields and json_rename_field, on its rendered content
+// and returns the new rendered content.
+// Unstructured logs which are not JSON objects are turned into JSON objects by
+// extract_kv rules, the original content being kept in the "message" field,
+// json rules are not applied on them.
+func (p *Processor) applyFieldRules(msg *message.Message, rendered []byte) []byte {
+	var fields map[string]interface{}
+	decoded, modified := false, false
+
+	for _, rules := range [][]*config.ProcessingRule{p.processingRules, msg.Origin.LogSource.Config.ProcessingRules} {
+		for _, rule := range rules {
+			switch rule.Type {
+			case config.ExtractKV, config.JSONDropFields, config.JSONRenameField:
+			default:
+				continue
+			}
+
+			// the content is decoded once, only when a field rule is configured
+			if !decoded {
+				fields = decodeJSONObject(rendered)
+				decoded = true
+			}
+			if fields == nil {
+				if rule.Type != config.ExtractKV {
+					continue
+				}
+				fields = map[string]interface{}{messageField: string(rendered)}
+			}
+
+			switch rule.Type {
+			case config.ExtractKV:
+				modified = extractKeyValues(rule, fields) || modified
+			case config.JSONDropFields:
+				for _, field := range rule.Fields {
+					modified = deleteField(fields, field) || modified
+				}
+			case config.JSONRenameField:
+				modified = renameField(fields, rule.SourceField, rule.TargetField) || modified
+			}
+		}
+	}
+
+	if !modified {
+		return rendered
+	}
+	content, err := json.Marshal(fields)
+	if err != nil {
+		log.Error("can't render the msg after applying field rules", err)
+		return rendered
+	}
+	return content
+}
+
+// decodeJSONObject returns the fields of content if it is a JSON object,
+// nil otherwise. Numbers are kept as is to not lose precision.
+func decodeJSONObject(content []byte) map[string]interface{} {
+	content = bytes.TrimSpace(content)
+	if len(content) == 0 || content[0] != '{' {
+		return nil
+	}
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// extractKeyValues adds the key/value pairs matched by the rule in the message
+// field to fields, it returns true if at least one pair was added.
+func extractKeyValues(rule *config.ProcessingRule, fields map[string]interface{}) bool {
+	msg, ok := fields[messageField].(string)
+	if !ok {
+		return false
+	}
+	extracted := false
+	for _, match := range rule.Regex.FindAllStringSubmatch(msg, -1) {
+		key, value := match[1], match[2]
+		if key == "" || key == messageField {
+			continue
+		}
+		if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+			value = value[1 : len(value)-1]
+		}
+		fields[key] = value
+		extracted = true
+	}
+	return extracted
+}
+
+// lookupParent returns the object holding the given dotted path and the last
+// key of the path. When create is true, missing intermediate objects are
+// created.
+func lookupParent(fields map[string]interface{}, path string, create bool) (map[string]interface{}, string) {
+	keys := strings.Split(path, ".")
+	parent := fields
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := parent[key].(map[string]interface{})
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			if _, exists := parent[key]; exists {
+				// do not override a value which is not an object
+				return nil, ""
+			}
+			child = make(map[string]interface{})
+			parent[key] = child
+		}
+		parent = child
+	}
+	return parent, keys[len(keys)-1]
+}
+
+// deleteField removes the field at the given dotted path, it returns true
+// if the field existed.
+func deleteField(fields map[string]interface{}, path string) bool {
+	if path == messageField {
+		return false
+	}
+	parent, key := lookupParent(fields, path, false)
+	if parent == nil {
+		return false
+	}
+	if _, ok := parent[key]; !ok {
+		return false
+	}
+	delete(parent, key)
+	return true
+}
+
+// renameField moves the field at the source dotted path to the target one,
+// it returns true if the field existed and has been moved.
+func renameField(fields map[string]interface{}, source, target string) bool {
+	if source == messageField || target == messageField || source == target {
+		return false
+	}
+	parent, key := lookupParent(fields, source, false)
+	if parent == nil {
+		return false
+	}
+	value, ok := parent[key]
+	if !ok {
+		return false
+	}
+	targetParent, targetKey := lookupParent(fields, target, true)
+	if targetParent == nil {
+		return false
+	}
+	delete(parent, key)
+	targetParent[targetKey] = value
+	return true
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func newFieldRulesSource(t *testing.T, rules ...*config.ProcessingRule) *sources.LogSource {
+	for _, rule := range rules {
+		rule.Name = "test"
+	}
+	require.NoError(t, config.ValidateProcessingRules(rules))
+	require.NoError(t, config.CompileProcessingRules(rules))
+	return sources.NewLogSource("", &config.LogsConfig{ProcessingRules: rules})
+}
+
+func TestExtractKV(t *testing.T) {
+	p := &Processor{}
+
+	source := newFieldRulesSource(t, &config.ProcessingRule{Type: config.ExtractKV})
+	msg := newMessage([]byte(`GET /users status=200 duration=12 user="john doe" message=ignored`), source, "")
+	rendered, _ := msg.Render()
+	assert.JSONEq(t, `{"message":"GET /users status=200 duration=12 user=\"john doe\" message=ignored","status":"200","duration":"12","user":"john doe"}`, string(p.applyFieldRules(msg, rendered)))
+
+	// structured and JSON logs get the pairs added to their fields
+	msg = newStructuredMessage([]byte("a=1 b=2"), source, "")
+	rendered, _ = msg.Render()
+	assert.JSONEq(t, `{"message":"a=1 b=2","a":"1","b":"2"}`, string(p.applyFieldRules(msg, rendered)))
+
+	msg = newMessage([]byte(`{"message":"a=1","id":12345678901234567890}`), source, "")
+	rendered, _ = msg.Render()
+	assert.JSONEq(t, `{"message":"a=1","a":"1","id":12345678901234567890}`, string(p.applyFieldRules(msg, rendered)))
+
+	// nothing is changed when no pair is found
+	msg = newMessage([]byte("hello world"), source, "")
+	rendered, _ = msg.Render()
+	assert.Equal(t, "hello world", string(p.applyFieldRules(msg, rendered)))
+
+	// custom pattern
+	source = newFieldRulesSource(t, &config.ProcessingRule{Type: config.ExtractKV, Pattern: `(\w+):(\w+)`})
+	msg = newMessage([]byte("user:john env:prod"), source, "")
+	rendered, _ = msg.Render()
+	assert.JSONEq(t, `{"message":"user:john env:prod","user":"john","env":"prod"}`, string(p.applyFieldRules(msg, rendered)))
+}
+
+func TestJSONDropFields(t *testing.T) {
+	p := &Processor{}
+	source := newFieldRulesSource(t, &config.ProcessingRule{Type: config.JSONDropFields, Fields: []string{"request_id", "http.headers", "message", "missing.field"}})
+
+	msg := newMessage([]byte(`{"message":"hello","request_id":"abc","http":{"status":200,"headers":{"a":"b"}}}`), source, "")
+	rendered, _ := msg.Render()
+	assert.JSONEq(t, `{"message":"hello","http":{"status":200}}`, string(p.applyFieldRules(msg, rendered)))
+
+	// logs which are not JSON objects are left untouched
+	for _, content := range []string{"request_id=abc", `["request_id"]`, `{"request_id":`} {
+		msg = newMessage([]byte(content), source, "")
+		rendered, _ = msg.Render()
+		assert.Equal(t, content, string(p.applyFieldRules(msg, rendered)))
+	}
+}
+
+func TestJSONRenameField(t *testing.T) {
+	p := &Processor{}
+	source := newFieldRulesSource(t,
+		&config.ProcessingRule{Type: config.JSONRenameField, SourceField: "lvl", TargetField: "status"},
+		&config.ProcessingRule{Type: config.JSONRenameField, SourceField: "user_id", TargetField: "usr.id"},
+		&config.ProcessingRule{Type: config.JSONRenameField, SourceField: "msg", TargetField: "message"},
+	)
+
+	msg := newMessage([]byte(`{"msg":"hello","lvl":"warn","user_id":42}`), source, "")
+	rendered, _ := msg.Render()
+	assert.JSONEq(t, `{"msg":"hello","status":"warn","usr":{"id":42}}`, string(p.applyFieldRules(msg, rendered)))
+
+	// a field is not moved into a value which is not an object
+	msg = newMessage([]byte(`{"usr":"john","user_id":42}`), source, "")
+	rendered, _ = msg.Render()
+	assert.Equal(t, `{"usr":"john","user_id":42}`, string(p.applyFieldRules(msg, rendered)))
+}
+
+func TestFieldRulesAreAppliedInOrder(t *testing.T) {
+	p := &Processor{processingRules: []*config.ProcessingRule{{Type: config.JSONDropFields, Fields: []string{"password"}}}}
+	source := newFieldRulesSource(t,
+		&config.ProcessingRule{Type: config.ExtractKV},
+		&config.ProcessingRule{Type: config.JSONRenameField, SourceField: "usr", TargetField: "user"},
+	)
+
+	// global rules are applied first, the password is thus only extracted afterwards
+	msg := newMessage([]byte("usr=john password=secret"), source, "")
+	rendered, _ := msg.Render()
+	assert.JSONEq(t, `{"message":"usr=john password=secret","user":"john","password":"secret"}`, string(p.applyFieldRules(msg, rendered)))
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// timestampField is the field holding the timestamp, in milliseconds, of the
+// logs parsed from a known format. It is used by the intake as the date of
+// the log.
+const timestampField = "timestamp"
+
+const (
+	accessLogTimeLayout  = "02/Jan/2006:15:04:05 -0700"
+	nginxErrorTimeLayout = "2006/01/02 15:04:05"
+)
+
+var (
+	// accessLogRegex matches the Common Log Format and the combined format,
+	// which adds the referer and the user agent, used by both Apache and nginx.
+	accessLogRegex = regexp.MustCompile(`^(\S+) (\S+) (\S+) \[([^\]]+)\] "([^"]*)" (\d{3}) (\d+|-)(?: "([^"]*)" "([^"]*)")?`)
+	// nginxErrorLogRegex matches the lines of the nginx error log.
+	nginxErrorLogRegex = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}) \[(\w+)\] (\d+)#(\d+): (?:\*(\d+) )?(.*)$`)
+)
+
+// formatParser returns the fields and the status of a log line, or nil if the
+// line does not match the format.
+type formatParser func(line string) (map[string]interface{}, string)
+
+var formatParsers = map[string][]formatParser{
+	config.ApacheCombinedFormat: {parseAccessLog},
+	config.NginxFormat:          {parseAccessLog, parseNginxErrorLog},
+	config.LogfmtFormat:         {parseLogfmt},
+	config.AutoFormat:           {parseAccessLog, parseNginxErrorLog, parseLogfmt},
+}
+
+// applyFormat turns the rendered content of messages from sources with a
+// format into a JSON object holding the parsed fields, and sets the status of
+// the message from them. Lines which do not match the format, or which are
+// already JSON, are left untouched.
+func (p *Processor) applyFormat(msg *message.Message, rendered []byte) []byte {
+	parsers := formatParsers[msg.Origin.LogSource.Config.Format]
+	if len(parsers) == 0 || len(rendered) == 0 || rendered[0] == '{' {
+		return rendered
+	}
+
+	line := string(rendered)
+	for _, parse := range parsers {
+		fields, status := parse(line)
+		if fields == nil {
+			continue
+		}
+		content, err := json.Marshal(fields)
+		if err != nil {
+			log.Error("can't render the msg after parsing its format", err)
+			return rendered
+		}
+		if status != "" {
+			msg.Status = status
+		}
+		// the timestamp_pattern of the source has precedence over the one of the format
+		if ts, ok := fields[timestampField].(int64); ok && !msg.Origin.LogSource.Config.HasTimestampPattern() {
+			msg.SetTimestamp(time.UnixMilli(ts))
+		}
+		return content
+	}
+	return rendered
+}
+
+// parseAccessLog parses lines of the Common Log Format or the combined format
+// into the standard attributes of HTTP access logs.
+func parseAccessLog(line string) (map[string]interface{}, string) {
+	match := accessLogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil, ""
+	}
+
+	httpFields := make(map[string]interface{})
+	network := map[string]interface{}{
+		"client": map[string]interface{}{"ip": match[1]},
+	}
+	fields := map[string]interface{}{
+		messageField: line,
+		"http":       httpFields,
+		"network":    network,
+	}
+
+	setIfPresent(httpFields, "ident", match[2])
+	setIfPresent(httpFields, "auth", match[3])
+	if ts, err := time.Parse(accessLogTimeLayout, match[4]); err == nil {
+		fields[timestampField] = ts.UnixMilli()
+	}
+	if request := strings.Fields(match[5]); len(request) >= 2 {
+		httpFields["method"] = request[0]
+		httpFields["url"] = request[1]
+		if len(request) > 2 {
+			httpFields["version"] = strings.TrimPrefix(request[2], "HTTP/")
+		}
+	}
+	statusCode, _ := strconv.Atoi(match[6])
+	httpFields["status_code"] = statusCode
+	if bytesWritten, err := strconv.Atoi(match[7]); err == nil {
+		network["bytes_written"] = bytesWritten
+	}
+	setIfPresent(httpFields, "referer", match[8])
+	setIfPresent(httpFields, "useragent", match[9])
+
+	status := message.StatusInfo
+	switch {
+	case statusCode >= 500:
+		status = message.StatusError
+	case statusCode >= 400:
+		status = message.StatusWarning
+	}
+	return fields, status
+}
+
+// parseNginxErrorLog parses lines of the nginx error log, its timestamps are
+// in the local time zone.
+func parseNginxErrorLog(line string) (map[string]interface{}, string) {
+	match := nginxErrorLogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil, ""
+	}
+
+	fields := map[string]interface{}{
+		messageField: match[6],
+		"level":      match[2],
+	}
+	if ts, err := time.ParseInLocation(nginxErrorTimeLayout, match[1], time.Local); err == nil {
+		fields[timestampField] = ts.UnixMilli()
+	}
+	fields["pid"], _ = strconv.Atoi(match[3])
+	fields["tid"], _ = strconv.Atoi(match[4])
+	if match[5] != "" {
+		fields["connection_id"], _ = strconv.Atoi(match[5])
+	}
+	return fields, statusFromLevel(match[2])
+}
+
+// parseLogfmt parses lines made only of key=value pairs, values can be quoted.
+func parseLogfmt(line string) (map[string]interface{}, string) {
+	fields := make(map[string]interface{})
+	rest := strings.TrimSpace(line)
+	for rest != "" {
+		eq := strings.IndexAny(rest, "= \t\"")
+		if eq <= 0 || rest[eq] != '=' {
+			return nil, ""
+		}
+		key := rest[:eq]
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := closingQuote(rest)
+			if end < 0 {
+				return nil, ""
+			}
+			unquoted, err := strconv.Unquote(rest[:end+1])
+			if err != nil {
+				return nil, ""
+			}
+			value, rest = unquoted, rest[end+1:]
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, ""
+			}
+		} else if end := strings.IndexAny(rest, " \t"); end >= 0 {
+			value, rest = rest[:end], rest[end:]
+		} else {
+			value, rest = rest, ""
+		}
+		fields[key] = value
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	if len(fields) == 0 {
+		return nil, ""
+	}
+
+	if _, ok := fields[messageField]; !ok {
+		if msg, ok := fields["msg"]; ok {
+			fields[messageField] = msg
+			delete(fields, "msg")
+		} else {
+			fields[messageField] = line
+		}
+	}
+	if _, ok := fields[timestampField]; !ok {
+		for _, key := range []string{"time", "ts"} {
+			value, _ := fields[key].(string)
+			if ts, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				fields[timestampField] = ts.UnixMilli()
+				break
+			}
+		}
+	}
+
+	var status string
+	for _, key := range []string{"level", "lvl", "severity"} {
+		if level, ok := fields[key].(string); ok {
+			status = statusFromLevel(level)
+			break
+		}
+	}
+	return fields, status
+}
+
+// closingQuote returns the index of the quote closing the quoted string s
+// starts with, -1 if there is none.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// statusFromLevel returns the status of a log level, or an empty status if
+// the level is unknown.
+func statusFromLevel(level string) string {
+	switch strings.ToLower(level) {
+	case "emerg", "emergency", "panic":
+		return message.StatusEmergency
+	case "alert":
+		return message.StatusAlert
+	case "crit", "critical", "fatal":
+		return message.StatusCritical
+	case "err", "error":
+		return message.StatusError
+	case "warn", "warning":
+		return message.StatusWarning
+	case "notice":
+		return message.StatusNotice
+	case "info", "informational":
+		return message.StatusInfo
+	case "debug", "trace":
+		return message.StatusDebug
+	}
+	return ""
+}
+
+// setIfPresent sets the field to value unless it is empty or "-", which
+// access logs use for missing values.
+func setIfPresent(fields map[string]interface{}, key, value string) {
+	if value != "" && value != "-" {
+		fields[key] = value
+	}
+}
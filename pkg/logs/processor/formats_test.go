@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+func applyFormat(format string, content string) (string, *message.Message) {
+	p := &Processor{}
+	msg := newMessage([]byte(content), sources.NewLogSource("", &config.LogsConfig{Format: format}), message.StatusInfo)
+	rendered, _ := msg.Render()
+	return string(p.applyFormat(msg, rendered)), msg
+}
+
+func TestApacheCombinedFormat(t *testing.T) {
+	line := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 404 2326 "http://www.example.com/start.html" "Mozilla/4.08"`
+	rendered, msg := applyFormat(config.ApacheCombinedFormat, line)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"message": %q,
+		"timestamp": 971211336000,
+		"network": {"client": {"ip": "127.0.0.1"}, "bytes_written": 2326},
+		"http": {"auth": "frank", "method": "GET", "url": "/apache_pb.gif", "version": "1.0", "status_code": 404, "referer": "http://www.example.com/start.html", "useragent": "Mozilla/4.08"}
+	}`, line), rendered)
+	assert.Equal(t, message.StatusWarning, msg.Status)
+
+	// common log format
+	line = `10.0.0.1 - - [10/Oct/2000:13:55:36 +0000] "POST /login HTTP/1.1" 502 -`
+	rendered, msg = applyFormat(config.ApacheCombinedFormat, line)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"message": %q,
+		"timestamp": 971186136000,
+		"network": {"client": {"ip": "10.0.0.1"}},
+		"http": {"method": "POST", "url": "/login", "version": "1.1", "status_code": 502}
+	}`, line), rendered)
+	assert.Equal(t, message.StatusError, msg.Status)
+}
+
+func TestNginxFormat(t *testing.T) {
+	line := `192.168.1.1 - - [01/Feb/2024:08:00:00 +0000] "GET / HTTP/1.1" 200 612 "-" "curl/8.0"`
+	rendered, msg := applyFormat(config.NginxFormat, line)
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"message": %q,
+		"timestamp": 1706774400000,
+		"network": {"client": {"ip": "192.168.1.1"}, "bytes_written": 612},
+		"http": {"method": "GET", "url": "/", "version": "1.1", "status_code": 200, "useragent": "curl/8.0"}
+	}`, line), rendered)
+	assert.Equal(t, message.StatusInfo, msg.Status)
+
+	line = `2024/02/01 08:00:00 [crit] 1234#0: *42 connect() failed (111: Connection refused) while connecting to upstream`
+	rendered, msg = applyFormat(config.NginxFormat, line)
+	ts := time.Date(2024, 2, 1, 8, 0, 0, 0, time.Local).UnixMilli()
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"message": "connect() failed (111: Connection refused) while connecting to upstream",
+		"timestamp": %d,
+		"level": "crit",
+		"pid": 1234,
+		"tid": 0,
+		"connection_id": 42
+	}`, ts), rendered)
+	assert.Equal(t, message.StatusCritical, msg.Status)
+}
+
+func TestLogfmtFormat(t *testing.T) {
+	rendered, msg := applyFormat(config.LogfmtFormat, `time=2024-02-01T08:00:00.5Z level=error msg="could not connect: \"db\" is down" retries=3 empty=`)
+	assert.JSONEq(t, `{
+		"message": "could not connect: \"db\" is down",
+		"time": "2024-02-01T08:00:00.5Z",
+		"timestamp": 1706774400500,
+		"level": "error",
+		"retries": "3",
+		"empty": ""
+	}`, rendered)
+	assert.Equal(t, message.StatusError, msg.Status)
+
+	// the line is kept as message when there is none
+	rendered, msg = applyFormat(config.LogfmtFormat, `a=1 b=2`)
+	assert.JSONEq(t, `{"message": "a=1 b=2", "a": "1", "b": "2"}`, rendered)
+	assert.Equal(t, message.StatusInfo, msg.Status)
+}
+
+func TestAutoFormat(t *testing.T) {
+	rendered, _ := applyFormat(config.AutoFormat, `level=warn msg=hello`)
+	assert.JSONEq(t, `{"message": "hello", "level": "warn"}`, rendered)
+
+	rendered, msg := applyFormat(config.AutoFormat, `2024/02/01 08:00:00 [warn] 1#1: hello`)
+	assert.Contains(t, rendered, `"message":"hello"`)
+	assert.Equal(t, message.StatusWarning, msg.Status)
+}
+
+func TestFormatFallsBackOnNonMatchingLines(t *testing.T) {
+	for _, format := range []string{config.ApacheCombinedFormat, config.NginxFormat, config.LogfmtFormat, config.AutoFormat} {
+		for _, line := range []string{
+			"hello world",
+			"GET / 200",
+			`a=1 b="unterminated`,
+			`a=1 b="x"c`,
+			`{"message":"a=1"}`,
+			"",
+		} {
+			rendered, msg := applyFormat(format, line)
+			assert.Equal(t, line, rendered, "%s: %s", format, line)
+			assert.Equal(t, message.StatusInfo, msg.Status)
+		}
+	}
+
+	// no format configured
+	rendered, _ := applyFormat("", `a=1`)
+	assert.Equal(t, `a=1`, rendered)
+}
+
+func TestApplyTimestamp(t *testing.T) {
+	logsConfig := &config.LogsConfig{Type: config.FileType, Path: "/tmp/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339"}
+	assert.NoError(t, logsConfig.Validate())
+	source := sources.NewLogSource("", logsConfig)
+
+	msg := newMessage([]byte("2024-02-01T08:00:00Z hello"), source, message.StatusInfo)
+	applyTimestamp(msg, msg.GetContent())
+	assert.Equal(t, time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC), msg.GetTimestamp())
+
+	// the ingestion time is used when the timestamp can't be parsed
+	for _, content := range []string{"", "yesterday hello"} {
+		failures := metrics.LogsTimestampParseFailures.Value()
+		msg = newMessage([]byte(content), source, message.StatusInfo)
+		applyTimestamp(msg, msg.GetContent())
+		assert.True(t, msg.ParsedTimestamp.IsZero())
+		assert.WithinDuration(t, time.Now(), msg.GetTimestamp(), time.Minute)
+		assert.Equal(t, failures+1, metrics.LogsTimestampParseFailures.Value())
+	}
+}
+
+func TestFormatSetsTimestamp(t *testing.T) {
+	_, msg := applyFormat(config.LogfmtFormat, `time=2024-02-01T08:00:00.5Z msg=hello`)
+	assert.Equal(t, time.UnixMilli(1706774400500).UTC(), msg.GetTimestamp())
+
+	// timestamp_pattern takes precedence over the timestamp of the format
+	logsConfig := &config.LogsConfig{Type: config.FileType, Path: "/tmp/foo.log", Format: config.LogfmtFormat, TimestampPattern: `ts=(\d+)`, TimestampFormat: config.UnixTimestampFormat}
+	assert.NoError(t, logsConfig.Validate())
+	msg = newMessage([]byte(`ts=1706774400 time=2024-02-01T08:00:00.5Z msg=hello`), sources.NewLogSource("", logsConfig), message.StatusInfo)
+	applyTimestamp(msg, msg.GetContent())
+	(&Processor{}).applyFormat(msg, msg.GetContent())
+	assert.Equal(t, time.Unix(1706774400, 0).UTC(), msg.GetTimestamp())
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// LogMetricsSender is the subset of the aggregator sender the metrics generated
+// from logs are submitted through. The metrics are only flushed by the aggregator
+// once committed.
+type LogMetricsSender interface {
+	Count(metric string, value float64, hostname string, tags []string)
+	Distribution(metric string, value float64, hostname string, tags []string)
+	Commit()
+}
+
+var logMetrics struct {
+	sync.RWMutex
+	sender LogMetricsSender
+}
+
+// StartLogMetrics registers the sender the metrics generated from logs are
+// submitted through and commits it every commitInterval, until ctx is done.
+func StartLogMetrics(ctx context.Context, sender LogMetricsSender, commitInterval time.Duration) {
+	logMetrics.Lock()
+	logMetrics.sender = sender
+	logMetrics.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(commitInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				sender.Commit()
+			case <-ctx.Done():
+				logMetrics.Lock()
+				logMetrics.sender = nil
+				logMetrics.Unlock()
+				sender.Commit()
+				return
+			}
+		}
+	}()
+}
+
+// applyLogMetrics submits the metrics of the log metric rules of the source
+// matching the content of the message through the log metrics sender.
+// It returns false if the message must be dropped because it has been turned
+// into a metric by a rule with drop_log.
+func applyLogMetrics(msg *message.Message, content []byte) bool {
+	rules := msg.Origin.LogSource.Config.LogMetrics
+	if len(rules) == 0 {
+		return true
+	}
+
+	logMetrics.RLock()
+	defer logMetrics.RUnlock()
+	sender := logMetrics.sender
+
+	keep := true
+	for _, rule := range rules {
+		match := rule.Regex.FindSubmatch(content)
+		if match == nil {
+			continue
+		}
+
+		value := 1.0
+		if rule.ValueField != "" {
+			raw := match[rule.Regex.SubexpIndex(rule.ValueField)]
+			parsed, err := strconv.ParseFloat(string(raw), 64)
+			if err != nil {
+				log.Debugf("Invalid value %q for log metric %s: %v", raw, rule.Name, err)
+				continue
+			}
+			value = parsed
+		}
+		tags := make([]string, 0, len(rule.TagFields))
+		for _, field := range rule.TagFields {
+			if tagValue := match[rule.Regex.SubexpIndex(field)]; len(tagValue) > 0 {
+				tags = append(tags, field+":"+string(tagValue))
+			}
+		}
+
+		if sender != nil {
+			switch rule.Type {
+			case config.CountLogMetric:
+				sender.Count(rule.Name, value, "", tags)
+			case config.DistributionLogMetric:
+				sender.Distribution(rule.Name, value, "", tags)
+			}
+		}
+		if rule.DropLog {
+			keep = false
+		}
+	}
+
+	if !keep {
+		metrics.TlmLogsConvertedToMetrics.Inc()
+	}
+	return keep
+}
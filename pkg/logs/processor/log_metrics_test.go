@@ -0,0 +1,221 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+type submittedMetric struct {
+	kind  string
+	name  string
+	value float64
+	tags  []string
+}
+
+// fakeLogMetricsSender records the metrics submitted in place of the aggregator.
+type fakeLogMetricsSender struct {
+	sync.Mutex
+	metrics []submittedMetric
+	commits int
+}
+
+func (s *fakeLogMetricsSender) Count(metric string, value float64, _ string, tags []string) {
+	s.Lock()
+	defer s.Unlock()
+	s.metrics = append(s.metrics, submittedMetric{"count", metric, value, tags})
+}
+
+func (s *fakeLogMetricsSender) Distribution(metric string, value float64, _ string, tags []string) {
+	s.Lock()
+	defer s.Unlock()
+	s.metrics = append(s.metrics, submittedMetric{"distribution", metric, value, tags})
+}
+
+func (s *fakeLogMetricsSender) Commit() {
+	s.Lock()
+	defer s.Unlock()
+	s.commits++
+}
+
+func (s *fakeLogMetricsSender) submitted() []submittedMetric {
+	s.Lock()
+	defer s.Unlock()
+	metrics := s.metrics
+	s.metrics = nil
+	return metrics
+}
+
+func (s *fakeLogMetricsSender) committed() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.commits
+}
+
+func startFakeLogMetricsSender(t *testing.T, commitInterval time.Duration) (*fakeLogMetricsSender, context.CancelFunc) {
+	sender := &fakeLogMetricsSender{}
+	ctx, cancel := context.WithCancel(context.Background())
+	StartLogMetrics(ctx, sender, commitInterval)
+	t.Cleanup(cancel)
+	return sender, cancel
+}
+
+func newLogMetricsSource(t *testing.T, rules ...*config.LogMetricRule) *sources.LogSource {
+	logsConfig := &config.LogsConfig{Type: config.FileType, Path: "/tmp/access.log", LogMetrics: rules}
+	require.NoError(t, logsConfig.Validate())
+	return sources.NewLogSource("", logsConfig)
+}
+
+func TestLogMetrics(t *testing.T) {
+	sender, _ := startFakeLogMetricsSender(t, time.Hour)
+	source := newLogMetricsSource(t,
+		&config.LogMetricRule{
+			Name:      "http.requests",
+			Type:      config.CountLogMetric,
+			Match:     `"(?P<method>[A-Z]+) \S+" (?P<status>\d{3})`,
+			TagFields: []string{"method", "status"},
+		},
+		&config.LogMetricRule{
+			Name:       "http.latency",
+			Type:       config.DistributionLogMetric,
+			Match:      `"(?P<method>[A-Z]+) \S+" \d{3} latency=(?P<latency>[\d.]+)(?:ms)?(?: route=(?P<route>\S+))?`,
+			ValueField: "latency",
+			TagFields:  []string{"method", "route"},
+		},
+	)
+
+	msg := newMessage([]byte(`"GET /" 200 latency=12.5ms`), source, message.StatusInfo)
+	assert.True(t, applyLogMetrics(msg, msg.GetContent()))
+	assert.Equal(t, []submittedMetric{
+		{"count", "http.requests", 1, []string{"method:GET", "status:200"}},
+		{"distribution", "http.latency", 12.5, []string{"method:GET"}},
+	}, sender.submitted())
+
+	// no metric is generated from logs which do not match
+	msg = newMessage([]byte("hello world"), source, message.StatusInfo)
+	assert.True(t, applyLogMetrics(msg, msg.GetContent()))
+	assert.Empty(t, sender.submitted())
+
+	// distributions are skipped when their value is invalid
+	msg = newMessage([]byte(`"POST /login" 500 latency=1.2.3 route=login`), source, message.StatusInfo)
+	assert.True(t, applyLogMetrics(msg, msg.GetContent()))
+	assert.Equal(t, []submittedMetric{
+		{"count", "http.requests", 1, []string{"method:POST", "status:500"}},
+	}, sender.submitted())
+}
+
+func TestLogMetricsDropLog(t *testing.T) {
+	sender, _ := startFakeLogMetricsSender(t, time.Hour)
+	source := newLogMetricsSource(t, &config.LogMetricRule{
+		Name:       "queue.size",
+		Type:       config.CountLogMetric,
+		Match:      `^queue size: (?P<size>\d+)`,
+		ValueField: "size",
+		DropLog:    true,
+	})
+
+	msg := newMessage([]byte("queue size: 42"), source, message.StatusInfo)
+	assert.False(t, applyLogMetrics(msg, msg.GetContent()))
+	assert.Equal(t, []submittedMetric{{"count", "queue.size", 42, []string{}}}, sender.submitted())
+
+	// logs which do not match are kept
+	msg = newMessage([]byte("queue is empty"), source, message.StatusInfo)
+	assert.True(t, applyLogMetrics(msg, msg.GetContent()))
+}
+
+func TestStartLogMetrics(t *testing.T) {
+	sender, stop := startFakeLogMetricsSender(t, 10*time.Millisecond)
+	source := newLogMetricsSource(t, &config.LogMetricRule{
+		Name:  "errors",
+		Type:  config.CountLogMetric,
+		Match: `ERROR`,
+	})
+
+	// the submitted metrics are committed periodically for the aggregator to flush them
+	msg := newMessage([]byte("ERROR: boom"), source, message.StatusInfo)
+	applyLogMetrics(msg, msg.GetContent())
+	assert.Equal(t, []submittedMetric{{"count", "errors", 1, []string{}}}, sender.submitted())
+	assert.Eventually(t, func() bool { return sender.committed() > 0 }, 5*time.Second, 10*time.Millisecond)
+
+	// the metrics are committed a last time and no longer submitted once stopped
+	commits := sender.committed()
+	stop()
+	assert.Eventually(t, func() bool { return sender.committed() > commits }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		applyLogMetrics(msg, msg.GetContent())
+		return len(sender.submitted()) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLogMetricsSampledLogs(t *testing.T) {
+	sender, _ := startFakeLogMetricsSender(t, time.Hour)
+	sampleRule := &config.ProcessingRule{Type: config.Sample, Name: "debug", SampleRate: 0.1, Pattern: "DEBUG"}
+	logsConfig := &config.LogsConfig{
+		Type:            config.FileType,
+		Path:            "/tmp/app.log",
+		ProcessingRules: []*config.ProcessingRule{sampleRule},
+		LogMetrics:      []*config.LogMetricRule{{Name: "debug.logs", Type: config.CountLogMetric, Match: `DEBUG`}},
+	}
+	require.NoError(t, logsConfig.Validate())
+	require.NoError(t, config.CompileProcessingRules(logsConfig.ProcessingRules))
+	source := sources.NewLogSource("", logsConfig)
+
+	// the metrics account for the logs dropped by the sampling rules
+	p := &Processor{}
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if p.applyRedactingRules(newMessage([]byte("DEBUG a debug log"), source, message.StatusInfo)) {
+			kept++
+		}
+	}
+	assert.Equal(t, 10, kept)
+	assert.Len(t, sender.submitted(), 100)
+}
+
+func TestLogMetricsProcessor(t *testing.T) {
+	sender, _ := startFakeLogMetricsSender(t, time.Hour)
+	source := newLogMetricsSource(t, &config.LogMetricRule{
+		Name:      "http.requests",
+		Type:      config.CountLogMetric,
+		Match:     `" (?P<status>\d{3})`,
+		TagFields: []string{"status"},
+		DropLog:   true,
+	})
+
+	hostnameComponent, _ := hostnameinterface.NewMock("hostname")
+	pm := metrics.NewNoopPipelineMonitor("")
+	p := &Processor{
+		encoder:                   RawEncoder,
+		inputChan:                 make(chan *message.Message, 2),
+		outputChan:                make(chan *message.Message, 2),
+		diagnosticMessageReceiver: diagnostic.NewBufferedMessageReceiver(nil, hostnameComponent),
+		done:                      make(chan struct{}),
+		pipelineMonitor:           pm,
+		utilization:               pm.MakeUtilizationMonitor("processor"),
+	}
+	p.Start()
+
+	// the logs turned into metrics are submitted through the sender and dropped
+	p.inputChan <- newMessage([]byte(`"GET /" 200`), source, message.StatusInfo)
+	p.inputChan <- newMessage([]byte("hello world"), source, message.StatusInfo)
+	p.Stop()
+	require.Len(t, p.outputChan, 1)
+	assert.Contains(t, string((<-p.outputChan).GetContent()), "hello world")
+	assert.Equal(t, []submittedMetric{{"count", "http.requests", 1, []string{"status:200"}}}, sender.submitted())
+}
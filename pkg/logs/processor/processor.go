@@ -8,8 +8,10 @@ package processor
 import (
 	"bytes"
 	"context"
+	"errors"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/hostname/hostnameinterface"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
@@ -239,6 +241,9 @@ func (p *Processor) processMessage(msg *message.Message) {
 			log.Error("can't render the msg", err)
 			return
 		}
+		applyTimestamp(msg, rendered)
+		rendered = p.applyFormat(msg, rendered)
+		rendered = p.applyFieldRules(msg, rendered)
 		msg.SetRendered(rendered)
 
 		// report this message to diagnostic receivers (e.g. `stream-logs` command)
@@ -266,6 +271,7 @@ func (p *Processor) applyRedactingRules(msg *message.Message) bool {
 	// ---------------------------
 
 	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
+	var samplingRules []*config.ProcessingRule
 	for _, rule := range rules {
 		switch rule.Type {
 		case config.ExcludeAtMatch:
@@ -282,6 +288,22 @@ func (p *Processor) applyRedactingRules(msg *message.Message) bool {
 			if isMatchingLiteralPrefix(rule.Regex, content) {
 				content = rule.Regex.ReplaceAll(content, rule.Placeholder)
 			}
+		case config.Sample, config.RateLimit:
+			// the logs are sampled once turned into metrics, so that the
+			// metrics account for all of them
+			samplingRules = append(samplingRules, rule)
+		}
+	}
+
+	// drop the messages turned into metrics
+	if !applyLogMetrics(msg, content) {
+		return false
+	}
+
+	now := time.Now()
+	for _, rule := range samplingRules {
+		if !applySamplingRule(rule, msg, content, now) {
+			return false
 		}
 	}
 
@@ -302,6 +324,28 @@ func (p *Processor) applyRedactingRules(msg *message.Message) bool {
 	return true // we want to send this message
 }
 
+// applyTimestamp sets the timestamp of the message from its content for
+// sources with a timestamp_pattern. The time of the encoding is used when it
+// can't be parsed.
+func applyTimestamp(msg *message.Message, content []byte) {
+	sourceConfig := msg.Origin.LogSource.Config
+	if !sourceConfig.HasTimestampPattern() {
+		return
+	}
+	ts, err := sourceConfig.ParseTimestamp(content)
+	if err != nil {
+		reason := "invalid"
+		if errors.Is(err, config.ErrTimestampNotFound) {
+			reason = "not_found"
+		}
+		metrics.LogsTimestampParseFailures.Add(1)
+		metrics.TlmLogsTimestampParseFailures.Inc(reason)
+		log.Debugf("Could not parse the timestamp of a log of source %s: %v", msg.Origin.LogSource.Name, err)
+		return
+	}
+	msg.SetTimestamp(ts)
+}
+
 // isMatchingLiteralPrefix uses a potential literal prefix from the given regex
 // to indicate if the contant even has a chance of matching the regex
 func isMatchingLiteralPrefix(r *regexp.Regexp, content []byte) bool {
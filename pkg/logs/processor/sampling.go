@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// maxRateLimitGroups bounds the number of groups tracked every second by a
+// rate_limit rule, logs of additional groups share the same budget.
+const maxRateLimitGroups = 1000
+
+// overflowGroup is the group of the logs exceeding maxRateLimitGroups.
+const overflowGroup = "\x00overflow"
+
+// creditEpsilon absorbs the rounding errors when accumulating sample rates.
+const creditEpsilon = 1e-9
+
+// samplersLock prevents two pipelines from registering concurrently a sampler
+// for the same source and rule.
+var samplersLock sync.Mutex
+
+// ruleSampler holds the state of a sample or rate_limit processing rule for a
+// source. It is registered as an info provider of the source so that the number
+// of logs it dropped is displayed on the status page.
+type ruleSampler struct {
+	rule    *config.ProcessingRule
+	dropped atomic.Int64
+
+	mu sync.Mutex
+	// credit accumulates the sample rate of a sample rule, a log is kept every
+	// time it reaches 1.
+	credit float64
+	// window is the second during which counts were collected by a rate_limit
+	// rule. Budgets are renewed every second so that the tail of a burst is
+	// still sent.
+	window int64
+	counts map[string]int
+}
+
+func newRuleSampler(rule *config.ProcessingRule) *ruleSampler {
+	return &ruleSampler{
+		rule: rule,
+		// keep the first log
+		credit: 1 - rule.SampleRate,
+		counts: make(map[string]int),
+	}
+}
+
+// samplerInfoKey returns the key of the sampler of the given rule in the info
+// registry of a source.
+func samplerInfoKey(rule *config.ProcessingRule) string {
+	return fmt.Sprintf("%s rule %s", rule.Type, rule.Name)
+}
+
+// getRuleSampler returns the sampler of the rule for the given source,
+// registering a new one if needed.
+func getRuleSampler(source *sources.LogSource, rule *config.ProcessingRule) *ruleSampler {
+	key := samplerInfoKey(rule)
+	if s, ok := source.GetInfo(key).(*ruleSampler); ok && s.rule == rule {
+		return s
+	}
+
+	samplersLock.Lock()
+	defer samplersLock.Unlock()
+	// the rule may have been replaced when the source configuration is updated
+	if s, ok := source.GetInfo(key).(*ruleSampler); ok && s.rule == rule {
+		return s
+	}
+	s := newRuleSampler(rule)
+	source.RegisterInfo(s)
+	return s
+}
+
+// keep returns true if the log with the given content should be kept.
+func (s *ruleSampler) keep(content []byte, now time.Time) bool {
+	group := ""
+	if s.rule.Regex != nil {
+		match := s.rule.Regex.FindSubmatch(content)
+		if match == nil {
+			// the rule does not apply to this log
+			return true
+		}
+		// logs are grouped by the first capturing group if any
+		if len(match) > 1 {
+			group = string(match[1])
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept bool
+	switch s.rule.Type {
+	case config.Sample:
+		kept = s.keepSampled()
+	case config.RateLimit:
+		kept = s.keepRateLimited(group, now)
+	default:
+		kept = true
+	}
+	if !kept {
+		s.dropped.Add(1)
+	}
+	return kept
+}
+
+func (s *ruleSampler) keepSampled() bool {
+	s.credit += s.rule.SampleRate
+	if s.credit >= 1-creditEpsilon {
+		s.credit--
+		return true
+	}
+	return false
+}
+
+func (s *ruleSampler) keepRateLimited(group string, now time.Time) bool {
+	if window := now.Unix(); window != s.window {
+		s.window = window
+		clear(s.counts)
+	}
+	if _, ok := s.counts[group]; !ok && len(s.counts) >= maxRateLimitGroups {
+		group = overflowGroup
+	}
+	if s.counts[group] >= s.rule.MaxPerSecond {
+		return false
+	}
+	s.counts[group]++
+	return true
+}
+
+// InfoKey implements InfoProvider#InfoKey.
+func (s *ruleSampler) InfoKey() string {
+	return samplerInfoKey(s.rule)
+}
+
+// Info implements InfoProvider#Info.
+func (s *ruleSampler) Info() []string {
+	return []string{fmt.Sprintf("%d logs dropped", s.dropped.Load())}
+}
+
+// applySamplingRule returns true if the message received at the given time
+// should be kept by the given sample or rate_limit rule.
+func applySamplingRule(rule *config.ProcessingRule, msg *message.Message, content []byte, now time.Time) bool {
+	if getRuleSampler(msg.Origin.LogSource, rule).keep(content, now) {
+		return true
+	}
+	metrics.LogsSampledOut.Add(1)
+	metrics.TlmLogsSampledOut.Inc(rule.Type)
+	return false
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+func TestSample(t *testing.T) {
+	p := &Processor{}
+	source := newFieldRulesSource(t, &config.ProcessingRule{Type: config.Sample, SampleRate: 0.1, Pattern: "DEBUG"})
+
+	kept := 0
+	for i := 0; i < 100; i++ {
+		if p.applyRedactingRules(newMessage([]byte("DEBUG a debug log"), source, "")) {
+			kept++
+		}
+	}
+	assert.Equal(t, 10, kept)
+
+	// logs not matching the pattern are not sampled
+	for i := 0; i < 10; i++ {
+		assert.True(t, p.applyRedactingRules(newMessage([]byte("INFO an info log"), source, "")))
+	}
+
+	info := source.GetInfoStatus()
+	assert.Equal(t, []string{"90 logs dropped"}, info["sample rule test"])
+}
+
+func TestRateLimit(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.RateLimit, MaxPerSecond: 2, Pattern: `user=(\w+)`}
+	source := newFieldRulesSource(t, rule)
+	sampler := getRuleSampler(source, rule)
+	assert.Same(t, sampler, getRuleSampler(source, rule))
+
+	now := time.Unix(1700000000, 0)
+	assert.True(t, sampler.keep([]byte("user=a"), now))
+	assert.True(t, sampler.keep([]byte("user=a"), now))
+	assert.False(t, sampler.keep([]byte("user=a"), now.Add(500*time.Millisecond)))
+	// groups have their own budget
+	assert.True(t, sampler.keep([]byte("user=b"), now))
+	// logs not matching the pattern are not limited
+	assert.True(t, sampler.keep([]byte("no user"), now))
+
+	// the budget is renewed every second
+	assert.True(t, sampler.keep([]byte("user=a"), now.Add(time.Second)))
+	assert.Equal(t, int64(1), sampler.dropped.Load())
+}
+
+func TestRateLimitGroupsAreBounded(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.RateLimit, MaxPerSecond: 1, Pattern: `id=(\S+)`}
+	require.NoError(t, config.CompileProcessingRules([]*config.ProcessingRule{rule}))
+	sampler := newRuleSampler(rule)
+
+	now := time.Unix(1700000000, 0)
+	for i := 0; i < maxRateLimitGroups; i++ {
+		assert.True(t, sampler.keep([]byte(fmt.Sprintf("id=%d", i)), now))
+	}
+	// additional groups share the same budget
+	assert.True(t, sampler.keep([]byte("id=-1"), now))
+	assert.False(t, sampler.keep([]byte("id=-2"), now))
+	assert.Len(t, sampler.counts, maxRateLimitGroups+1)
+}
+
+func TestSamplingRulesAreReportedInMetrics(t *testing.T) {
+	rule := &config.ProcessingRule{Type: config.RateLimit, MaxPerSecond: 1}
+	source := newFieldRulesSource(t, rule)
+	msg := newMessage([]byte("hello"), source, "")
+
+	now := time.Unix(1700000000, 0)
+	before := metrics.LogsSampledOut.Value()
+	assert.True(t, applySamplingRule(rule, msg, msg.GetContent(), now))
+	assert.False(t, applySamplingRule(rule, msg, msg.GetContent(), now.Add(500*time.Millisecond)))
+	assert.Equal(t, before+1, metrics.LogsSampledOut.Value())
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	pkgconfigmodel "github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	payloadFileExtension = ".payload"
+	tmpFileExtension     = ".tmp"
+)
+
+var (
+	tlmDiskBufferSpilled  = telemetry.NewCounter("logs_sender", "disk_buffer_spilled", []string{}, "Payloads stored in the disk buffer")
+	tlmDiskBufferReplayed = telemetry.NewCounter("logs_sender", "disk_buffer_replayed", []string{}, "Payloads replayed from the disk buffer")
+	tlmDiskBufferEvicted  = telemetry.NewCounter("logs_sender", "disk_buffer_evicted", []string{}, "Payloads evicted from the disk buffer when it is full")
+	tlmDiskBufferSize     = telemetry.NewGauge("logs_sender", "disk_buffer_size", []string{"pipeline"}, "Size in bytes of the payloads stored in the disk buffer")
+
+	errPayloadTooLarge = errors.New("payload is larger than the disk buffer")
+)
+
+// replayedSource is the source of the messages replayed from the disk buffer.
+// Their origin has no identifier so that the auditor does not commit their
+// offsets again, it was done when they were stored.
+var replayedSource = sources.NewLogSource("disk_buffer", &config.LogsConfig{})
+
+// storedPayload is the representation of a payload on disk.
+type storedPayload struct {
+	Encoded       []byte          `json:"encoded"`
+	Encoding      string          `json:"encoding"`
+	UnencodedSize int             `json:"unencoded_size"`
+	Messages      []storedMessage `json:"messages"`
+}
+
+// storedMessage keeps the encoded content of a message so that endpoints
+// which re-encode payloads can still send the replayed ones.
+type storedMessage struct {
+	Content            []byte `json:"content"`
+	Hostname           string `json:"hostname"`
+	Status             string `json:"status"`
+	IngestionTimestamp int64  `json:"ingestion_timestamp"`
+}
+
+type storedFile struct {
+	name string
+	size int64
+}
+
+// diskBuffer stores payloads on disk while the reliable destinations are
+// unreachable. Each payload is written in its own file, named after a sequence
+// number so that they are replayed oldest first, including after a restart.
+// When the buffer is full, the oldest payloads are evicted. It is not safe
+// for concurrent use, it is owned by the sender.
+type diskBuffer struct {
+	path    string
+	maxSize int64
+	files   []storedFile
+	size    int64
+	nextSeq uint64
+	// head caches the oldest payload while it can't be replayed
+	head        *message.Payload
+	pipelineTag string
+}
+
+// newSenderDiskBuffer returns the disk buffer of the given pipeline, or nil if
+// it is disabled or can't be used.
+func newSenderDiskBuffer(cfg pkgconfigmodel.Reader, pipelineID string) *diskBuffer {
+	if !cfg.GetBool("logs_config.disk_buffer.enabled") {
+		return nil
+	}
+	path := cfg.GetString("logs_config.disk_buffer.path")
+	if path == "" {
+		path = filepath.Join(cfg.GetString("logs_config.run_path"), "buffer")
+	}
+	// the size is shared by all the pipelines
+	maxSize := cfg.GetInt64("logs_config.disk_buffer.max_size") / int64(max(1, cfg.GetInt("logs_config.pipelines")))
+
+	buffer, err := newDiskBuffer(filepath.Join(path, pipelineID), maxSize, pipelineID)
+	if err != nil {
+		log.Errorf("Could not use the logs disk buffer, payloads won't be buffered during outages: %v", err)
+		return nil
+	}
+	if !buffer.empty() {
+		log.Infof("Replaying %d payloads from the logs disk buffer %s", len(buffer.files), buffer.path)
+	}
+	return buffer
+}
+
+// newDiskBuffer returns a disk buffer storing payloads in path, loading the
+// payloads stored by a previous run.
+func newDiskBuffer(path string, maxSize int64, pipelineTag string) (*diskBuffer, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("invalid disk buffer size %d", maxSize)
+	}
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &diskBuffer{
+		path:        path,
+		maxSize:     maxSize,
+		pipelineTag: pipelineTag,
+	}
+	// entries are sorted by name, hence by sequence number
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, tmpFileExtension) {
+			// interrupted write
+			_ = os.Remove(filepath.Join(path, name))
+			continue
+		}
+		if !strings.HasSuffix(name, payloadFileExtension) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, payloadFileExtension), 16, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		b.files = append(b.files, storedFile{name: name, size: info.Size()})
+		b.size += info.Size()
+		b.nextSeq = seq + 1
+	}
+	tlmDiskBufferSize.Set(float64(b.size), b.pipelineTag)
+	return b, nil
+}
+
+// empty returns true if no payload is stored.
+func (b *diskBuffer) empty() bool {
+	return len(b.files) == 0
+}
+
+// push durably stores the payload, evicting the oldest ones if needed.
+func (b *diskBuffer) push(payload *message.Payload) error {
+	stored := storedPayload{
+		Encoded:       payload.Encoded,
+		Encoding:      payload.Encoding,
+		UnencodedSize: payload.UnencodedSize,
+		Messages:      make([]storedMessage, 0, len(payload.Messages)),
+	}
+	for _, msg := range payload.Messages {
+		stored.Messages = append(stored.Messages, storedMessage{
+			Content:            msg.GetContent(),
+			Hostname:           msg.Hostname,
+			Status:             msg.Status,
+			IngestionTimestamp: msg.IngestionTimestamp,
+		})
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	size := int64(len(data))
+	if size > b.maxSize {
+		return errPayloadTooLarge
+	}
+	for b.size+size > b.maxSize {
+		log.Warnf("Logs disk buffer %s is full, dropping its oldest payload", b.path)
+		b.pop()
+		tlmDiskBufferEvicted.Inc()
+	}
+
+	name := fmt.Sprintf("%016x%s", b.nextSeq, payloadFileExtension)
+	if err := writeFileSync(filepath.Join(b.path, name), data); err != nil {
+		return err
+	}
+	b.nextSeq++
+	b.files = append(b.files, storedFile{name: name, size: size})
+	b.size += size
+	tlmDiskBufferSize.Set(float64(b.size), b.pipelineTag)
+	tlmDiskBufferSpilled.Inc()
+	return nil
+}
+
+// peek returns the oldest payload. An error is returned if it can't be read,
+// it should then be popped.
+func (b *diskBuffer) peek() (*message.Payload, error) {
+	if b.head != nil {
+		return b.head, nil
+	}
+	data, err := os.ReadFile(filepath.Join(b.path, b.files[0].name))
+	if err != nil {
+		return nil, err
+	}
+	var stored storedPayload
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+
+	payload := &message.Payload{
+		Encoded:       stored.Encoded,
+		Encoding:      stored.Encoding,
+		UnencodedSize: stored.UnencodedSize,
+		Messages:      make([]*message.Message, 0, len(stored.Messages)),
+	}
+	for _, m := range stored.Messages {
+		msg := message.NewMessage(nil, message.NewOrigin(replayedSource), m.Status, m.IngestionTimestamp)
+		msg.SetEncoded(m.Content)
+		msg.Hostname = m.Hostname
+		payload.Messages = append(payload.Messages, msg)
+	}
+	b.head = payload
+	return payload, nil
+}
+
+// pop removes the oldest payload.
+func (b *diskBuffer) pop() {
+	if b.empty() {
+		return
+	}
+	if err := os.Remove(filepath.Join(b.path, b.files[0].name)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove payload from the logs disk buffer: %v", err)
+	}
+	b.size -= b.files[0].size
+	b.files = b.files[1:]
+	b.head = nil
+	tlmDiskBufferSize.Set(float64(b.size), b.pipelineTag)
+}
+
+// writeFileSync writes data to the file at path, which exists only once its
+// content has been flushed to disk.
+func writeFileSync(path string, data []byte) error {
+	tmpPath := path + tmpFileExtension
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
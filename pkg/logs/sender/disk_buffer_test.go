@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sender
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newEncodedPayload(content string) *message.Payload {
+	msg := message.NewMessage(nil, nil, message.StatusInfo, 42)
+	msg.SetEncoded([]byte(content))
+	msg.Hostname = "host"
+	return &message.Payload{
+		Messages:      []*message.Message{msg},
+		Encoded:       []byte("[" + content + "]"),
+		Encoding:      "identity",
+		UnencodedSize: len(content) + 2,
+	}
+}
+
+func TestDiskBufferPushPeekPop(t *testing.T) {
+	b, err := newDiskBuffer(t.TempDir(), 1024*1024, "0")
+	require.NoError(t, err)
+	assert.True(t, b.empty())
+
+	require.NoError(t, b.push(newEncodedPayload("a")))
+	require.NoError(t, b.push(newEncodedPayload("b")))
+	assert.False(t, b.empty())
+
+	payload, err := b.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[a]"), payload.Encoded)
+	assert.Equal(t, "identity", payload.Encoding)
+	assert.Equal(t, 3, payload.UnencodedSize)
+	require.Len(t, payload.Messages, 1)
+	assert.Equal(t, []byte("a"), payload.Messages[0].GetContent())
+	assert.Equal(t, message.StateEncoded, payload.Messages[0].State)
+	assert.Equal(t, message.StatusInfo, payload.Messages[0].Status)
+	assert.Equal(t, "host", payload.Messages[0].Hostname)
+	assert.Equal(t, int64(42), payload.Messages[0].IngestionTimestamp)
+	// offsets are not committed again
+	assert.Empty(t, payload.Messages[0].Origin.Identifier)
+	assert.NotNil(t, payload.Messages[0].Origin.LogSource.Config)
+
+	b.pop()
+	payload, err = b.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[b]"), payload.Encoded)
+
+	b.pop()
+	assert.True(t, b.empty())
+	assert.Zero(t, b.size)
+	entries, err := os.ReadDir(b.path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestDiskBufferReloadsStoredPayloads(t *testing.T) {
+	path := t.TempDir()
+	b, err := newDiskBuffer(path, 1024*1024, "0")
+	require.NoError(t, err)
+	for _, content := range []string{"a", "b", "c"} {
+		require.NoError(t, b.push(newEncodedPayload(content)))
+	}
+	// leftover of an interrupted write
+	require.NoError(t, os.WriteFile(filepath.Join(path, "0000000000000003.payload.tmp"), []byte("{"), 0600))
+
+	b, err = newDiskBuffer(path, 1024*1024, "0")
+	require.NoError(t, err)
+	assert.Len(t, b.files, 3)
+	assert.Equal(t, uint64(3), b.nextSeq)
+	assert.NoFileExists(t, filepath.Join(path, "0000000000000003.payload.tmp"))
+
+	for _, content := range []string{"a", "b", "c"} {
+		payload, err := b.peek()
+		require.NoError(t, err)
+		assert.Equal(t, []byte("["+content+"]"), payload.Encoded)
+		b.pop()
+	}
+	assert.True(t, b.empty())
+}
+
+func TestDiskBufferEvictsOldestPayloads(t *testing.T) {
+	b, err := newDiskBuffer(t.TempDir(), 1024*1024, "0")
+	require.NoError(t, err)
+	require.NoError(t, b.push(newEncodedPayload("a")))
+	// room for two payloads
+	b.maxSize = 2 * b.size
+
+	require.NoError(t, b.push(newEncodedPayload("b")))
+	require.NoError(t, b.push(newEncodedPayload("c")))
+	assert.Len(t, b.files, 2)
+	assert.LessOrEqual(t, b.size, b.maxSize)
+
+	payload, err := b.peek()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("[b]"), payload.Encoded)
+}
+
+func TestDiskBufferRejectsPayloadsLargerThanItsSize(t *testing.T) {
+	b, err := newDiskBuffer(t.TempDir(), 10, "0")
+	require.NoError(t, err)
+	assert.ErrorIs(t, b.push(newEncodedPayload("a")), errPayloadTooLarge)
+	assert.True(t, b.empty())
+}
+
+func TestDiskBufferDropsUnreadablePayloads(t *testing.T) {
+	b, err := newDiskBuffer(t.TempDir(), 1024*1024, "0")
+	require.NoError(t, err)
+	require.NoError(t, b.push(newEncodedPayload("a")))
+	require.NoError(t, os.WriteFile(filepath.Join(b.path, b.files[0].name), []byte("{"), 0600))
+
+	_, err = b.peek()
+	assert.Error(t, err)
+	b.pop()
+	assert.True(t, b.empty())
+}
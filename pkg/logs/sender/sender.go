@@ -15,8 +15,13 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// diskBufferReplayInterval is the interval at which the sender tries to replay
+// the payloads of the disk buffer when it does not receive new ones.
+const diskBufferReplayInterval = time.Second
+
 var (
 	tlmPayloadsDropped = telemetry.NewCounterWithOpts("logs_sender", "payloads_dropped", []string{"reliable", "destination"}, "Payloads dropped", telemetry.Options{DefaultMetric: true})
 	tlmMessagesDropped = telemetry.NewCounterWithOpts("logs_sender", "messages_dropped", []string{"reliable", "destination"}, "Messages dropped", telemetry.Options{DefaultMetric: true})
@@ -39,6 +44,9 @@ type Sender struct {
 	bufferSize     int
 	senderDoneChan chan *sync.WaitGroup
 	flushWg        *sync.WaitGroup
+	// diskBuffer stores the payloads while the reliable destinations are
+	// unreachable, nil when disabled.
+	diskBuffer *diskBuffer
 
 	pipelineMonitor metrics.PipelineMonitor
 	utilization     metrics.UtilizationMonitor
@@ -46,6 +54,12 @@ type Sender struct {
 
 // NewSender returns a new sender.
 func NewSender(config pkgconfigmodel.Reader, inputChan chan *message.Payload, outputChan chan *message.Payload, destinations *client.Destinations, bufferSize int, senderDoneChan chan *sync.WaitGroup, flushWg *sync.WaitGroup, pipelineMonitor metrics.PipelineMonitor) *Sender {
+	var buffer *diskBuffer
+	// serverless flushes synchronously, payloads can't be kept for later
+	if senderDoneChan == nil && config != nil {
+		buffer = newSenderDiskBuffer(config, pipelineMonitor.ID())
+	}
+
 	return &Sender{
 		config:         config,
 		inputChan:      inputChan,
@@ -55,6 +69,7 @@ func NewSender(config pkgconfigmodel.Reader, inputChan chan *message.Payload, ou
 		bufferSize:     bufferSize,
 		senderDoneChan: senderDoneChan,
 		flushWg:        flushWg,
+		diskBuffer:     buffer,
 
 		// Telemetry
 		pipelineMonitor: pipelineMonitor,
@@ -80,13 +95,53 @@ func (s *Sender) run() {
 	sink := additionalDestinationsSink(s.bufferSize)
 	unreliableDestinations := buildDestinationSenders(s.config, s.destinations.Unreliable, sink, s.bufferSize)
 
-	for payload := range s.inputChan {
-		s.utilization.Start()
-		var startInUse = time.Now()
-		senderDoneWg := &sync.WaitGroup{}
+	var replayTicker <-chan time.Time
+	if s.diskBuffer != nil {
+		ticker := time.NewTicker(diskBufferReplayInterval)
+		defer ticker.Stop()
+		replayTicker = ticker.C
+	}
 
-		sent := false
-		for !sent {
+loop:
+	for {
+		select {
+		case payload, isOpen := <-s.inputChan:
+			if !isOpen {
+				break loop
+			}
+			s.send(payload, reliableDestinations, unreliableDestinations)
+		case <-replayTicker:
+			s.replay(reliableDestinations)
+		}
+	}
+
+	// Cleanup the destinations
+	for _, destSender := range reliableDestinations {
+		destSender.Stop()
+	}
+	for _, destSender := range unreliableDestinations {
+		destSender.Stop()
+	}
+	close(sink)
+	s.done <- struct{}{}
+}
+
+// send sends the payload to the reliable destinations, blocking until one of
+// them accepts it or it is stored in the disk buffer, and to the unreliable
+// ones if they have room for it.
+func (s *Sender) send(payload *message.Payload, reliableDestinations []*DestinationSender, unreliableDestinations []*DestinationSender) {
+	s.utilization.Start()
+	var startInUse = time.Now()
+	senderDoneWg := &sync.WaitGroup{}
+
+	sent, spilled := false, false
+	for !sent && !spilled {
+		if s.diskBuffer != nil {
+			// payloads are sent in order, the stored ones go first
+			s.replay(reliableDestinations)
+		}
+
+		if s.diskBuffer == nil || s.diskBuffer.empty() {
 			for _, destSender := range reliableDestinations {
 				if destSender.Send(payload) {
 					if destSender.destination.Metadata().ReportingEnabled {
@@ -99,60 +154,101 @@ func (s *Sender) run() {
 					}
 				}
 			}
+		}
 
-			if !sent {
-				// Throttle the poll loop while waiting for a send to succeed
-				// This will only happen when all reliable destinations
-				// are blocked so logs have no where to go.
-				time.Sleep(100 * time.Millisecond)
-			}
+		if !sent {
+			spilled = s.spill(payload)
 		}
 
-		for i, destSender := range reliableDestinations {
-			// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
-			// loss on intermittent failures.
-			if !destSender.lastSendSucceeded {
-				if !destSender.NonBlockingSend(payload) {
-					tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
-					tlmMessagesDropped.Add(float64(len(payload.Messages)), "true", strconv.Itoa(i))
-				}
-			}
+		if !sent && !spilled {
+			// Throttle the poll loop while waiting for a send to succeed
+			// This will only happen when all reliable destinations
+			// are blocked so logs have no where to go.
+			time.Sleep(100 * time.Millisecond)
 		}
+	}
 
-		// Attempt to send to unreliable destinations
-		for i, destSender := range unreliableDestinations {
+	for i, destSender := range reliableDestinations {
+		// If an endpoint is stuck in the previous step, try to buffer the payloads if we have room to mitigate
+		// loss on intermittent failures. Stored payloads are replayed to all of them later on.
+		if sent && !destSender.lastSendSucceeded {
 			if !destSender.NonBlockingSend(payload) {
-				tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
-				tlmMessagesDropped.Add(float64(len(payload.Messages)), "false", strconv.Itoa(i))
-				if s.senderDoneChan != nil {
-					senderDoneWg.Add(1)
-					s.senderDoneChan <- senderDoneWg
-				}
+				tlmPayloadsDropped.Inc("true", strconv.Itoa(i))
+				tlmMessagesDropped.Add(float64(len(payload.Messages)), "true", strconv.Itoa(i))
 			}
 		}
+	}
 
-		inUse := float64(time.Since(startInUse) / time.Millisecond)
-		tlmSendWaitTime.Add(inUse)
-		s.utilization.Stop()
-
-		if s.senderDoneChan != nil && s.flushWg != nil {
-			// Wait for all destinations to finish sending the payload
-			senderDoneWg.Wait()
-			// Decrement the wait group when this payload has been sent
-			s.flushWg.Done()
+	// Attempt to send to unreliable destinations
+	for i, destSender := range unreliableDestinations {
+		if !destSender.NonBlockingSend(payload) {
+			tlmPayloadsDropped.Inc("false", strconv.Itoa(i))
+			tlmMessagesDropped.Add(float64(len(payload.Messages)), "false", strconv.Itoa(i))
+			if s.senderDoneChan != nil {
+				senderDoneWg.Add(1)
+				s.senderDoneChan <- senderDoneWg
+			}
 		}
-		s.pipelineMonitor.ReportComponentEgress(payload, "sender")
 	}
 
-	// Cleanup the destinations
-	for _, destSender := range reliableDestinations {
-		destSender.Stop()
+	inUse := float64(time.Since(startInUse) / time.Millisecond)
+	tlmSendWaitTime.Add(inUse)
+	s.utilization.Stop()
+
+	if s.senderDoneChan != nil && s.flushWg != nil {
+		// Wait for all destinations to finish sending the payload
+		senderDoneWg.Wait()
+		// Decrement the wait group when this payload has been sent
+		s.flushWg.Done()
 	}
-	for _, destSender := range unreliableDestinations {
-		destSender.Stop()
+	s.pipelineMonitor.ReportComponentEgress(payload, "sender")
+}
+
+// spill stores the payload in the disk buffer and reports it to the auditor
+// since it is durable, it returns false if it could not be stored.
+func (s *Sender) spill(payload *message.Payload) bool {
+	if s.diskBuffer == nil {
+		return false
+	}
+	if err := s.diskBuffer.push(payload); err != nil {
+		log.Warnf("Could not store payload in the logs disk buffer: %v", err)
+		return false
+	}
+	s.outputChan <- payload
+	return true
+}
+
+// replay sends the payloads of the disk buffer to the reliable destinations,
+// oldest first, until none of them accepts more.
+func (s *Sender) replay(reliableDestinations []*DestinationSender) {
+	for !s.diskBuffer.empty() {
+		payload, err := s.diskBuffer.peek()
+		if err != nil {
+			log.Warnf("Dropping unreadable payload from the logs disk buffer: %v", err)
+			s.diskBuffer.pop()
+			continue
+		}
+
+		sent := false
+		for _, destSender := range reliableDestinations {
+			if destSender.Send(payload) {
+				if destSender.destination.Metadata().ReportingEnabled {
+					s.pipelineMonitor.ReportComponentIngress(payload, destSender.destination.Metadata().MonitorTag())
+				}
+				sent = true
+			}
+		}
+		if !sent {
+			return
+		}
+		for _, destSender := range reliableDestinations {
+			if !destSender.lastSendSucceeded {
+				destSender.NonBlockingSend(payload)
+			}
+		}
+		s.diskBuffer.pop()
+		tlmDiskBufferReplayed.Inc()
 	}
-	close(sink)
-	s.done <- struct{}{}
 }
 
 // Drains the output channel from destinations that don't update the auditor.
@@ -6,9 +6,13 @@
 package sender
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
@@ -317,3 +321,66 @@ func TestSenderReliableWhenOneFailsAndRecovers(t *testing.T) {
 	reliableServer2.Stop()
 	sender.Stop()
 }
+
+// startedDestination signals when the sender started the destination.
+type startedDestination struct {
+	*mockDestination
+	started chan struct{}
+}
+
+func (d *startedDestination) Start(input chan *message.Payload, output chan *message.Payload, isRetrying chan bool) (stopChan <-chan struct{}) {
+	defer close(d.started)
+	return d.mockDestination.Start(input, output, isRetrying)
+}
+
+func TestSenderDiskBuffer(t *testing.T) {
+	path := t.TempDir()
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("logs_config.disk_buffer.enabled", true)
+	cfg.SetWithoutSource("logs_config.disk_buffer.path", path)
+	cfg.SetWithoutSource("logs_config.pipelines", 1)
+
+	input := make(chan *message.Payload, 1)
+	output := make(chan *message.Payload, 1)
+
+	dest := &startedDestination{mockDestination: &mockDestination{}, started: make(chan struct{})}
+	destinations := client.NewDestinations([]client.Destination{dest}, nil)
+
+	sender := NewSender(cfg, input, output, destinations, 0, nil, nil, metrics.NewNoopPipelineMonitor("0"))
+	require.NotNil(t, sender.diskBuffer)
+	sender.Start()
+	<-dest.started
+
+	input <- newEncodedPayload("a")
+	assert.Equal(t, []byte("[a]"), (<-dest.input).Encoded)
+
+	// the destination is unreachable, payloads are stored on disk and
+	// reported to the auditor
+	dest.isRetrying <- true
+	for _, content := range []string{"b", "c"} {
+		payload := newEncodedPayload(content)
+		input <- payload
+		assert.Equal(t, payload, <-output)
+	}
+	entries, err := os.ReadDir(filepath.Join(path, "0"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// the destination recovers, stored payloads are replayed in order
+	dest.isRetrying <- false
+	for _, content := range []string{"b", "c"} {
+		payload := <-dest.input
+		assert.Equal(t, []byte("["+content+"]"), payload.Encoded)
+		assert.Equal(t, []byte(content), payload.Messages[0].GetContent())
+	}
+	assert.Eventually(t, func() bool {
+		entries, err := os.ReadDir(filepath.Join(path, "0"))
+		return err == nil && len(entries) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	input <- newEncodedPayload("d")
+	assert.Equal(t, []byte("[d]"), (<-dest.input).Encoded)
+
+	close(dest.stopChan)
+	sender.Stop()
+}
@@ -69,6 +69,10 @@ func NewLogSource(name string, cfg *config.LogsConfig) *LogSource {
 	}
 	source.RegisterInfo(source.BytesRead)
 	source.RegisterInfo(source.LatencyStats)
+	if cfg != nil {
+		// invalid settings are reported when the config is validated
+		_ = cfg.CompileTimestamp()
+	}
 	return source
 }
 
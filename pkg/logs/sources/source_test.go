@@ -10,6 +10,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 )
 
 type LogSourceSuite struct {
@@ -35,6 +37,12 @@ func (s *LogSourceSuite) TestDump() {
 	assert.Contains(s.T(), dump, "mysource")
 }
 
+func (s *LogSourceSuite) TestCompilesTimestamp() {
+	cfg := &config.LogsConfig{Type: config.FileType, Path: "/var/log/foo.log", TimestampPattern: `^(\S+)`, TimestampFormat: "rfc3339"}
+	s.source = NewLogSource("", cfg)
+	s.True(s.source.Config.HasTimestampPattern())
+}
+
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
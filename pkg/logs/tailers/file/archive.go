@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/util/filesystem"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// ArchiveConsumedOffset is the offset committed in the registry once an
+// archive has been entirely read, it is never read again.
+const ArchiveConsumedOffset = "consumed"
+
+// archiveFingerprintSize is the number of bytes used to identify an archive.
+const archiveFingerprintSize = 1024
+
+// archiveReaders returns the decompressing readers of archives by extension.
+var archiveReaders = map[string]func(io.Reader) (io.ReadCloser, error){
+	".gz": func(r io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(r)
+	},
+	".zst": func(r io.Reader) (io.ReadCloser, error) {
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	},
+}
+
+// IsArchive returns true if the file is a compressed archive that its source
+// reads once, from the beginning.
+func (t *File) IsArchive() bool {
+	if t.Source == nil || t.Source.Config() == nil || !t.Source.Config().ReadCompressed {
+		return false
+	}
+	_, ok := archiveReaders[strings.ToLower(filepath.Ext(t.Path))]
+	return ok
+}
+
+// ArchiveIdentifier returns the identifier of the archive at path in the
+// registry. It is computed from the first bytes of the archive rather than
+// its path so that archives renamed by a log rotation are not read again.
+func ArchiveIdentifier(path string) (string, error) {
+	f, err := filesystem.OpenShared(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.CopyN(h, f, archiveFingerprintSize); err != nil && err != io.EOF {
+		return "", err
+	}
+	return fmt.Sprintf("archive:%x", h.Sum(nil)[:16]), nil
+}
+
+// setupArchive opens the archive of the tailer, skipping the first offset
+// decompressed bytes which were already sent.
+func (t *Tailer) setupArchive(offset int64) error {
+	fullpath, err := filepath.Abs(t.file.Path)
+	if err != nil {
+		return err
+	}
+	t.fullpath = fullpath
+	t.tags = t.buildTailerTags()
+
+	t.archiveID, err = ArchiveIdentifier(fullpath)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Opening archive", t.file.Path, "for tailer key", t.file.GetScanKey())
+	f, err := filesystem.OpenShared(fullpath)
+	if err != nil {
+		return err
+	}
+	reader, err := archiveReaders[strings.ToLower(filepath.Ext(fullpath))](f)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("could not decompress %s: %w", t.file.Path, err)
+	}
+	if offset > 0 {
+		if offset, err = io.CopyN(io.Discard, reader, offset); err != nil && err != io.EOF {
+			reader.Close()
+			f.Close()
+			return fmt.Errorf("could not decompress %s: %w", t.file.Path, err)
+		}
+	}
+
+	t.osFile = f
+	t.archive = reader
+	t.lastReadOffset.Store(offset)
+	t.decodedOffset.Store(offset)
+	return nil
+}
+
+// readArchive reads the next decompressed bytes of the archive, io.EOF is
+// returned once it has been entirely read.
+func (t *Tailer) readArchive() (int, error) {
+	inBuf := make([]byte, 4096)
+	n, err := t.archive.Read(inBuf)
+	if n > 0 {
+		t.lastReadOffset.Add(int64(n))
+		t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+	}
+	switch {
+	case err == io.EOF && n == 0:
+		log.Info("Archive", t.file.Path, "has been entirely read")
+		t.archiveConsumed.Store(true)
+		return 0, err
+	case err != nil && err != io.EOF:
+		t.file.Source.Status().Error(err)
+		return 0, log.Error("Unexpected error occurred while reading archive: ", err)
+	}
+	return n, nil
+}
+
+// IsArchiveConsumed returns true if the archive of the tailer has been
+// entirely read.
+func (t *Tailer) IsArchiveConsumed() bool {
+	return t.archiveConsumed.Load()
+}
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	status "github.com/DataDog/datadog-agent/pkg/logs/status/utils"
+)
+
+var archiveWriters = map[string]func(io.Writer) io.WriteCloser{
+	".gz": func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	},
+	".zst": func(w io.Writer) io.WriteCloser {
+		zw, _ := zstd.NewWriter(w)
+		return zw
+	},
+}
+
+func writeArchive(t *testing.T, path string, content string) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	w := archiveWriters[filepath.Ext(path)](f)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func newArchiveTailer(path string, readCompressed bool) (*Tailer, chan *message.Message) {
+	source := sources.NewReplaceableSource(sources.NewLogSource("", &config.LogsConfig{
+		Type:           config.FileType,
+		Path:           path,
+		ReadCompressed: readCompressed,
+	}))
+	info := status.NewInfoRegistry()
+	outputChan := make(chan *message.Message, 10)
+	return NewTailer(&TailerOptions{
+		OutputChan:      outputChan,
+		File:            NewFile(path, source.UnderlyingSource(), false),
+		SleepDuration:   10 * time.Millisecond,
+		Decoder:         decoder.NewDecoderFromSource(source, info),
+		Info:            info,
+		PipelineMonitor: metrics.NewNoopPipelineMonitor(""),
+	}), outputChan
+}
+
+func TestIsArchive(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"/var/log/app.log.1.gz": true,
+		"/var/log/app.log.zst":  true,
+		"/var/log/APP.LOG.GZ":   true,
+		"/var/log/app.log":      false,
+		"/var/log/app.log.1":    false,
+		"/var/log/app.tar.bz2":  false,
+	} {
+		tailer, _ := newArchiveTailer(path, true)
+		assert.Equal(t, expected, tailer.file.IsArchive(), path)
+	}
+
+	tailer, _ := newArchiveTailer("/var/log/app.log.1.gz", false)
+	assert.False(t, tailer.file.IsArchive())
+}
+
+func TestArchiveIdentifierDoesNotDependOnPath(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log.1.gz")
+	writeArchive(t, path, "line 1\n")
+
+	id, err := ArchiveIdentifier(path)
+	require.NoError(t, err)
+	assert.Regexp(t, "^archive:[0-9a-f]{32}$", id)
+
+	rotatedPath := filepath.Join(dir, "app.log.2.gz")
+	require.NoError(t, os.Rename(path, rotatedPath))
+	rotatedID, err := ArchiveIdentifier(rotatedPath)
+	require.NoError(t, err)
+	assert.Equal(t, id, rotatedID)
+
+	writeArchive(t, path, "line 2\n")
+	otherID, err := ArchiveIdentifier(path)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherID)
+}
+
+func TestTailerReadsArchive(t *testing.T) {
+	for ext := range archiveWriters {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log"+ext)
+			writeArchive(t, path, "line 1\nline 2\nline 3\n")
+			id, err := ArchiveIdentifier(path)
+			require.NoError(t, err)
+
+			tailer, outputChan := newArchiveTailer(path, true)
+			require.NoError(t, tailer.StartFromBeginning())
+			defer tailer.Stop()
+
+			for i, expected := range []struct{ content, offset string }{
+				{"line 1", "7"},
+				{"line 2", "14"},
+				{"line 3", "21"},
+			} {
+				msg := <-outputChan
+				assert.Equal(t, expected.content, string(msg.GetContent()), i)
+				assert.Equal(t, expected.offset, msg.Origin.Offset, i)
+				assert.Equal(t, id, msg.Origin.Identifier, i)
+			}
+			assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+			assert.True(t, tailer.IsArchiveConsumed())
+		})
+	}
+}
+
+func TestTailerResumesArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	writeArchive(t, path, "line 1\nline 2\nline 3\n")
+
+	tailer, outputChan := newArchiveTailer(path, true)
+	require.NoError(t, tailer.Start(7, io.SeekStart))
+	defer tailer.Stop()
+
+	msg := <-outputChan
+	assert.Equal(t, "line 2", string(msg.GetContent()))
+	assert.Equal(t, "14", msg.Origin.Offset)
+	msg = <-outputChan
+	assert.Equal(t, "line 3", string(msg.GetContent()))
+	assert.Equal(t, "21", msg.Origin.Offset)
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, tailer.IsArchiveConsumed())
+}
+
+func TestTailerDoesNotConsumeCorruptedArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	writeArchive(t, path, "line 1\nline 2\nline 3\n")
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, content[:len(content)-8], 0644))
+
+	tailer, _ := newArchiveTailer(path, true)
+	require.NoError(t, tailer.StartFromBeginning())
+	defer tailer.Stop()
+
+	assert.Eventually(t, tailer.IsFinished, 5*time.Second, 10*time.Millisecond)
+	assert.False(t, tailer.IsArchiveConsumed())
+}
@@ -62,6 +62,16 @@ type Tailer struct {
 	// is platform-specific.
 	osFile *os.File
 
+	// archive is the decompressed content of osFile when the file is an
+	// archive, which is read once from the beginning.
+	archive io.ReadCloser
+
+	// archiveID is the identifier of the archive in the registry.
+	archiveID string
+
+	// archiveConsumed is true once the archive has been entirely read.
+	archiveConsumed *atomic.Bool
+
 	// tags are the tags to be attached to each log message, excluding tags provided
 	// by the tag provider.
 	tags []string
@@ -181,6 +191,7 @@ func NewTailer(opts *TailerOptions) *Tailer {
 		stopForward:            stopForward,
 		isFinished:             atomic.NewBool(false),
 		didFileRotate:          atomic.NewBool(false),
+		archiveConsumed:        atomic.NewBool(false),
 		info:                   opts.Info,
 		bytesRead:              bytesRead,
 		movingSum:              movingSum,
@@ -227,12 +238,21 @@ func (t *Tailer) Identifier() string {
 	//
 	// This is the identifier used in the registry, so changing it will invalidate existing
 	// registry entries on upgrade.
+	if t.archiveID != "" {
+		return t.archiveID
+	}
 	return fmt.Sprintf("file:%s", t.file.Path)
 }
 
-// Start begins the tailer's operation in a dedicated goroutine.
+// Start begins the tailer's operation in a dedicated goroutine. Archives are
+// read from the given offset in their decompressed content.
 func (t *Tailer) Start(offset int64, whence int) error {
-	err := t.setup(offset, whence)
+	var err error
+	if t.file.IsArchive() {
+		err = t.setupArchive(offset)
+	} else {
+		err = t.setup(offset, whence)
+	}
 	if err != nil {
 		t.file.Source.Status().Error(err)
 		return err
@@ -289,14 +309,22 @@ func (t *Tailer) StopAfterFileRotation() {
 // readForever lets the tailer tail the content of a file
 // until it is closed or the tailer is stopped.
 func (t *Tailer) readForever() {
+	read := t.read
+	if t.archive != nil {
+		read = t.readArchive
+	}
+
 	defer func() {
+		if t.archive != nil {
+			t.archive.Close()
+		}
 		t.osFile.Close()
 		t.decoder.Stop()
 		log.Info("Closed", t.file.Path, "for tailer key", t.file.GetScanKey(), "read", t.Source().BytesRead.Get(), "bytes and", t.decoder.GetLineCount(), "lines")
 	}()
 
 	for {
-		n, err := t.read()
+		n, err := read()
 		if err != nil {
 			return
 		}
@@ -365,15 +393,20 @@ func (t *Tailer) forwardMessages() {
 		}
 
 		msg := message.NewMessage(output.GetContent(), origin, output.Status, output.IngestionTimestamp)
-		// Make the write to the output chan cancellable to be able to stop the tailer
-		// after a file rotation when it is stuck on it.
-		// We don't return directly to keep the same shutdown sequence that in the
-		// normal case.
-		select {
-		case t.outputChan <- msg:
-			t.PipelineMonitor.ReportComponentIngress(msg, "processor")
-		case <-t.forwardContext.Done():
-		}
+		t.forwardMessage(msg)
+	}
+}
+
+// forwardMessage sends the message to the output channel.
+func (t *Tailer) forwardMessage(msg *message.Message) {
+	// Make the write to the output chan cancellable to be able to stop the tailer
+	// after a file rotation when it is stuck on it.
+	// We don't return directly to keep the same shutdown sequence that in the
+	// normal case.
+	select {
+	case t.outputChan <- msg:
+		t.PipelineMonitor.ReportComponentIngress(msg, "processor")
+	case <-t.forwardContext.Done():
 	}
 }
 
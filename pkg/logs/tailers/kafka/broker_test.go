@@ -0,0 +1,413 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// fakeBrokerVersions are the versions of the requests supported by the fake
+// broker, they are the ones needed to consume as a member of a group.
+var fakeBrokerVersions = map[int16][2]int16{
+	kmsg.Fetch.Int16():           {4, 6},
+	kmsg.ListOffsets.Int16():     {1, 3},
+	kmsg.Metadata.Int16():        {1, 6},
+	kmsg.OffsetCommit.Int16():    {2, 6},
+	kmsg.OffsetFetch.Int16():     {1, 5},
+	kmsg.FindCoordinator.Int16(): {0, 2},
+	kmsg.JoinGroup.Int16():       {0, 5},
+	kmsg.Heartbeat.Int16():       {0, 3},
+	kmsg.LeaveGroup.Int16():      {0, 2},
+	kmsg.SyncGroup.Int16():       {0, 3},
+	kmsg.ApiVersions.Int16():     {0, 3},
+}
+
+type fakeRecord struct {
+	value   []byte
+	headers []kmsg.Header
+}
+
+// fakeBroker is an in-process single node Kafka cluster, acting as the
+// coordinator of groups of a single member.
+type fakeBroker struct {
+	listener net.Listener
+	host     string
+	port     int32
+
+	mu         sync.Mutex
+	records    map[string][][]fakeRecord
+	committed  map[string]map[string]map[int32]int64
+	generation int32
+	memberSeq  int
+	assignment []byte
+}
+
+// newFakeBroker starts a broker serving the given topics.
+func newFakeBroker(t *testing.T, partitions int, topics ...string) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	b := &fakeBroker{
+		listener:  listener,
+		host:      host,
+		port:      int32(portNumber),
+		records:   make(map[string][][]fakeRecord),
+		committed: make(map[string]map[string]map[int32]int64),
+	}
+	for _, topic := range topics {
+		b.records[topic] = make([][]fakeRecord, partitions)
+	}
+	go b.serve()
+	t.Cleanup(func() { listener.Close() })
+	return b
+}
+
+func (b *fakeBroker) addr() string {
+	return b.listener.Addr().String()
+}
+
+// produce appends a record to a partition.
+func (b *fakeBroker) produce(topic string, partition int32, value string, headers ...kmsg.Header) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records[topic][partition] = append(b.records[topic][partition], fakeRecord{value: []byte(value), headers: headers})
+}
+
+// committedOffset returns the offset committed by a group, -1 if there is none.
+func (b *fakeBroker) committedOffset(group, topic string, partition int32) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if offset, ok := b.committed[group][topic][partition]; ok {
+		return offset
+	}
+	return -1
+}
+
+func (b *fakeBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+// handle answers the requests received on a connection, in order.
+func (b *fakeBroker) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		var size int32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return
+		}
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return
+		}
+
+		key := int16(binary.BigEndian.Uint16(buf))
+		version := int16(binary.BigEndian.Uint16(buf[2:]))
+		correlationID := buf[4:8]
+		clientIDLength := int16(binary.BigEndian.Uint16(buf[8:]))
+		body := buf[10:]
+		if clientIDLength > 0 {
+			body = body[clientIDLength:]
+		}
+
+		req := kmsg.RequestForKey(key)
+		if req == nil {
+			return
+		}
+		req.SetVersion(version)
+		if req.IsFlexible() {
+			// empty tagged fields of the header
+			body = body[1:]
+		}
+		if err := req.ReadFrom(body); err != nil {
+			return
+		}
+
+		resp := b.respond(req)
+		if resp == nil {
+			return
+		}
+		out := append([]byte{0, 0, 0, 0}, correlationID...)
+		if resp.IsFlexible() && key != kmsg.ApiVersions.Int16() {
+			out = append(out, 0)
+		}
+		out = resp.AppendTo(out)
+		binary.BigEndian.PutUint32(out, uint32(len(out)-4))
+		if _, err := conn.Write(out); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) respond(req kmsg.Request) kmsg.Response {
+	switch req := req.(type) {
+	case *kmsg.ApiVersionsRequest:
+		resp := req.ResponseKind().(*kmsg.ApiVersionsResponse)
+		for key, versions := range fakeBrokerVersions {
+			apiKey := kmsg.NewApiVersionsResponseApiKey()
+			apiKey.ApiKey, apiKey.MinVersion, apiKey.MaxVersion = key, versions[0], versions[1]
+			resp.ApiKeys = append(resp.ApiKeys, apiKey)
+		}
+		return resp
+	case *kmsg.MetadataRequest:
+		return b.metadata(req)
+	case *kmsg.FindCoordinatorRequest:
+		resp := req.ResponseKind().(*kmsg.FindCoordinatorResponse)
+		resp.NodeID, resp.Host, resp.Port = 0, b.host, b.port
+		return resp
+	case *kmsg.JoinGroupRequest:
+		return b.joinGroup(req)
+	case *kmsg.SyncGroupRequest:
+		return b.syncGroup(req)
+	case *kmsg.HeartbeatRequest:
+		return req.ResponseKind()
+	case *kmsg.LeaveGroupRequest:
+		return req.ResponseKind()
+	case *kmsg.OffsetFetchRequest:
+		return b.offsetFetch(req)
+	case *kmsg.OffsetCommitRequest:
+		return b.offsetCommit(req)
+	case *kmsg.ListOffsetsRequest:
+		return b.listOffsets(req)
+	case *kmsg.FetchRequest:
+		return b.fetch(req)
+	}
+	return nil
+}
+
+func (b *fakeBroker) metadata(req *kmsg.MetadataRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.MetadataResponse)
+	broker := kmsg.NewMetadataResponseBroker()
+	broker.NodeID, broker.Host, broker.Port = 0, b.host, b.port
+	resp.Brokers = append(resp.Brokers, broker)
+
+	var topics []string
+	if req.Topics == nil {
+		for topic := range b.records {
+			topics = append(topics, topic)
+		}
+	}
+	for _, requested := range req.Topics {
+		topics = append(topics, *requested.Topic)
+	}
+	for _, topic := range topics {
+		respTopic := kmsg.NewMetadataResponseTopic()
+		respTopic.Topic = kmsg.StringPtr(topic)
+		partitions, ok := b.records[topic]
+		if !ok {
+			respTopic.ErrorCode = kerr.UnknownTopicOrPartition.Code
+		}
+		for partition := range partitions {
+			respPartition := kmsg.NewMetadataResponseTopicPartition()
+			respPartition.Partition = int32(partition)
+			respPartition.Replicas = []int32{0}
+			respPartition.ISR = []int32{0}
+			respTopic.Partitions = append(respTopic.Partitions, respPartition)
+		}
+		resp.Topics = append(resp.Topics, respTopic)
+	}
+	return resp
+}
+
+// joinGroup makes the joining member the leader of a new generation of its group.
+func (b *fakeBroker) joinGroup(req *kmsg.JoinGroupRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.JoinGroupResponse)
+	memberID := req.MemberID
+	if memberID == "" {
+		b.memberSeq++
+		memberID = fmt.Sprintf("member-%d", b.memberSeq)
+	}
+	b.generation++
+	resp.Generation = b.generation
+	resp.Protocol = kmsg.StringPtr(req.Protocols[0].Name)
+	resp.LeaderID = memberID
+	resp.MemberID = memberID
+	member := kmsg.NewJoinGroupResponseMember()
+	member.MemberID = memberID
+	member.ProtocolMetadata = req.Protocols[0].Metadata
+	resp.Members = append(resp.Members, member)
+	return resp
+}
+
+func (b *fakeBroker) syncGroup(req *kmsg.SyncGroupRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.SyncGroupResponse)
+	for _, assignment := range req.GroupAssignment {
+		if assignment.MemberID == req.MemberID {
+			b.assignment = assignment.MemberAssignment
+		}
+	}
+	resp.MemberAssignment = b.assignment
+	return resp
+}
+
+func (b *fakeBroker) offsetFetch(req *kmsg.OffsetFetchRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.OffsetFetchResponse)
+	for _, topic := range req.Topics {
+		respTopic := kmsg.NewOffsetFetchResponseTopic()
+		respTopic.Topic = topic.Topic
+		for _, partition := range topic.Partitions {
+			respPartition := kmsg.NewOffsetFetchResponseTopicPartition()
+			respPartition.Partition = partition
+			respPartition.Offset = -1
+			if offset, ok := b.committed[req.Group][topic.Topic][partition]; ok {
+				respPartition.Offset = offset
+			}
+			respTopic.Partitions = append(respTopic.Partitions, respPartition)
+		}
+		resp.Topics = append(resp.Topics, respTopic)
+	}
+	return resp
+}
+
+func (b *fakeBroker) offsetCommit(req *kmsg.OffsetCommitRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.OffsetCommitResponse)
+	if b.committed[req.Group] == nil {
+		b.committed[req.Group] = make(map[string]map[int32]int64)
+	}
+	for _, topic := range req.Topics {
+		if b.committed[req.Group][topic.Topic] == nil {
+			b.committed[req.Group][topic.Topic] = make(map[int32]int64)
+		}
+		respTopic := kmsg.NewOffsetCommitResponseTopic()
+		respTopic.Topic = topic.Topic
+		for _, partition := range topic.Partitions {
+			b.committed[req.Group][topic.Topic][partition.Partition] = partition.Offset
+			respPartition := kmsg.NewOffsetCommitResponseTopicPartition()
+			respPartition.Partition = partition.Partition
+			respTopic.Partitions = append(respTopic.Partitions, respPartition)
+		}
+		resp.Topics = append(resp.Topics, respTopic)
+	}
+	return resp
+}
+
+func (b *fakeBroker) listOffsets(req *kmsg.ListOffsetsRequest) kmsg.Response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.ListOffsetsResponse)
+	for _, topic := range req.Topics {
+		respTopic := kmsg.NewListOffsetsResponseTopic()
+		respTopic.Topic = topic.Topic
+		for _, partition := range topic.Partitions {
+			respPartition := kmsg.NewListOffsetsResponseTopicPartition()
+			respPartition.Partition = partition.Partition
+			respPartition.Timestamp = -1
+			respPartition.Offset = 0
+			if partition.Timestamp == -1 {
+				// latest
+				respPartition.Offset = int64(len(b.records[topic.Topic][partition.Partition]))
+			}
+			respTopic.Partitions = append(respTopic.Partitions, respPartition)
+		}
+		resp.Topics = append(resp.Topics, respTopic)
+	}
+	return resp
+}
+
+// fetch returns the records following the requested offsets, waiting a bit
+// for new ones when there are none.
+func (b *fakeBroker) fetch(req *kmsg.FetchRequest) kmsg.Response {
+	deadline := time.Now().Add(min(time.Duration(req.MaxWaitMillis)*time.Millisecond, 100*time.Millisecond))
+	for {
+		resp, empty := b.tryFetch(req)
+		if !empty || time.Now().After(deadline) {
+			return resp
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (b *fakeBroker) tryFetch(req *kmsg.FetchRequest) (kmsg.Response, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := req.ResponseKind().(*kmsg.FetchResponse)
+	empty := true
+	for _, topic := range req.Topics {
+		respTopic := kmsg.NewFetchResponseTopic()
+		respTopic.Topic = topic.Topic
+		for _, partition := range topic.Partitions {
+			records := b.records[topic.Topic][partition.Partition]
+			respPartition := kmsg.NewFetchResponseTopicPartition()
+			respPartition.Partition = partition.Partition
+			respPartition.HighWatermark = int64(len(records))
+			respPartition.LastStableOffset = int64(len(records))
+			switch {
+			case partition.FetchOffset > int64(len(records)):
+				respPartition.ErrorCode = kerr.OffsetOutOfRange.Code
+			case partition.FetchOffset < int64(len(records)):
+				respPartition.RecordBatches = recordBatch(partition.FetchOffset, records[partition.FetchOffset:])
+				empty = false
+			}
+			respTopic.Partitions = append(respTopic.Partitions, respPartition)
+		}
+		resp.Topics = append(resp.Topics, respTopic)
+	}
+	return resp, empty
+}
+
+// recordBatch encodes records in a batch starting at the given offset.
+func recordBatch(firstOffset int64, records []fakeRecord) []byte {
+	var encoded []byte
+	for i, r := range records {
+		record := kmsg.NewRecord()
+		record.OffsetDelta = int32(i)
+		record.Value = r.value
+		record.Headers = r.headers
+		// the length is the one of the fields following it, encoded with a zero length
+		record.Length = int32(len(record.AppendTo(nil)) - 1)
+		encoded = record.AppendTo(encoded)
+	}
+
+	batch := kmsg.NewRecordBatch()
+	batch.FirstOffset = firstOffset
+	batch.PartitionLeaderEpoch = -1
+	batch.Magic = 2
+	batch.LastOffsetDelta = int32(len(records) - 1)
+	batch.FirstTimestamp = time.Now().UnixMilli()
+	batch.MaxTimestamp = batch.FirstTimestamp
+	batch.ProducerID = -1
+	batch.ProducerEpoch = -1
+	batch.FirstSequence = -1
+	batch.NumRecords = int32(len(records))
+	batch.Records = encoded
+
+	raw := batch.AppendTo(nil)
+	// the length covers what follows the length field, the crc what follows the crc field
+	binary.BigEndian.PutUint32(raw[8:], uint32(len(raw)-12))
+	binary.BigEndian.PutUint32(raw[17:], crc32.Checksum(raw[21:], crc32.MakeTable(crc32.Castagnoli)))
+	return raw
+}
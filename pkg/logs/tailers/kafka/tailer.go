@@ -0,0 +1,323 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package kafka implements a tailer consuming logs from Kafka topics as a
+// member of a consumer group.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// DefaultConsumerGroup is the consumer group joined by sources which do
+	// not configure one.
+	DefaultConsumerGroup = "datadog-agent"
+
+	// defaultCommitInterval is the delay between two commits of the offsets
+	// of the registry to Kafka.
+	defaultCommitInterval = 5 * time.Second
+
+	// stopTimeout bounds the time spent committing the offsets and leaving
+	// the consumer group when the tailer is stopped, so that an unreachable
+	// broker can't block the shutdown.
+	stopTimeout = 10 * time.Second
+)
+
+// Tailer consumes the records of the topics of a source as a member of its
+// consumer group. Records are acknowledged to Kafka only once the auditor has
+// recorded that they were sent: the offsets committed to the consumer group
+// are the ones of the registry.
+type Tailer struct {
+	source         *sources.LogSource
+	outputChan     chan *message.Message
+	registry       auditor.Registry
+	group          string
+	commitInterval time.Duration
+
+	client *kgo.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+
+	mu sync.Mutex
+	// assigned holds the partitions of each topic assigned to this member
+	assigned map[string][]int32
+	// committed holds the last offset committed by partition identifier
+	committed map[string]int64
+}
+
+// NewTailer returns a new Tailer.
+func NewTailer(source *sources.LogSource, outputChan chan *message.Message, registry auditor.Registry) *Tailer {
+	group := source.Config.ConsumerGroup
+	if group == "" {
+		group = DefaultConsumerGroup
+	}
+	return &Tailer{
+		source:         source,
+		outputChan:     outputChan,
+		registry:       registry,
+		group:          group,
+		commitInterval: defaultCommitInterval,
+		assigned:       make(map[string][]int32),
+		committed:      make(map[string]int64),
+	}
+}
+
+// Identifier returns the registry identifier of a partition consumed by the
+// given group.
+func Identifier(group, topic string, partition int32) string {
+	return fmt.Sprintf("kafka:%s:%s:%d", group, topic, partition)
+}
+
+// Start joins the consumer group and starts consuming records.
+func (t *Tailer) Start() error {
+	resetOffset := kgo.NewOffset().AtEnd()
+	if mode, _ := config.TailingModeFromString(t.source.Config.TailingMode); mode == config.Beginning {
+		resetOffset = kgo.NewOffset().AtStart()
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(t.source.Config.Brokers...),
+		kgo.ConsumerGroup(t.group),
+		kgo.ConsumeTopics(t.source.Config.Topics...),
+		kgo.ConsumeResetOffset(resetOffset),
+		kgo.DisableAutoCommit(),
+		kgo.OnPartitionsAssigned(t.onAssigned),
+		kgo.OnPartitionsRevoked(t.onRevoked),
+		kgo.OnPartitionsLost(t.onLost),
+	)
+	if err != nil {
+		t.source.Status.Error(err)
+		return err
+	}
+
+	t.client = client
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.source.Status.Success()
+	log.Infof("Start consuming topics %v as a member of consumer group %s", t.source.Config.Topics, t.group)
+
+	t.done.Add(2)
+	go t.consume()
+	go t.commitPeriodically()
+	return nil
+}
+
+// Stop stops consuming records and leaves the consumer group, the offsets of
+// the registry are committed before leaving. Committing and leaving give up
+// after stopTimeout.
+func (t *Tailer) Stop() {
+	log.Infof("Stop consuming topics %v as a member of consumer group %s", t.source.Config.Topics, t.group)
+	t.cancel()
+	t.done.Wait()
+	t.mu.Lock()
+	assigned := t.assigned
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	t.commit(ctx, assigned)
+	if err := t.client.LeaveGroupContext(ctx); err != nil {
+		log.Warnf("Could not leave consumer group %s: %v", t.group, err)
+	}
+	t.client.Close()
+}
+
+// IsStopped returns whether the tailer was stopped.
+func (t *Tailer) IsStopped() bool {
+	return t.ctx != nil && t.ctx.Err() != nil
+}
+
+// consume forwards the records of the assigned partitions until the tailer is stopped.
+func (t *Tailer) consume() {
+	defer t.done.Done()
+	for {
+		fetches := t.client.PollFetches(t.ctx)
+		if t.ctx.Err() != nil {
+			return
+		}
+		fetches.EachError(func(topic string, partition int32, err error) {
+			if errors.Is(err, context.Canceled) || errors.Is(err, kgo.ErrClientClosed) {
+				return
+			}
+			log.Warnf("Could not fetch records of partition %d of topic %s: %v", partition, topic, err)
+			t.source.Status.Error(err)
+		})
+
+		iter := fetches.RecordIter()
+		for !iter.Done() {
+			record := iter.Next()
+			if len(record.Value) == 0 {
+				continue
+			}
+			select {
+			case t.outputChan <- t.toMessage(record):
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// toMessage returns the message of a record, its headers are mapped to tags.
+func (t *Tailer) toMessage(record *kgo.Record) *message.Message {
+	t.source.RecordBytes(int64(len(record.Value)))
+
+	origin := message.NewOrigin(t.source)
+	origin.Identifier = Identifier(t.group, record.Topic, record.Partition)
+	// the offset committed is the one of the next record to consume
+	origin.Offset = strconv.FormatInt(record.Offset+1, 10)
+
+	tags := make([]string, 0, len(record.Headers)+1)
+	tags = append(tags, "kafka_topic:"+record.Topic)
+	for _, header := range record.Headers {
+		if len(header.Value) == 0 {
+			tags = append(tags, header.Key)
+			continue
+		}
+		tags = append(tags, header.Key+":"+string(header.Value))
+	}
+	origin.SetTags(tags)
+
+	return message.NewMessage(record.Value, origin, message.StatusInfo, time.Now().UnixNano())
+}
+
+// commitPeriodically commits the offsets of the registry until the tailer is stopped.
+func (t *Tailer) commitPeriodically() {
+	defer t.done.Done()
+	ticker := time.NewTicker(t.commitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.mu.Lock()
+			assigned := t.assigned
+			t.mu.Unlock()
+			t.commit(t.ctx, assigned)
+		case <-t.ctx.Done():
+			return
+		}
+	}
+}
+
+// commit commits the offsets of the registry of the given partitions that
+// have not been committed yet.
+func (t *Tailer) commit(ctx context.Context, partitions map[string][]int32) {
+	offsets := make(map[string]map[int32]kgo.EpochOffset)
+	updated := make(map[string]int64)
+
+	t.mu.Lock()
+	for topic, topicPartitions := range partitions {
+		for _, partition := range topicPartitions {
+			identifier := Identifier(t.group, topic, partition)
+			offset, err := strconv.ParseInt(t.registry.GetOffset(identifier), 10, 64)
+			if err != nil || offset <= t.committed[identifier] {
+				continue
+			}
+			if offsets[topic] == nil {
+				offsets[topic] = make(map[int32]kgo.EpochOffset)
+			}
+			offsets[topic][partition] = kgo.EpochOffset{Epoch: -1, Offset: offset}
+			updated[identifier] = offset
+		}
+	}
+	t.mu.Unlock()
+	if len(offsets) == 0 {
+		return
+	}
+
+	t.client.CommitOffsetsSync(ctx, offsets, func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, resp *kmsg.OffsetCommitResponse, err error) {
+		if err == nil {
+			err = commitError(resp)
+		}
+		if err != nil {
+			log.Warnf("Could not commit offsets of consumer group %s: %v", t.group, err)
+			return
+		}
+		t.mu.Lock()
+		for identifier, offset := range updated {
+			t.committed[identifier] = offset
+		}
+		t.mu.Unlock()
+	})
+}
+
+// onAssigned tracks the partitions assigned to this member, whose offsets are
+// committed periodically.
+func (t *Tailer) onAssigned(_ context.Context, _ *kgo.Client, assigned map[string][]int32) {
+	log.Infof("Partitions %v assigned to consumer group %s", assigned, t.group)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// the map is replaced rather than updated as it is read without the lock
+	updated := make(map[string][]int32, len(t.assigned))
+	for topic, partitions := range t.assigned {
+		updated[topic] = append([]int32(nil), partitions...)
+	}
+	for topic, partitions := range assigned {
+		updated[topic] = append(updated[topic], partitions...)
+		for _, partition := range partitions {
+			t.source.AddInput(Identifier(t.group, topic, partition))
+		}
+	}
+	t.assigned = updated
+}
+
+// onRevoked commits the offsets of the revoked partitions so that the member
+// they are assigned to next resumes where this one stopped.
+func (t *Tailer) onRevoked(ctx context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	t.commit(ctx, revoked)
+	t.onLost(ctx, nil, revoked)
+}
+
+// onLost stops tracking the partitions no longer assigned to this member.
+func (t *Tailer) onLost(_ context.Context, _ *kgo.Client, lost map[string][]int32) {
+	if len(lost) == 0 {
+		return
+	}
+	log.Infof("Partitions %v revoked from consumer group %s", lost, t.group)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	assigned := make(map[string][]int32)
+	for topic, partitions := range t.assigned {
+		for _, partition := range partitions {
+			if slices.Contains(lost[topic], partition) {
+				identifier := Identifier(t.group, topic, partition)
+				t.source.RemoveInput(identifier)
+				delete(t.committed, identifier)
+				continue
+			}
+			assigned[topic] = append(assigned[topic], partition)
+		}
+	}
+	t.assigned = assigned
+}
+
+// commitError returns the first error of the partitions of a commit response.
+func commitError(resp *kmsg.OffsetCommitResponse) error {
+	for _, topic := range resp.Topics {
+		for _, partition := range topic.Partitions {
+			if err := kerr.ErrorForCode(partition.ErrorCode); err != nil {
+				return fmt.Errorf("partition %d of topic %s: %w", partition.Partition, topic.Topic, err)
+			}
+		}
+	}
+	return nil
+}
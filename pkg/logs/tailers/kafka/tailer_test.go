@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package kafka
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kmsg"
+
+	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sources"
+)
+
+// fakeRegistry holds the offsets set by the tests in place of the auditor.
+type fakeRegistry struct {
+	sync.Mutex
+	offsets map[string]string
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{offsets: make(map[string]string)}
+}
+
+func (r *fakeRegistry) GetOffset(identifier string) string {
+	r.Lock()
+	defer r.Unlock()
+	return r.offsets[identifier]
+}
+
+func (r *fakeRegistry) GetTailingMode(string) string {
+	return ""
+}
+
+func (r *fakeRegistry) KeepAlive(string) {}
+
+func (r *fakeRegistry) CommitOffset(identifier string, offset string) {
+	r.setOffset(identifier, offset)
+}
+
+func (r *fakeRegistry) setOffset(identifier string, offset string) {
+	r.Lock()
+	defer r.Unlock()
+	r.offsets[identifier] = offset
+}
+
+func newTestTailer(broker *fakeBroker, registry *fakeRegistry) (*Tailer, chan *message.Message) {
+	source := sources.NewLogSource("", &config.LogsConfig{
+		Type:          config.KafkaType,
+		Brokers:       []string{broker.addr()},
+		Topics:        []string{"logs"},
+		ConsumerGroup: "agents",
+		TailingMode:   "beginning",
+	})
+	outputChan := make(chan *message.Message, 10)
+	tailer := NewTailer(source, outputChan, registry)
+	tailer.commitInterval = 10 * time.Millisecond
+	return tailer, outputChan
+}
+
+func receive(t *testing.T, outputChan chan *message.Message) *message.Message {
+	select {
+	case msg := <-outputChan:
+		return msg
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "no message received")
+	}
+	return nil
+}
+
+func TestTailerConsumesRecords(t *testing.T) {
+	broker := newFakeBroker(t, 1, "logs")
+	broker.produce("logs", 0, "hello", kmsg.Header{Key: "env", Value: []byte("prod")}, kmsg.Header{Key: "canary"})
+	broker.produce("logs", 0, "")
+	broker.produce("logs", 0, "world")
+
+	tailer, outputChan := newTestTailer(broker, newFakeRegistry())
+	require.NoError(t, tailer.Start())
+	defer tailer.Stop()
+
+	msg := receive(t, outputChan)
+	assert.Equal(t, "hello", string(msg.GetContent()))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Equal(t, "kafka:agents:logs:0", msg.Origin.Identifier)
+	assert.Equal(t, "1", msg.Origin.Offset)
+	assert.ElementsMatch(t, []string{"kafka_topic:logs", "env:prod", "canary"}, msg.Origin.Tags(nil))
+
+	// empty records are skipped
+	msg = receive(t, outputChan)
+	assert.Equal(t, "world", string(msg.GetContent()))
+	assert.Equal(t, "3", msg.Origin.Offset)
+	assert.ElementsMatch(t, []string{"kafka_topic:logs"}, msg.Origin.Tags(nil))
+
+	assert.Contains(t, tailer.source.GetInputs(), "kafka:agents:logs:0")
+}
+
+func TestTailerCommitsRegistryOffsets(t *testing.T) {
+	broker := newFakeBroker(t, 2, "logs")
+	broker.produce("logs", 0, "a")
+	broker.produce("logs", 0, "b")
+	broker.produce("logs", 1, "c")
+
+	registry := newFakeRegistry()
+	tailer, outputChan := newTestTailer(broker, registry)
+	require.NoError(t, tailer.Start())
+	defer tailer.Stop()
+	for i := 0; i < 3; i++ {
+		receive(t, outputChan)
+	}
+
+	// nothing is committed until the auditor records the messages as sent
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int64(-1), broker.committedOffset("agents", "logs", 0))
+
+	registry.setOffset("kafka:agents:logs:0", "1")
+	assert.Eventually(t, func() bool { return broker.committedOffset("agents", "logs", 0) == 1 }, 5*time.Second, 10*time.Millisecond)
+	registry.setOffset("kafka:agents:logs:0", "2")
+	assert.Eventually(t, func() bool { return broker.committedOffset("agents", "logs", 0) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(-1), broker.committedOffset("agents", "logs", 1))
+}
+
+func TestTailerResumesFromCommittedOffsets(t *testing.T) {
+	broker := newFakeBroker(t, 1, "logs")
+	broker.produce("logs", 0, "a")
+	broker.produce("logs", 0, "b")
+
+	registry := newFakeRegistry()
+	tailer, outputChan := newTestTailer(broker, registry)
+	tailer.commitInterval = time.Hour
+	require.NoError(t, tailer.Start())
+	receive(t, outputChan)
+	receive(t, outputChan)
+	registry.setOffset("kafka:agents:logs:0", "2")
+	// the offsets of the registry are committed when stopping
+	tailer.Stop()
+	assert.Equal(t, int64(2), broker.committedOffset("agents", "logs", 0))
+
+	broker.produce("logs", 0, "c")
+	tailer, outputChan = newTestTailer(broker, registry)
+	require.NoError(t, tailer.Start())
+	defer tailer.Stop()
+	msg := receive(t, outputChan)
+	assert.Equal(t, "c", string(msg.GetContent()))
+	assert.Equal(t, "3", msg.Origin.Offset)
+}
@@ -72,6 +72,14 @@ func (o *Obfuscator) ObfuscateCreditCardNumber(key, val string) string {
 	return val
 }
 
+// IsCardNumber reports whether b could be a credit card number, using the same checks as the
+// credit card obfuscation. If validateLuhn is true, the Luhn checksum is also applied to
+// potential candidates.
+func IsCardNumber(b string, validateLuhn bool) bool {
+	cc := creditCard{luhn: validateLuhn}
+	return cc.IsCardNumber(b)
+}
+
 // IsCardNumber checks if b could be a credit card number by checking the digit count and IIN prefix.
 // If validateLuhn is true, the Luhn checksum is also applied to potential candidates.
 func (cc *creditCard) IsCardNumber(b string) (ok bool) {
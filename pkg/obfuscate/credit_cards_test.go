@@ -294,3 +294,10 @@ func BenchmarkIsSensitive(b *testing.B) {
 	b.Run("basic", run("4001 0200 0000 0009", false))
 	b.Run("luhn", run("4001 0200 0000 0009", true))
 }
+
+func TestIsCardNumber(t *testing.T) {
+	assert.True(t, IsCardNumber("4111 1111 1111 1111", true))
+	assert.True(t, IsCardNumber("4111-1111-1111-1112", false))
+	assert.False(t, IsCardNumber("4111-1111-1111-1112", true))
+	assert.False(t, IsCardNumber("1234", false))
+}
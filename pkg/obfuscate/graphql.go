@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObfuscateGraphQLString obfuscates the given GraphQL query. The literal values of
+// arguments, directives and variable defaults are replaced with "?", variables are kept
+// and whitespace is normalized. Aliases are removed if configured. The part of the query
+// following a syntax error is replaced with "?".
+func (o *Obfuscator) ObfuscateGraphQLString(query string) string {
+	if query == "" {
+		return query
+	}
+	removeAliases := o.opts.GraphQL.RemoveAliases
+	cacheKey := fmt.Sprintf("graphql:%t:%s", removeAliases, query)
+	if v, ok := o.queryCache.Get(cacheKey); ok {
+		return v.(string)
+	}
+	out := obfuscateGraphQL(query, removeAliases)
+	// the cost is the length of the query plus its string header
+	o.queryCache.Set(cacheKey, out, int64(len(out))+16)
+	return out
+}
+
+// graphqlScope specifies the kind of block the obfuscator is in.
+type graphqlScope int
+
+const (
+	// graphqlScopeSelection is a selection set, or the body of a type definition.
+	graphqlScopeSelection graphqlScope = iota
+
+	// graphqlScopeArguments is a list of arguments or of variable definitions.
+	graphqlScopeArguments
+
+	// graphqlScopeObject is an input object value.
+	graphqlScopeObject
+
+	// graphqlScopeList is a list value.
+	graphqlScopeList
+
+	// graphqlScopeListType is a list type, e.g. [ID!].
+	graphqlScopeListType
+)
+
+type graphqlToken struct {
+	tok string
+	typ graphqlTokenType
+}
+
+// graphqlObfuscator obfuscates the tokens of a GraphQL query.
+type graphqlObfuscator struct {
+	out    strings.Builder
+	last   string // last written token
+	scopes []graphqlScope
+
+	// expectValue is true when the next token starts a value, e.g. after the
+	// colon of an argument or the equal sign of a variable default.
+	expectValue bool
+	// varKey is true when the current key of an arguments scope is a variable,
+	// in which case it is followed by a type rather than a value.
+	varKey bool
+}
+
+// obfuscateGraphQL returns query obfuscated, with its aliases removed if removeAliases is set.
+func obfuscateGraphQL(query string, removeAliases bool) string {
+	var tokens []graphqlToken
+	tokenizer := newGraphQLTokenizer(query)
+	for {
+		tok, typ := tokenizer.scan()
+		if typ == graphqlTokenEOF {
+			break
+		}
+		tokens = append(tokens, graphqlToken{tok: tok, typ: typ})
+	}
+	g := &graphqlObfuscator{}
+	for i := 0; i < len(tokens); i++ {
+		tok, typ := tokens[i].tok, tokens[i].typ
+		scope, ok := g.scope()
+		inValue := g.expectValue || ok && scope == graphqlScopeList
+		switch typ {
+		case graphqlTokenError:
+			// better obfuscate everything than to leak what follows a syntax error
+			g.write("?")
+		case graphqlTokenInt, graphqlTokenFloat, graphqlTokenString, graphqlTokenBlockString:
+			g.write("?")
+			g.expectValue = false
+		case graphqlTokenName:
+			if inValue {
+				// enum values, booleans and null
+				g.write("?")
+				g.expectValue = false
+				break
+			}
+			if removeAliases && ok && scope == graphqlScopeSelection &&
+				i+2 < len(tokens) && tokens[i+1].tok == ":" && tokens[i+2].typ == graphqlTokenName {
+				// skip the alias and its colon
+				i++
+				break
+			}
+			if ok && scope == graphqlScopeArguments {
+				g.varKey = false
+			}
+			g.write(tok)
+		case graphqlTokenVariable:
+			if !inValue && ok && scope == graphqlScopeArguments {
+				g.varKey = true
+			}
+			g.expectValue = false
+			g.write(tok)
+		case graphqlTokenPunctuator:
+			switch tok {
+			case "(":
+				g.push(graphqlScopeArguments)
+			case "[":
+				if inValue {
+					g.push(graphqlScopeList)
+				} else {
+					g.push(graphqlScopeListType)
+				}
+			case "{":
+				if inValue {
+					g.push(graphqlScopeObject)
+				} else {
+					g.push(graphqlScopeSelection)
+				}
+			case ")", "]", "}":
+				g.pop()
+			case ":":
+				g.expectValue = ok && (scope == graphqlScopeArguments && !g.varKey || scope == graphqlScopeObject)
+			case "=":
+				g.expectValue = true
+			}
+			g.write(tok)
+		default:
+			g.write(tok)
+		}
+	}
+	return g.out.String()
+}
+
+// scope returns the current scope, and false at the top level of the document.
+func (g *graphqlObfuscator) scope() (graphqlScope, bool) {
+	if len(g.scopes) == 0 {
+		return 0, false
+	}
+	return g.scopes[len(g.scopes)-1], true
+}
+
+// push enters a new scope.
+func (g *graphqlObfuscator) push(s graphqlScope) {
+	g.scopes = append(g.scopes, s)
+	g.expectValue = false
+	g.varKey = false
+}
+
+// pop leaves the current scope. Closing a list or an object completes a value.
+func (g *graphqlObfuscator) pop() {
+	if len(g.scopes) > 0 {
+		g.scopes = g.scopes[:len(g.scopes)-1]
+	}
+	g.expectValue = false
+}
+
+// write writes tok to the output, separated from the last token by a single space when needed.
+func (g *graphqlObfuscator) write(tok string) {
+	if g.out.Len() > 0 && graphqlNeedsSpace(g.last, tok) {
+		g.out.WriteByte(' ')
+	}
+	g.out.WriteString(tok)
+	g.last = tok
+}
+
+// graphqlNeedsSpace reports whether the tokens prev and next should be separated by a space.
+func graphqlNeedsSpace(prev, next string) bool {
+	switch prev {
+	case "(", "[":
+		return false
+	case "...":
+		// fragment spreads are written ...Name, but inline fragments ... on Type
+		return !isGraphQLNameStart(next[0]) || next == "on"
+	}
+	switch next {
+	case ")", "]", ":", "!", "(", ",":
+		return false
+	}
+	return true
+}
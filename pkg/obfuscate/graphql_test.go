@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQL(t *testing.T) {
+	for _, tt := range []struct {
+		in, out       string
+		removeAliases bool
+	}{
+		{
+			in:  "",
+			out: "",
+		},
+		{
+			in:  "{ viewer { login } }",
+			out: "{ viewer { login } }",
+		},
+		{
+			in: `
+				query   GetUser {
+					# the user to look up
+					user(id: "123", active: true, role: ADMIN, score: 4.5, parent: null) {
+						name
+					}
+				}`,
+			out: "query GetUser { user(id: ?, active: ?, role: ?, score: ?, parent: ?) { name } }",
+		},
+		{
+			in:  `query Q($id: ID!, $first: Int = 10, $tags: [String!]! = ["a", "b"]) { user(id: $id) { friends(first: $first, tags: $tags) { name } } }`,
+			out: "query Q($id: ID!, $first: Int = ?, $tags: [String!]! = [?, ?]) { user(id: $id) { friends(first: $first, tags: $tags) { name } } }",
+		},
+		{
+			in:  `mutation { createUser(input: {name: "Jane", emails: ["jane@example.com"], address: {zip: 75001, city: $city}, tags: [[1, 2], []]}) { id } }`,
+			out: "mutation { createUser(input: { name: ?, emails: [?], address: { zip: ?, city: $city }, tags: [[?, ?], []] }) { id } }",
+		},
+		{
+			in:  `{ user @include(if: true) { name @skip(if: $skip) description(format: """multi\nline""") } }`,
+			out: "{ user @include(if: ?) { name @skip(if: $skip) description(format: ?) } }",
+		},
+		{
+			in:  "{ me: user(id: 4) { ...UserFields ... on Admin { level } ... @include(if: $all) { email } } } fragment UserFields on User { first: name }",
+			out: "{ me: user(id: ?) { ...UserFields ... on Admin { level } ... @include(if: $all) { email } } } fragment UserFields on User { first: name }",
+		},
+		{
+			in:            "{ me: user(id: 4) { ...UserFields ... on Admin { level } } } fragment UserFields on User { first: name, last: surname }",
+			out:           "{ user(id: ?) { ...UserFields ... on Admin { level } } } fragment UserFields on User { name, surname }",
+			removeAliases: true,
+		},
+		{
+			in:            "{ user(filter: {name: \"x\"}) { name } }",
+			out:           "{ user(filter: { name: ? }) { name } }",
+			removeAliases: true,
+		},
+		{
+			// the rest of the query is obfuscated after a syntax error
+			in:  `{ user(id: "123", name: "unterminated) { name } }`,
+			out: "{ user(id: ?, name: ?",
+		},
+		{
+			in:  "{ user.name(id: 1) }",
+			out: "{ user ?",
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, RemoveAliases: tt.removeAliases}})
+			assert.Equal(t, tt.out, o.ObfuscateGraphQLString(tt.in))
+		})
+	}
+}
+
+func TestObfuscateGraphQLCache(t *testing.T) {
+	in := `{ me: user(id: "123") { name } }`
+	o := NewObfuscator(Config{
+		Cache: CacheConfig{
+			Enabled: true,
+			MaxSize: 1_000_000,
+		},
+	})
+	defer o.Stop()
+
+	out := o.ObfuscateGraphQLString(in)
+	assert.Equal(t, "{ me: user(id: ?) { name } }", out)
+	o.queryCache.Wait()
+
+	assert.Equal(t, out, o.ObfuscateGraphQLString(in))
+	assert.Equal(t, uint64(1), o.queryCache.Metrics.Hits())
+
+	// the aliases option is part of the cache key
+	o.opts.GraphQL.RemoveAliases = true
+	assert.Equal(t, "{ user(id: ?) { name } }", o.ObfuscateGraphQLString(in))
+	assert.Equal(t, uint64(1), o.queryCache.Metrics.Hits())
+}
+
+func BenchmarkObfuscateGraphQL(b *testing.B) {
+	in := `query Q($id: ID!, $first: Int = 10) { me: user(id: $id) { friends(first: $first, filter: {name: "jane", age: [18, 21]}) { ...UserFields } } }`
+	o := NewObfuscator(Config{GraphQL: GraphQLConfig{Enabled: true, RemoveAliases: true}})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.ObfuscateGraphQLString(in)
+	}
+}
@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"strings"
+)
+
+// graphqlTokenType specifies the token type returned by the tokenizer.
+type graphqlTokenType int
+
+const (
+	// graphqlTokenEOF is returned once the whole input was scanned.
+	graphqlTokenEOF graphqlTokenType = iota
+
+	// graphqlTokenError is returned when the input is not valid GraphQL. The
+	// error is available through the err field of the tokenizer.
+	graphqlTokenError
+
+	// graphqlTokenPunctuator is one of ! ( ) ... : = [ ] { | } & or a comma. Commas are
+	// insignificant in GraphQL but they are kept for readability.
+	graphqlTokenPunctuator
+
+	// graphqlTokenName is a name, such as a field, a type, a keyword or an enum value.
+	graphqlTokenName
+
+	// graphqlTokenVariable is a variable, including its leading "$".
+	graphqlTokenVariable
+
+	// graphqlTokenDirective is a directive, including its leading "@".
+	graphqlTokenDirective
+
+	// graphqlTokenInt is an integer value.
+	graphqlTokenInt
+
+	// graphqlTokenFloat is a float value.
+	graphqlTokenFloat
+
+	// graphqlTokenString is a quoted string value, including its quotes.
+	graphqlTokenString
+
+	// graphqlTokenBlockString is a triple-quoted block string value, including its quotes.
+	graphqlTokenBlockString
+)
+
+// String implements fmt.Stringer.
+func (t graphqlTokenType) String() string {
+	return map[graphqlTokenType]string{
+		graphqlTokenEOF:         "eof",
+		graphqlTokenError:       "error",
+		graphqlTokenPunctuator:  "punctuator",
+		graphqlTokenName:        "name",
+		graphqlTokenVariable:    "variable",
+		graphqlTokenDirective:   "directive",
+		graphqlTokenInt:         "int",
+		graphqlTokenFloat:       "float",
+		graphqlTokenString:      "string",
+		graphqlTokenBlockString: "block string",
+	}[t]
+}
+
+var (
+	errGraphQLUnexpectedChar     = errors.New("unexpected character")
+	errGraphQLUnterminatedString = errors.New("unterminated string")
+)
+
+// graphqlTokenizer tokenizes a GraphQL document, following the lexical grammar of
+// https://spec.graphql.org/October2021/#sec-Language.Source-Text. Comments and
+// whitespace are insignificant and skipped.
+type graphqlTokenizer struct {
+	data string
+	off  int
+	err  error // the error which caused a graphqlTokenError
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given data.
+func newGraphQLTokenizer(data string) *graphqlTokenizer {
+	return &graphqlTokenizer{data: data}
+}
+
+// scan returns the next token and its type. It returns graphqlTokenEOF once the
+// input is consumed and graphqlTokenError, along with the rest of the input, if it
+// is not valid.
+func (t *graphqlTokenizer) scan() (tok string, typ graphqlTokenType) {
+	t.skipIgnored()
+	if t.off >= len(t.data) {
+		return "", graphqlTokenEOF
+	}
+	start := t.off
+	ch := t.data[t.off]
+	switch {
+	case ch == '.':
+		if !strings.HasPrefix(t.data[t.off:], "...") {
+			return t.error(errGraphQLUnexpectedChar)
+		}
+		t.off += 3
+		return "...", graphqlTokenPunctuator
+	case strings.IndexByte("!():=[]{|}&,", ch) >= 0:
+		t.off++
+		return t.data[start:t.off], graphqlTokenPunctuator
+	case ch == '$' || ch == '@':
+		t.off++
+		if t.scanName() == 0 {
+			t.off = start
+			return t.error(errGraphQLUnexpectedChar)
+		}
+		if ch == '$' {
+			return t.data[start:t.off], graphqlTokenVariable
+		}
+		return t.data[start:t.off], graphqlTokenDirective
+	case isGraphQLNameStart(ch):
+		t.scanName()
+		return t.data[start:t.off], graphqlTokenName
+	case ch == '-' || isDigit(rune(ch)):
+		return t.scanNumber()
+	case ch == '"':
+		if strings.HasPrefix(t.data[t.off:], `"""`) {
+			return t.scanBlockString()
+		}
+		return t.scanString()
+	default:
+		return t.error(errGraphQLUnexpectedChar)
+	}
+}
+
+// error stops the scan on err, returning the rest of the input.
+func (t *graphqlTokenizer) error(err error) (tok string, typ graphqlTokenType) {
+	t.err = err
+	tok = t.data[t.off:]
+	t.off = len(t.data)
+	return tok, graphqlTokenError
+}
+
+// skipIgnored skips whitespace, line terminators, comments and the byte order mark.
+func (t *graphqlTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case ' ', '\t', '\n', '\r':
+			t.off++
+		case '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		default:
+			if strings.HasPrefix(t.data[t.off:], "\uFEFF") {
+				t.off += len("\uFEFF")
+				continue
+			}
+			return
+		}
+	}
+}
+
+// scanName advances past the name at the current offset and returns its length.
+func (t *graphqlTokenizer) scanName() int {
+	start := t.off
+	if t.off < len(t.data) && isGraphQLNameStart(t.data[t.off]) {
+		t.off++
+		for t.off < len(t.data) && (isGraphQLNameStart(t.data[t.off]) || isDigit(rune(t.data[t.off]))) {
+			t.off++
+		}
+	}
+	return t.off - start
+}
+
+// scanNumber scans an int or a float value.
+func (t *graphqlTokenizer) scanNumber() (tok string, typ graphqlTokenType) {
+	start := t.off
+	typ = graphqlTokenInt
+	if t.data[t.off] == '-' {
+		t.off++
+	}
+	if t.skipDigits() == 0 {
+		t.off = start
+		return t.error(errGraphQLUnexpectedChar)
+	}
+	if t.off < len(t.data) && t.data[t.off] == '.' {
+		t.off++
+		if t.skipDigits() == 0 {
+			t.off = start
+			return t.error(errGraphQLUnexpectedChar)
+		}
+		typ = graphqlTokenFloat
+	}
+	if t.off < len(t.data) && (t.data[t.off] == 'e' || t.data[t.off] == 'E') {
+		t.off++
+		if t.off < len(t.data) && (t.data[t.off] == '+' || t.data[t.off] == '-') {
+			t.off++
+		}
+		if t.skipDigits() == 0 {
+			t.off = start
+			return t.error(errGraphQLUnexpectedChar)
+		}
+		typ = graphqlTokenFloat
+	}
+	if t.off < len(t.data) && (isGraphQLNameStart(t.data[t.off]) || t.data[t.off] == '.') {
+		// a number can't be directly followed by a name or a dot, e.g. 123abc or 1.2.3
+		t.off = start
+		return t.error(errGraphQLUnexpectedChar)
+	}
+	return t.data[start:t.off], typ
+}
+
+// skipDigits advances past the digits at the current offset and returns their count.
+func (t *graphqlTokenizer) skipDigits() int {
+	start := t.off
+	for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+		t.off++
+	}
+	return t.off - start
+}
+
+// scanString scans a quoted string, which can't span multiple lines.
+func (t *graphqlTokenizer) scanString() (tok string, typ graphqlTokenType) {
+	start := t.off
+	t.off++ // opening quote
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '"':
+			t.off++
+			return t.data[start:t.off], graphqlTokenString
+		case '\\':
+			t.off += 2
+		case '\n', '\r':
+			t.off = start
+			return t.error(errGraphQLUnterminatedString)
+		default:
+			t.off++
+		}
+	}
+	t.off = start
+	return t.error(errGraphQLUnterminatedString)
+}
+
+// scanBlockString scans a triple-quoted block string, in which only \""" is escaped.
+func (t *graphqlTokenizer) scanBlockString() (tok string, typ graphqlTokenType) {
+	start := t.off
+	t.off += 3 // opening quotes
+	for t.off < len(t.data) {
+		switch {
+		case strings.HasPrefix(t.data[t.off:], `\"""`):
+			t.off += 4
+		case strings.HasPrefix(t.data[t.off:], `"""`):
+			t.off += 3
+			return t.data[start:t.off], graphqlTokenBlockString
+		default:
+			t.off++
+		}
+	}
+	t.off = start
+	return t.error(errGraphQLUnterminatedString)
+}
+
+// isGraphQLNameStart reports whether ch can start a GraphQL name.
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
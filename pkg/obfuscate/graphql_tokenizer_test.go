@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQLTokenizer(t *testing.T) {
+	type testResult struct {
+		tok string
+		typ graphqlTokenType
+	}
+	for _, tt := range []struct {
+		in  string
+		out []testResult
+		err error
+	}{
+		{
+			in: "",
+		},
+		{
+			in: "  # only a comment\n\t",
+		},
+		{
+			in: "\uFEFF{ user { id } }",
+			out: []testResult{
+				{"{", graphqlTokenPunctuator},
+				{"user", graphqlTokenName},
+				{"{", graphqlTokenPunctuator},
+				{"id", graphqlTokenName},
+				{"}", graphqlTokenPunctuator},
+				{"}", graphqlTokenPunctuator},
+			},
+		},
+		{
+			in: "query Q($id: ID! = 12, $ids: [Int]) @cached {\n  # the user\n  u: user(id: $id) { ...F ... on Admin { level } }\n}",
+			out: []testResult{
+				{"query", graphqlTokenName},
+				{"Q", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"$id", graphqlTokenVariable},
+				{":", graphqlTokenPunctuator},
+				{"ID", graphqlTokenName},
+				{"!", graphqlTokenPunctuator},
+				{"=", graphqlTokenPunctuator},
+				{"12", graphqlTokenInt},
+				{",", graphqlTokenPunctuator},
+				{"$ids", graphqlTokenVariable},
+				{":", graphqlTokenPunctuator},
+				{"[", graphqlTokenPunctuator},
+				{"Int", graphqlTokenName},
+				{"]", graphqlTokenPunctuator},
+				{")", graphqlTokenPunctuator},
+				{"@cached", graphqlTokenDirective},
+				{"{", graphqlTokenPunctuator},
+				{"u", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{"user", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"id", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{"$id", graphqlTokenVariable},
+				{")", graphqlTokenPunctuator},
+				{"{", graphqlTokenPunctuator},
+				{"...", graphqlTokenPunctuator},
+				{"F", graphqlTokenName},
+				{"...", graphqlTokenPunctuator},
+				{"on", graphqlTokenName},
+				{"Admin", graphqlTokenName},
+				{"{", graphqlTokenPunctuator},
+				{"level", graphqlTokenName},
+				{"}", graphqlTokenPunctuator},
+				{"}", graphqlTokenPunctuator},
+				{"}", graphqlTokenPunctuator},
+			},
+		},
+		{
+			in: `0 -12 1.5 -0.5e10 2E-3`,
+			out: []testResult{
+				{"0", graphqlTokenInt},
+				{"-12", graphqlTokenInt},
+				{"1.5", graphqlTokenFloat},
+				{"-0.5e10", graphqlTokenFloat},
+				{"2E-3", graphqlTokenFloat},
+			},
+		},
+		{
+			in: `"a \"quoted\" \\ string" """block "string" \""" with
+lines"""`,
+			out: []testResult{
+				{`"a \"quoted\" \\ string"`, graphqlTokenString},
+				{"\"\"\"block \"string\" \\\"\"\" with\nlines\"\"\"", graphqlTokenBlockString},
+			},
+		},
+		{
+			in: `f(a: "unterminated)`,
+			out: []testResult{
+				{"f", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"a", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{`"unterminated)`, graphqlTokenError},
+			},
+			err: errGraphQLUnterminatedString,
+		},
+		{
+			in: "f(a: \"line\nbreak\")",
+			out: []testResult{
+				{"f", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"a", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{"\"line\nbreak\")", graphqlTokenError},
+			},
+			err: errGraphQLUnterminatedString,
+		},
+		{
+			in: `"""unterminated block`,
+			out: []testResult{
+				{`"""unterminated block`, graphqlTokenError},
+			},
+			err: errGraphQLUnterminatedString,
+		},
+		{
+			in: "a.b",
+			out: []testResult{
+				{"a", graphqlTokenName},
+				{".b", graphqlTokenError},
+			},
+			err: errGraphQLUnexpectedChar,
+		},
+		{
+			in: "f(a: 12abc)",
+			out: []testResult{
+				{"f", graphqlTokenName},
+				{"(", graphqlTokenPunctuator},
+				{"a", graphqlTokenName},
+				{":", graphqlTokenPunctuator},
+				{"12abc)", graphqlTokenError},
+			},
+			err: errGraphQLUnexpectedChar,
+		},
+		{
+			in: "$ 1",
+			out: []testResult{
+				{"$ 1", graphqlTokenError},
+			},
+			err: errGraphQLUnexpectedChar,
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			tokenizer := newGraphQLTokenizer(tt.in)
+			var out []testResult
+			for {
+				tok, typ := tokenizer.scan()
+				if typ == graphqlTokenEOF {
+					break
+				}
+				out = append(out, testResult{tok, typ})
+			}
+			assert.Equal(t, tt.out, out)
+			assert.Equal(t, tt.err, tokenizer.err)
+		})
+	}
+}
@@ -101,6 +101,9 @@ type Config struct {
 	// Memcached holds the obfuscation settings for Memcached commands.
 	Memcached MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the obfuscation settings for GraphQL queries.
+	GraphQL GraphQLConfig `mapstructure:"graphql"`
+
 	// Memcached holds the obfuscation settings for obfuscation of CC numbers in meta.
 	CreditCard CreditCardsConfig `mapstructure:"credit_cards"`
 
@@ -111,7 +114,7 @@ type Config struct {
 	// If unset, no logs will be outputted.
 	Logger Logger
 
-	// Cache enables the query cache for obfuscation for SQL and MongoDB queries.
+	// Cache enables the query cache for obfuscation for SQL, MongoDB and GraphQL queries.
 	Cache CacheConfig `mapstructure:"cache"`
 }
 
@@ -259,6 +262,16 @@ type MemcachedConfig struct {
 	KeepCommand bool `mapstructure:"keep_command"`
 }
 
+// GraphQLConfig holds the configuration settings for GraphQL obfuscation
+type GraphQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// RemoveAliases specifies whether the aliases of the fields should
+	// be removed from the queries.
+	RemoveAliases bool `mapstructure:"remove_aliases"`
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
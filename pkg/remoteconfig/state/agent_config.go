@@ -26,7 +26,8 @@ type AgentConfig struct {
 
 // ConfigContent contains the configurations set by remote-config
 type ConfigContent struct {
-	LogLevel string `json:"log_level"`
+	LogLevel               string        `json:"log_level"`
+	DogstatsdTagFilterList []interface{} `json:"dogstatsd_tag_filterlist,omitempty"`
 }
 
 type agentConfigData struct {
@@ -145,15 +146,24 @@ func MergeRCAgentConfig(applyStatus func(cfgPath string, status ApplyStatus), up
 	mergedConfig := ConfigContent{}
 	for i := len(orderFile.Config.Order) - 1; i >= 0; i-- {
 		if layer, found := parsedLayers[orderFile.Config.Order[i]]; found {
-			mergedConfig.LogLevel = layer.Config.Config.LogLevel
+			mergeConfigContent(&mergedConfig, layer.Config.Config)
 		}
 	}
 	// Same for internal config
 	for i := len(orderFile.Config.InternalOrder) - 1; i >= 0; i-- {
 		if layer, found := parsedLayers[orderFile.Config.InternalOrder[i]]; found {
-			mergedConfig.LogLevel = layer.Config.Config.LogLevel
+			mergeConfigContent(&mergedConfig, layer.Config.Config)
 		}
 	}
 
 	return mergedConfig, nil
 }
+
+// mergeConfigContent applies a layer on top of the merged configuration, the
+// tag filter rules are only overridden by the layers setting them.
+func mergeConfigContent(mergedConfig *ConfigContent, layer ConfigContent) {
+	mergedConfig.LogLevel = layer.LogLevel
+	if layer.DogstatsdTagFilterList != nil {
+		mergedConfig.DogstatsdTagFilterList = layer.DogstatsdTagFilterList
+	}
+}
@@ -17,3 +17,21 @@ func TestMergeRCConfigWithEmptyData(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, ConfigContent{}, content)
 }
+
+func TestMergeRCConfigDogstatsdTagFilterList(t *testing.T) {
+	emptyUpdateStatus := func(_ string, _ ApplyStatus) {}
+
+	content, err := MergeRCAgentConfig(emptyUpdateStatus, map[string]RawConfig{
+		"datadog/2/AGENT_CONFIG/configuration_order/config": {Config: []byte(`{"order":["level","filters"]}`)},
+		"datadog/2/AGENT_CONFIG/filters/config":             {Config: []byte(`{"config":{"dogstatsd_tag_filterlist":[{"metric_name":"http.*","action":"exclude","tags":["request_id"]}]}}`)},
+		"datadog/2/AGENT_CONFIG/level/config":               {Config: []byte(`{"config":{"log_level":"debug"}}`)},
+	})
+	assert.NoError(t, err)
+	// the layers which do not set the tag filter rules don't override them
+	assert.Equal(t, ConfigContent{
+		LogLevel: "debug",
+		DogstatsdTagFilterList: []interface{}{
+			map[string]interface{}{"metric_name": "http.*", "action": "exclude", "tags": []interface{}{"request_id"}},
+		},
+	}, content)
+}
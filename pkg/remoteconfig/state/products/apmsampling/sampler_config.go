@@ -17,6 +17,21 @@ type SamplerEnvConfig struct {
 	PrioritySamplerTargetTPS *float64 `json:"priority_sampler_target_TPS"`
 	ErrorsSamplerTargetTPS   *float64 `json:"errors_sampler_target_TPS"`
 	RareSamplerEnabled       *bool    `json:"rare_sampler_enabled"`
+	// SamplingRules replaces the agent-side sampling rules when it is set,
+	// including to an empty list.
+	SamplingRules *[]SamplingRule `json:"sampling_rules"`
+}
+
+// SamplingRule is an agent-side trace sampling rule, see the
+// apm_config.sampling_rules setting
+type SamplingRule struct {
+	Service    string            `json:"service"`
+	Env        string            `json:"env"`
+	Resource   string            `json:"resource"`
+	Name       string            `json:"name"`
+	Tags       map[string]string `json:"tags"`
+	SampleRate *float64          `json:"sample_rate"`
+	TargetTPS  *float64          `json:"target_tps"`
 }
 
 // EnvAndConfig breaks down configuration by environment
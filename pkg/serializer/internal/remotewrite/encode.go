@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/richardartoul/molecule"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+// Field numbers of the messages of
+// https://github.com/prometheus/prometheus/blob/main/prompb/remote.proto and
+// https://github.com/prometheus/prometheus/blob/main/prompb/types.proto
+const (
+	writeRequestTimeseries = 1
+
+	timeSeriesLabels  = 1
+	timeSeriesSamples = 2
+
+	labelName  = 1
+	labelValue = 2
+
+	sampleValue     = 1
+	sampleTimestamp = 2
+)
+
+const (
+	metricNameLabel = "__name__"
+	hostLabel       = "host"
+	quantileLabel   = "quantile"
+	// bareTagValue is the label value of the tags without value
+	bareTagValue = "true"
+)
+
+// sketchQuantiles are the quantiles of the sketches sent as time series
+var sketchQuantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+type label struct {
+	name  string
+	value string
+}
+
+type sample struct {
+	value float64
+	// timestamp in milliseconds
+	timestamp int64
+}
+
+func (e *encoder) encodeSerie(serie *metrics.Serie) {
+	samples := make([]sample, 0, len(serie.Points))
+	for _, p := range serie.Points {
+		samples = append(samples, sample{value: p.Value, timestamp: int64(p.Ts * 1000)})
+	}
+	e.setLabels(serie.Name, serie.Host, serie.Tags)
+	e.encodeTimeSeries(samples)
+	e.flushIfFull()
+}
+
+// encodeSketch encodes the sketches as a summary, the count and the sum of the
+// sketches, and their quantiles.
+func (e *encoder) encodeSketch(sketch *metrics.SketchSeries) {
+	counts := make([]sample, 0, len(sketch.Points))
+	sums := make([]sample, 0, len(sketch.Points))
+	for _, p := range sketch.Points {
+		counts = append(counts, sample{value: float64(p.Sketch.Basic.Cnt), timestamp: p.Ts * 1000})
+		sums = append(sums, sample{value: p.Sketch.Basic.Sum, timestamp: p.Ts * 1000})
+	}
+	e.setLabels(sketch.Name+"_count", sketch.Host, sketch.Tags)
+	e.encodeTimeSeries(counts)
+	e.setLabels(sketch.Name+"_sum", sketch.Host, sketch.Tags)
+	e.encodeTimeSeries(sums)
+
+	cfg := quantile.Default()
+	for _, q := range sketchQuantiles {
+		samples := make([]sample, 0, len(sketch.Points))
+		for _, p := range sketch.Points {
+			samples = append(samples, sample{value: p.Sketch.Quantile(cfg, q), timestamp: p.Ts * 1000})
+		}
+		e.setLabels(sketch.Name, sketch.Host, sketch.Tags)
+		e.labels = append(e.labels, label{name: quantileLabel, value: strconv.FormatFloat(q, 'f', -1, 64)})
+		e.sortLabels()
+		e.encodeTimeSeries(samples)
+	}
+	e.flushIfFull()
+}
+
+// setLabels sets the labels of the next time series, the tags are converted
+// to labels with sanitized names and the values of the tags with the same
+// name are joined.
+func (e *encoder) setLabels(name string, host string, tags tagset.CompositeTags) {
+	e.labels = append(e.labels[:0], label{name: metricNameLabel, value: sanitizeName(name, true)})
+	if host != "" {
+		e.labels = append(e.labels, label{name: hostLabel, value: host})
+	}
+	tags.ForEach(func(tag string) {
+		name, value, found := strings.Cut(tag, ":")
+		if !found {
+			value = bareTagValue
+		}
+		e.labels = append(e.labels, label{name: sanitizeName(name, false), value: value})
+	})
+	e.sortLabels()
+}
+
+// sortLabels sorts the labels by name, as required by the remote-write
+// protocol, and merges the labels with the same name.
+func (e *encoder) sortLabels() {
+	slices.SortStableFunc(e.labels, func(a, b label) int {
+		return strings.Compare(a.name, b.name)
+	})
+	merged := e.labels[:0]
+	for _, l := range e.labels {
+		if n := len(merged); n > 0 && merged[n-1].name == l.name {
+			if merged[n-1].value != l.value {
+				merged[n-1].value += "," + l.value
+			}
+			continue
+		}
+		merged = append(merged, l)
+	}
+	e.labels = merged
+}
+
+func (e *encoder) encodeTimeSeries(samples []sample) {
+	if len(samples) == 0 {
+		return
+	}
+	err := e.ps.Embedded(writeRequestTimeseries, func(ps *molecule.ProtoStream) error {
+		for _, l := range e.labels {
+			err := ps.Embedded(timeSeriesLabels, func(ps *molecule.ProtoStream) error {
+				if err := ps.String(labelName, l.name); err != nil {
+					return err
+				}
+				return ps.String(labelValue, l.value)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		for _, s := range samples {
+			err := ps.Embedded(timeSeriesSamples, func(ps *molecule.ProtoStream) error {
+				if err := ps.Double(sampleValue, s.value); err != nil {
+					return err
+				}
+				return ps.Int64(sampleTimestamp, s.timestamp)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		e.logger.Warnf("Unable to encode the remote-write time series %s: %v", e.labels[0].value, err)
+	}
+}
+
+// sanitizeName replaces the characters not allowed in Prometheus metric
+// names, or label names if allowColon is false, by underscores.
+func sanitizeName(name string, allowColon bool) string {
+	valid := func(i int, c byte) bool {
+		return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			(c >= '0' && c <= '9' && i > 0) || (c == ':' && allowColon)
+	}
+
+	i := 0
+	for i < len(name) && valid(i, name[i]) {
+		i++
+	}
+	if i == len(name) && i > 0 {
+		return name
+	}
+
+	var b strings.Builder
+	b.Grow(len(name) + 1)
+	if len(name) == 0 || (name[0] >= '0' && name[0] <= '9') {
+		b.WriteByte('_')
+	}
+	for i := 0; i < len(name); i++ {
+		if valid(i, name[i]) || (name[i] >= '0' && name[i] <= '9') {
+			b.WriteByte(name[i])
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package remotewrite sends the series and sketches flushed by the aggregator
+// to a Prometheus remote-write endpoint.
+package remotewrite
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/richardartoul/molecule"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// maxPayloadSize is the size of the uncompressed write requests above which
+// the series are split into another request.
+const maxPayloadSize = 2 * 1024 * 1024
+
+// Sink encodes the series and sketches as Prometheus remote-write requests
+// and sends them to the configured endpoint.
+type Sink struct {
+	sender *sender
+	logger log.Component
+}
+
+// NewSink returns a new Sink configured by the prometheus_remote_write
+// settings. It starts sending once it has a first payload and must be
+// stopped by its owner.
+func NewSink(config config.Component, logger log.Component) (*Sink, error) {
+	url := config.GetString("prometheus_remote_write.url")
+	if url == "" {
+		return nil, errors.New("prometheus_remote_write.url is required")
+	}
+
+	headers := make(http.Header)
+	for k, v := range config.GetStringMapString("prometheus_remote_write.headers") {
+		headers.Set(k, v)
+	}
+	headers.Set("Content-Type", "application/x-protobuf")
+	headers.Set("Content-Encoding", "snappy")
+	headers.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	headers.Set("User-Agent", "datadog-agent/"+version.AgentVersion)
+
+	timeout := time.Duration(config.GetInt("prometheus_remote_write.timeout")) * time.Second
+	return &Sink{
+		sender: newSender(config, logger, url, headers, timeout),
+		logger: logger,
+	}, nil
+}
+
+// Stop stops sending the payloads, the payloads still queued and the ones
+// submitted afterwards are dropped.
+func (s *Sink) Stop() {
+	s.sender.stop()
+}
+
+// RecordSeries returns a SerieSource encoding the series of source as they
+// are consumed. Flush must be called once the source is consumed.
+func (s *Sink) RecordSeries(source metrics.SerieSource) *SeriesRecorder {
+	return &SeriesRecorder{SerieSource: source, encoder: s.newEncoder()}
+}
+
+// RecordSketches returns a SketchesSource encoding the sketches of source as
+// they are consumed. Flush must be called once the source is consumed.
+func (s *Sink) RecordSketches(source metrics.SketchesSource) *SketchesRecorder {
+	return &SketchesRecorder{SketchesSource: source, encoder: s.newEncoder()}
+}
+
+func (s *Sink) newEncoder() *encoder {
+	buf := &bytes.Buffer{}
+	return &encoder{
+		buf:    buf,
+		ps:     molecule.NewProtoStream(buf),
+		submit: s.sender.submit,
+		logger: s.logger,
+	}
+}
+
+// SeriesRecorder is a SerieSource encoding the series it iterates over.
+type SeriesRecorder struct {
+	metrics.SerieSource
+	*encoder
+}
+
+// MoveNext advances to the next serie and encodes it.
+func (r *SeriesRecorder) MoveNext() bool {
+	if !r.SerieSource.MoveNext() {
+		return false
+	}
+	r.encodeSerie(r.Current())
+	return true
+}
+
+// SketchesRecorder is a SketchesSource encoding the sketches it iterates over.
+type SketchesRecorder struct {
+	metrics.SketchesSource
+	*encoder
+}
+
+// MoveNext advances to the next sketch serie and encodes it.
+func (r *SketchesRecorder) MoveNext() bool {
+	if !r.SketchesSource.MoveNext() {
+		return false
+	}
+	r.encodeSketch(r.Current())
+	return true
+}
+
+// encoder encodes the time series of a write request and submits the
+// compressed requests once they reach maxPayloadSize.
+type encoder struct {
+	buf    *bytes.Buffer
+	ps     *molecule.ProtoStream
+	labels []label
+	submit func(payload []byte)
+	logger log.Component
+}
+
+// Flush submits the time series encoded since the last request.
+func (e *encoder) Flush() {
+	if e.buf.Len() > 0 {
+		e.submit(snappy.Encode(nil, e.buf.Bytes()))
+		e.buf.Reset()
+	}
+}
+
+func (e *encoder) flushIfFull() {
+	if e.buf.Len() >= maxPayloadSize {
+		e.Flush()
+	}
+}
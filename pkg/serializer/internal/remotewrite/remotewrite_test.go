@@ -0,0 +1,305 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build test
+
+package remotewrite
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DataDog/opentelemetry-mapping-go/pkg/quantile"
+	"github.com/klauspost/compress/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+type timeSeries struct {
+	labels  map[string]string
+	samples []sample
+}
+
+// receiver is a remote-write endpoint decoding the requests it receives
+type receiver struct {
+	*httptest.Server
+	requests chan []timeSeries
+	headers  chan http.Header
+	statuses chan int
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{
+		requests: make(chan []timeSeries, 10),
+		headers:  make(chan http.Header, 10),
+		statuses: make(chan int, 10),
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case status := <-r.statuses:
+			w.WriteHeader(status)
+			return
+		default:
+		}
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		decoded, err := snappy.Decode(nil, body)
+		assert.NoError(t, err)
+		r.headers <- req.Header
+		r.requests <- decodeWriteRequest(t, decoded)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) next(t *testing.T) []timeSeries {
+	select {
+	case request := <-r.requests:
+		return request
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no request received")
+		return nil
+	}
+}
+
+func decodeWriteRequest(t *testing.T, b []byte) []timeSeries {
+	var series []timeSeries
+	eachField(t, b, func(num protowire.Number, v []byte) {
+		require.EqualValues(t, writeRequestTimeseries, num)
+		ts := timeSeries{labels: map[string]string{}}
+		eachField(t, v, func(num protowire.Number, v []byte) {
+			switch num {
+			case timeSeriesLabels:
+				var name, value string
+				eachField(t, v, func(num protowire.Number, v []byte) {
+					if num == labelName {
+						name = string(v)
+					} else {
+						value = string(v)
+					}
+				})
+				ts.labels[name] = value
+			case timeSeriesSamples:
+				var s sample
+				eachField(t, v, func(num protowire.Number, v []byte) {
+					if num == sampleValue {
+						bits, _ := protowire.ConsumeFixed64(v)
+						s.value = math.Float64frombits(bits)
+					} else {
+						timestamp, _ := protowire.ConsumeVarint(v)
+						s.timestamp = int64(timestamp)
+					}
+				})
+				ts.samples = append(ts.samples, s)
+			}
+		})
+		series = append(series, ts)
+	})
+	return series
+}
+
+// eachField calls fn with the number and the raw value of each field of b
+func eachField(t *testing.T, b []byte, fn func(protowire.Number, []byte)) {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		require.GreaterOrEqual(t, m, 0)
+		v := b[:m]
+		if typ == protowire.BytesType {
+			v, _ = protowire.ConsumeBytes(v)
+		}
+		fn(num, v)
+		b = b[m:]
+	}
+}
+
+func newTestSink(t *testing.T, url string) *Sink {
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("prometheus_remote_write.url", url)
+	cfg.SetWithoutSource("prometheus_remote_write.headers", map[string]string{"Authorization": "Bearer token"})
+	cfg.SetWithoutSource("forwarder_backoff_base", 0.01)
+	cfg.SetWithoutSource("forwarder_backoff_max", 0.05)
+	sink, err := NewSink(cfg, logmock.New(t))
+	require.NoError(t, err)
+	t.Cleanup(sink.Stop)
+	return sink
+}
+
+func TestNewSinkWithoutURL(t *testing.T) {
+	_, err := NewSink(configmock.New(t), logmock.New(t))
+	assert.Error(t, err)
+}
+
+func TestSinkSeries(t *testing.T) {
+	r := newReceiver(t)
+	sink := newTestSink(t, r.URL)
+
+	source := sink.RecordSeries(metricsserializer.CreateSerieSource(metrics.Series{
+		{
+			Name:   "http.requests",
+			Host:   "web01",
+			Tags:   tagset.CompositeTagsFromSlice([]string{"env:prod", "1team:core", "env:staging", "canary"}),
+			Points: []metrics.Point{{Ts: 10, Value: 1}, {Ts: 20.5, Value: 2}},
+		},
+		{
+			Name:   "no.points",
+			Points: []metrics.Point{},
+		},
+		{
+			Name:   "system.load",
+			Points: []metrics.Point{{Ts: 10, Value: 0.5}},
+		},
+	}))
+	count := 0
+	for source.MoveNext() {
+		count++
+	}
+	source.Flush()
+	assert.Equal(t, 3, count)
+
+	series := r.next(t)
+	require.Len(t, series, 2)
+	assert.Equal(t, map[string]string{
+		"__name__": "http_requests",
+		"host":     "web01",
+		"env":      "prod,staging",
+		"_1team":   "core",
+		"canary":   "true",
+	}, series[0].labels)
+	assert.Equal(t, []sample{{value: 1, timestamp: 10000}, {value: 2, timestamp: 20500}}, series[0].samples)
+	assert.Equal(t, map[string]string{"__name__": "system_load"}, series[1].labels)
+	assert.Equal(t, []sample{{value: 0.5, timestamp: 10000}}, series[1].samples)
+
+	headers := <-r.headers
+	assert.Equal(t, "snappy", headers.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", headers.Get("Content-Type"))
+	assert.Equal(t, "0.1.0", headers.Get("X-Prometheus-Remote-Write-Version"))
+	assert.Equal(t, "Bearer token", headers.Get("Authorization"))
+}
+
+func TestSinkSketches(t *testing.T) {
+	r := newReceiver(t)
+	sink := newTestSink(t, r.URL)
+
+	sketch := &quantile.Sketch{}
+	for i := 1; i <= 1000; i++ {
+		sketch.Insert(quantile.Default(), float64(i))
+	}
+	sketches := metrics.NewSketchesSourceTest()
+	sketches.Append(&metrics.SketchSeries{
+		Name:   "request.latency",
+		Tags:   tagset.CompositeTagsFromSlice([]string{"service:web"}),
+		Points: []metrics.SketchPoint{{Ts: 10, Sketch: sketch}},
+	})
+	source := sink.RecordSketches(sketches)
+	for source.MoveNext() {
+	}
+	source.Flush()
+
+	series := r.next(t)
+	require.Len(t, series, 2+len(sketchQuantiles))
+	assert.Equal(t, map[string]string{"__name__": "request_latency_count", "service": "web"}, series[0].labels)
+	assert.Equal(t, []sample{{value: 1000, timestamp: 10000}}, series[0].samples)
+	assert.Equal(t, map[string]string{"__name__": "request_latency_sum", "service": "web"}, series[1].labels)
+	assert.Equal(t, []sample{{value: 500500, timestamp: 10000}}, series[1].samples)
+	assert.Equal(t, map[string]string{"__name__": "request_latency", "quantile": "0.5", "service": "web"}, series[2].labels)
+	assert.InEpsilon(t, 500, series[2].samples[0].value, 0.02)
+	assert.Equal(t, map[string]string{"__name__": "request_latency", "quantile": "0.99", "service": "web"}, series[5].labels)
+	assert.InEpsilon(t, 990, series[5].samples[0].value, 0.02)
+}
+
+func TestSenderRetry(t *testing.T) {
+	r := newReceiver(t)
+	sink := newTestSink(t, r.URL)
+
+	// retryable errors are retried, the others are dropped
+	r.statuses <- http.StatusServiceUnavailable
+	r.statuses <- http.StatusTooManyRequests
+	sink.sender.submit(snappy.Encode(nil, nil))
+	assert.Empty(t, r.next(t))
+
+	r.statuses <- http.StatusBadRequest
+	sink.sender.submit(snappy.Encode(nil, nil))
+	source := sink.RecordSeries(metricsserializer.CreateSerieSource(metrics.Series{
+		{Name: "system.load", Points: []metrics.Point{{Ts: 10, Value: 0.5}}},
+	}))
+	for source.MoveNext() {
+	}
+	source.Flush()
+	series := r.next(t)
+	require.Len(t, series, 1)
+	assert.Equal(t, "system_load", series[0].labels["__name__"])
+}
+
+func TestSinkStop(t *testing.T) {
+	r := newReceiver(t)
+
+	// a sink which never had a payload has nothing to stop
+	sink := newTestSink(t, r.URL)
+	sink.Stop()
+	assert.False(t, sink.sender.started)
+
+	// the sender is started by the first payload and stopped once
+	sink = newTestSink(t, r.URL)
+	sink.sender.submit(snappy.Encode(nil, nil))
+	assert.Empty(t, r.next(t))
+	assert.True(t, sink.sender.started)
+	sink.Stop()
+	sink.Stop()
+	select {
+	case <-sink.sender.stoppedChan:
+	default:
+		assert.Fail(t, "the sender is still running")
+	}
+
+	// the payloads submitted once stopped are dropped
+	dropped := expvarsPayloadsDropped.Value()
+	sink.sender.submit(snappy.Encode(nil, nil))
+	assert.Equal(t, dropped+1, expvarsPayloadsDropped.Value())
+}
+
+func TestSenderQueueMaxSize(t *testing.T) {
+	cfg := configmock.New(t)
+	cfg.SetWithoutSource("prometheus_remote_write.retry_queue_max_size", 10)
+	s := newSender(cfg, logmock.New(t), "", nil, time.Second)
+
+	s.enqueue(make([]byte, 4))
+	s.enqueue(make([]byte, 4))
+	s.enqueue(make([]byte, 4))
+	assert.Len(t, s.queue, 2)
+	assert.Equal(t, 8, s.queueSize)
+
+	// payloads bigger than the queue are kept until they are sent
+	s.enqueue(make([]byte, 20))
+	assert.Len(t, s.queue, 1)
+	assert.Equal(t, 20, s.queueSize)
+}
+
+func TestSanitizeName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"system.load.1": "system_load_1",
+		"valid_name":    "valid_name",
+		"ns:metric":     "ns:metric",
+		"2xx":           "_2xx",
+		"":              "_",
+		"kube-pod.é":    "kube_pod___",
+	} {
+		assert.Equal(t, expected, sanitizeName(name, true), name)
+	}
+	assert.Equal(t, "ns_label", sanitizeName("ns:label", false))
+}
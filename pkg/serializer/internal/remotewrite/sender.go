@@ -0,0 +1,221 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/util/backoff"
+)
+
+// inputChanSize is the number of payloads waiting to be queued by the sender
+const inputChanSize = 100
+
+var (
+	expvars                = expvar.NewMap("prometheus_remote_write")
+	expvarsPayloadsSent    = expvar.Int{}
+	expvarsPayloadsErrors  = expvar.Int{}
+	expvarsPayloadsDropped = expvar.Int{}
+	expvarsPayloadsRetried = expvar.Int{}
+)
+
+func init() {
+	expvars.Set("PayloadsSent", &expvarsPayloadsSent)
+	expvars.Set("PayloadsErrors", &expvarsPayloadsErrors)
+	expvars.Set("PayloadsDropped", &expvarsPayloadsDropped)
+	expvars.Set("PayloadsRetried", &expvarsPayloadsRetried)
+}
+
+// sender sends the payloads to the remote-write endpoint in order. Like the
+// forwarder, the payloads failing with a retryable error are kept in a retry
+// queue, bounded in bytes, and retried with an exponential backoff.
+type sender struct {
+	client  *http.Client
+	url     string
+	headers http.Header
+	logger  log.Component
+
+	input         chan []byte
+	queue         [][]byte
+	queueSize     int
+	maxQueueSize  int
+	backoffPolicy backoff.Policy
+	numErrors     int
+	stopChan      chan struct{}
+	stoppedChan   chan struct{}
+
+	// m protects started and stopped, the sender is started by the first
+	// submitted payload
+	m       sync.Mutex
+	started bool
+	stopped bool
+}
+
+func newSender(config config.Component, logger log.Component, url string, headers http.Header, timeout time.Duration) *sender {
+	return &sender{
+		client:       &http.Client{Timeout: timeout},
+		url:          url,
+		headers:      headers,
+		logger:       logger,
+		input:        make(chan []byte, inputChanSize),
+		maxQueueSize: config.GetInt("prometheus_remote_write.retry_queue_max_size"),
+		backoffPolicy: backoff.NewExpBackoffPolicy(
+			config.GetFloat64("forwarder_backoff_factor"),
+			config.GetFloat64("forwarder_backoff_base"),
+			config.GetFloat64("forwarder_backoff_max"),
+			config.GetInt("forwarder_recovery_interval"),
+			config.GetBool("forwarder_recovery_reset"),
+		),
+		stopChan:    make(chan struct{}),
+		stoppedChan: make(chan struct{}),
+	}
+}
+
+func (s *sender) stop() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	if s.started {
+		close(s.stopChan)
+		<-s.stoppedChan
+	}
+}
+
+// submit queues a payload without blocking, starting the sender if needed.
+// The payload is dropped if the sender is late or stopped.
+func (s *sender) submit(payload []byte) {
+	s.m.Lock()
+	if s.stopped {
+		s.m.Unlock()
+		expvarsPayloadsDropped.Add(1)
+		return
+	}
+	if !s.started {
+		s.started = true
+		go s.run()
+	}
+	s.m.Unlock()
+
+	select {
+	case s.input <- payload:
+	default:
+		expvarsPayloadsDropped.Add(1)
+		s.logger.Warnf("Prometheus remote-write sender is late, dropping a payload of %d bytes", len(payload))
+	}
+}
+
+func (s *sender) run() {
+	defer close(s.stoppedChan)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stopChan
+		cancel()
+	}()
+
+	var retry <-chan time.Time
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case payload := <-s.input:
+			s.enqueue(payload)
+			if retry != nil {
+				// wait for the backoff before sending the queue again
+				continue
+			}
+		case <-retry:
+			retry = nil
+		}
+
+		if !s.sendQueue(ctx) {
+			retry = time.After(s.backoffPolicy.GetBackoffDuration(s.numErrors))
+		}
+	}
+}
+
+// enqueue adds the payload to the queue, dropping the oldest payloads if
+// the queue is full.
+func (s *sender) enqueue(payload []byte) {
+	s.queue = append(s.queue, payload)
+	s.queueSize += len(payload)
+	for s.queueSize > s.maxQueueSize && len(s.queue) > 1 {
+		s.queueSize -= len(s.queue[0])
+		s.queue = s.queue[1:]
+		expvarsPayloadsDropped.Add(1)
+		s.logger.Warnf("Prometheus remote-write retry queue is full, dropping the oldest payload")
+	}
+}
+
+// sendQueue sends the queued payloads in order, and returns false if a
+// payload failed with a retryable error.
+func (s *sender) sendQueue(ctx context.Context) bool {
+	for len(s.queue) > 0 {
+		payload := s.queue[0]
+		retryable, err := s.send(ctx, payload)
+		if ctx.Err() != nil {
+			// the sender is stopped
+			return false
+		}
+		if err != nil {
+			expvarsPayloadsErrors.Add(1)
+		}
+		if err != nil && retryable {
+			s.numErrors = s.backoffPolicy.IncError(s.numErrors)
+			expvarsPayloadsRetried.Add(1)
+			s.logger.Warnf("Error sending a Prometheus remote-write payload, retrying later: %v", err)
+			return false
+		}
+		if err != nil {
+			expvarsPayloadsDropped.Add(1)
+			s.logger.Errorf("Error sending a Prometheus remote-write payload, dropping it: %v", err)
+		} else {
+			expvarsPayloadsSent.Add(1)
+			s.numErrors = s.backoffPolicy.DecError(s.numErrors)
+		}
+		s.queue[0] = nil
+		s.queue = s.queue[1:]
+		s.queueSize -= len(payload)
+	}
+	return true
+}
+
+// send sends a payload and returns whether the request can be retried if it
+// failed. As in the remote-write specification, the requests failing with 5xx
+// and 429 status codes, or without response, are retried.
+func (s *sender) send(ctx context.Context, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+	req.Header = s.headers.Clone()
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	err = fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	return resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests, err
+}
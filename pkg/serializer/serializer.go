@@ -26,6 +26,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/process/util/api/headers"
 	metricsserializer "github.com/DataDog/datadog-agent/pkg/serializer/internal/metrics"
+	"github.com/DataDog/datadog-agent/pkg/serializer/internal/remotewrite"
 	"github.com/DataDog/datadog-agent/pkg/serializer/internal/stream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
@@ -131,6 +132,10 @@ type Serializer struct {
 	enableSketchProtobufStream    bool
 	hostname                      string
 	logger                        log.Component
+
+	// remoteWrite is the optional Prometheus remote-write output of the
+	// series and sketches, nil if disabled
+	remoteWrite *remotewrite.Sink
 }
 
 // NewSerializer returns a new Serializer initialized
@@ -178,6 +183,15 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder orchestr
 		logger.Warn("JSON to V1 intake is disabled: all payloads to that endpoint will be dropped")
 	}
 
+	if config.GetBool("prometheus_remote_write.enabled") {
+		remoteWrite, err := remotewrite.NewSink(config, logger)
+		if err != nil {
+			logger.Errorf("Prometheus remote-write output is disabled: %v", err)
+		} else {
+			s.remoteWrite = remoteWrite
+		}
+	}
+
 	if !config.GetBool("enable_sketch_stream_payload_serialization") {
 		logger.Warn("'enable_sketch_stream_payload_serialization' is set to false which is not recommended. This option is deprecated and will removed in the future. If you need this option, please reach out to support")
 	}
@@ -185,6 +199,14 @@ func NewSerializer(forwarder forwarder.Forwarder, orchestratorForwarder orchestr
 	return s
 }
 
+// Stop stops the outputs of the serializer which are not shared, i.e. the
+// Prometheus remote-write output.
+func (s *Serializer) Stop() {
+	if s.remoteWrite != nil {
+		s.remoteWrite.Stop()
+	}
+}
+
 func (s Serializer) serializePayload(
 	jsonMarshaler marshaler.JSONMarshaler,
 	protoMarshaler marshaler.ProtoMarshaler,
@@ -339,6 +361,12 @@ func (s *Serializer) SendIterableSeries(serieSource metrics.SerieSource) error {
 		return nil
 	}
 
+	if s.remoteWrite != nil {
+		recorder := s.remoteWrite.RecordSeries(serieSource)
+		defer recorder.Flush()
+		serieSource = recorder
+	}
+
 	seriesSerializer := metricsserializer.CreateIterableSeries(serieSource)
 	useV1API := !s.config.GetBool("use_v2_api.series")
 
@@ -434,6 +462,11 @@ func (s *Serializer) SendSketch(sketches metrics.SketchesSource) error {
 		s.logger.Debug("sketches payloads are disabled: dropping it")
 		return nil
 	}
+	if s.remoteWrite != nil {
+		recorder := s.remoteWrite.RecordSketches(sketches)
+		defer recorder.Flush()
+		sketches = recorder
+	}
 	sketchesSerializer := metricsserializer.SketchSeriesList{SketchesSource: sketches}
 	if s.enableSketchProtobufStream {
 		failoverActive, allowlist := s.getFailoverAllowlist()
@@ -9,10 +9,13 @@ package serializer
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/protocolbuffers/protoscope"
@@ -437,6 +440,39 @@ func TestSendSketch(t *testing.T) {
 
 }
 
+func TestSendSeriesWithRemoteWrite(t *testing.T) {
+	requests := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- body
+	}))
+	defer server.Close()
+
+	f := &forwarder.MockedForwarder{}
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("prometheus_remote_write.enabled", true)
+	mockConfig.SetWithoutSource("prometheus_remote_write.url", server.URL)
+
+	compressor := metricscompressionimpl.NewCompressorReq(metricscompressionimpl.Requires{Cfg: mockConfig}).Comp
+	s := NewSerializer(f, nil, compressor, mockConfig, logmock.New(t), "testhost")
+	require.NotNil(t, s.remoteWrite)
+	defer s.Stop()
+	f.On("SubmitSeries", mock.Anything, s.protobufExtraHeadersWithCompression).Return(nil).Times(1)
+
+	err := s.SendIterableSeries(metricsserializer.CreateSerieSource(metrics.Series{
+		&metrics.Serie{Name: "system.load", Points: []metrics.Point{{Ts: 10, Value: 0.5}}},
+	}))
+	require.NoError(t, err)
+	f.AssertExpectations(t)
+
+	select {
+	case body := <-requests:
+		assert.NotEmpty(t, body)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no remote-write request received")
+	}
+}
+
 func TestSendMetadata(t *testing.T) {
 
 	tests := map[string]struct {
@@ -89,12 +89,15 @@ type Agent struct {
 	ClientStatsAggregator *stats.ClientStatsAggregator
 	Blacklister           *filters.Blacklister
 	Replacer              *filters.Replacer
+	PIIRedactor           *filters.PIIRedactor // nil unless the PII redaction is enabled
 	PrioritySampler       *sampler.PrioritySampler
 	ErrorsSampler         *sampler.ErrorsSampler
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	ProbabilisticSampler  *sampler.ProbabilisticSampler
+	RuleSampler           *sampler.RuleSampler
 	SamplerMetrics        *sampler.Metrics
+	TailSampler           *TailSampler // nil unless the tail-based sampling is enabled
 	EventProcessor        *event.Processor
 	TraceWriter           TraceWriter
 	StatsWriter           *writer.DatadogStatsWriter
@@ -157,6 +160,7 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		RareSampler:           sampler.NewRareSampler(conf),
 		NoPrioritySampler:     sampler.NewNoPrioritySampler(conf),
 		ProbabilisticSampler:  sampler.NewProbabilisticSampler(conf),
+		RuleSampler:           sampler.NewRuleSampler(conf),
 		SamplerMetrics:        sampler.NewMetrics(statsd),
 		EventProcessor:        newEventProcessor(conf, statsd),
 		StatsWriter:           statsWriter,
@@ -169,9 +173,15 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		Timing:                timing,
 	}
 	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler)
+	if conf.PIIRedaction.Enabled {
+		agnt.PIIRedactor = filters.NewPIIRedactor(conf.PIIRedaction.Rules, statsd)
+	}
+	if conf.TailSampling.Enabled {
+		agnt.TailSampler = NewTailSampler(conf.TailSampling, statsd, agnt.processTailSampledTraces)
+	}
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector, statsd, timing)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, statsd, timing)
-	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
+	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler, agnt.RuleSampler)
 	agnt.TraceWriter = writer.NewTraceWriter(conf, agnt.PrioritySampler, agnt.ErrorsSampler, agnt.RareSampler, telemetryCollector, statsd, timing, comp)
 	return agnt
 }
@@ -192,6 +202,9 @@ func (a *Agent) Run() {
 	} {
 		starter.Start()
 	}
+	if a.TailSampler != nil {
+		a.TailSampler.Start()
+	}
 
 	go a.StatsWriter.Run()
 
@@ -264,6 +277,7 @@ func (a *Agent) loop() {
 	for _, stopper := range []interface{ Stop() }{
 		a.Concentrator,
 		a.ClientStatsAggregator,
+		a.TailSampler, // before the TraceWriter as it writes the buffered traces
 		a.TraceWriter,
 		a.StatsWriter,
 		a.SamplerMetrics,
@@ -316,6 +330,7 @@ func (a *Agent) Process(p *api.Payload) {
 	defer a.Timing.Since("datadog.trace_agent.internal.process_payload_ms", now)
 	ts := p.Source
 	sampledChunks := new(writer.SampledChunks)
+	var tailSampled []*traceutil.ProcessedTrace
 	statsInput := stats.NewStatsInput(len(p.TracerPayload.Chunks), p.TracerPayload.ContainerID, p.ClientComputedStats, a.conf)
 
 	p.TracerPayload.Env = traceutil.NormalizeTagValue(p.TracerPayload.Env)
@@ -378,6 +393,9 @@ func (a *Agent) Process(p *api.Payload) {
 			}
 		}
 		a.Replacer.Replace(chunk.Spans)
+		if a.PIIRedactor != nil {
+			a.PIIRedactor.Redact(chunk.Spans)
+		}
 
 		a.setRootSpanTags(root)
 		if !p.ClientComputedTopLevel {
@@ -393,6 +411,14 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		if a.TailSampler != nil {
+			// The chunk is sampled and written once its whole trace is decided,
+			// it is buffered once its stats are computed.
+			tailSampled = append(tailSampled, pt)
+			p.RemoveChunk(i)
+			continue
+		}
+
 		keep, numEvents := a.sample(now, ts, pt)
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
@@ -422,6 +448,10 @@ func (a *Agent) Process(p *api.Payload) {
 			sampledChunks = new(writer.SampledChunks)
 		}
 	}
+	var tailSampledPayload *pb.TracerPayload
+	if len(tailSampled) > 0 {
+		tailSampledPayload = tracerPayloadMetadata(p.TracerPayload)
+	}
 	sampledChunks.TracerPayload = p.TracerPayload
 	sampledChunks.TracerPayload.Chunks = newChunksArray(p.TracerPayload.Chunks)
 	if sampledChunks.Size > 0 {
@@ -430,6 +460,12 @@ func (a *Agent) Process(p *api.Payload) {
 	if len(statsInput.Traces) > 0 {
 		a.Concentrator.Add(statsInput)
 	}
+	// The stats share the spans of the chunks, which are modified when they
+	// are sampled: the chunks are passed to the TailSampler once the stats are
+	// computed, as they can be sampled by another goroutine from then on.
+	for _, pt := range tailSampled {
+		a.TailSampler.Add(now, ts, tailSampledPayload, pt)
+	}
 }
 
 func (a *Agent) setPayloadAttributes(p *api.Payload, root *pb.Span, chunk *pb.TraceChunk) {
@@ -637,10 +673,11 @@ func (a *Agent) getAnalyzedEvents(pt *traceutil.ProcessedTrace, ts *info.TagStat
 //
 // If the agent is set as Error Tracking Standalone, only the ErrorSampler is run (other samplers are bypassed).
 // Otherwise, the rare sampler is run first, catching all rare traces early. If the probabilistic sampler is
-// enabled, it is run on the trace, followed by the error sampler. Otherwise, If the trace has a
-// priority set, the sampling priority is used with the Priority Sampler. When there is no priority
-// set, the NoPrioritySampler is run. Finally, if the trace has not been sampled by the other
-// samplers, the error sampler is run.
+// enabled, it is run on the trace, followed by the error sampler. Otherwise, if an agent-side
+// sampling rule matches the trace, the rule decides on it unless it was manually kept. If not, and
+// the trace has a priority set, the sampling priority is used with the Priority Sampler. When there
+// is no priority set, the NoPrioritySampler is run. Finally, if the trace has not been sampled by
+// the other samplers, the error sampler is run.
 func (a *Agent) runSamplers(now time.Time, ts *info.TagStats, pt traceutil.ProcessedTrace) (keep bool, checkAnalyticsEvents bool) {
 	samplerName := sampler.NameUnknown
 	samplingPriority := sampler.PriorityNone
@@ -707,11 +744,27 @@ func (a *Agent) runSamplers(now time.Time, ts *info.TagStats, pt traceutil.Proce
 		return true, true
 	}
 
-	if hasPriority {
+	// The agent-side sampling rules override the decision of the tracer,
+	// unless the trace was kept manually.
+	var ruleMatched bool
+	if priority != sampler.PriorityUserKeep {
+		var keep bool
+		if keep, ruleMatched = a.RuleSampler.Sample(now, pt.Root, pt.TracerEnv); ruleMatched {
+			samplerName = sampler.NameRule
+			if keep {
+				return true, true
+			}
+		}
+	}
+
+	switch {
+	case ruleMatched:
+		// dropped by the rule, only the error sampler can still keep it
+	case hasPriority:
 		if a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight) {
 			return true, true
 		}
-	} else if a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv) {
+	case a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv):
 		return true, true
 	}
 
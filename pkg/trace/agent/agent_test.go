@@ -44,6 +44,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/timing"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
 	mockStatsd "github.com/DataDog/datadog-go/v5/statsd/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -208,6 +209,36 @@ func TestProcess(t *testing.T) {
 		assert.Equal(t, 42.0, span.Metrics["safe.data"])
 	})
 
+	t.Run("PIIRedactor", func(t *testing.T) {
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.PIIRedaction = config.PIIRedactionConfig{
+			Enabled: true,
+			Rules:   []*config.PIIRedactionRule{{Key: "user.*", Detectors: []string{config.PIIDetectorEmail}}},
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+		defer cancel()
+
+		now := time.Now()
+		span := &pb.Span{
+			TraceID:  1,
+			SpanID:   1,
+			Resource: "resource",
+			Type:     "web",
+			Start:    now.Add(-time.Second).UnixNano(),
+			Duration: (500 * time.Millisecond).Nanoseconds(),
+			Meta:     map[string]string{"user.email": "jane@example.com", "keep.me": "jane@example.com"},
+		}
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpan(span)),
+			Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+
+		assert.Equal(t, "?", span.Meta["user.email"])
+		assert.Equal(t, "jane@example.com", span.Meta["keep.me"])
+	})
+
 	t.Run("Blacklister", func(t *testing.T) {
 		cfg := config.New()
 		cfg.Endpoints[0].APIKey = "test"
@@ -1079,6 +1110,7 @@ func TestSampling(t *testing.T) {
 		a := &Agent{
 			NoPrioritySampler:    sampler.NewNoPrioritySampler(cfg),
 			ErrorsSampler:        sampler.NewErrorsSampler(cfg),
+			RuleSampler:          sampler.NewRuleSampler(cfg),
 			PrioritySampler:      sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 			RareSampler:          sampler.NewRareSampler(cfg),
 			ProbabilisticSampler: sampler.NewProbabilisticSampler(cfg),
@@ -1848,6 +1880,7 @@ func TestSampleTrace(t *testing.T) {
 			a := &Agent{
 				NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
 				ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+				RuleSampler:       sampler.NewRuleSampler(cfg),
 				PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 				RareSampler:       sampler.NewRareSampler(config.New()),
 				EventProcessor:    newEventProcessor(cfg, statsd),
@@ -1981,6 +2014,7 @@ func TestSample(t *testing.T) {
 		a := &Agent{
 			NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
 			ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+			RuleSampler:       sampler.NewRuleSampler(cfg),
 			PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 			RareSampler:       sampler.NewRareSampler(config.New()),
 			EventProcessor:    newEventProcessor(cfg, statsd),
@@ -2000,6 +2034,56 @@ func TestSample(t *testing.T) {
 	}
 }
 
+func TestSampleWithRules(t *testing.T) {
+	now := time.Now()
+	cfg := &config.AgentConfig{TargetTPS: 5, ErrorTPS: 1000, Features: make(map[string]struct{})}
+	cfg.SamplingRules = []*config.SamplingRule{
+		{Service: "serv1", Resource: "GET /health", SampleRate: pointer.Ptr(0.0)},
+		{Service: "serv1", Resource: "GET /debug", SampleRate: pointer.Ptr(1.0)},
+	}
+	statsd := &statsd.NoOpClient{}
+	a := &Agent{
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		RuleSampler:       sampler.NewRuleSampler(cfg),
+		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
+		RareSampler:       sampler.NewRareSampler(config.New()),
+		EventProcessor:    newEventProcessor(cfg, statsd),
+		SamplerMetrics:    sampler.NewMetrics(statsd),
+		conf:              cfg,
+	}
+	genTrace := func(resource string, priority sampler.SamplingPriority, err int32) traceutil.ProcessedTrace {
+		root := &pb.Span{
+			Service:  "serv1",
+			Resource: resource,
+			Start:    now.UnixNano(),
+			Duration: (100 * time.Millisecond).Nanoseconds(),
+			Metrics:  map[string]float64{"_top_level": 1},
+			Error:    err,
+		}
+		chunk := testutil.TraceChunkWithSpan(root)
+		chunk.Priority = int32(priority)
+		return traceutil.ProcessedTrace{TraceChunk: chunk, Root: root}
+	}
+
+	for name, tt := range map[string]struct {
+		trace traceutil.ProcessedTrace
+		keep  bool
+	}{
+		"rule-drops-autokeep":   {trace: genTrace("GET /health", sampler.PriorityAutoKeep, 0), keep: false},
+		"rule-keeps-autodrop":   {trace: genTrace("GET /debug", sampler.PriorityAutoDrop, 0), keep: true},
+		"rule-keeps-nopriority": {trace: genTrace("GET /debug", sampler.PriorityNone, 0), keep: true},
+		"userkeep-overrides":    {trace: genTrace("GET /health", sampler.PriorityUserKeep, 0), keep: true},
+		"error-sampler":         {trace: genTrace("GET /health", sampler.PriorityAutoKeep, 1), keep: true},
+		"no-rule-matching":      {trace: genTrace("GET /users", sampler.PriorityAutoKeep, 0), keep: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			keep, _ := a.sample(now, info.NewReceiverStats().GetTagStats(info.Tags{}), &tt.trace)
+			assert.Equal(t, tt.keep, keep)
+		})
+	}
+}
+
 func TestSampleManualUserDropNoAnalyticsEvents(t *testing.T) {
 	// This test exists to confirm previous behavior where we did not extract nor tag analytics events on
 	// user manual drop traces
@@ -2019,6 +2103,7 @@ func TestSampleManualUserDropNoAnalyticsEvents(t *testing.T) {
 	a := &Agent{
 		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
 		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		RuleSampler:       sampler.NewRuleSampler(cfg),
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 		RareSampler:       sampler.NewRareSampler(config.New()),
 		EventProcessor:    newEventProcessor(cfg, statsd),
@@ -2041,6 +2126,7 @@ func TestPartialSamplingFree(t *testing.T) {
 		Replacer:          filters.NewReplacer(cfg.ReplaceTags),
 		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
 		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		RuleSampler:       sampler.NewRuleSampler(cfg),
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 		EventProcessor:    newEventProcessor(cfg, statsd),
 		RareSampler:       sampler.NewRareSampler(config.New()),
@@ -7,6 +7,7 @@ package agent
 
 import (
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/obfuscate"
@@ -18,6 +19,8 @@ import (
 const (
 	tagRedisRawCommand  = transform.TagRedisRawCommand
 	tagMemcachedCommand = transform.TagMemcachedCommand
+	tagGraphQLQuery     = transform.TagGraphQLQuery
+	tagGraphQLSource    = transform.TagGraphQLSource
 	tagMongoDBQuery     = transform.TagMongoDBQuery
 	tagElasticBody      = transform.TagElasticBody
 	tagOpenSearchBody   = transform.TagOpenSearchBody
@@ -80,6 +83,16 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagMemcachedCommand] = o.ObfuscateMemcachedString(span.Meta[tagMemcachedCommand])
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		span.Resource = obfuscateGraphQLResource(o, span.Resource)
+		for _, k := range []string{tagGraphQLQuery, tagGraphQLSource} {
+			if span.Meta[k] != "" {
+				span.Meta[k] = o.ObfuscateGraphQLString(span.Meta[k])
+			}
+		}
 	case "web", "http":
 		if span.Meta == nil || span.Meta[tagHTTPURL] == "" {
 			return
@@ -170,7 +183,20 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis", "valkey":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if a.conf.Obfuscation.GraphQL.Enabled {
+			b.Resource = obfuscateGraphQLResource(o, b.Resource)
+		}
+	}
+}
+
+// obfuscateGraphQLResource obfuscates the resource of a GraphQL span when it holds a query.
+// Some tracers set it to the operation name instead, which is kept as is.
+func obfuscateGraphQLResource(o *obfuscate.Obfuscator, resource string) string {
+	if !strings.Contains(resource, "{") {
+		return resource
 	}
+	return o.ObfuscateGraphQLString(resource)
 }
 
 var (
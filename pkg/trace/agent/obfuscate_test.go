@@ -296,6 +296,63 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ me: user(id: "123") { name } }`,
+		"{ me: user(id: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/source", testConfig(
+		"graphql",
+		"graphql.source",
+		`{ me: user(id: "123") { name } }`,
+		"{ me: user(id: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/remove_aliases", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ me: user(id: "123") { name } }`,
+		"{ user(id: ?) { name } }",
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{
+			Enabled:       true,
+			RemoveAliases: true,
+		}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`{ me: user(id: "123") { name } }`,
+		`{ me: user(id: "123") { name } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("graphql/resource", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+
+		span := &pb.Span{Type: "graphql", Resource: `query GetUser { user(id: 1) { name } }`}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "query GetUser { user(id: ?) { name } }", span.Resource)
+
+		// operation names are kept as is
+		span = &pb.Span{Type: "graphql", Resource: "GetUser"}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "GetUser", span.Resource)
+
+		stats := &pb.ClientGroupedStats{Type: "graphql", Resource: `{ user(id: 1) { name } }`}
+		agnt.obfuscateStatsGroup(stats)
+		assert.Equal(t, "{ user(id: ?) { name } }", stats.Resource)
+	})
+
 	t.Run("creditcard", func(t *testing.T) {
 		for _, tt := range []struct {
 			k, v string
@@ -0,0 +1,365 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+	"github.com/DataDog/datadog-agent/pkg/trace/writer"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	// tagTailSamplingRule is set on the chunks kept by a tail sampling rule.
+	tagTailSamplingRule = "_dd.tail_sampling.rule"
+
+	// tailSamplingFlushInterval is the interval at which the buffered traces
+	// are checked for a decision.
+	tailSamplingFlushInterval = time.Second
+
+	// tailSamplingEvictedQueueSize is the number of batches of evicted traces
+	// waiting for a decision, Add blocks once it is reached.
+	tailSamplingEvictedQueueSize = 100
+)
+
+// tailSampledChunk is a chunk buffered by the TailSampler.
+type tailSampledChunk struct {
+	pt *traceutil.ProcessedTrace
+	// payload holds the metadata of the payload the chunk was received in,
+	// without its chunks.
+	payload *pb.TracerPayload
+	source  *info.TagStats
+	size    int
+}
+
+// tailSampledTrace holds the buffered chunks of a trace.
+type tailSampledTrace struct {
+	traceID  uint64
+	received time.Time // reception of the first chunk
+	chunks   []tailSampledChunk
+	size     int
+	// rule is the name of the rule keeping the trace, it is empty when the
+	// trace is left to the other samplers.
+	rule string
+}
+
+// keptTrace is a trace kept by a rule, remembered for DecisionWait after the
+// decision so that its late chunks are kept as well.
+type keptTrace struct {
+	rule  string
+	until time.Time
+}
+
+// TailSampler buffers the trace chunks by trace ID for DecisionWait and
+// decides on whole traces: the traces matching one of the rules are kept,
+// the chunks of the others are sampled by the other samplers as if they
+// had not been buffered.
+//
+// The buffered chunks are limited to MaxMemory bytes, the oldest traces are
+// decided early when it is exceeded. Like the traces whose DecisionWait is
+// over, they are decided by the goroutine of the TailSampler, never by the
+// goroutine adding a chunk.
+//
+// The chunks must be added once the concentrator computed their stats, as
+// sampling them modifies their spans.
+type TailSampler struct {
+	conf   config.TailSamplingConfig
+	statsd statsd.ClientInterface
+	// process samples and writes the chunks of the decided traces.
+	process func(now time.Time, traces []*tailSampledTrace)
+
+	mu     sync.Mutex
+	traces map[uint64]*tailSampledTrace
+	queue  []*tailSampledTrace // by reception, which is also the decision order
+	size   int64
+	kept   map[uint64]keptTrace
+
+	// evicted holds the traces evicted by Add, until they are decided.
+	evicted chan []*tailSampledTrace
+
+	exit chan struct{}
+	done chan struct{}
+}
+
+// NewTailSampler creates a TailSampler passing the decided traces to process.
+func NewTailSampler(conf config.TailSamplingConfig, statsd statsd.ClientInterface, process func(now time.Time, traces []*tailSampledTrace)) *TailSampler {
+	return &TailSampler{
+		conf:    conf,
+		statsd:  statsd,
+		process: process,
+		traces:  make(map[uint64]*tailSampledTrace),
+		kept:    make(map[uint64]keptTrace),
+		evicted: make(chan []*tailSampledTrace, tailSamplingEvictedQueueSize),
+		exit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start starts deciding on the buffered traces once their DecisionWait is over.
+func (t *TailSampler) Start() {
+	log.Infof("Tail sampling enabled: buffering traces for %v with %d rules", t.conf.DecisionWait, len(t.conf.Rules))
+	go func() {
+		defer watchdog.LogOnPanic(t.statsd)
+		defer close(t.done)
+		ticker := time.NewTicker(tailSamplingFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				t.flush(now, false)
+			case traces := <-t.evicted:
+				t.decide(time.Now(), traces)
+			case <-t.exit:
+				t.drainEvicted()
+				t.flush(time.Now(), true)
+				return
+			}
+		}
+	}()
+}
+
+// Stop decides on all the buffered traces and stops the TailSampler.
+func (t *TailSampler) Stop() {
+	close(t.exit)
+	<-t.done
+}
+
+// Add buffers the chunk until the decision on its trace. The chunks of the
+// traces already kept by a rule are written right away.
+func (t *TailSampler) Add(now time.Time, source *info.TagStats, payload *pb.TracerPayload, pt *traceutil.ProcessedTrace) {
+	chunk := tailSampledChunk{pt: pt, payload: payload, source: source, size: pt.TraceChunk.Msgsize()}
+	traceID := pt.TraceChunk.Spans[0].TraceID
+
+	t.mu.Lock()
+	if kept, ok := t.kept[traceID]; ok && now.Before(kept.until) {
+		t.mu.Unlock()
+		t.process(now, []*tailSampledTrace{{traceID: traceID, received: now, chunks: []tailSampledChunk{chunk}, size: chunk.size, rule: kept.rule}})
+		return
+	}
+
+	trace, ok := t.traces[traceID]
+	if !ok {
+		trace = &tailSampledTrace{traceID: traceID, received: now}
+		t.traces[traceID] = trace
+		t.queue = append(t.queue, trace)
+	}
+	trace.chunks = append(trace.chunks, chunk)
+	trace.size += chunk.size
+	t.size += int64(chunk.size)
+
+	n := 0
+	for t.size > t.conf.MaxMemory && n < len(t.queue) {
+		n++
+		t.size -= int64(t.queue[n-1].size)
+	}
+	evicted := t.pop(n)
+	t.mu.Unlock()
+
+	if len(evicted) > 0 {
+		_ = t.statsd.Count("datadog.trace_agent.tail_sampling.evicted", int64(len(evicted)), nil, 1)
+		select {
+		case t.evicted <- evicted:
+		case <-t.done:
+			// the TailSampler is stopped, nothing else decides on the traces
+			t.decide(now, evicted)
+		}
+	}
+}
+
+// drainEvicted decides on the evicted traces waiting for a decision.
+func (t *TailSampler) drainEvicted() {
+	for {
+		select {
+		case traces := <-t.evicted:
+			t.decide(time.Now(), traces)
+		default:
+			return
+		}
+	}
+}
+
+// flush decides on the traces whose DecisionWait is over, or on all the
+// traces when all is true.
+func (t *TailSampler) flush(now time.Time, all bool) {
+	t.mu.Lock()
+	n := 0
+	for n < len(t.queue) && (all || now.Sub(t.queue[n].received) >= t.conf.DecisionWait) {
+		t.size -= int64(t.queue[n].size)
+		n++
+	}
+	traces := t.pop(n)
+	for traceID, kept := range t.kept {
+		if !now.Before(kept.until) {
+			delete(t.kept, traceID)
+		}
+	}
+	buffered, size := len(t.traces), t.size
+	t.mu.Unlock()
+
+	_ = t.statsd.Gauge("datadog.trace_agent.tail_sampling.buffered_traces", float64(buffered), nil, 1)
+	_ = t.statsd.Gauge("datadog.trace_agent.tail_sampling.buffered_bytes", float64(size), nil, 1)
+	if len(traces) > 0 {
+		t.decide(now, traces)
+	}
+}
+
+// pop removes the n oldest traces from the buffer. It must be called with the
+// lock held.
+func (t *TailSampler) pop(n int) []*tailSampledTrace {
+	if n == 0 {
+		return nil
+	}
+	traces := make([]*tailSampledTrace, n)
+	copy(traces, t.queue)
+	for i := 0; i < n; i++ {
+		delete(t.traces, t.queue[i].traceID)
+		t.queue[i] = nil
+	}
+	t.queue = t.queue[n:]
+	return traces
+}
+
+// decide matches the traces against the rules and passes them to process.
+func (t *TailSampler) decide(now time.Time, traces []*tailSampledTrace) {
+	for _, trace := range traces {
+		trace.rule = t.match(trace)
+		if trace.rule == "" {
+			continue
+		}
+		_ = t.statsd.Count("datadog.trace_agent.tail_sampling.kept", 1, []string{"rule:" + trace.rule}, 1)
+		t.mu.Lock()
+		t.kept[trace.traceID] = keptTrace{rule: trace.rule, until: now.Add(t.conf.DecisionWait)}
+		t.mu.Unlock()
+	}
+	t.process(now, traces)
+}
+
+// match returns the name of the first rule matching the trace, or an empty
+// string.
+func (t *TailSampler) match(trace *tailSampledTrace) string {
+	var start, end int64
+	hasError := false
+	for i, chunk := range trace.chunks {
+		for j, span := range chunk.pt.TraceChunk.Spans {
+			if i == 0 && j == 0 || span.Start < start {
+				start = span.Start
+			}
+			if spanEnd := span.Start + span.Duration; spanEnd > end {
+				end = spanEnd
+			}
+			hasError = hasError || span.Error != 0
+		}
+	}
+
+	for _, rule := range t.conf.Rules {
+		switch rule.Type {
+		case config.TailSamplingRuleLatency:
+			if end-start >= rule.MinDurationMs*int64(time.Millisecond) {
+				return rule.Name
+			}
+		case config.TailSamplingRuleError:
+			if hasError {
+				return rule.Name
+			}
+		case config.TailSamplingRuleTag:
+			if traceHasTag(trace, rule.TagKey, rule.TagValue) {
+				return rule.Name
+			}
+		}
+	}
+	return ""
+}
+
+// traceHasTag returns whether a span of the trace has the tag, with the value
+// when it is not empty.
+func traceHasTag(trace *tailSampledTrace, key, value string) bool {
+	for _, chunk := range trace.chunks {
+		for _, span := range chunk.pt.TraceChunk.Spans {
+			if v, ok := span.Meta[key]; ok && (value == "" || v == value) {
+				return true
+			}
+			if v, ok := span.Metrics[key]; ok && (value == "" || strconv.FormatFloat(v, 'f', -1, 64) == value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tracerPayloadMetadata returns a copy of the payload without its chunks.
+func tracerPayloadMetadata(p *pb.TracerPayload) *pb.TracerPayload {
+	return &pb.TracerPayload{
+		ContainerID:     p.ContainerID,
+		LanguageName:    p.LanguageName,
+		LanguageVersion: p.LanguageVersion,
+		TracerVersion:   p.TracerVersion,
+		RuntimeID:       p.RuntimeID,
+		Tags:            p.Tags,
+		Env:             p.Env,
+		Hostname:        p.Hostname,
+		AppVersion:      p.AppVersion,
+	}
+}
+
+// processTailSampledTraces samples the chunks of the traces decided by the
+// TailSampler and writes them. The chunks of the traces kept by a rule are
+// kept, the others are sampled by the other samplers.
+func (a *Agent) processTailSampledTraces(now time.Time, traces []*tailSampledTrace) {
+	payloads := make(map[*pb.TracerPayload]*writer.SampledChunks)
+	for _, trace := range traces {
+		for _, chunk := range trace.chunks {
+			pt := chunk.pt
+			var keep bool
+			var numEvents int
+			if trace.rule != "" {
+				keep = true
+				pt.TraceChunk.DroppedTrace = false
+				if pt.TraceChunk.Tags == nil {
+					pt.TraceChunk.Tags = make(map[string]string)
+				}
+				pt.TraceChunk.Tags[tagTailSamplingRule] = trace.rule
+				numEvents = len(a.getAnalyzedEvents(pt, chunk.source))
+				a.SamplerMetrics.RecordMetricsKey(true, sampler.NewMetricsKey(pt.Root.Service, pt.TracerEnv, sampler.NameTailSampling, sampler.PriorityNone))
+			} else {
+				keep, numEvents = a.sample(now, chunk.source, pt)
+			}
+			if !keep && len(pt.TraceChunk.Spans) == 0 {
+				continue
+			}
+
+			sampledChunks, ok := payloads[chunk.payload]
+			if !ok {
+				sampledChunks = &writer.SampledChunks{TracerPayload: tracerPayloadMetadata(chunk.payload)}
+				payloads[chunk.payload] = sampledChunks
+			}
+			sampledChunks.TracerPayload.Chunks = append(sampledChunks.TracerPayload.Chunks, pt.TraceChunk)
+			if !pt.TraceChunk.DroppedTrace {
+				a.setFirstTraceTags(pt.Root)
+				sampledChunks.SpanCount += int64(len(pt.TraceChunk.Spans))
+			}
+			sampledChunks.EventCount += int64(numEvents)
+			sampledChunks.Size += pt.TraceChunk.Msgsize()
+
+			if sampledChunks.Size > writer.MaxPayloadSize {
+				a.TraceWriter.WriteChunks(sampledChunks)
+				delete(payloads, chunk.payload)
+			}
+		}
+	}
+	for _, sampledChunks := range payloads {
+		a.TraceWriter.WriteChunks(sampledChunks)
+	}
+}
@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/stats"
+	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+func newTailSamplingTestAgent(t *testing.T, maxMemory int64) *Agent {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.TailSampling = config.TailSamplingConfig{
+		Enabled:      true,
+		DecisionWait: 10 * time.Second,
+		MaxMemory:    maxMemory,
+		Rules: []*config.TailSamplingRule{
+			{Name: "slow", Type: config.TailSamplingRuleLatency, MinDurationMs: 500},
+			{Name: "errors", Type: config.TailSamplingRuleError},
+			{Name: "checkout", Type: config.TailSamplingRuleTag, TagKey: "http.route", TagValue: "/checkout"},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+}
+
+// processDroppedChunk processes a chunk the priority sampler would drop.
+func processDroppedChunk(a *Agent, spans ...*pb.Span) {
+	chunk := testutil.TraceChunkWithSpans(spans)
+	chunk.Priority = int32(sampler.PriorityAutoDrop)
+	a.Process(&api.Payload{
+		TracerPayload: testutil.TracerPayloadWithChunk(chunk),
+		Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+	})
+}
+
+func tailSamplingSpan(traceID, spanID, parentID uint64, start time.Time, duration time.Duration) *pb.Span {
+	return &pb.Span{
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Service:  "web",
+		Name:     "http.request",
+		Resource: "GET /",
+		Start:    start.UnixNano(),
+		Duration: duration.Nanoseconds(),
+		Meta:     map[string]string{},
+		Metrics:  map[string]float64{},
+	}
+}
+
+// writtenChunks returns the chunks written by the agent by trace ID.
+func writtenChunks(a *Agent) map[uint64][]*pb.TraceChunk {
+	w := a.TraceWriter.(*mockTraceWriter)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	chunks := make(map[uint64][]*pb.TraceChunk)
+	for _, p := range w.payloads {
+		for _, chunk := range p.TracerPayload.Chunks {
+			chunks[chunk.Spans[0].TraceID] = append(chunks[chunk.Spans[0].TraceID], chunk)
+		}
+	}
+	return chunks
+}
+
+func TestTailSampling(t *testing.T) {
+	a := newTailSamplingTestAgent(t, 1<<30)
+	now := time.Now()
+
+	// the slow span of trace 1 and the error of trace 2 are not in the root chunks
+	processDroppedChunk(a, tailSamplingSpan(1, 1, 0, now, 100*time.Millisecond))
+	processDroppedChunk(a, tailSamplingSpan(1, 2, 1, now, 800*time.Millisecond))
+	processDroppedChunk(a, tailSamplingSpan(2, 1, 0, now, 100*time.Millisecond))
+	errorSpan := tailSamplingSpan(2, 2, 1, now, 10*time.Millisecond)
+	errorSpan.Error = 1
+	processDroppedChunk(a, errorSpan)
+	checkout := tailSamplingSpan(3, 1, 0, now, 100*time.Millisecond)
+	checkout.Meta["http.route"] = "/checkout"
+	processDroppedChunk(a, checkout)
+	processDroppedChunk(a, tailSamplingSpan(4, 1, 0, now, 100*time.Millisecond))
+
+	// nothing is written before the decision
+	assert.Empty(t, a.TraceWriter.(*mockTraceWriter).payloads)
+	a.TailSampler.flush(time.Now(), false)
+	assert.Empty(t, a.TraceWriter.(*mockTraceWriter).payloads)
+
+	a.TailSampler.flush(time.Now().Add(10*time.Second), false)
+	chunks := writtenChunks(a)
+	for traceID, rule := range map[uint64]string{1: "slow", 2: "errors", 3: "checkout"} {
+		expected := 2
+		if traceID == 3 {
+			expected = 1
+		}
+		require.Len(t, chunks[traceID], expected, "trace %d", traceID)
+		for _, chunk := range chunks[traceID] {
+			assert.False(t, chunk.DroppedTrace)
+			assert.Equal(t, rule, chunk.Tags[tagTailSamplingRule])
+		}
+	}
+	// the traces matching no rule are sampled by the other samplers
+	assert.NotContains(t, chunks, uint64(4))
+
+	// the late chunks of a kept trace are kept without waiting
+	processDroppedChunk(a, tailSamplingSpan(1, 3, 1, now, 10*time.Millisecond))
+	chunks = writtenChunks(a)
+	require.Len(t, chunks[1], 3)
+	assert.Equal(t, "slow", chunks[1][2].Tags[tagTailSamplingRule])
+}
+
+func TestTailSamplingMaxMemory(t *testing.T) {
+	a := newTailSamplingTestAgent(t, 1)
+	a.TailSampler.Start()
+	defer a.TailSampler.Stop()
+	now := time.Now()
+
+	// the traces exceeding the memory limit are decided right away, by the
+	// goroutine of the TailSampler
+	processDroppedChunk(a, tailSamplingSpan(1, 1, 0, now, time.Second))
+	a.TailSampler.mu.Lock()
+	assert.Empty(t, a.TailSampler.traces)
+	assert.Zero(t, a.TailSampler.size)
+	a.TailSampler.mu.Unlock()
+	assert.Eventually(t, func() bool { return len(writtenChunks(a)[1]) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "slow", writtenChunks(a)[1][0].Tags[tagTailSamplingRule])
+}
+
+func TestTailSamplingMaxMemoryConcurrentProcess(t *testing.T) {
+	// the buffer holds a few chunks, which are evicted by the chunks added by
+	// the other Process calls
+	a := newTailSamplingTestAgent(t, 1500)
+	// the concentrator reads the spans of the chunks, it is not started as
+	// the stats are not flushed
+	a.Concentrator = stats.NewConcentrator(a.conf, nil, time.Now(), &statsd.NoOpClient{})
+	a.TailSampler.Start()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				traceID := uint64(i*1000 + j + 1)
+				// the priority sampler sets the sample rate of the kept roots
+				chunk := testutil.TraceChunkWithSpans([]*pb.Span{
+					tailSamplingSpan(traceID, 1, 0, now, 10*time.Millisecond),
+					tailSamplingSpan(traceID, 2, 1, now, time.Millisecond),
+				})
+				chunk.Priority = int32(sampler.PriorityAutoKeep)
+				a.Process(&api.Payload{
+					TracerPayload: testutil.TracerPayloadWithChunk(chunk),
+					Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+				})
+			}
+		}(i)
+	}
+	wg.Wait()
+	a.TailSampler.Stop()
+
+	assert.Empty(t, a.TailSampler.traces)
+	assert.Empty(t, a.TailSampler.evicted)
+}
+
+func TestTailSamplingStop(t *testing.T) {
+	a := newTailSamplingTestAgent(t, 1<<30)
+	a.TailSampler.Start()
+
+	processDroppedChunk(a, tailSamplingSpan(1, 1, 0, time.Now(), time.Second))
+	assert.Empty(t, a.TraceWriter.(*mockTraceWriter).payloads)
+
+	// the buffered traces are decided when stopping
+	a.TailSampler.Stop()
+	assert.Len(t, writtenChunks(a)[1], 1)
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/tinylib/msgp/msgp"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.uber.org/atomic"
 
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
@@ -115,6 +116,10 @@ type HTTPReceiver struct {
 	// outOfCPUCounter is counter to throttle the out of cpu warning log
 	outOfCPUCounter *atomic.Uint32
 
+	// otlpReceiver ingests the spans translated from the Zipkin and Jaeger formats, so that they
+	// follow the same path as OTLP spans. It is nil unless one of these receivers is enabled.
+	otlpReceiver *OTLPReceiver
+
 	statsd   statsd.ClientInterface
 	timing   timing.Reporter
 	info     *watchdog.CurrentInfo
@@ -144,6 +149,10 @@ func NewHTTPReceiver(
 	log.Infof("Receiver configured with %d decoders and a timeout of %dms", semcount, conf.DecoderTimeout)
 	containerIDProvider := NewIDProvider(conf.ContainerProcRoot, conf.ContainerIDFromOriginInfo)
 	telemetryForwarder := NewTelemetryForwarder(conf, containerIDProvider, statsd)
+	var otlpReceiver *OTLPReceiver
+	if conf.ZipkinReceiverEnabled || conf.JaegerReceiverEnabled {
+		otlpReceiver = NewOTLPReceiver(out, conf, statsd, timing)
+	}
 	return &HTTPReceiver{
 		Stats: info.NewReceiverStats(),
 
@@ -169,6 +178,7 @@ func NewHTTPReceiver(
 		recvsem: make(chan struct{}, semcount),
 
 		outOfCPUCounter: atomic.NewUint32(0),
+		otlpReceiver:    otlpReceiver,
 
 		statsd:   statsd,
 		timing:   timing,
@@ -668,6 +678,48 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 	r.out <- payload
 }
 
+// handleTranslatedTraces returns a handler for trace payloads in non-Datadog formats. decode converts
+// the request body into OpenTelemetry traces, which are then ingested through the OTLP receiver so that
+// they are normalized, sampled and accounted for like OTLP spans.
+func (r *HTTPReceiver) handleTranslatedTraces(endpointVersion string, decode func(body []byte, mediaType string) (ptrace.Traces, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Sec-Fetch-Site") == "cross-site" {
+			http.Error(w, "cross-site request rejected", http.StatusForbidden)
+			return
+		}
+		req.Body = apiutil.NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+		defer req.Body.Close()
+
+		select {
+		case r.recvsem <- struct{}{}:
+		case <-time.After(time.Duration(r.conf.DecoderTimeout) * time.Millisecond):
+			log.Debugf("trace-agent is overwhelmed, a %s payload has been rejected", endpointVersion)
+			io.Copy(io.Discard, req.Body) //nolint:errcheck
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		defer func() { <-r.recvsem }()
+
+		tags := []string{"handler:" + endpointVersion}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			httpDecodingError(err, tags, w, r.statsd)
+			return
+		}
+		traces, err := decode(body, getMediaType(req))
+		if err != nil {
+			log.Errorf("Cannot decode %s traces payload: %v", endpointVersion, err)
+			httpDecodingError(err, tags, w, r.statsd)
+			return
+		}
+		rspans := traces.ResourceSpans()
+		for i := 0; i < rspans.Len(); i++ {
+			r.otlpReceiver.receiveResourceSpans(req.Context(), rspans.At(i), req.Header, nil, endpointVersion)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
 // isHeaderTrue returns true if value is non-empty and not a "false"-like value as defined by strconv.ParseBool
 // e.g. (0, f, F, FALSE, False, false) will be considered false while all other values will be true.
 func isHeaderTrue(key, value string) bool {
@@ -773,7 +825,12 @@ func (r *HTTPReceiver) watchdog(now time.Time) {
 		CPU: cpu,
 	}
 	if r.conf.MaxMemory > 0 {
-		if current, allowed := float64(wi.Mem.Alloc), r.conf.MaxMemory*1.5; current > allowed {
+		maxMemory := r.conf.MaxMemory
+		if r.conf.TailSampling.Enabled {
+			// the traces buffered by the tail sampling are expected on top of the usual usage
+			maxMemory += float64(r.conf.TailSampling.MaxMemory)
+		}
+		if current, allowed := float64(wi.Mem.Alloc), maxMemory*1.5; current > allowed {
 			// This is a safety mechanism: if the agent is using more than 1.5x max. memory, there
 			// is likely a leak somewhere; we'll kill the process to avoid polluting host memory.
 			_ = r.statsd.Count("datadog.trace_agent.receiver.oom_kill", 1, nil, 1)
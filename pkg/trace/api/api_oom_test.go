@@ -85,3 +85,28 @@ loop:
 	}
 	t.Fatal("didn't get OOM killed")
 }
+
+func TestWatchdogTailSamplingMemory(t *testing.T) {
+	kills := atomic.NewUint64(0)
+
+	defer func(old func(string, ...interface{})) { killProcess = old }(killProcess)
+	killProcess = func(string, ...interface{}) {
+		kills.Inc()
+	}
+
+	conf := config.New()
+	conf.MaxMemory = 1
+	r := newTestReceiverFromConfig(conf)
+	r.watchdog(time.Now())
+	if kills.Load() != 1 {
+		t.Fatal("didn't get OOM killed")
+	}
+
+	// the memory of the tail sampling buffer is allowed on top of MaxMemory
+	conf.TailSampling.Enabled = true
+	conf.TailSampling.MaxMemory = 1 << 40
+	r.watchdog(time.Now())
+	if kills.Load() != 1 {
+		t.Fatal("got OOM killed within the tail sampling memory")
+	}
+}
@@ -37,6 +37,7 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		Redis                obfuscate.RedisConfig     `json:"redis"`
 		Valkey               obfuscate.ValkeyConfig    `json:"valkey"`
 		Memcached            obfuscate.MemcachedConfig `json:"memcached"`
+		GraphQL              obfuscate.GraphQLConfig   `json:"graphql"`
 	}
 	type reducedConfig struct {
 		DefaultEnv             string                        `json:"default_env"`
@@ -53,6 +54,11 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		AnalyzedSpansByService map[string]map[string]float64 `json:"analyzed_spans_by_service"`
 		Obfuscation            reducedObfuscationConfig      `json:"obfuscation"`
 	}
+	type receiversConfig struct {
+		OTLP   bool `json:"otlp"`
+		Zipkin bool `json:"zipkin"`
+		Jaeger bool `json:"jaeger"`
+	}
 	var oconf reducedObfuscationConfig
 	if o := r.conf.Obfuscation; o != nil {
 		oconf.ElasticSearch = o.ES.Enabled
@@ -64,6 +70,7 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		oconf.Redis = o.Redis
 		oconf.Valkey = o.Valkey
 		oconf.Memcached = o.Memcached
+		oconf.GraphQL = o.GraphQL
 	}
 
 	// We check that endpoints contains stats, even though we know this version of the
@@ -79,19 +86,20 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 	}
 
 	txt, err := json.MarshalIndent(struct {
-		Version                string        `json:"version"`
-		GitCommit              string        `json:"git_commit"`
-		Endpoints              []string      `json:"endpoints"`
-		FeatureFlags           []string      `json:"feature_flags,omitempty"`
-		ClientDropP0s          bool          `json:"client_drop_p0s"`
-		SpanMetaStructs        bool          `json:"span_meta_structs"`
-		LongRunningSpans       bool          `json:"long_running_spans"`
-		SpanEvents             bool          `json:"span_events"`
-		EvpProxyAllowedHeaders []string      `json:"evp_proxy_allowed_headers"`
-		Config                 reducedConfig `json:"config"`
-		PeerTags               []string      `json:"peer_tags"`
-		SpanKindsStatsComputed []string      `json:"span_kinds_stats_computed"`
-		ObfuscationVersion     int           `json:"obfuscation_version"`
+		Version                string          `json:"version"`
+		GitCommit              string          `json:"git_commit"`
+		Endpoints              []string        `json:"endpoints"`
+		FeatureFlags           []string        `json:"feature_flags,omitempty"`
+		ClientDropP0s          bool            `json:"client_drop_p0s"`
+		SpanMetaStructs        bool            `json:"span_meta_structs"`
+		LongRunningSpans       bool            `json:"long_running_spans"`
+		SpanEvents             bool            `json:"span_events"`
+		EvpProxyAllowedHeaders []string        `json:"evp_proxy_allowed_headers"`
+		Config                 reducedConfig   `json:"config"`
+		PeerTags               []string        `json:"peer_tags"`
+		SpanKindsStatsComputed []string        `json:"span_kinds_stats_computed"`
+		ObfuscationVersion     int             `json:"obfuscation_version"`
+		Receivers              receiversConfig `json:"receivers"`
 	}{
 		Version:                r.conf.AgentVersion,
 		GitCommit:              r.conf.GitCommit,
@@ -104,6 +112,11 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		EvpProxyAllowedHeaders: EvpProxyAllowedHeaders,
 		SpanKindsStatsComputed: spanKindsStatsComputed,
 		ObfuscationVersion:     obfuscate.Version,
+		Receivers: receiversConfig{
+			OTLP:   r.conf.OTLPReceiver != nil && r.conf.OTLPReceiver.GRPCPort != 0,
+			Zipkin: r.conf.ZipkinReceiverEnabled,
+			Jaeger: r.conf.JaegerReceiverEnabled,
+		},
 		Config: reducedConfig{
 			DefaultEnv:             r.conf.DefaultEnv,
 			TargetTPS:              r.conf.TargetTPS,
@@ -232,6 +232,7 @@ func TestInfoHandler(t *testing.T) {
 		Redis:             obfuscate.RedisConfig{Enabled: true},
 		Valkey:            obfuscate.ValkeyConfig{Enabled: true},
 		Memcached:         obfuscate.MemcachedConfig{Enabled: false},
+		GraphQL:           obfuscate.GraphQLConfig{Enabled: true},
 	}
 	conf := &config.AgentConfig{
 		Enabled:      true,
@@ -306,6 +307,11 @@ func TestInfoHandler(t *testing.T) {
 		"peer_tags":                 nil,
 		"span_kinds_stats_computed": nil,
 		"obfuscation_version":       nil,
+		"receivers": map[string]interface{}{
+			"otlp":   nil,
+			"zipkin": nil,
+			"jaeger": nil,
+		},
 		"config": map[string]interface{}{
 			"default_env":               nil,
 			"target_tps":                nil,
@@ -332,6 +338,7 @@ func TestInfoHandler(t *testing.T) {
 				"redis":               nil,
 				"valkey":              nil,
 				"memcached":           nil,
+				"graphql":             nil,
 			},
 		},
 	}
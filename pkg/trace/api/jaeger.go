@@ -0,0 +1,396 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// jaegerEndpointVersion is the endpoint version reported in the stats of the spans received
+// on the Jaeger endpoint.
+const jaegerEndpointVersion = "jaeger_thrift"
+
+// decodeJaegerBatch decodes a jaeger.thrift Batch, encoded with the Thrift binary protocol as sent
+// by the Jaeger clients to the collector's HTTP endpoint, and converts it to OpenTelemetry traces.
+// See https://github.com/jaegertracing/jaeger-idl/blob/main/thrift/jaeger.thrift.
+func decodeJaegerBatch(body []byte, mediaType string) (ptrace.Traces, error) {
+	switch mediaType {
+	case "application/x-thrift", "application/vnd.apache.thrift.binary":
+	default:
+		return ptrace.Traces{}, fmt.Errorf("unsupported media type: %q", mediaType)
+	}
+	traces := ptrace.NewTraces()
+	rspans := traces.ResourceSpans().AppendEmpty()
+	spans := rspans.ScopeSpans().AppendEmpty().Spans()
+	r := &thriftReader{b: body}
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftStruct:
+			readJaegerProcess(r, rspans.Resource().Attributes())
+		case id == 2 && typ == thriftList:
+			r.readList(thriftStruct, func() { readJaegerSpan(r, spans.AppendEmpty()) })
+		default:
+			return false
+		}
+		return true
+	})
+	if r.err != nil {
+		return ptrace.Traces{}, r.err
+	}
+	return traces, nil
+}
+
+// readJaegerProcess reads a jaeger.thrift Process into the resource attributes attrs.
+func readJaegerProcess(r *thriftReader, attrs pcommon.Map) {
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftString:
+			attrs.PutStr(semconv.AttributeServiceName, r.readString())
+		case id == 2 && typ == thriftList:
+			r.readList(thriftStruct, func() { readJaegerTag(r, attrs) })
+		default:
+			return false
+		}
+		return true
+	})
+}
+
+// readJaegerSpan reads a jaeger.thrift Span into span.
+func readJaegerSpan(r *thriftReader, span ptrace.Span) {
+	var (
+		traceIDLow, traceIDHigh, parentID uint64
+		startTime, duration               uint64
+		refParentID                       uint64
+	)
+	attrs := pcommon.NewMap()
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftI64:
+			traceIDLow = uint64(r.readI64())
+		case id == 2 && typ == thriftI64:
+			traceIDHigh = uint64(r.readI64())
+		case id == 3 && typ == thriftI64:
+			span.SetSpanID(uint64ToSpanID(uint64(r.readI64())))
+		case id == 4 && typ == thriftI64:
+			parentID = uint64(r.readI64())
+		case id == 5 && typ == thriftString:
+			span.SetName(r.readString())
+		case id == 6 && typ == thriftList:
+			r.readList(thriftStruct, func() {
+				if ref := readJaegerSpanRef(r); ref.childOf && refParentID == 0 {
+					refParentID = ref.spanID
+				}
+			})
+		case id == 8 && typ == thriftI64:
+			startTime = uint64(r.readI64())
+		case id == 9 && typ == thriftI64:
+			duration = uint64(r.readI64())
+		case id == 10 && typ == thriftList:
+			r.readList(thriftStruct, func() { readJaegerTag(r, attrs) })
+		case id == 11 && typ == thriftList:
+			r.readList(thriftStruct, func() { readJaegerLog(r, span.Events().AppendEmpty()) })
+		default:
+			return false
+		}
+		return true
+	})
+
+	var traceID [16]byte
+	binary.BigEndian.PutUint64(traceID[:8], traceIDHigh)
+	binary.BigEndian.PutUint64(traceID[8:], traceIDLow)
+	span.SetTraceID(traceID)
+	if parentID == 0 {
+		parentID = refParentID
+	}
+	if parentID != 0 {
+		span.SetParentSpanID(uint64ToSpanID(parentID))
+	}
+	span.SetStartTimestamp(microsToTimestamp(startTime))
+	span.SetEndTimestamp(microsToTimestamp(startTime + duration))
+
+	span.SetKind(ptrace.SpanKindUnspecified)
+	if v, ok := attrs.Get("span.kind"); ok {
+		switch v.Str() {
+		case "client":
+			span.SetKind(ptrace.SpanKindClient)
+		case "server":
+			span.SetKind(ptrace.SpanKindServer)
+		case "producer":
+			span.SetKind(ptrace.SpanKindProducer)
+		case "consumer":
+			span.SetKind(ptrace.SpanKindConsumer)
+		case "internal":
+			span.SetKind(ptrace.SpanKindInternal)
+		}
+		attrs.Remove("span.kind")
+	}
+	if v, ok := attrs.Get("error"); ok {
+		if isErr, _ := strconv.ParseBool(v.AsString()); isErr {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+		attrs.Remove("error")
+	}
+	attrs.MoveTo(span.Attributes())
+}
+
+// jaegerSpanRef holds the fields of a jaeger.thrift SpanRef needed to find a span's parent.
+type jaegerSpanRef struct {
+	childOf bool
+	spanID  uint64
+}
+
+func readJaegerSpanRef(r *thriftReader) jaegerSpanRef {
+	var ref jaegerSpanRef
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftI32:
+			ref.childOf = r.readI32() == 0 // CHILD_OF
+		case id == 4 && typ == thriftI64:
+			ref.spanID = uint64(r.readI64())
+		default:
+			return false
+		}
+		return true
+	})
+	return ref
+}
+
+// readJaegerLog reads a jaeger.thrift Log into event. The "event" field, when set, names the event.
+func readJaegerLog(r *thriftReader, event ptrace.SpanEvent) {
+	attrs := event.Attributes()
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftI64:
+			event.SetTimestamp(microsToTimestamp(uint64(r.readI64())))
+		case id == 2 && typ == thriftList:
+			r.readList(thriftStruct, func() { readJaegerTag(r, attrs) })
+		default:
+			return false
+		}
+		return true
+	})
+	if v, ok := attrs.Get("event"); ok && v.Type() == pcommon.ValueTypeStr {
+		event.SetName(v.Str())
+		attrs.Remove("event")
+	}
+}
+
+// readJaegerTag reads a jaeger.thrift Tag into attrs.
+func readJaegerTag(r *thriftReader, attrs pcommon.Map) {
+	var (
+		key   string
+		vType int32
+		value pcommon.Value
+	)
+	value = pcommon.NewValueEmpty()
+	r.readStruct(func(id int16, typ byte) bool {
+		switch {
+		case id == 1 && typ == thriftString:
+			key = r.readString()
+		case id == 2 && typ == thriftI32:
+			vType = r.readI32()
+		case id == 3 && typ == thriftString:
+			value = pcommon.NewValueStr(r.readString())
+		case id == 4 && typ == thriftDouble:
+			value = pcommon.NewValueDouble(r.readDouble())
+		case id == 5 && typ == thriftBool:
+			value = pcommon.NewValueBool(r.readBool())
+		case id == 6 && typ == thriftI64:
+			value = pcommon.NewValueInt(r.readI64())
+		case id == 7 && typ == thriftString:
+			value = pcommon.NewValueBytes()
+			value.Bytes().FromRaw(r.readBinary())
+		default:
+			return false
+		}
+		return true
+	})
+	if key == "" || vType < 0 || vType > 4 {
+		// unknown tag types are dropped
+		return
+	}
+	value.CopyTo(attrs.PutEmpty(key))
+}
+
+func uint64ToSpanID(id uint64) pcommon.SpanID {
+	var sid [8]byte
+	binary.BigEndian.PutUint64(sid[:], id)
+	return sid
+}
+
+// Thrift type identifiers, as used by the binary protocol.
+const (
+	thriftStop   byte = 0
+	thriftBool   byte = 2
+	thriftByte   byte = 3
+	thriftDouble byte = 4
+	thriftI16    byte = 6
+	thriftI32    byte = 8
+	thriftI64    byte = 10
+	thriftString byte = 11
+	thriftStruct byte = 12
+	thriftMap    byte = 13
+	thriftSet    byte = 14
+	thriftList   byte = 15
+)
+
+// thriftMaxDepth limits the nesting of the skipped values, guarding against maliciously deep payloads.
+const thriftMaxDepth = 64
+
+var errThriftMalformed = errors.New("malformed thrift payload")
+
+// thriftReader reads values encoded with the Thrift binary protocol. The first error encountered
+// is kept in err, after which all reads return zero values.
+type thriftReader struct {
+	b   []byte
+	err error
+}
+
+func (r *thriftReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.b) {
+		r.err = errThriftMalformed
+		return nil
+	}
+	b := r.b[:n]
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *thriftReader) readByte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *thriftReader) readBool() bool { return r.readByte() == 1 }
+
+func (r *thriftReader) readI16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readI32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readI64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *thriftReader) readDouble() float64 {
+	return math.Float64frombits(uint64(r.readI64()))
+}
+
+func (r *thriftReader) readBinary() []byte {
+	return r.next(int(r.readI32()))
+}
+
+func (r *thriftReader) readString() string {
+	return string(r.readBinary())
+}
+
+// readStruct reads a struct, calling field with the id and type of each of its fields. field
+// reports whether it read the value; the fields it does not know about are skipped.
+func (r *thriftReader) readStruct(field func(id int16, typ byte) bool) {
+	for r.err == nil {
+		typ := r.readByte()
+		if typ == thriftStop {
+			return
+		}
+		id := r.readI16()
+		if r.err == nil && !field(id, typ) {
+			r.skip(typ, 0)
+		}
+	}
+}
+
+// readList reads a list of elements of type typ, calling elem to read each of them.
+func (r *thriftReader) readList(typ byte, elem func()) {
+	elemType := r.readByte()
+	size := r.readI32()
+	if r.err != nil {
+		return
+	}
+	if elemType != typ || size < 0 || int(size) > len(r.b) {
+		r.err = errThriftMalformed
+		return
+	}
+	for i := int32(0); i < size && r.err == nil; i++ {
+		elem()
+	}
+}
+
+// skip reads and discards a value of type typ, nested at the given depth.
+func (r *thriftReader) skip(typ byte, depth int) {
+	if depth > thriftMaxDepth {
+		r.err = errThriftMalformed
+		return
+	}
+	switch typ {
+	case thriftBool, thriftByte:
+		r.next(1)
+	case thriftI16:
+		r.next(2)
+	case thriftI32:
+		r.next(4)
+	case thriftDouble, thriftI64:
+		r.next(8)
+	case thriftString:
+		r.readBinary()
+	case thriftStruct:
+		for r.err == nil {
+			ftyp := r.readByte()
+			if ftyp == thriftStop {
+				return
+			}
+			r.readI16()
+			r.skip(ftyp, depth+1)
+		}
+	case thriftMap:
+		ktyp, vtyp := r.readByte(), r.readByte()
+		size := r.readI32()
+		if size < 0 || int(size) > len(r.b) {
+			r.err = errThriftMalformed
+			return
+		}
+		for i := int32(0); i < size && r.err == nil; i++ {
+			r.skip(ktyp, depth+1)
+			r.skip(vtyp, depth+1)
+		}
+	case thriftSet, thriftList:
+		etyp := r.readByte()
+		size := r.readI32()
+		if size < 0 || int(size) > len(r.b) {
+			r.err = errThriftMalformed
+			return
+		}
+		for i := int32(0); i < size && r.err == nil; i++ {
+			r.skip(etyp, depth+1)
+		}
+	default:
+		r.err = errThriftMalformed
+	}
+}
@@ -0,0 +1,203 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// thriftWriter encodes values with the Thrift binary protocol.
+type thriftWriter struct{ bytes.Buffer }
+
+func (w *thriftWriter) field(typ byte, id int16) {
+	w.WriteByte(typ)
+	binary.Write(w, binary.BigEndian, id) //nolint:errcheck
+}
+
+func (w *thriftWriter) stop() { w.WriteByte(thriftStop) }
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(thriftI32, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(thriftI64, id)
+	binary.Write(w, binary.BigEndian, v) //nolint:errcheck
+}
+
+func (w *thriftWriter) str(id int16, v string) {
+	w.field(thriftString, id)
+	binary.Write(w, binary.BigEndian, int32(len(v))) //nolint:errcheck
+	w.WriteString(v)
+}
+
+func (w *thriftWriter) list(id int16, n int, elem func(i int)) {
+	w.field(thriftList, id)
+	w.WriteByte(thriftStruct)
+	binary.Write(w, binary.BigEndian, int32(n)) //nolint:errcheck
+	for i := 0; i < n; i++ {
+		elem(i)
+	}
+}
+
+// tags writes the list of jaeger.thrift tags with the given values.
+func (w *thriftWriter) tags(id int16, tags [][2]any) {
+	w.list(id, len(tags), func(i int) {
+		w.str(1, tags[i][0].(string))
+		switch v := tags[i][1].(type) {
+		case string:
+			w.i32(2, 0)
+			w.str(3, v)
+		case float64:
+			w.i32(2, 1)
+			w.field(thriftDouble, 4)
+			binary.Write(w, binary.BigEndian, math.Float64bits(v)) //nolint:errcheck
+		case bool:
+			w.i32(2, 2)
+			w.field(thriftBool, 5)
+			if v {
+				w.WriteByte(1)
+			} else {
+				w.WriteByte(0)
+			}
+		case int64:
+			w.i32(2, 3)
+			w.i64(6, v)
+		}
+		w.stop()
+	})
+}
+
+func testJaegerBatch() []byte {
+	var w thriftWriter
+	w.field(thriftStruct, 1) // process
+	w.str(1, "web")
+	w.tags(2, [][2]any{{"hostname", "host-a"}})
+	w.stop()
+	w.list(2, 2, func(i int) {
+		w.i64(1, 0x5af7183fb1d4cf5f)
+		w.i64(2, 0x463ac35c9f6413ad)
+		if i == 0 {
+			w.i64(3, 0x352bff9a74ca9ad2)
+			w.str(5, "get /users")
+			w.i64(8, 1556604172355737)
+			w.i64(9, 1431)
+			w.tags(10, [][2]any{{"span.kind", "server"}, {"http.status_code", int64(200)}, {"sampler.param", true}})
+			w.field(thriftMap, 20) // unknown fields are skipped
+			w.WriteByte(thriftString)
+			w.WriteByte(thriftI32)
+			binary.Write(&w, binary.BigEndian, int32(0)) //nolint:errcheck
+		} else {
+			w.i64(3, 0x0a2fb4a1d1a96d31)
+			w.i64(4, 0)
+			w.list(6, 1, func(int) {
+				w.i32(1, 0) // CHILD_OF
+				w.i64(2, 0x5af7183fb1d4cf5f)
+				w.i64(3, 0x463ac35c9f6413ad)
+				w.i64(4, 0x352bff9a74ca9ad2)
+				w.stop()
+			})
+			w.str(5, "select")
+			w.i64(8, 1556604172356000)
+			w.i64(9, 500)
+			w.tags(10, [][2]any{{"span.kind", "client"}, {"error", true}, {"db.load", 0.5}})
+			w.list(11, 1, func(int) {
+				w.i64(1, 1556604172356100)
+				w.tags(2, [][2]any{{"event", "retry"}, {"attempt", int64(2)}})
+				w.stop()
+			})
+		}
+		w.stop()
+	})
+	w.stop()
+	return w.Bytes()
+}
+
+func TestDecodeJaegerBatch(t *testing.T) {
+	traces, err := decodeJaegerBatch(testJaegerBatch(), "application/x-thrift")
+	require.NoError(t, err)
+
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	rspans := traces.ResourceSpans().At(0)
+	assert.Equal(t, map[string]any{"service.name": "web", "hostname": "host-a"}, rspans.Resource().Attributes().AsRaw())
+
+	spans := rspans.ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+
+	root := spans.At(0)
+	assert.Equal(t, "463ac35c9f6413ad5af7183fb1d4cf5f", root.TraceID().String())
+	assert.Equal(t, "352bff9a74ca9ad2", root.SpanID().String())
+	assert.True(t, root.ParentSpanID().IsEmpty())
+	assert.Equal(t, "get /users", root.Name())
+	assert.Equal(t, ptrace.SpanKindServer, root.Kind())
+	assert.EqualValues(t, 1556604172355737000, root.StartTimestamp())
+	assert.EqualValues(t, 1556604172357168000, root.EndTimestamp())
+	assert.Equal(t, map[string]any{"http.status_code": int64(200), "sampler.param": true}, root.Attributes().AsRaw())
+	assert.Equal(t, ptrace.StatusCodeUnset, root.Status().Code())
+
+	child := spans.At(1)
+	assert.Equal(t, "352bff9a74ca9ad2", child.ParentSpanID().String())
+	assert.Equal(t, ptrace.SpanKindClient, child.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, child.Status().Code())
+	assert.Equal(t, map[string]any{"db.load": 0.5}, child.Attributes().AsRaw())
+	require.Equal(t, 1, child.Events().Len())
+	event := child.Events().At(0)
+	assert.Equal(t, "retry", event.Name())
+	assert.EqualValues(t, 1556604172356100000, event.Timestamp())
+	assert.Equal(t, map[string]any{"attempt": int64(2)}, event.Attributes().AsRaw())
+
+	t.Run("invalid", func(t *testing.T) {
+		payload := testJaegerBatch()
+		for i := 0; i < len(payload); i++ {
+			_, err := decodeJaegerBatch(payload[:i], "application/x-thrift")
+			assert.Error(t, err, "truncated at %d", i)
+		}
+		_, err := decodeJaegerBatch(payload, "application/json")
+		assert.Error(t, err)
+	})
+}
+
+func TestJaegerEndpoint(t *testing.T) {
+	conf := NewTestConfig(t)
+	conf.DecoderTimeout = 10000
+	conf.JaegerReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	mux := rcv.buildMux()
+
+	req := httptest.NewRequest("POST", "/api/traces", bytes.NewReader(testJaegerBatch()))
+	req.Header.Set("Content-Type", "application/x-thrift")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	p := <-rcv.out
+	assert.Equal(t, jaegerEndpointVersion, p.Source.EndpointVersion)
+	chunks := p.TracerPayload.Chunks
+	require.Len(t, chunks, 1)
+	require.Len(t, chunks[0].Spans, 2)
+	root, child := chunks[0].Spans[0], chunks[0].Spans[1]
+	assert.Equal(t, "web", root.Service)
+	assert.EqualValues(t, 0x5af7183fb1d4cf5f, root.TraceID)
+	assert.EqualValues(t, 0x352bff9a74ca9ad2, root.SpanID)
+	assert.Equal(t, root.SpanID, child.ParentID)
+	assert.EqualValues(t, 1, child.Error)
+
+	req = httptest.NewRequest("POST", "/api/traces", bytes.NewReader(testJaegerBatch()))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,335 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// zipkinEndpointVersion is the endpoint version reported in the stats of the spans received
+// on the Zipkin v2 endpoint.
+const zipkinEndpointVersion = "zipkin_v2"
+
+// zipkinSpan is a span in the Zipkin v2 model. See https://zipkin.io/zipkin-api/#/default/post_spans.
+type zipkinSpan struct {
+	TraceID        string             `json:"traceId"`
+	ParentID       string             `json:"parentId"`
+	ID             string             `json:"id"`
+	Kind           string             `json:"kind"`
+	Name           string             `json:"name"`
+	Timestamp      uint64             `json:"timestamp"` // epoch microseconds
+	Duration       uint64             `json:"duration"`  // microseconds
+	LocalEndpoint  *zipkinEndpoint    `json:"localEndpoint"`
+	RemoteEndpoint *zipkinEndpoint    `json:"remoteEndpoint"`
+	Annotations    []zipkinAnnotation `json:"annotations"`
+	Tags           map[string]string  `json:"tags"`
+}
+
+// zipkinEndpoint is the network context of a node in the service graph.
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int64  `json:"port"`
+}
+
+// zipkinAnnotation associates an event that explains latency with a timestamp.
+type zipkinAnnotation struct {
+	Timestamp uint64 `json:"timestamp"` // epoch microseconds
+	Value     string `json:"value"`
+}
+
+// decodeZipkinSpans decodes a list of Zipkin v2 spans, encoded as JSON or as a proto3 ListOfSpans
+// depending on mediaType, and converts it to OpenTelemetry traces.
+func decodeZipkinSpans(body []byte, mediaType string) (ptrace.Traces, error) {
+	var (
+		spans []zipkinSpan
+		err   error
+	)
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf":
+		spans, err = decodeZipkinProto(body)
+	default:
+		err = json.Unmarshal(body, &spans)
+	}
+	if err != nil {
+		return ptrace.Traces{}, err
+	}
+	return zipkinToTraces(spans)
+}
+
+// zipkinToTraces converts spans to OpenTelemetry traces, grouping them into one resource per
+// local service.
+func zipkinToTraces(spans []zipkinSpan) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	byService := make(map[string]ptrace.SpanSlice)
+	for i := range spans {
+		zspan := &spans[i]
+		var service string
+		if zspan.LocalEndpoint != nil {
+			service = zspan.LocalEndpoint.ServiceName
+		}
+		ss, ok := byService[service]
+		if !ok {
+			rspans := traces.ResourceSpans().AppendEmpty()
+			if service != "" {
+				rspans.Resource().Attributes().PutStr(semconv.AttributeServiceName, service)
+			}
+			ss = rspans.ScopeSpans().AppendEmpty().Spans()
+			byService[service] = ss
+		}
+		if err := zipkinToSpan(zspan, ss.AppendEmpty()); err != nil {
+			return ptrace.Traces{}, err
+		}
+	}
+	return traces, nil
+}
+
+// zipkinToSpan fills span with the contents of zspan.
+func zipkinToSpan(zspan *zipkinSpan, span ptrace.Span) error {
+	traceID, err := parseHexID(zspan.TraceID, 16)
+	if err != nil {
+		return fmt.Errorf("invalid trace ID %q: %v", zspan.TraceID, err)
+	}
+	spanID, err := parseHexID(zspan.ID, 8)
+	if err != nil {
+		return fmt.Errorf("invalid span ID %q: %v", zspan.ID, err)
+	}
+	span.SetTraceID(pcommon.TraceID(traceID))
+	span.SetSpanID(pcommon.SpanID(spanID))
+	if zspan.ParentID != "" {
+		parentID, err := parseHexID(zspan.ParentID, 8)
+		if err != nil {
+			return fmt.Errorf("invalid parent ID %q: %v", zspan.ParentID, err)
+		}
+		span.SetParentSpanID(pcommon.SpanID(parentID))
+	}
+	span.SetName(zspan.Name)
+	span.SetStartTimestamp(microsToTimestamp(zspan.Timestamp))
+	span.SetEndTimestamp(microsToTimestamp(zspan.Timestamp + zspan.Duration))
+
+	switch zspan.Kind {
+	case "CLIENT":
+		span.SetKind(ptrace.SpanKindClient)
+	case "SERVER":
+		span.SetKind(ptrace.SpanKindServer)
+	case "PRODUCER":
+		span.SetKind(ptrace.SpanKindProducer)
+	case "CONSUMER":
+		span.SetKind(ptrace.SpanKindConsumer)
+	default:
+		span.SetKind(ptrace.SpanKindInternal)
+	}
+
+	attrs := span.Attributes()
+	for k, v := range zspan.Tags {
+		if k == "error" {
+			// by convention, the "error" tag holds the error message, if any
+			span.Status().SetCode(ptrace.StatusCodeError)
+			span.Status().SetMessage(v)
+			continue
+		}
+		attrs.PutStr(k, v)
+	}
+	if remote := zspan.RemoteEndpoint; remote != nil {
+		if remote.ServiceName != "" {
+			attrs.PutStr(semconv.AttributePeerService, remote.ServiceName)
+		}
+		if ip := remote.IPv4; ip != "" {
+			attrs.PutStr(semconv.AttributeNetPeerIP, ip)
+		} else if ip := remote.IPv6; ip != "" {
+			attrs.PutStr(semconv.AttributeNetPeerIP, ip)
+		}
+		if remote.Port != 0 {
+			attrs.PutInt(semconv.AttributeNetPeerPort, remote.Port)
+		}
+	}
+	for _, a := range zspan.Annotations {
+		event := span.Events().AppendEmpty()
+		event.SetName(a.Value)
+		event.SetTimestamp(microsToTimestamp(a.Timestamp))
+	}
+	return nil
+}
+
+// parseHexID parses the lower-hex encoded ID s into an ID of size bytes. IDs shorter than
+// size are left-padded with zeros, as Zipkin allows 64-bit trace IDs.
+func parseHexID(s string, size int) ([]byte, error) {
+	if s == "" || len(s) > 2*size {
+		return nil, errors.New("unexpected length")
+	}
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, size)
+	copy(id[size-len(b):], b)
+	return id, nil
+}
+
+// microsToTimestamp converts epoch microseconds to an OpenTelemetry timestamp.
+func microsToTimestamp(us uint64) pcommon.Timestamp {
+	return pcommon.Timestamp(us * 1000)
+}
+
+// decodeZipkinProto decodes a zipkin.proto3.ListOfSpans message.
+// See https://github.com/openzipkin/zipkin-api/blob/master/zipkin.proto.
+func decodeZipkinProto(b []byte) ([]zipkinSpan, error) {
+	var spans []zipkinSpan
+	err := rangeProtoFields(b, func(f protoField) error {
+		if f.num != 1 || f.typ != protowire.BytesType {
+			return nil
+		}
+		span, err := decodeZipkinProtoSpan(f.bytes)
+		if err != nil {
+			return err
+		}
+		spans = append(spans, span)
+		return nil
+	})
+	return spans, err
+}
+
+// zipkinProtoKinds maps the values of the zipkin.proto3.Span.Kind enum to their JSON names.
+var zipkinProtoKinds = map[uint64]string{
+	1: "CLIENT",
+	2: "SERVER",
+	3: "PRODUCER",
+	4: "CONSUMER",
+}
+
+func decodeZipkinProtoSpan(b []byte) (zipkinSpan, error) {
+	var span zipkinSpan
+	err := rangeProtoFields(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			span.TraceID = hex.EncodeToString(f.bytes)
+		case 2:
+			span.ParentID = hex.EncodeToString(f.bytes)
+		case 3:
+			span.ID = hex.EncodeToString(f.bytes)
+		case 4:
+			span.Kind = zipkinProtoKinds[f.num64]
+		case 5:
+			span.Name = string(f.bytes)
+		case 6:
+			span.Timestamp = f.num64
+		case 7:
+			span.Duration = f.num64
+		case 8:
+			span.LocalEndpoint, err = decodeZipkinProtoEndpoint(f.bytes)
+		case 9:
+			span.RemoteEndpoint, err = decodeZipkinProtoEndpoint(f.bytes)
+		case 10:
+			var a zipkinAnnotation
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					a.Timestamp = f.num64
+				case 2:
+					a.Value = string(f.bytes)
+				}
+				return nil
+			})
+			span.Annotations = append(span.Annotations, a)
+		case 11:
+			var k, v string
+			err = rangeProtoFields(f.bytes, func(f protoField) error {
+				switch f.num {
+				case 1:
+					k = string(f.bytes)
+				case 2:
+					v = string(f.bytes)
+				}
+				return nil
+			})
+			if span.Tags == nil {
+				span.Tags = make(map[string]string)
+			}
+			span.Tags[k] = v
+		}
+		return err
+	})
+	return span, err
+}
+
+func decodeZipkinProtoEndpoint(b []byte) (*zipkinEndpoint, error) {
+	var e zipkinEndpoint
+	err := rangeProtoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			e.ServiceName = string(f.bytes)
+		case 2, 3:
+			// addresses are sent as raw bytes, in network byte order
+			ip := net.IP(f.bytes).String()
+			if f.num == 2 {
+				e.IPv4 = ip
+			} else {
+				e.IPv6 = ip
+			}
+		case 4:
+			e.Port = int64(f.num64)
+		}
+		return nil
+	})
+	return &e, err
+}
+
+// protoField is a field of an encoded protobuf message.
+type protoField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	num64 uint64 // value of the varint and fixed-size fields
+	bytes []byte // value of the length-delimited fields
+}
+
+// rangeProtoFields calls f for each field of the encoded protobuf message b, stopping at the
+// first error.
+func rangeProtoFields(b []byte, f func(protoField) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		field := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			field.num64, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed64Type:
+			field.num64, n = protowire.ConsumeFixed64(b)
+		case protowire.Fixed32Type:
+			var v uint32
+			v, n = protowire.ConsumeFixed32(b)
+			field.num64 = uint64(v)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := f(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const testZipkinJSON = `[
+	{
+		"traceId": "5af7183fb1d4cf5f",
+		"id": "352bff9a74ca9ad2",
+		"kind": "SERVER",
+		"name": "get /users",
+		"timestamp": 1556604172355737,
+		"duration": 1431,
+		"localEndpoint": {"serviceName": "web", "ipv4": "10.0.0.1"},
+		"tags": {"http.method": "GET", "http.path": "/users"}
+	},
+	{
+		"traceId": "5af7183fb1d4cf5f",
+		"parentId": "352bff9a74ca9ad2",
+		"id": "a2fb4a1d1a96d312",
+		"kind": "CLIENT",
+		"name": "select",
+		"timestamp": 1556604172356000,
+		"duration": 500,
+		"localEndpoint": {"serviceName": "web"},
+		"remoteEndpoint": {"serviceName": "db", "ipv4": "10.0.0.2", "port": 5432},
+		"annotations": [{"timestamp": 1556604172356100, "value": "retry"}],
+		"tags": {"error": "connection reset"}
+	}
+]`
+
+func assertTestZipkinTraces(t *testing.T, traces ptrace.Traces) {
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	rspans := traces.ResourceSpans().At(0)
+	service, ok := rspans.Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "web", service.Str())
+
+	spans := rspans.ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+
+	root := spans.At(0)
+	assert.Equal(t, "00000000000000005af7183fb1d4cf5f", root.TraceID().String())
+	assert.Equal(t, "352bff9a74ca9ad2", root.SpanID().String())
+	assert.True(t, root.ParentSpanID().IsEmpty())
+	assert.Equal(t, ptrace.SpanKindServer, root.Kind())
+	assert.Equal(t, "get /users", root.Name())
+	assert.EqualValues(t, 1556604172355737000, root.StartTimestamp())
+	assert.EqualValues(t, 1556604172357168000, root.EndTimestamp())
+	assert.Equal(t, map[string]any{"http.method": "GET", "http.path": "/users"}, root.Attributes().AsRaw())
+	assert.Equal(t, ptrace.StatusCodeUnset, root.Status().Code())
+
+	child := spans.At(1)
+	assert.Equal(t, "352bff9a74ca9ad2", child.ParentSpanID().String())
+	assert.Equal(t, ptrace.SpanKindClient, child.Kind())
+	assert.Equal(t, ptrace.StatusCodeError, child.Status().Code())
+	assert.Equal(t, "connection reset", child.Status().Message())
+	assert.Equal(t, map[string]any{
+		"peer.service":  "db",
+		"net.peer.ip":   "10.0.0.2",
+		"net.peer.port": int64(5432),
+	}, child.Attributes().AsRaw())
+	require.Equal(t, 1, child.Events().Len())
+	assert.Equal(t, "retry", child.Events().At(0).Name())
+	assert.EqualValues(t, 1556604172356100000, child.Events().At(0).Timestamp())
+}
+
+func TestDecodeZipkinSpans(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		traces, err := decodeZipkinSpans([]byte(testZipkinJSON), "application/json")
+		require.NoError(t, err)
+		assertTestZipkinTraces(t, traces)
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		field := func(b []byte, num protowire.Number, v []byte) []byte {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			return protowire.AppendBytes(b, v)
+		}
+		varint := func(b []byte, num protowire.Number, v uint64) []byte {
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			return protowire.AppendVarint(b, v)
+		}
+		fixed := func(b []byte, num protowire.Number, v uint64) []byte {
+			b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+			return protowire.AppendFixed64(b, v)
+		}
+		tag := func(k, v string) []byte {
+			return field(field(nil, 1, []byte(k)), 2, []byte(v))
+		}
+
+		var root []byte
+		root = field(root, 1, []byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f})
+		root = field(root, 3, []byte{0x35, 0x2b, 0xff, 0x9a, 0x74, 0xca, 0x9a, 0xd2})
+		root = varint(root, 4, 2) // SERVER
+		root = field(root, 5, []byte("get /users"))
+		root = fixed(root, 6, 1556604172355737)
+		root = varint(root, 7, 1431)
+		root = field(root, 8, field(field(nil, 1, []byte("web")), 2, []byte{10, 0, 0, 1}))
+		root = field(root, 11, tag("http.method", "GET"))
+		root = field(root, 11, tag("http.path", "/users"))
+		root = varint(root, 99, 1) // unknown fields are skipped
+
+		var child []byte
+		child = field(child, 1, []byte{0x5a, 0xf7, 0x18, 0x3f, 0xb1, 0xd4, 0xcf, 0x5f})
+		child = field(child, 2, []byte{0x35, 0x2b, 0xff, 0x9a, 0x74, 0xca, 0x9a, 0xd2})
+		child = field(child, 3, []byte{0xa2, 0xfb, 0x4a, 0x1d, 0x1a, 0x96, 0xd3, 0x12})
+		child = varint(child, 4, 1) // CLIENT
+		child = field(child, 5, []byte("select"))
+		child = fixed(child, 6, 1556604172356000)
+		child = varint(child, 7, 500)
+		child = field(child, 8, field(nil, 1, []byte("web")))
+		child = field(child, 9, varint(field(field(nil, 1, []byte("db")), 2, []byte{10, 0, 0, 2}), 4, 5432))
+		child = field(child, 10, field(fixed(nil, 1, 1556604172356100), 2, []byte("retry")))
+		child = field(child, 11, tag("error", "connection reset"))
+
+		traces, err := decodeZipkinSpans(field(field(nil, 1, root), 1, child), "application/x-protobuf")
+		require.NoError(t, err)
+		assertTestZipkinTraces(t, traces)
+
+		_, err = decodeZipkinSpans(field(nil, 1, root)[:10], "application/x-protobuf")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, payload := range []string{
+			`{`,
+			`[{"traceId": "xyz", "id": "352bff9a74ca9ad2"}]`,
+			`[{"traceId": "5af7183fb1d4cf5f", "id": "352bff9a74ca9ad2352bff9a74ca9ad2"}]`,
+			`[{"traceId": "5af7183fb1d4cf5f"}]`,
+		} {
+			_, err := decodeZipkinSpans([]byte(payload), "application/json")
+			assert.Error(t, err, payload)
+		}
+	})
+}
+
+func TestZipkinEndpoint(t *testing.T) {
+	conf := NewTestConfig(t)
+	conf.DecoderTimeout = 10000
+	conf.ZipkinReceiverEnabled = true
+	rcv := newTestReceiverFromConfig(conf)
+	mux := rcv.buildMux()
+
+	req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewBufferString(testZipkinJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	p := <-rcv.out
+	assert.Equal(t, zipkinEndpointVersion, p.Source.EndpointVersion)
+	chunks := p.TracerPayload.Chunks
+	require.Len(t, chunks, 1)
+	require.Len(t, chunks[0].Spans, 2)
+	root, child := chunks[0].Spans[0], chunks[0].Spans[1]
+	assert.Equal(t, "web", root.Service)
+	assert.EqualValues(t, 0x5af7183fb1d4cf5f, root.TraceID)
+	assert.EqualValues(t, 0x352bff9a74ca9ad2, root.SpanID)
+	assert.Equal(t, "GET", root.Meta["http.method"])
+	assert.Equal(t, root.SpanID, child.ParentID)
+	assert.EqualValues(t, 1, child.Error)
+
+	req = httptest.NewRequest("POST", "/api/v2/spans", bytes.NewBufferString("["))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	t.Run("disabled", func(t *testing.T) {
+		rcv := newTestReceiverFromConfig(NewTestConfig(t))
+		req := httptest.NewRequest("POST", "/api/v2/spans", bytes.NewBufferString(testZipkinJSON))
+		rec := httptest.NewRecorder()
+		rcv.buildMux().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -8,11 +8,13 @@ package config
 import (
 	"crypto/tls"
 	"errors"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"time"
 
 	"github.com/DataDog/opentelemetry-mapping-go/pkg/otlp/attributes"
@@ -128,6 +130,10 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached obfuscate.MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the resource and the "graphql.query"
+	// and "graphql.source" tags for spans of type "graphql".
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards obfuscate.CreditCardsConfig `mapstructure:"credit_cards"`
 
@@ -168,6 +174,7 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		Redis:                o.Redis,
 		Valkey:               o.Valkey,
 		Memcached:            o.Memcached,
+		GraphQL:              o.GraphQL,
 		CreditCard:           o.CreditCards,
 		Logger:               new(debugLogger),
 		Cache:                o.Cache,
@@ -209,6 +216,145 @@ type ReplaceRule struct {
 	Repl string `mapstructure:"repl"`
 }
 
+// TailSamplingConfig holds the configuration of the tail-based sampling.
+type TailSamplingConfig struct {
+	// Enabled enables buffering the chunks by trace ID to decide on whole
+	// traces rather than on each chunk as it arrives.
+	Enabled bool
+	// DecisionWait is how long the chunks of a trace are buffered, starting
+	// from the reception of its first chunk, before deciding on the trace.
+	DecisionWait time.Duration
+	// MaxMemory is the maximum size in bytes of the buffered chunks, the
+	// oldest traces are decided early when it is exceeded. The watchdog
+	// accounts for it on top of MaxMemory.
+	MaxMemory int64
+	// Rules keep the traces matching any of them, the traces matching none
+	// are sampled by the other samplers.
+	Rules []*TailSamplingRule
+}
+
+// Types of tail sampling rules.
+const (
+	// TailSamplingRuleLatency keeps the traces lasting at least MinDuration.
+	TailSamplingRuleLatency = "latency"
+	// TailSamplingRuleError keeps the traces having a span in error.
+	TailSamplingRuleError = "error"
+	// TailSamplingRuleTag keeps the traces having a span with the TagKey tag,
+	// and the TagValue value when it is set.
+	TailSamplingRuleTag = "tag"
+)
+
+// TailSamplingRule specifies a rule of the tail-based sampling.
+type TailSamplingRule struct {
+	// Name of the rule, reported in the metrics and in the
+	// _dd.tail_sampling.rule tag of the kept chunks.
+	Name string `mapstructure:"name"`
+
+	// Type of the rule, one of latency, error or tag.
+	Type string `mapstructure:"type"`
+
+	// MinDurationMs is the minimum duration of the traces kept by a latency
+	// rule, in milliseconds.
+	MinDurationMs int64 `mapstructure:"min_duration_ms"`
+
+	// TagKey and TagValue are the tag matched by a tag rule.
+	TagKey   string `mapstructure:"key"`
+	TagValue string `mapstructure:"value"`
+}
+
+// SamplingRule specifies an agent-side trace sampling rule. The traces whose
+// root span matches all the set fields are sampled with either SampleRate or
+// TargetTPS instead of the priority sampler.
+type SamplingRule struct {
+	// Service, Env, Resource and OperationName are glob patterns matching the
+	// root span, where "*" matches any string and "?" any single character.
+	// Empty patterns match everything.
+	Service       string `mapstructure:"service"`
+	Env           string `mapstructure:"env"`
+	Resource      string `mapstructure:"resource"`
+	OperationName string `mapstructure:"name"`
+
+	// Tags maps tag keys to glob patterns their value must match on the root span.
+	Tags map[string]string `mapstructure:"tags"`
+
+	// SampleRate is the rate, between 0 and 1, at which the matching traces are kept.
+	SampleRate *float64 `mapstructure:"sample_rate"`
+
+	// TargetTPS is the maximum number of matching traces kept per second.
+	TargetTPS *float64 `mapstructure:"target_tps"`
+}
+
+// Validate returns an error if the rule does not have exactly one valid
+// SampleRate or TargetTPS.
+func (r *SamplingRule) Validate() error {
+	switch {
+	case r.SampleRate == nil && r.TargetTPS == nil:
+		return errors.New(`rules must have either a "sample_rate" or a "target_tps"`)
+	case r.SampleRate != nil && r.TargetTPS != nil:
+		return errors.New(`rules must not have both a "sample_rate" and a "target_tps"`)
+	case r.SampleRate != nil && (*r.SampleRate < 0 || *r.SampleRate > 1):
+		return fmt.Errorf("sample_rate must be between 0 and 1, got %v", *r.SampleRate)
+	case r.TargetTPS != nil && *r.TargetTPS < 0:
+		return fmt.Errorf("target_tps must not be negative, got %v", *r.TargetTPS)
+	}
+	return nil
+}
+
+// PIIRedactionConfig holds the configuration of the detection and redaction
+// of personally identifiable information (PII) in span tags.
+type PIIRedactionConfig struct {
+	// Enabled enables the redaction of the PII found by the detectors of Rules.
+	Enabled bool
+
+	// Rules lists the detectors to apply to the tags, by tag key.
+	Rules []*PIIRedactionRule
+}
+
+// PII detectors, as named in the PII redaction rules.
+const (
+	PIIDetectorEmail       = "email"
+	PIIDetectorJWT         = "jwt"
+	PIIDetectorBearerToken = "bearer_token"
+	PIIDetectorCreditCard  = "credit_card"
+	PIIDetectorIBAN        = "iban"
+	PIIDetectorPhoneNumber = "phone_number"
+)
+
+// PIIDetectors lists all the available PII detectors, in the order they are applied.
+var PIIDetectors = []string{
+	PIIDetectorEmail,
+	PIIDetectorJWT,
+	PIIDetectorBearerToken,
+	PIIDetectorCreditCard,
+	PIIDetectorIBAN,
+	PIIDetectorPhoneNumber,
+}
+
+// PIIRedactionRule enables a set of PII detectors on the span tags, span event
+// attributes and metrics whose key matches Key.
+type PIIRedactionRule struct {
+	// Key is a glob pattern matching the tag keys, where "*" matches any string
+	// and "?" any single character.
+	Key string `mapstructure:"key"`
+
+	// Detectors lists the names of the detectors to apply. All of them are
+	// applied when empty.
+	Detectors []string `mapstructure:"detectors"`
+}
+
+// Validate returns an error if the rule has no key or names unknown detectors.
+func (r *PIIRedactionRule) Validate() error {
+	if r.Key == "" {
+		return errors.New(`rules must have a "key"`)
+	}
+	for _, d := range r.Detectors {
+		if !slices.Contains(PIIDetectors, d) {
+			return fmt.Errorf("unknown detector %q, must be one of %v", d, PIIDetectors)
+		}
+	}
+	return nil
+}
+
 // WriterConfig specifies configuration for an API writer.
 type WriterConfig struct {
 	// ConnectionLimit specifies the maximum number of concurrent outgoing
@@ -346,6 +492,13 @@ type AgentConfig struct {
 	// Error Tracking Standalone
 	ErrorTrackingStandalone bool
 
+	// Tail-based sampling configuration
+	TailSampling TailSamplingConfig
+
+	// SamplingRules are the agent-side sampling rules, evaluated in order
+	// before the priority sampler.
+	SamplingRules []*SamplingRule
+
 	// Receiver
 	ReceiverEnabled bool // specifies whether Receiver listeners are enabled. Unless OTLPReceiver is used, this should always be true.
 	ReceiverHost    string
@@ -359,6 +512,9 @@ type AgentConfig struct {
 	MaxConnections  int   // specifies the maximum number of concurrent incoming connections allowed.
 	DecoderTimeout  int   // specifies the maximum time in milliseconds that the decoders will wait for a turn to accept a payload before returning 429
 
+	ZipkinReceiverEnabled bool // specifies whether Zipkin v2 spans are accepted on /api/v2/spans.
+	JaegerReceiverEnabled bool // specifies whether Jaeger Thrift batches are accepted on /api/traces.
+
 	WindowsPipeName        string
 	PipeBufferSize         int
 	PipeSecurityDescriptor string
@@ -407,6 +563,10 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// PIIRedaction holds the configuration of the built-in PII detectors, which
+	// redact sensitive data from the tag values without requiring a ReplaceRule.
+	PIIRedaction PIIRedactionConfig
+
 	// GlobalTags list metadata that will be added to all spans
 	GlobalTags map[string]string
 
@@ -546,6 +706,11 @@ func New() *AgentConfig {
 
 		ErrorTrackingStandalone: false,
 
+		TailSampling: TailSamplingConfig{
+			DecisionWait: 10 * time.Second,
+			MaxMemory:    50 * 1024 * 1024, // 50MB
+		},
+
 		ReceiverEnabled:        true,
 		ReceiverHost:           "localhost",
 		ReceiverPort:           8126,
@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package filters
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+// redactedPII replaces the PII found in tag values.
+const redactedPII = "?"
+
+// piiDetector finds a kind of PII in strings.
+type piiDetector struct {
+	// index is the position of the detector in config.PIIDetectors.
+	index int
+	re    *regexp.Regexp
+	// hint cheaply reports whether a string may contain a match of re.
+	hint func(string) bool
+	// valid reports whether a match of re is PII, for the detectors whose pattern
+	// is too loose to tell by itself. It is nil when all the matches are PII.
+	valid func(string) bool
+}
+
+// redact replaces the PII found in s, counting the redactions in counts.
+func (d *piiDetector) redact(s string, counts []int64) string {
+	if !d.hint(s) {
+		return s
+	}
+	return d.re.ReplaceAllStringFunc(s, func(m string) string {
+		if d.valid != nil && !d.valid(m) {
+			return m
+		}
+		counts[d.index]++
+		if d.re.NumSubexp() > 0 {
+			// the first group is a prefix which is kept, e.g. the "Bearer" scheme
+			return d.re.FindStringSubmatch(m)[1] + redactedPII
+		}
+		return redactedPII
+	})
+}
+
+// newPIIDetectors returns the detectors of config.PIIDetectors, by name.
+func newPIIDetectors() map[string]*piiDetector {
+	detectors := map[string]*piiDetector{
+		config.PIIDetectorEmail: {
+			re:   regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`),
+			hint: func(s string) bool { return strings.IndexByte(s, '@') >= 0 },
+		},
+		config.PIIDetectorJWT: {
+			re:   regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`),
+			hint: func(s string) bool { return strings.Contains(s, "eyJ") },
+		},
+		config.PIIDetectorBearerToken: {
+			re:   regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+			hint: func(s string) bool { return strings.Contains(s, "earer") || strings.Contains(s, "EARER") },
+		},
+		config.PIIDetectorCreditCard: {
+			re:    regexp.MustCompile(`\b\d(?:[ \-]?\d){11,15}\b`),
+			hint:  containsDigit,
+			valid: func(s string) bool { return obfuscate.IsCardNumber(s, true) },
+		},
+		config.PIIDetectorIBAN: {
+			re:    regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+			hint:  containsDigit,
+			valid: validIBAN,
+		},
+		config.PIIDetectorPhoneNumber: {
+			re: regexp.MustCompile(
+				`\+\d{1,3}(?:[ .\-]?\(?\d{1,4}\)?){2,5}` + // international: +33 6 12 34 56 78, +1 (555) 123-4567
+					`|\(\d{3}\) ?\d{3}[ .\-]\d{4}\b` + // (555) 123-4567
+					`|\b\d{3}[.\-]\d{3}[.\-]\d{4}\b`, // 555-123-4567
+			),
+			hint: containsDigit,
+			valid: func(s string) bool {
+				n := countDigits(s)
+				return n >= 7 && n <= 15
+			},
+		},
+	}
+	for name, d := range detectors {
+		d.index = slices.Index(config.PIIDetectors, name)
+	}
+	return detectors
+}
+
+func containsDigit(s string) bool {
+	return strings.IndexAny(s, "0123456789") >= 0
+}
+
+func countDigits(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			n++
+		}
+	}
+	return n
+}
+
+// validIBAN reports whether s, an IBAN which may contain spaces, has a valid length and checksum.
+// See https://en.wikipedia.org/wiki/International_Bank_Account_Number#Validating_the_IBAN.
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 15 || len(s) > 34 {
+		return false
+	}
+	mod := 0
+	for _, c := range s[4:] + s[:4] {
+		switch {
+		case c >= '0' && c <= '9':
+			mod = (mod*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			mod = (mod*100 + int(c-'A'+10)) % 97
+		default:
+			return false
+		}
+	}
+	return mod == 1
+}
+
+// piiRule applies a set of detectors to the tags whose key matches a pattern.
+type piiRule struct {
+	key       *regexp.Regexp
+	detectors []*piiDetector
+}
+
+// PIIRedactor is a filter which redacts the personally identifiable information (PII)
+// found by its detectors in span tags, metrics and span event attributes. It keeps all spans.
+type PIIRedactor struct {
+	rules  []*piiRule
+	statsd statsd.ClientInterface
+}
+
+// NewPIIRedactor returns a new PIIRedactor applying the given rules. The redactions are
+// reported to statsd. Invalid rules are logged and ignored.
+func NewPIIRedactor(rules []*config.PIIRedactionRule, statsd statsd.ClientInterface) *PIIRedactor {
+	detectors := newPIIDetectors()
+	r := &PIIRedactor{statsd: statsd}
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			log.Errorf("Ignoring invalid PII redaction rule %+v: %v", *rule, err)
+			continue
+		}
+		names := rule.Detectors
+		if len(names) == 0 {
+			names = config.PIIDetectors
+		}
+		pr := &piiRule{key: compileKeyGlob(rule.Key)}
+		for _, name := range config.PIIDetectors {
+			// detectors are applied in the same order for all rules
+			if slices.Contains(names, name) {
+				pr.detectors = append(pr.detectors, detectors[name])
+			}
+		}
+		r.rules = append(r.rules, pr)
+	}
+	return r
+}
+
+// compileKeyGlob compiles the glob pattern p, in which "*" matches any string and "?"
+// any single character.
+func compileKeyGlob(p string) *regexp.Regexp {
+	p = regexp.QuoteMeta(p)
+	p = strings.ReplaceAll(p, `\*`, ".*")
+	p = strings.ReplaceAll(p, `\?`, ".")
+	return regexp.MustCompile("^" + p + "$")
+}
+
+// redact applies the detectors of the rules matching key to val.
+func (f *PIIRedactor) redact(key, val string, counts []int64) string {
+	for _, rule := range f.rules {
+		if !rule.key.MatchString(key) {
+			continue
+		}
+		for _, d := range rule.detectors {
+			val = d.redact(val, counts)
+		}
+	}
+	return val
+}
+
+// Redact redacts the PII found in the tags, metrics and span event attributes of trace.
+// Metrics are checked in their string form and moved to the meta when redacted.
+func (f *PIIRedactor) Redact(trace pb.Trace) {
+	if len(f.rules) == 0 {
+		return
+	}
+	counts := make([]int64, len(config.PIIDetectors))
+	for _, s := range trace {
+		for k, v := range s.Meta {
+			if !strings.HasPrefix(k, hiddenTagPrefix) {
+				s.Meta[k] = f.redact(k, v, counts)
+			}
+		}
+		for k, v := range s.Metrics {
+			if strings.HasPrefix(k, hiddenTagPrefix) {
+				continue
+			}
+			str := strconv.FormatFloat(v, 'f', -1, 64)
+			if redacted := f.redact(k, str, counts); redacted != str {
+				if s.Meta == nil {
+					s.Meta = make(map[string]string)
+				}
+				s.Meta[k] = redacted
+				delete(s.Metrics, k)
+			}
+		}
+		for _, spanEvent := range s.SpanEvents {
+			if spanEvent == nil {
+				continue
+			}
+			for k, v := range spanEvent.Attributes {
+				if v != nil && !strings.HasPrefix(k, hiddenTagPrefix) {
+					spanEvent.Attributes[k] = f.redactAttributeAnyValue(k, v, counts)
+				}
+			}
+		}
+	}
+	for i, n := range counts {
+		if n > 0 {
+			_ = f.statsd.Count("datadog.trace_agent.pii_redaction.redacted", n, []string{"detector:" + config.PIIDetectors[i]}, 1)
+		}
+	}
+}
+
+// redactAttributeAnyValue returns val redacted. Numeric values are turned into strings when redacted.
+func (f *PIIRedactor) redactAttributeAnyValue(key string, val *pb.AttributeAnyValue, counts []int64) *pb.AttributeAnyValue {
+	var str string
+	switch val.Type {
+	case pb.AttributeAnyValue_STRING_VALUE:
+		str = val.StringValue
+	case pb.AttributeAnyValue_INT_VALUE:
+		str = strconv.FormatInt(val.IntValue, 10)
+	case pb.AttributeAnyValue_DOUBLE_VALUE:
+		str = strconv.FormatFloat(val.DoubleValue, 'f', -1, 64)
+	case pb.AttributeAnyValue_ARRAY_VALUE:
+		if val.ArrayValue != nil {
+			for _, v := range val.ArrayValue.Values {
+				if v != nil && v.Type == pb.AttributeArrayValue_STRING_VALUE {
+					v.StringValue = f.redact(key, v.StringValue, counts)
+				}
+			}
+		}
+		return val
+	default:
+		return val
+	}
+	if redacted := f.redact(key, str, counts); redacted != str {
+		return &pb.AttributeAnyValue{
+			Type:        pb.AttributeAnyValue_STRING_VALUE,
+			StringValue: redacted,
+		}
+	}
+	return val
+}
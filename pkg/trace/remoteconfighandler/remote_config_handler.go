@@ -34,12 +34,17 @@ type rareSampler interface {
 	SetEnabled(enabled bool)
 }
 
+type ruleSampler interface {
+	UpdateRules(rules []*config.SamplingRule) error
+}
+
 // RemoteConfigHandler holds pointers to samplers that need to be updated when APM remote config changes
 type RemoteConfigHandler struct {
 	remoteClient                  config.RemoteClient
 	prioritySampler               prioritySampler
 	errorsSampler                 errorsSampler
 	rareSampler                   rareSampler
+	ruleSampler                   ruleSampler
 	agentConfig                   *config.AgentConfig
 	configState                   *state.AgentConfigState
 	configHTTPClient              *http.Client
@@ -47,7 +52,7 @@ type RemoteConfigHandler struct {
 }
 
 // New creates a new RemoteConfigHandler
-func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler) *RemoteConfigHandler {
+func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler rareSampler, errorsSampler errorsSampler, ruleSampler ruleSampler) *RemoteConfigHandler {
 	if conf.RemoteConfigClient == nil {
 		return nil
 	}
@@ -68,6 +73,7 @@ func New(conf *config.AgentConfig, prioritySampler prioritySampler, rareSampler
 		prioritySampler: prioritySampler,
 		rareSampler:     rareSampler,
 		errorsSampler:   errorsSampler,
+		ruleSampler:     ruleSampler,
 		agentConfig:     conf,
 		configState: &state.AgentConfigState{
 			FallbackLogLevel: level.String(),
@@ -230,4 +236,30 @@ func (h *RemoteConfigHandler) updateSamplers(config apmsampling.SamplerConfig) {
 		rareSamplerEnabled = h.agentConfig.RareSamplerEnabled
 	}
 	h.rareSampler.SetEnabled(rareSamplerEnabled)
+
+	samplingRules := h.agentConfig.SamplingRules
+	if confForEnv != nil && confForEnv.SamplingRules != nil {
+		samplingRules = convertSamplingRules(*confForEnv.SamplingRules)
+	} else if config.AllEnvs.SamplingRules != nil {
+		samplingRules = convertSamplingRules(*config.AllEnvs.SamplingRules)
+	}
+	if err := h.ruleSampler.UpdateRules(samplingRules); err != nil {
+		log.Errorf("couldn't apply the remote configuration sampling rules: %s", err)
+	}
+}
+
+func convertSamplingRules(rules []apmsampling.SamplingRule) []*config.SamplingRule {
+	converted := make([]*config.SamplingRule, 0, len(rules))
+	for _, r := range rules {
+		converted = append(converted, &config.SamplingRule{
+			Service:       r.Service,
+			Env:           r.Env,
+			Resource:      r.Resource,
+			OperationName: r.Name,
+			Tags:          r.Tags,
+			SampleRate:    r.SampleRate,
+			TargetTPS:     r.TargetTPS,
+		})
+	}
+	return converted
 }
@@ -33,9 +33,10 @@ func TestStart(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	remoteClient.EXPECT().Subscribe(state.ProductAPMSampling, gomock.Any()).Times(1)
 	remoteClient.EXPECT().Subscribe(state.ProductAgentConfig, gomock.Any()).Times(1)
@@ -57,10 +58,11 @@ func TestPrioritySampler(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -76,6 +78,7 @@ func TestPrioritySampler(t *testing.T) {
 	prioritySampler.EXPECT().UpdateTargetTPS(float64(42)).Times(1)
 	errorsSampler.EXPECT().UpdateTargetTPS(float64(41)).Times(1)
 	rareSampler.EXPECT().SetEnabled(true).Times(1)
+	ruleSampler.EXPECT().UpdateRules(gomock.Nil()).Times(1)
 
 	h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": config}, applyEmpty)
 
@@ -88,10 +91,11 @@ func TestErrorsSampler(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -107,6 +111,7 @@ func TestErrorsSampler(t *testing.T) {
 	prioritySampler.EXPECT().UpdateTargetTPS(float64(41)).Times(1)
 	errorsSampler.EXPECT().UpdateTargetTPS(float64(42)).Times(1)
 	rareSampler.EXPECT().SetEnabled(true).Times(1)
+	ruleSampler.EXPECT().UpdateRules(gomock.Nil()).Times(1)
 
 	h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": config}, applyEmpty)
 
@@ -119,10 +124,11 @@ func TestRareSampler(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -138,6 +144,7 @@ func TestRareSampler(t *testing.T) {
 	prioritySampler.EXPECT().UpdateTargetTPS(float64(41)).Times(1)
 	errorsSampler.EXPECT().UpdateTargetTPS(float64(41)).Times(1)
 	rareSampler.EXPECT().SetEnabled(false).Times(1)
+	ruleSampler.EXPECT().UpdateRules(gomock.Nil()).Times(1)
 
 	h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": config}, applyEmpty)
 
@@ -150,10 +157,11 @@ func TestEnvPrecedence(t *testing.T) {
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 
 	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, RareSamplerEnabled: true, DefaultEnv: "agent-env", DebugServerPort: 1}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	payload := apmsampling.SamplerConfig{
 		AllEnvs: apmsampling.SamplerEnvConfig{
@@ -179,18 +187,81 @@ func TestEnvPrecedence(t *testing.T) {
 	prioritySampler.EXPECT().UpdateTargetTPS(float64(43)).Times(1)
 	errorsSampler.EXPECT().UpdateTargetTPS(float64(43)).Times(1)
 	rareSampler.EXPECT().SetEnabled(false).Times(1)
+	ruleSampler.EXPECT().UpdateRules(gomock.Nil()).Times(1)
 
 	h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": config}, applyEmpty)
 
 	ctrl.Finish()
 }
 
+func TestSamplingRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	remoteClient := NewMockRemoteClient(ctrl)
+	prioritySampler := NewMockprioritySampler(ctrl)
+	errorsSampler := NewMockerrorsSampler(ctrl)
+	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
+	pkglog.SetupLogger(pkglog.Default(), "debug")
+
+	localRules := []*config.SamplingRule{{Service: "web", SampleRate: pointer.Ptr(0.5)}}
+	agentConfig := config.AgentConfig{RemoteConfigClient: remoteClient, TargetTPS: 41, ErrorTPS: 41, DefaultEnv: "agent-env", DebugServerPort: 1, SamplingRules: localRules}
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
+
+	prioritySampler.EXPECT().UpdateTargetTPS(float64(41)).AnyTimes()
+	errorsSampler.EXPECT().UpdateTargetTPS(float64(41)).AnyTimes()
+	rareSampler.EXPECT().SetEnabled(false).AnyTimes()
+
+	update := func(payload apmsampling.SamplerConfig) {
+		raw, _ := json.Marshal(payload)
+		h.onUpdate(map[string]state.RawConfig{"datadog/2/APM_SAMPLING/samplerconfig/config": {Config: raw}}, applyEmpty)
+	}
+
+	// the rules of the agent env take precedence
+	ruleSampler.EXPECT().UpdateRules([]*config.SamplingRule{{
+		Service:       "web",
+		Resource:      "GET /health*",
+		OperationName: "http.request",
+		Tags:          map[string]string{"http.method": "GET"},
+		TargetTPS:     pointer.Ptr(1.0),
+	}}).Times(1)
+	update(apmsampling.SamplerConfig{
+		AllEnvs: apmsampling.SamplerEnvConfig{
+			SamplingRules: &[]apmsampling.SamplingRule{{Service: "web", SampleRate: pointer.Ptr(0.1)}},
+		},
+		ByEnv: []apmsampling.EnvAndConfig{{
+			Env: "agent-env",
+			Config: apmsampling.SamplerEnvConfig{
+				SamplingRules: &[]apmsampling.SamplingRule{{
+					Service:   "web",
+					Resource:  "GET /health*",
+					Name:      "http.request",
+					Tags:      map[string]string{"http.method": "GET"},
+					TargetTPS: pointer.Ptr(1.0),
+				}},
+			},
+		}},
+	})
+
+	// an empty list removes the rules
+	ruleSampler.EXPECT().UpdateRules([]*config.SamplingRule{}).Times(1)
+	update(apmsampling.SamplerConfig{
+		AllEnvs: apmsampling.SamplerEnvConfig{SamplingRules: &[]apmsampling.SamplingRule{}},
+	})
+
+	// the local rules are restored when the remote ones are removed
+	ruleSampler.EXPECT().UpdateRules(localRules).Times(1)
+	update(apmsampling.SamplerConfig{})
+
+	ctrl.Finish()
+}
+
 func TestLogLevel(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	remoteClient := NewMockRemoteClient(ctrl)
 	prioritySampler := NewMockprioritySampler(ctrl)
 	errorsSampler := NewMockerrorsSampler(ctrl)
 	rareSampler := NewMockrareSampler(ctrl)
+	ruleSampler := NewMockruleSampler(ctrl)
 
 	pkglog.SetupLogger(pkglog.Default(), "debug")
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -208,7 +279,7 @@ func TestLogLevel(t *testing.T) {
 			return "fakeToken"
 		},
 	}
-	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler)
+	h := New(&agentConfig, prioritySampler, rareSampler, errorsSampler, ruleSampler)
 
 	layer := state.RawConfig{Config: []byte(`{"name": "layer1", "config": {"log_level": "debug"}}`)}
 	configOrder := state.RawConfig{Config: []byte(`{"internal_order": ["layer1", "layer2"]}`)}
@@ -38,6 +38,10 @@ const (
 	NameRare
 	// NameProbabilistic is the name of the probabilistic sampler.
 	NameProbabilistic
+	// NameTailSampling is the name of the tail-based sampling rules.
+	NameTailSampling
+	// NameRule is the name of the agent-side sampling rules.
+	NameRule
 )
 
 // String returns the string representation of the Name.
@@ -53,13 +57,17 @@ func (n Name) String() string {
 		return "rare"
 	case NameProbabilistic:
 		return "probabilistic"
+	case NameTailSampling:
+		return "tail_sampling"
+	case NameRule:
+		return "rule"
 	default:
 		return "unknown"
 	}
 }
 
 func (n Name) shouldAddEnvTag() bool {
-	return n == NamePriority || n == NameNoPriority || n == NameRare || n == NameError
+	return n == NamePriority || n == NameNoPriority || n == NameRare || n == NameError || n == NameTailSampling || n == NameRule
 }
 
 // Metrics is a structure to record metrics for the different samplers.
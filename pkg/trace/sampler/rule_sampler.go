@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+// agentRuleRateKey is set on the root span of the traces kept by an agent-side
+// sampling rule with a sample rate.
+const agentRuleRateKey = "_dd.agent_rule_sr"
+
+// RuleSampler samples the traces matching the agent-side sampling rules. The
+// first rule matching the root span of a chunk decides whether it is kept,
+// with either a sample rate or a target TPS. It runs before the priority
+// sampler, which only samples the chunks matching no rule.
+type RuleSampler struct {
+	mu    sync.RWMutex
+	rules []*samplingRule
+}
+
+// samplingRule is a compiled config.SamplingRule. A nil pattern matches
+// everything.
+type samplingRule struct {
+	service   *regexp.Regexp
+	env       *regexp.Regexp
+	resource  *regexp.Regexp
+	operation *regexp.Regexp
+	tags      map[string]*regexp.Regexp
+
+	rate    float64
+	limiter *rate.Limiter // nil for the rules with a sample rate
+}
+
+// NewRuleSampler returns a RuleSampler applying the sampling rules of conf.
+func NewRuleSampler(conf *config.AgentConfig) *RuleSampler {
+	s := &RuleSampler{}
+	if err := s.UpdateRules(conf.SamplingRules); err != nil {
+		log.Errorf("Ignoring the agent sampling rules: %v", err)
+	}
+	return s
+}
+
+// UpdateRules replaces the sampling rules. The rules are left unchanged when
+// one of the new ones is invalid.
+func (s *RuleSampler) UpdateRules(rules []*config.SamplingRule) error {
+	compiled := make([]*samplingRule, 0, len(rules))
+	for i, r := range rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %v", i, err)
+		}
+		rule := &samplingRule{
+			service:   compileGlob(r.Service),
+			env:       compileGlob(r.Env),
+			resource:  compileGlob(r.Resource),
+			operation: compileGlob(r.OperationName),
+		}
+		if len(r.Tags) > 0 {
+			rule.tags = make(map[string]*regexp.Regexp, len(r.Tags))
+			for k, v := range r.Tags {
+				rule.tags[k] = compileGlob(v)
+			}
+		}
+		if r.SampleRate != nil {
+			rule.rate = *r.SampleRate
+		} else {
+			rule.limiter = rate.NewLimiter(rate.Limit(*r.TargetTPS), int(math.Ceil(*r.TargetTPS)))
+		}
+		compiled = append(compiled, rule)
+	}
+
+	s.mu.Lock()
+	s.rules = compiled
+	s.mu.Unlock()
+	return nil
+}
+
+// Sample returns whether a rule matches the chunk of the given root span and
+// env, and if so whether the chunk should be kept.
+func (s *RuleSampler) Sample(now time.Time, root *pb.Span, env string) (keep bool, matched bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if !rule.match(root, env) {
+			continue
+		}
+		if rule.limiter != nil {
+			return rule.limiter.AllowN(now, 1), true
+		}
+		if SampleByRate(root.TraceID, rule.rate) {
+			setMetric(root, agentRuleRateKey, rule.rate)
+			return true, true
+		}
+		return false, true
+	}
+	return false, false
+}
+
+func (r *samplingRule) match(root *pb.Span, env string) bool {
+	if !matchGlob(r.service, root.Service) || !matchGlob(r.env, env) ||
+		!matchGlob(r.resource, root.Resource) || !matchGlob(r.operation, root.Name) {
+		return false
+	}
+	for k, pattern := range r.tags {
+		if v, ok := root.Meta[k]; ok && matchGlob(pattern, v) {
+			continue
+		}
+		if v, ok := root.Metrics[k]; ok && matchGlob(pattern, strconv.FormatFloat(v, 'f', -1, 64)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// compileGlob compiles a glob pattern where "*" matches any string and "?" any
+// single character. It returns nil for the patterns matching everything.
+func compileGlob(pattern string) *regexp.Regexp {
+	if pattern == "" || pattern == "*" {
+		return nil
+	}
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\*`, ".*")
+	re = strings.ReplaceAll(re, `\?`, ".")
+	return regexp.MustCompile("^(?s:" + re + ")$")
+}
+
+func matchGlob(re *regexp.Regexp, s string) bool {
+	return re == nil || re.MatchString(s)
+}
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/util/pointer"
+)
+
+func ruleTestSpan(traceID uint64, service, resource string) *pb.Span {
+	return &pb.Span{
+		TraceID:  traceID,
+		Service:  service,
+		Name:     "http.request",
+		Resource: resource,
+		Meta:     map[string]string{"http.method": "GET"},
+		Metrics:  map[string]float64{"http.status_code": 200},
+	}
+}
+
+func TestRuleSamplerMatch(t *testing.T) {
+	for name, tt := range map[string]struct {
+		rule    config.SamplingRule
+		env     string
+		matched bool
+	}{
+		"empty":              {rule: config.SamplingRule{}, matched: true},
+		"service":            {rule: config.SamplingRule{Service: "web"}, matched: true},
+		"other-service":      {rule: config.SamplingRule{Service: "db"}},
+		"service-glob":       {rule: config.SamplingRule{Service: "w?b"}, matched: true},
+		"resource-glob":      {rule: config.SamplingRule{Resource: "GET /health*"}, matched: true},
+		"resource-no-prefix": {rule: config.SamplingRule{Resource: "/health*"}},
+		"regexp-chars":       {rule: config.SamplingRule{Resource: "GET /health.+"}},
+		"env":                {rule: config.SamplingRule{Env: "prod*"}, env: "production", matched: true},
+		"other-env":          {rule: config.SamplingRule{Env: "prod*"}, env: "staging"},
+		"operation":          {rule: config.SamplingRule{OperationName: "http.*"}, matched: true},
+		"meta":               {rule: config.SamplingRule{Tags: map[string]string{"http.method": "GET"}}, matched: true},
+		"metric":             {rule: config.SamplingRule{Tags: map[string]string{"http.status_code": "2*"}}, matched: true},
+		"other-tag-value":    {rule: config.SamplingRule{Tags: map[string]string{"http.method": "POST"}}},
+		"missing-tag":        {rule: config.SamplingRule{Tags: map[string]string{"http.route": "*"}}},
+		"all": {
+			rule: config.SamplingRule{
+				Service:       "web",
+				Env:           "prod",
+				Resource:      "GET /health/*",
+				OperationName: "http.request",
+				Tags:          map[string]string{"http.method": "GET"},
+			},
+			env:     "prod",
+			matched: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tt.rule.SampleRate = pointer.Ptr(1.0)
+			s := NewRuleSampler(&config.AgentConfig{SamplingRules: []*config.SamplingRule{&tt.rule}})
+			keep, matched := s.Sample(time.Now(), ruleTestSpan(1, "web", "GET /health/live"), tt.env)
+			assert.Equal(t, tt.matched, matched)
+			assert.Equal(t, tt.matched, keep)
+		})
+	}
+}
+
+func TestRuleSamplerSampleRate(t *testing.T) {
+	s := NewRuleSampler(&config.AgentConfig{SamplingRules: []*config.SamplingRule{
+		{Resource: "GET /health", SampleRate: pointer.Ptr(0.0)},
+		{Service: "web", SampleRate: pointer.Ptr(0.5)},
+	}})
+
+	// the first matching rule decides
+	keep, matched := s.Sample(time.Now(), ruleTestSpan(1, "web", "GET /health"), "")
+	assert.True(t, matched)
+	assert.False(t, keep)
+
+	kept := 0
+	for i := uint64(0); i < 1000; i++ {
+		root := ruleTestSpan(i, "web", "GET /users")
+		keep, matched := s.Sample(time.Now(), root, "")
+		assert.True(t, matched)
+		if keep {
+			kept++
+			assert.Equal(t, 0.5, root.Metrics[agentRuleRateKey])
+		}
+	}
+	assert.InDelta(t, 500, kept, 100)
+
+	_, matched = s.Sample(time.Now(), ruleTestSpan(1, "db", "SELECT"), "")
+	assert.False(t, matched)
+}
+
+func TestRuleSamplerTargetTPS(t *testing.T) {
+	s := NewRuleSampler(&config.AgentConfig{SamplingRules: []*config.SamplingRule{
+		{Service: "web", TargetTPS: pointer.Ptr(10.0)},
+	}})
+
+	now := time.Now()
+	kept := 0
+	for i := uint64(0); i < 100; i++ {
+		if keep, _ := s.Sample(now, ruleTestSpan(i, "web", "GET /"), ""); keep {
+			kept++
+		}
+	}
+	assert.Equal(t, 10, kept)
+
+	keep, _ := s.Sample(now.Add(time.Second), ruleTestSpan(1, "web", "GET /"), "")
+	assert.True(t, keep)
+}
+
+func TestRuleSamplerUpdateRules(t *testing.T) {
+	s := NewRuleSampler(&config.AgentConfig{SamplingRules: []*config.SamplingRule{
+		{Service: "web", SampleRate: pointer.Ptr(0.0)},
+	}})
+
+	// invalid rules are not applied
+	for _, rule := range []*config.SamplingRule{
+		{Service: "web"},
+		{Service: "web", SampleRate: pointer.Ptr(0.5), TargetTPS: pointer.Ptr(10.0)},
+		{Service: "web", SampleRate: pointer.Ptr(1.5)},
+		{Service: "web", TargetTPS: pointer.Ptr(-1.0)},
+	} {
+		assert.Error(t, s.UpdateRules([]*config.SamplingRule{rule}))
+	}
+	keep, matched := s.Sample(time.Now(), ruleTestSpan(1, "web", "GET /"), "")
+	assert.True(t, matched)
+	assert.False(t, keep)
+
+	assert.NoError(t, s.UpdateRules([]*config.SamplingRule{{Service: "web", SampleRate: pointer.Ptr(1.0)}}))
+	keep, _ = s.Sample(time.Now(), ruleTestSpan(1, "web", "GET /"), "")
+	assert.True(t, keep)
+
+	assert.NoError(t, s.UpdateRules(nil))
+	_, matched = s.Sample(time.Now(), ruleTestSpan(1, "web", "GET /"), "")
+	assert.False(t, matched)
+}
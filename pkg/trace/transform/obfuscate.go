@@ -18,6 +18,10 @@ const (
 	TagValkeyRawCommand = "valkey.raw_command"
 	// TagMemcachedCommand represents a memcached command tag
 	TagMemcachedCommand = "memcached.command"
+	// TagGraphQLQuery represents a GraphQL query tag
+	TagGraphQLQuery = "graphql.query"
+	// TagGraphQLSource represents a GraphQL query tag, as set by some tracers
+	TagGraphQLSource = "graphql.source"
 	// TagMongoDBQuery represents a MongoDB query tag
 	TagMongoDBQuery = "mongodb.query"
 	// TagElasticBody represents an Elasticsearch body tag